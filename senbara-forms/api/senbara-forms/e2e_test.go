@@ -0,0 +1,2466 @@
+package senbaraForms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/controllers"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/devauth"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/persisters"
+)
+
+const (
+	testClientID   = "senbara-forms-test"
+	testEmail      = "jean.doe@example.com"
+	testOtherEmail = "john.doe@example.com"
+)
+
+var (
+	idRegex     = regexp.MustCompile(`[?&]id=(\d+)`)
+	secretRegex = regexp.MustCompile(`<pre><code>([^<]+)</code></pre>`)
+	tokenRegex  = regexp.MustCompile(`name="token" value="([^"]+)"`)
+)
+
+// testServer serves Senbara Forms with an in-memory persister and signs users
+// in with the development OIDC provider, so that the tests go through the same
+// flows as a browser
+type testServer struct {
+	url       string
+	persister *persisters.MemoryPersister
+
+	// The test server's certificate is only trusted by its own client
+	transport http.RoundTripper
+
+	patternsLock sync.Mutex
+	patterns     map[string]struct{}
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	issuerServer := httptest.NewUnstartedServer(nil)
+	t.Cleanup(issuerServer.Close)
+
+	appServer := httptest.NewUnstartedServer(nil)
+	t.Cleanup(appServer.Close)
+
+	issuerURL := "http://" + issuerServer.Listener.Addr().String()
+	appURL := "https://" + appServer.Listener.Addr().String()
+
+	issuer, err := devauth.NewIssuer(issuerURL, testClientID, appURL+"/authorize", []string{testEmail, testOtherEmail})
+	if err != nil {
+		t.Fatalf("could not create OIDC provider: %v", err)
+	}
+
+	issuerServer.Config.Handler = issuer
+	issuerServer.Start()
+
+	persister := persisters.NewMemoryPersister()
+	if err := persister.Init(); err != nil {
+		t.Fatalf("could not initialize persister: %v", err)
+	}
+
+	c := controllers.NewController(
+		persister,
+
+		issuerURL,
+		testClientID,
+		appURL+"/authorize",
+
+		"https://example.com/privacy",
+		"https://example.com/imprint",
+	)
+
+	if err := c.Init(context.Background()); err != nil {
+		t.Fatalf("could not initialize controller: %v", err)
+	}
+
+	s := &testServer{
+		url:       appURL,
+		persister: persister,
+
+		patterns: map[string]struct{}{},
+	}
+
+	// The mux is only used to find out which route a request matches
+	mux := newSenbaraFormsMux(c)
+	appServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := mux.Handler(r)
+
+		s.patternsLock.Lock()
+		s.patterns[pattern] = struct{}{}
+		s.patternsLock.Unlock()
+
+		SenbaraFormsHandler(w, r, c)
+	})
+	appServer.StartTLS()
+
+	s.transport = appServer.Client().Transport
+
+	return s
+}
+
+type testUser struct {
+	t      *testing.T
+	server *testServer
+	client *http.Client
+	email  string
+}
+
+// signIn signs in with the development OIDC provider and returns a user with
+// a new session
+func (s *testServer) signIn(t *testing.T, email string) *testUser {
+	t.Helper()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("could not create cookie jar: %v", err)
+	}
+
+	u := &testUser{
+		t:      t,
+		server: s,
+		client: &http.Client{
+			Transport: s.transport,
+			Jar:       jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		email: email,
+	}
+
+	u.signIn()
+
+	return u
+}
+
+func (u *testUser) signIn() {
+	u.t.Helper()
+
+	res := u.do(http.MethodGet, "/contacts", nil, nil, http.StatusFound)
+
+	authURL, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		u.t.Fatalf("could not parse authorization URL: %v", err)
+	}
+
+	q := authURL.Query()
+	q.Set("email", u.email)
+
+	res, err = u.client.PostForm((&url.URL{Scheme: authURL.Scheme, Host: authURL.Host, Path: authURL.Path}).String(), q)
+	if err != nil {
+		u.t.Fatalf("could not sign in with OIDC provider: %v", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		u.t.Fatalf("OIDC provider returned status %v, expected %v", res.StatusCode, http.StatusFound)
+	}
+
+	res, err = u.client.Get(res.Header.Get("Location"))
+	if err != nil {
+		u.t.Fatalf("could not complete sign in: %v", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		u.t.Fatalf("sign in returned status %v, expected %v", res.StatusCode, http.StatusOK)
+	}
+}
+
+func (u *testUser) csrfToken() string {
+	u.t.Helper()
+
+	appURL, err := url.Parse(u.server.url)
+	if err != nil {
+		u.t.Fatalf("could not parse server URL: %v", err)
+	}
+
+	for _, cookie := range u.client.Jar.Cookies(appURL) {
+		if cookie.Name == "csrf_token" {
+			return cookie.Value
+		}
+	}
+
+	// Requests without a CSRF token get one
+	u.do(http.MethodGet, "/static/main.css", nil, nil, http.StatusOK)
+
+	for _, cookie := range u.client.Jar.Cookies(appURL) {
+		if cookie.Name == "csrf_token" {
+			return cookie.Value
+		}
+	}
+
+	u.t.Fatal("server didn't set a CSRF token")
+
+	return ""
+}
+
+// do sends a request to the server and fails the test if it doesn't return
+// `status`. The response body is read into `res.Body` so that it can be read
+// after the connection was closed.
+func (u *testUser) do(method, path string, body io.Reader, header http.Header, status int) *http.Response {
+	u.t.Helper()
+
+	req, err := http.NewRequest(method, u.server.url+path, body)
+	if err != nil {
+		u.t.Fatalf("could not create request: %v", err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := u.client.Do(req)
+	if err != nil {
+		u.t.Fatalf("could not send request: %v", err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		u.t.Fatalf("could not read response: %v", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(b))
+
+	if res.StatusCode != status {
+		u.t.Fatalf("%v %v returned status %v, expected %v: %s", method, path, res.StatusCode, status, b)
+	}
+
+	return res
+}
+
+func (u *testUser) get(path string) string {
+	u.t.Helper()
+
+	return readBody(u.t, u.do(http.MethodGet, path, nil, nil, http.StatusOK))
+}
+
+// post submits a form like a browser would, including the CSRF token
+func (u *testUser) post(path string, form url.Values, status int) *http.Response {
+	u.t.Helper()
+
+	if form == nil {
+		form = url.Values{}
+	}
+	form.Set("csrf_token", u.csrfToken())
+
+	return u.do(http.MethodPost, path, strings.NewReader(form.Encode()), http.Header{
+		"Content-Type": {"application/x-www-form-urlencoded"},
+	}, status)
+}
+
+// upload submits a multipart form with a single file like a browser would
+func (u *testUser) upload(path, field string, content []byte, status int) *http.Response {
+	u.t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writer.WriteField("csrf_token", u.csrfToken()); err != nil {
+		u.t.Fatalf("could not write form: %v", err)
+	}
+
+	file, err := writer.CreateFormFile(field, "upload")
+	if err != nil {
+		u.t.Fatalf("could not write form: %v", err)
+	}
+
+	if _, err := file.Write(content); err != nil {
+		u.t.Fatalf("could not write form: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		u.t.Fatalf("could not write form: %v", err)
+	}
+
+	return u.do(http.MethodPost, path, &body, http.Header{
+		"Content-Type": {writer.FormDataContentType()},
+	}, status)
+}
+
+// api sends a JSON request with the given authorization and decodes the
+// response into `v` if it isn't nil
+func (u *testUser) api(method, path, authorization string, body any, status int, v any) {
+	u.t.Helper()
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			u.t.Fatalf("could not encode request: %v", err)
+		}
+
+		reqBody = bytes.NewReader(b)
+	}
+
+	res := u.do(method, path, reqBody, http.Header{
+		"Authorization": {authorization},
+		"Content-Type":  {"application/json"},
+	}, status)
+
+	if v == nil {
+		return
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		u.t.Fatalf("could not decode response of %v %v: %v", method, path, err)
+	}
+}
+
+func readBody(t *testing.T, res *http.Response) string {
+	t.Helper()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("could not read response: %v", err)
+	}
+
+	return string(b)
+}
+
+func basicAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+func mustAtoi(t *testing.T, s string) int32 {
+	t.Helper()
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatalf("could not parse ID: %v", err)
+	}
+
+	return int32(v)
+}
+
+func redirectedID(t *testing.T, res *http.Response) string {
+	t.Helper()
+
+	match := idRegex.FindStringSubmatch(res.Header.Get("Location"))
+	if match == nil {
+		t.Fatalf("expected a redirect to a record, got %q", res.Header.Get("Location"))
+	}
+
+	return match[1]
+}
+
+func findSecret(t *testing.T, body string) string {
+	t.Helper()
+
+	match := secretRegex.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatal("response doesn't contain a new secret")
+	}
+
+	return html.UnescapeString(match[1])
+}
+
+func assertContains(t *testing.T, body, substr string) {
+	t.Helper()
+
+	if !strings.Contains(body, html.EscapeString(substr)) {
+		t.Errorf("expected response to contain %q", substr)
+	}
+}
+
+func assertNotContains(t *testing.T, body, substr string) {
+	t.Helper()
+
+	if strings.Contains(body, html.EscapeString(substr)) {
+		t.Errorf("expected response not to contain %q", substr)
+	}
+}
+
+// createPersonalAccessToken creates a personal access token with the scopes and
+// returns it as an `Authorization` header
+func (u *testUser) createPersonalAccessToken(name string, scopes ...string) string {
+	u.t.Helper()
+
+	return "Bearer " + findSecret(u.t, readBody(u.t, u.post("/personalaccesstokens", url.Values{
+		"name":   {name},
+		"scopes": scopes,
+	}, http.StatusOK)))
+}
+
+func (u *testUser) createContact(firstName, lastName, email string) string {
+	u.t.Helper()
+
+	return redirectedID(u.t, u.post("/contacts", url.Values{
+		"first_name": {firstName},
+		"last_name":  {lastName},
+		"email":      {email},
+		"nickname":   {""},
+		"pronouns":   {"they/them"},
+	}, http.StatusFound))
+}
+
+// nullInt32 returns the value of an exported `sql.NullInt32`
+func nullInt32(v any) float64 {
+	value, _ := v.(map[string]any)["Int32"].(float64)
+
+	return value
+}
+
+// userDataRecords returns the records of a user data export without the IDs,
+// which change on every import
+func userDataRecords(t *testing.T, export string) []string {
+	t.Helper()
+
+	contactNames := map[float64]string{}
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(export), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("could not parse user data record: %v", err)
+		}
+
+		if record["entityName"] == "contact" {
+			contactNames[record["id"].(float64)] = fmt.Sprint(record["firstName"], " ", record["lastName"])
+		}
+
+		records = append(records, record)
+	}
+
+	keys := []string{}
+	for _, record := range records {
+		switch record["entityName"] {
+		case "contact":
+			keys = append(keys, fmt.Sprint("contact ", record["firstName"], " ", record["lastName"], " ", record["emails"], " ", record["phones"], " ", record["addresses"], " ", record["websites"], " ", record["birthday"], " ", record["notes"], " ", record["tags"]))
+
+		case "debt":
+			keys = append(keys, fmt.Sprint("debt ", record["amount"], " ", record["currency"], " ", record["description"], " ", record["date"], " ", record["payments"], " for ", contactNames[nullInt32(record["contactId"])]))
+
+		case "activity":
+			participants := []string{}
+			for _, contactID := range record["contactIds"].([]any) {
+				participants = append(participants, contactNames[contactID.(float64)])
+			}
+			sort.Strings(participants)
+
+			keys = append(keys, fmt.Sprint("activity ", record["name"], " ", record["date"], " ", record["description"], " with ", strings.Join(participants, ", ")))
+
+		case "relationship":
+			keys = append(keys, fmt.Sprint("relationship ", contactNames[nullInt32(record["contactId"])], " ", record["type"], " ", record["label"], " ", record["reciprocal"], " ", contactNames[nullInt32(record["relatedContactId"])]))
+
+		case "group":
+			members := []string{}
+			for _, contactID := range record["contactIds"].([]any) {
+				members = append(members, contactNames[contactID.(float64)])
+			}
+			sort.Strings(members)
+
+			keys = append(keys, fmt.Sprint("group ", record["name"], " with ", strings.Join(members, ", ")))
+
+		case "journalEntry":
+			keys = append(keys, fmt.Sprint("journal entry ", record["title"], " ", record["body"], " ", record["rating"], " ", record["tags"]))
+
+		case "manifest":
+			keys = append(keys, fmt.Sprint("manifest ", record["counts"]))
+
+		default:
+			t.Errorf("unexpected entity name %v", record["entityName"])
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func TestEndToEnd(t *testing.T) {
+	s := newTestServer(t)
+
+	user := s.signIn(t, testEmail)
+	otherUser := s.signIn(t, testOtherEmail)
+
+	t.Run("Index", func(t *testing.T) {
+		user.t = t
+
+		if location := user.do(http.MethodGet, "/", nil, nil, http.StatusFound).Header.Get("Location"); location != "/contacts" {
+			t.Errorf("expected index to redirect to the contacts, got %q", location)
+		}
+
+		user.do(http.MethodGet, "/does-not-exist", nil, nil, http.StatusNotFound)
+
+		user.get("/static/main.css")
+		user.get("/code/")
+		user.get("/.well-known/openapi.json")
+	})
+
+	t.Run("CSRF", func(t *testing.T) {
+		user.t = t
+
+		user.do(http.MethodPost, "/journal", strings.NewReader(url.Values{
+			"title":  {"Forged"},
+			"body":   {"Forged"},
+			"rating": {"2"},
+		}.Encode()), http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+		}, http.StatusForbidden)
+
+		assertNotContains(t, user.get("/journal"), "Forged")
+	})
+
+	t.Run("Journal", func(t *testing.T) {
+		user.t = t
+		otherUser.t = t
+
+		user.get("/journal/add")
+
+		id := redirectedID(t, user.post("/journal", url.Values{
+			"title":  {"First day"},
+			"body":   {"Today was *good*."},
+			"rating": {"3"},
+		}, http.StatusFound))
+
+		assertContains(t, user.get("/journal"), "First day")
+		assertContains(t, user.get("/journal/view?id="+id), "First day")
+		assertContains(t, user.get("/journal/edit?id="+id), "First day")
+
+		assertNotContains(t, otherUser.get("/journal"), "First day")
+		otherUser.do(http.MethodGet, "/journal/view?id="+id, nil, nil, http.StatusInternalServerError)
+
+		user.post("/journal/update", url.Values{
+			"id":     {id},
+			"title":  {"Second day"},
+			"body":   {"Today was *fine*."},
+			"rating": {"2"},
+		}, http.StatusFound)
+
+		assertContains(t, user.get("/journal/view?id="+id), "Second day")
+
+		// Other users can neither change nor delete the entry
+		otherUser.post("/journal/update", url.Values{
+			"id":     {id},
+			"title":  {"Hijacked"},
+			"body":   {"Hijacked"},
+			"rating": {"1"},
+		}, http.StatusFound)
+		otherUser.post("/journal/delete", url.Values{"id": {id}}, http.StatusFound)
+
+		assertContains(t, user.get("/journal/view?id="+id), "Second day")
+
+		user.post("/journal/delete", url.Values{"id": {id}}, http.StatusFound)
+
+		assertNotContains(t, user.get("/journal"), "Second day")
+	})
+
+	t.Run("Contacts", func(t *testing.T) {
+		user.t = t
+		otherUser.t = t
+
+		user.get("/contacts/add")
+
+		id := user.createContact("Alex", "Doe", "alex@example.com")
+
+		assertContains(t, user.get("/contacts"), "Alex")
+		assertContains(t, user.get("/contacts/view?id="+id), "Alex")
+		assertContains(t, user.get("/contacts/edit?id="+id), "Alex")
+
+		assertNotContains(t, otherUser.get("/contacts"), "Alex")
+		otherUser.do(http.MethodGet, "/contacts/view?id="+id, nil, nil, http.StatusInternalServerError)
+
+		user.post("/contacts/update", url.Values{
+			"id":            {id},
+			"first_name":    {"Alexis"},
+			"last_name":     {"Doe"},
+			"email":         {"alexis@example.com", "alexis@work.example.com", ""},
+			"email_label":   {"home", "work", "other"},
+			"phone":         {"+49 151 123-456", ""},
+			"phone_label":   {"mobile", "other"},
+			"nickname":      {"Lex"},
+			"pronouns":      {"they/them"},
+			"birthday":      {"1990-02-03"},
+			"address":       {"1 Main Street", ""},
+			"address_label": {"home", "other"},
+			"website":       {"alexis.example.com", ""},
+			"website_label": {"work", "other"},
+			"notes":         {"Likes tea"},
+		}, http.StatusFound)
+
+		view := user.get("/contacts/view?id=" + id)
+		assertContains(t, view, "Alexis")
+		assertContains(t, view, "Likes tea")
+		assertContains(t, view, "alexis@work.example.com")
+		assertContains(t, view, "49151123456")
+		assertContains(t, view, "1 Main Street")
+		assertContains(t, view, "https://alexis.example.com")
+
+		edit := user.get("/contacts/edit?id=" + id)
+		assertContains(t, edit, "alexis@work.example.com")
+		assertContains(t, edit, "+49151123456")
+
+		// Phone numbers which aren't in E.164 format are rejected
+		user.post("/contacts/update", url.Values{
+			"id":          {id},
+			"first_name":  {"Alexis"},
+			"last_name":   {"Doe"},
+			"phone":       {"123"},
+			"phone_label": {"mobile"},
+			"pronouns":    {"they/them"},
+		}, http.StatusUnprocessableEntity)
+		assertContains(t, user.get("/contacts/view?id="+id), "49151123456")
+
+		card := user.get("/contacts/vcard?id=" + id)
+		assertContains(t, card, "BEGIN:VCARD")
+		assertContains(t, card, "Alexis")
+		assertContains(t, card, "alexis@work.example.com")
+		assertContains(t, card, "+49151123456")
+		assertContains(t, card, "https://alexis.example.com")
+
+		assertContains(t, user.get("/contacts/vcard"), "Alexis")
+
+		// Importing the exported vCard creates a copy of the contact
+		importedID := redirectedID(t, user.upload("/contacts/vcard", "vcard", []byte(card), http.StatusFound))
+		if importedID == id {
+			t.Fatal("expected the vCard import to create a new contact")
+		}
+
+		imported := user.get("/contacts/view?id=" + importedID)
+		assertContains(t, imported, "Alexis")
+		assertContains(t, imported, "alexis@work.example.com")
+		assertContains(t, imported, "49151123456")
+		assertContains(t, imported, "1 Main Street")
+
+		// Birthdays without a year are dropped instead of being stored as year 0
+		yearlessID := redirectedID(t, user.upload("/contacts/vcard", "vcard", []byte("BEGIN:VCARD\r\nVERSION:4.0\r\nN:Doe;Yael;;;\r\nFN:Yael Doe\r\nBDAY:--0412\r\nEND:VCARD\r\n"), http.StatusFound))
+		assertNotContains(t, user.get("/contacts/vcard?id="+yearlessID), "BDAY")
+		assertNotContains(t, user.get("/contacts/edit?id="+yearlessID), "04-12")
+		user.post("/contacts/delete", url.Values{"id": {yearlessID}}, http.StatusFound)
+
+		otherUser.post("/contacts/delete", url.Values{"id": {id}}, http.StatusFound)
+		user.get("/contacts/view?id=" + id)
+
+		user.post("/contacts/delete", url.Values{"id": {id}}, http.StatusFound)
+		user.post("/contacts/delete", url.Values{"id": {importedID}}, http.StatusFound)
+
+		assertNotContains(t, user.get("/contacts"), "Alexis")
+	})
+
+	t.Run("Relationships", func(t *testing.T) {
+		user.t = t
+		otherUser.t = t
+
+		parentID := user.createContact("Robin", "Doe", "robin@example.com")
+		childID := user.createContact("Kit", "Doe", "kit@example.com")
+		otherContactID := otherUser.createContact("Lou", "Doe", "lou@example.com")
+
+		assertContains(t, user.get("/contacts/view?id="+childID), "No relationships with other contacts yet.")
+		assertContains(t, user.get("/contacts/graph"), "No relationships yet.")
+
+		user.post("/contacts/relationships", url.Values{
+			"contact_id":         {childID},
+			"related_contact_id": {parentID},
+			"type":               {"child_of"},
+			"reciprocal":         {"1"},
+		}, http.StatusFound)
+
+		// Relationships are shown on both contacts
+		child := user.get("/contacts/view?id=" + childID)
+		assertContains(t, child, "Child of")
+		assertContains(t, child, "Robin Doe")
+
+		parent := user.get("/contacts/view?id=" + parentID)
+		assertContains(t, parent, "Parent of")
+		assertContains(t, parent, "Kit Doe")
+		assertNotContains(t, parent, "(one-way)")
+
+		// Relationships managed from the edit page return to it
+		if location := user.post("/contacts/relationships", url.Values{
+			"contact_id":         {parentID},
+			"related_contact_id": {childID},
+			"type":               {"custom"},
+			"label":              {"Climbs with"},
+			"return":             {"edit"},
+		}, http.StatusFound).Header.Get("Location"); location != "/contacts/edit?id="+parentID {
+			t.Errorf("expected a redirect to the edit page, got %q", location)
+		}
+
+		edit := user.get("/contacts/edit?id=" + parentID)
+		assertContains(t, edit, "Climbs with")
+		assertContains(t, edit, "(one-way)")
+
+		// Unknown types, custom relationships without a label and relationships of
+		// a contact to itself are rejected
+		user.post("/contacts/relationships", url.Values{
+			"contact_id":         {childID},
+			"related_contact_id": {parentID},
+			"type":               {"sibling_of"},
+		}, http.StatusUnprocessableEntity)
+		user.post("/contacts/relationships", url.Values{
+			"contact_id":         {childID},
+			"related_contact_id": {parentID},
+			"type":               {"custom"},
+			"label":              {" "},
+		}, http.StatusUnprocessableEntity)
+		user.post("/contacts/relationships", url.Values{
+			"contact_id":         {childID},
+			"related_contact_id": {childID},
+			"type":               {"spouse"},
+		}, http.StatusUnprocessableEntity)
+
+		// Both contacts must belong to the user
+		user.post("/contacts/relationships", url.Values{
+			"contact_id":         {childID},
+			"related_contact_id": {otherContactID},
+			"type":               {"works_with"},
+		}, http.StatusInternalServerError)
+		otherUser.post("/contacts/relationships", url.Values{
+			"contact_id":         {childID},
+			"related_contact_id": {parentID},
+			"type":               {"works_with"},
+		}, http.StatusInternalServerError)
+
+		graph := user.get("/contacts/graph")
+		if !strings.Contains(graph, "<svg") || !strings.Contains(graph, "marker-end") {
+			t.Error("expected the graph to contain an SVG with a one-way relationship")
+		}
+		assertContains(t, graph, "Child of / Parent of")
+		assertContains(t, graph, "Climbs with")
+		assertContains(t, graph, "Kit Doe")
+		assertNotContains(t, otherUser.get("/contacts/graph"), "Kit Doe")
+
+		relationships, err := s.persister.GetContactRelationships(context.Background(), mustAtoi(t, childID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get relationships: %v", err)
+		}
+
+		if len(relationships) != 2 {
+			t.Fatalf("expected two relationships, got %v", len(relationships))
+		}
+		relationshipID := strconv.Itoa(int(relationships[0].ID))
+
+		otherUser.post("/contacts/relationships/delete", url.Values{"id": {relationshipID}, "contact_id": {childID}}, http.StatusFound)
+		user.post("/contacts/relationships/delete", url.Values{"id": {relationshipID}, "contact_id": {childID}}, http.StatusFound)
+
+		relationships, err = s.persister.GetContactRelationships(context.Background(), mustAtoi(t, childID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get relationships: %v", err)
+		}
+
+		if len(relationships) != 1 || relationships[0].Label != "Climbs with" {
+			t.Fatalf("expected only the custom relationship to be left, got %v", relationships)
+		}
+
+		// Deleting a contact deletes its relationships
+		user.post("/contacts/delete", url.Values{"id": {parentID}}, http.StatusFound)
+		assertNotContains(t, user.get("/contacts/view?id="+childID), "Climbs with")
+
+		user.post("/contacts/delete", url.Values{"id": {childID}}, http.StatusFound)
+		otherUser.post("/contacts/delete", url.Values{"id": {otherContactID}}, http.StatusFound)
+	})
+
+	t.Run("Debts", func(t *testing.T) {
+		user.t = t
+		otherUser.t = t
+
+		contactID := user.createContact("Sam", "Doe", "sam@example.com")
+
+		user.get("/debts/add?id=" + contactID)
+
+		user.post("/debts", url.Values{
+			"contact_id":  {contactID},
+			"you_owe":     {"1"},
+			"amount":      {"12.5"},
+			"currency":    {"EUR"},
+			"description": {"Lunch"},
+		}, http.StatusFound)
+
+		// Debts can only be added to the user's own contacts
+		otherUser.post("/debts", url.Values{
+			"contact_id":  {contactID},
+			"you_owe":     {"1"},
+			"amount":      {"100"},
+			"currency":    {"EUR"},
+			"description": {"Forged"},
+		}, http.StatusInternalServerError)
+
+		// Currencies must be ISO 4217 codes, and amounts can't be more precise
+		// than the minor unit of their currency
+		for _, invalid := range []url.Values{
+			{"amount": {"12.5"}, "currency": {"Euro"}},
+			{"amount": {"12.505"}, "currency": {"EUR"}},
+			{"amount": {"12.5"}, "currency": {"JPY"}},
+			{"amount": {"1e3"}, "currency": {"EUR"}},
+		} {
+			invalid.Set("contact_id", contactID)
+			invalid.Set("you_owe", "1")
+
+			user.post("/debts", invalid, http.StatusUnprocessableEntity)
+		}
+
+		debts, err := s.persister.GetDebts(context.Background(), mustAtoi(t, contactID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get debts: %v", err)
+		}
+
+		if len(debts) != 1 {
+			t.Fatalf("expected one debt, got %v", len(debts))
+		}
+		debtID := strconv.Itoa(int(debts[0].ID))
+
+		assertContains(t, user.get("/contacts/view?id="+contactID), "Lunch")
+		assertContains(t, user.get("/debts/edit?id="+debtID+"&contact_id="+contactID), "Lunch")
+
+		user.post("/debts/update", url.Values{
+			"id":          {debtID},
+			"contact_id":  {contactID},
+			"you_owe":     {"0"},
+			"amount":      {"20"},
+			"currency":    {"USD"},
+			"description": {"Dinner"},
+			"date":        {"2024-03-01"},
+		}, http.StatusFound)
+
+		assertContains(t, user.get("/contacts/view?id="+contactID), "Dinner")
+		assertContains(t, user.get("/contacts/view?id="+contactID), "Balance: Sam owes you 20.00 USD")
+
+		otherUser.post("/debts/settle", url.Values{"id": {debtID}, "contact_id": {contactID}}, http.StatusInternalServerError)
+		assertContains(t, user.get("/contacts/view?id="+contactID), "Dinner")
+
+		// Debts can be paid back in parts, but not by more than what is left
+		for _, amount := range []string{"0", "-5", "20.01", "many"} {
+			user.post("/debts/settle", url.Values{"id": {debtID}, "contact_id": {contactID}, "amount": {amount}}, http.StatusUnprocessableEntity)
+		}
+		for _, date := range []string{"tomorrow", "2024-02-29"} {
+			user.post("/debts/settle", url.Values{"id": {debtID}, "contact_id": {contactID}, "amount": {"5"}, "date": {date}}, http.StatusUnprocessableEntity)
+		}
+
+		user.post("/debts/settle", url.Values{
+			"id":          {debtID},
+			"contact_id":  {contactID},
+			"amount":      {"7.5"},
+			"date":        {"2024-03-04"},
+			"description": {"Cash"},
+		}, http.StatusFound)
+
+		contact := user.get("/contacts/view?id=" + contactID)
+		assertContains(t, contact, "Dinner")
+		assertContains(t, contact, "12.50 USD left")
+		assertContains(t, contact, "Balance: Sam owes you 12.50 USD")
+
+		// Debts can't be reduced below what has been paid back or moved after
+		// their payments
+		user.post("/debts/update", url.Values{
+			"id":          {debtID},
+			"contact_id":  {contactID},
+			"you_owe":     {"0"},
+			"amount":      {"5"},
+			"currency":    {"USD"},
+			"description": {"Dinner"},
+		}, http.StatusUnprocessableEntity)
+		user.post("/debts/update", url.Values{
+			"id":          {debtID},
+			"contact_id":  {contactID},
+			"you_owe":     {"0"},
+			"amount":      {"20"},
+			"currency":    {"USD"},
+			"description": {"Dinner"},
+			"date":        {"2024-03-05"},
+		}, http.StatusUnprocessableEntity)
+
+		user.post("/debts/settle", url.Values{"id": {debtID}, "contact_id": {contactID}}, http.StatusFound)
+		assertNotContains(t, user.get("/contacts/view?id="+contactID), "Dinner")
+		assertNotContains(t, user.get("/contacts/view?id="+contactID), "Balance")
+
+		// Settled debts can't be paid back again
+		user.post("/debts/settle", url.Values{"id": {debtID}, "contact_id": {contactID}}, http.StatusUnprocessableEntity)
+
+		// Settled debts and all payments stay in the history with the running
+		// balance
+		history := user.get("/contacts/view?id=" + contactID + "&debts=history")
+		assertContains(t, history, "Settled debts")
+		assertContains(t, history, "2024-03-01: Debt of 20.00 USD (Dinner), balance 20.00 USD")
+		assertContains(t, history, "2024-03-04: Payment of -7.50 USD (Cash), balance 12.50 USD")
+		assertContains(t, history, "Payment of -12.50 USD (Dinner), balance 0.00 USD")
+
+		debts, err = s.persister.GetDebts(context.Background(), mustAtoi(t, contactID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get debts: %v", err)
+		}
+
+		if len(debts) != 1 || debts[0].Paid != 2000 {
+			t.Fatalf("expected the settled debt to be kept, got %v", debts)
+		}
+
+		payments, err := s.persister.GetDebtPayments(context.Background(), mustAtoi(t, contactID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get debt payments: %v", err)
+		}
+
+		if len(payments) != 2 || payments[0].Amount != 750 || payments[1].Amount != 1250 {
+			t.Fatalf("expected a partial and a full payment, got %v", payments)
+		}
+
+		// Amounts are stored exactly in cents, so they add up without drifting,
+		// and are formatted for the user's language
+		for _, amount := range []string{"1234.1", "0.2"} {
+			user.post("/debts", url.Values{
+				"contact_id":  {contactID},
+				"you_owe":     {"0"},
+				"amount":      {amount},
+				"currency":    {"eur"},
+				"description": {"Gum"},
+			}, http.StatusFound)
+		}
+
+		assertContains(t, user.get("/contacts/view?id="+contactID), "Balance: Sam owes you 1,234.30 EUR")
+		assertContains(t, readBody(t, user.do(http.MethodGet, "/contacts/view?id="+contactID, nil, http.Header{"Accept-Language": {"de-DE"}}, http.StatusOK)), "Balance: Sam owes you 1.234,30 EUR")
+
+		user.post("/contacts/delete", url.Values{"id": {contactID}}, http.StatusFound)
+	})
+
+	t.Run("Activities", func(t *testing.T) {
+		user.t = t
+		otherUser.t = t
+
+		contactID := user.createContact("Kim", "Doe", "kim@example.com")
+
+		user.get("/activities/add?id=" + contactID)
+
+		user.post("/activities", url.Values{
+			"contact_id":  {contactID},
+			"name":        {"Hiking"},
+			"date":        {"2024-05-06"},
+			"description": {"Up the hill"},
+		}, http.StatusFound)
+
+		otherUser.post("/activities", url.Values{
+			"contact_id":  {contactID},
+			"name":        {"Forged"},
+			"date":        {"2024-05-06"},
+			"description": {"Forged"},
+		}, http.StatusInternalServerError)
+
+		activities, err := s.persister.GetActivities(context.Background(), mustAtoi(t, contactID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get activities: %v", err)
+		}
+
+		if len(activities) != 1 {
+			t.Fatalf("expected one activity, got %v", len(activities))
+		}
+		activityID := strconv.Itoa(int(activities[0].ID))
+
+		assertContains(t, user.get("/contacts/view?id="+contactID), "Hiking")
+		assertContains(t, user.get("/activities/view?id="+activityID+"&contact_id="+contactID), "Up the hill")
+		assertContains(t, user.get("/activities/edit?id="+activityID+"&contact_id="+contactID), "Hiking")
+
+		user.post("/activities/update", url.Values{
+			"id":          {activityID},
+			"contact_id":  {contactID},
+			"name":        {"Swimming"},
+			"date":        {"2024-05-07"},
+			"description": {"In the lake"},
+		}, http.StatusFound)
+
+		assertContains(t, user.get("/activities/view?id="+activityID+"&contact_id="+contactID), "In the lake")
+
+		otherUser.post("/activities/delete", url.Values{"id": {activityID}, "contact_id": {contactID}}, http.StatusFound)
+		assertContains(t, user.get("/contacts/view?id="+contactID), "Swimming")
+
+		user.post("/activities/delete", url.Values{"id": {activityID}, "contact_id": {contactID}}, http.StatusFound)
+		assertNotContains(t, user.get("/contacts/view?id="+contactID), "Swimming")
+
+		user.post("/contacts/delete", url.Values{"id": {contactID}}, http.StatusFound)
+	})
+
+	t.Run("SharedActivities", func(t *testing.T) {
+		user.t = t
+		otherUser.t = t
+
+		anaID := user.createContact("Ana", "Doe", "ana@example.com")
+		benID := user.createContact("Ben", "Doe", "ben@example.com")
+		caseyID := user.createContact("Casey", "Doe", "casey@example.com")
+		otherContactID := otherUser.createContact("Dee", "Doe", "dee@example.com")
+
+		assertContains(t, user.get("/activities/add?id="+anaID), "Ben Doe")
+
+		// Participants must be valid IDs of the user's own contacts
+		user.post("/activities", url.Values{
+			"contact_id":     {anaID},
+			"participant_id": {"many"},
+			"name":           {"Dinner"},
+			"date":           {"2024-05-08"},
+		}, http.StatusUnprocessableEntity)
+		user.post("/activities", url.Values{
+			"contact_id":     {anaID},
+			"participant_id": {otherContactID},
+			"name":           {"Forged"},
+			"date":           {"2024-05-08"},
+		}, http.StatusInternalServerError)
+
+		user.post("/activities", url.Values{
+			"contact_id":     {anaID},
+			"participant_id": {benID, anaID},
+			"name":           {"Dinner"},
+			"date":           {"2024-05-08"},
+			"description":    {"At the corner"},
+		}, http.StatusFound)
+
+		activities, err := s.persister.GetActivities(context.Background(), mustAtoi(t, benID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get activities: %v", err)
+		}
+
+		if len(activities) != 1 {
+			t.Fatalf("expected one shared activity, got %v", len(activities))
+		}
+		activityID := strconv.Itoa(int(activities[0].ID))
+
+		participants, err := s.persister.GetActivityParticipants(context.Background(), activities[0].ID, testEmail)
+		if err != nil {
+			t.Fatalf("could not get activity participants: %v", err)
+		}
+
+		if len(participants) != 2 || participants[0].FirstName != "Ana" || participants[1].FirstName != "Ben" {
+			t.Fatalf("expected Ana and Ben to participate, got %v", participants)
+		}
+
+		// The activity is shown on every participant's view
+		assertContains(t, user.get("/contacts/view?id="+anaID), "Dinner")
+		assertContains(t, user.get("/contacts/view?id="+benID), "Dinner")
+		assertNotContains(t, user.get("/contacts/view?id="+caseyID), "Dinner")
+		assertContains(t, user.get("/activities/view?id="+activityID+"&contact_id="+benID), "Ana Doe")
+		assertContains(t, user.get("/activities/edit?id="+activityID+"&contact_id="+anaID), "Casey Doe")
+
+		// Updating replaces the participants, but always keeps the contact
+		// the activity is edited from
+		user.post("/activities/update", url.Values{
+			"id":             {activityID},
+			"contact_id":     {anaID},
+			"participant_id": {caseyID},
+			"name":           {"Dinner"},
+			"date":           {"2024-05-08"},
+			"description":    {"At the corner"},
+		}, http.StatusFound)
+
+		assertNotContains(t, user.get("/contacts/view?id="+benID), "Dinner")
+		assertContains(t, user.get("/contacts/view?id="+caseyID), "Dinner")
+
+		// Updating without participants removes everyone else
+		user.post("/activities/update", url.Values{
+			"id":          {activityID},
+			"contact_id":  {caseyID},
+			"name":        {"Dinner"},
+			"date":        {"2024-05-08"},
+			"description": {"At the corner"},
+		}, http.StatusFound)
+		assertNotContains(t, user.get("/contacts/view?id="+anaID), "Dinner")
+
+		user.post("/activities/update", url.Values{
+			"id":             {activityID},
+			"contact_id":     {caseyID},
+			"participant_id": {anaID, benID},
+			"name":           {"Dinner"},
+			"date":           {"2024-05-08"},
+			"description":    {"At the corner"},
+		}, http.StatusFound)
+
+		// Shared activities are exported once with all attendees
+		password := findSecret(t, readBody(t, user.post("/apppasswords", url.Values{"name": {"Calendar"}}, http.StatusOK)))
+		davHeader := http.Header{
+			"Authorization": {"Basic " + basicAuth(testEmail, password)},
+			"Depth":         {"1"},
+		}
+
+		event := readBody(t, user.do(http.MethodGet, "/caldav/activities/"+activityID+".ics", nil, davHeader, http.StatusOK))
+		for _, email := range []string{"ana@example.com", "ben@example.com", "casey@example.com"} {
+			assertContains(t, event, email)
+		}
+
+		feedURL, err := url.Parse(findSecret(t, readBody(t, user.post("/calendar", nil, http.StatusOK))))
+		if err != nil {
+			t.Fatalf("could not parse feed URL: %v", err)
+		}
+
+		assertContains(t, user.get(feedURL.RequestURI()), `Dinner with Ana Doe\, Ben Doe\, Casey Doe`)
+		user.post("/calendar/delete", nil, http.StatusFound)
+
+		var activity struct {
+			ID         int32   `json:"id"`
+			ContactID  int32   `json:"contactId"`
+			ContactIDs []int32 `json:"contactIds"`
+		}
+		authorization := user.createPersonalAccessToken("Activities", "activities:read")
+		user.api(http.MethodGet, fmt.Sprintf("/api/v1/contacts/%v/activities/%v", benID, activityID), authorization, nil, http.StatusOK, &activity)
+		if fmt.Sprint(activity.ContactIDs) != fmt.Sprint([]int32{mustAtoi(t, anaID), mustAtoi(t, benID), mustAtoi(t, caseyID)}) {
+			t.Errorf("expected all participants in the API, got %v", activity.ContactIDs)
+		}
+
+		// Deleting a contact keeps the activity for the other participants
+		user.post("/contacts/delete", url.Values{"id": {anaID}}, http.StatusFound)
+		assertContains(t, user.get("/contacts/view?id="+benID), "Dinner")
+
+		otherUser.post("/activities/delete", url.Values{"id": {activityID}, "contact_id": {benID}}, http.StatusFound)
+		assertContains(t, user.get("/contacts/view?id="+caseyID), "Dinner")
+
+		// Deleting the activity removes it for everyone
+		user.post("/activities/delete", url.Values{"id": {activityID}, "contact_id": {benID}}, http.StatusFound)
+		assertNotContains(t, user.get("/contacts/view?id="+caseyID), "Dinner")
+
+		appPasswords, err := s.persister.GetAppPasswords(context.Background(), testEmail)
+		if err != nil || len(appPasswords) != 1 {
+			t.Fatalf("could not get app password: %v", err)
+		}
+
+		user.post("/apppasswords/delete", url.Values{"id": {strconv.Itoa(int(appPasswords[0].ID))}}, http.StatusFound)
+
+		personalAccessTokens, err := s.persister.GetPersonalAccessTokens(context.Background(), testEmail)
+		if err != nil || len(personalAccessTokens) != 1 {
+			t.Fatalf("could not get personal access token: %v", err)
+		}
+
+		user.post("/personalaccesstokens/delete", url.Values{"id": {strconv.Itoa(int(personalAccessTokens[0].ID))}}, http.StatusFound)
+		user.post("/contacts/delete", url.Values{"id": {benID}}, http.StatusFound)
+		user.post("/contacts/delete", url.Values{"id": {caseyID}}, http.StatusFound)
+		otherUser.post("/contacts/delete", url.Values{"id": {otherContactID}}, http.StatusFound)
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		user.t = t
+		otherUser.t = t
+
+		alexID := user.createContact("Alex", "Roe", "alex@example.com")
+		billieID := user.createContact("Billie", "Roe", "billie@example.com")
+		charlieID := user.createContact("Charlie", "Roe", "charlie@example.com")
+		otherContactID := otherUser.createContact("Lou", "Roe", "lou@example.com")
+
+		assertContains(t, user.get("/groups"), "No groups yet.")
+
+		user.post("/groups", url.Values{"name": {" "}}, http.StatusUnprocessableEntity)
+		groupID := redirectedID(t, user.post("/groups", url.Values{"name": {"Climbing crew"}}, http.StatusFound))
+
+		assertContains(t, user.get("/groups/view?id="+groupID), "No members yet.")
+		otherUser.do(http.MethodGet, "/groups/view?id="+groupID, nil, nil, http.StatusInternalServerError)
+
+		// Groups without members can't fan out
+		user.post("/groups/activities", url.Values{
+			"id":   {groupID},
+			"name": {"Bouldering"},
+			"date": {"2024-06-01"},
+		}, http.StatusUnprocessableEntity)
+
+		for _, contactID := range []string{alexID, billieID, charlieID} {
+			user.post("/groups/members", url.Values{"id": {groupID}, "contact_id": {contactID}}, http.StatusFound)
+		}
+
+		// Adding a member twice, another user's contact or a contact to another
+		// user's group doesn't do anything
+		user.post("/groups/members", url.Values{"id": {groupID}, "contact_id": {alexID}}, http.StatusFound)
+		user.post("/groups/members", url.Values{"id": {groupID}, "contact_id": {otherContactID}}, http.StatusFound)
+		otherUser.post("/groups/members", url.Values{"id": {groupID}, "contact_id": {otherContactID}}, http.StatusFound)
+
+		members, err := s.persister.GetContactGroupMembers(context.Background(), mustAtoi(t, groupID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get group members: %v", err)
+		}
+
+		if len(members) != 3 {
+			t.Fatalf("expected three members, got %v", members)
+		}
+
+		assertContains(t, user.get("/groups"), "3 members")
+
+		user.post("/groups/update", url.Values{"id": {groupID}, "name": {"Bouldering crew"}}, http.StatusFound)
+		otherUser.post("/groups/update", url.Values{"id": {groupID}, "name": {"Forged"}}, http.StatusFound)
+
+		group := user.get("/groups/view?id=" + groupID)
+		assertContains(t, group, "Bouldering crew")
+		assertContains(t, group, "Billie Roe")
+
+		// Activities are added to every member
+		assertContains(t, user.get("/groups/activities/add?id="+groupID), "Alex Roe, Billie Roe, Charlie Roe")
+
+		user.post("/groups/activities", url.Values{
+			"id":          {groupID},
+			"name":        {"Bouldering"},
+			"date":        {"2024-06-01"},
+			"description": {"At the gym"},
+		}, http.StatusFound)
+		otherUser.post("/groups/activities", url.Values{
+			"id":   {groupID},
+			"name": {"Forged"},
+			"date": {"2024-06-01"},
+		}, http.StatusUnprocessableEntity)
+
+		for _, contactID := range []string{alexID, billieID, charlieID} {
+			activities, err := s.persister.GetActivities(context.Background(), mustAtoi(t, contactID), testEmail)
+			if err != nil {
+				t.Fatalf("could not get activities: %v", err)
+			}
+
+			if len(activities) != 1 || activities[0].Name != "Bouldering" {
+				t.Errorf("expected the group activity for contact %v, got %v", contactID, activities)
+			}
+		}
+
+		// Debts are split evenly in cents, with the remainder going to the first
+		// members, or by relative shares
+		assertContains(t, user.get("/groups/debts/add?id="+groupID), "Share of Charlie Roe")
+
+		user.post("/groups/debts", url.Values{
+			"id":          {groupID},
+			"you_owe":     {"1"},
+			"amount":      {"10"},
+			"currency":    {"EUR"},
+			"description": {"Day passes"},
+			"split":       {"even"},
+		}, http.StatusFound)
+		user.post("/groups/debts", url.Values{
+			"id":                 {groupID},
+			"you_owe":            {"0"},
+			"amount":             {"9"},
+			"currency":           {"EUR"},
+			"description":        {"Rope"},
+			"split":              {"custom"},
+			"share_" + alexID:    {"2"},
+			"share_" + billieID:  {"1"},
+			"share_" + charlieID: {""},
+		}, http.StatusFound)
+
+		// Currencies without a minor unit are split into whole units, by decimal
+		// shares which are exact
+		user.post("/groups/debts", url.Values{
+			"id":                 {groupID},
+			"you_owe":            {"1"},
+			"amount":             {"100"},
+			"currency":           {"JPY"},
+			"description":        {"Chalk"},
+			"split":              {"custom"},
+			"share_" + alexID:    {"0.1"},
+			"share_" + billieID:  {"0.2"},
+			"share_" + charlieID: {"0.3"},
+		}, http.StatusFound)
+
+		for _, invalid := range []url.Values{
+			{"split": {"weird"}},
+			{"split": {"custom"}, "share_" + alexID: {"0"}},
+			{"split": {"custom"}, "share_" + alexID: {"-1"}, "share_" + billieID: {"2"}},
+			{"split": {"custom"}, "share_" + alexID: {"many"}},
+			{"split": {"custom"}, "share_" + alexID: {"1e3"}},
+		} {
+			invalid.Set("id", groupID)
+			invalid.Set("you_owe", "1")
+			invalid.Set("amount", "5")
+			invalid.Set("currency", "EUR")
+
+			user.post("/groups/debts", invalid, http.StatusUnprocessableEntity)
+		}
+
+		for contactID, expected := range map[string][]int64{
+			alexID:    {-334, -17, 600},
+			billieID:  {-333, -33, 300},
+			charlieID: {-333, -50},
+		} {
+			debts, err := s.persister.GetDebts(context.Background(), mustAtoi(t, contactID), testEmail)
+			if err != nil {
+				t.Fatalf("could not get debts: %v", err)
+			}
+
+			amounts := []int64{}
+			for _, debt := range debts {
+				amounts = append(amounts, debt.Amount)
+			}
+			slices.Sort(amounts)
+
+			if fmt.Sprint(amounts) != fmt.Sprint(expected) {
+				t.Errorf("expected debts of %v for contact %v, got %v", expected, contactID, amounts)
+			}
+		}
+
+		// Removing members and deleting contacts removes them from the group
+		otherUser.post("/groups/members/delete", url.Values{"id": {groupID}, "contact_id": {charlieID}}, http.StatusFound)
+		user.post("/groups/members/delete", url.Values{"id": {groupID}, "contact_id": {charlieID}}, http.StatusFound)
+		user.post("/contacts/delete", url.Values{"id": {billieID}}, http.StatusFound)
+
+		members, err = s.persister.GetContactGroupMembers(context.Background(), mustAtoi(t, groupID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get group members: %v", err)
+		}
+
+		if len(members) != 1 || members[0].FirstName != "Alex" {
+			t.Fatalf("expected only Alex to be left in the group, got %v", members)
+		}
+
+		otherUser.post("/groups/delete", url.Values{"id": {groupID}}, http.StatusFound)
+		assertContains(t, user.get("/groups"), "Bouldering crew")
+
+		user.post("/groups/delete", url.Values{"id": {groupID}}, http.StatusFound)
+		assertContains(t, user.get("/groups"), "No groups yet.")
+
+		// Deleting a group keeps its members
+		if _, err := s.persister.GetContact(context.Background(), mustAtoi(t, alexID), testEmail); err != nil {
+			t.Errorf("expected the members of a deleted group to be kept: %v", err)
+		}
+
+		user.post("/contacts/delete", url.Values{"id": {alexID}}, http.StatusFound)
+		user.post("/contacts/delete", url.Values{"id": {charlieID}}, http.StatusFound)
+		otherUser.post("/contacts/delete", url.Values{"id": {otherContactID}}, http.StatusFound)
+	})
+
+	t.Run("ListsPaginationAndFilters", func(t *testing.T) {
+		user.t = t
+
+		nextPageRegex := regexp.MustCompile(`href="([^"]+)">Next page</a>`)
+
+		// pages follows the next page links and returns the IDs on each page
+		pages := func(path string, linkRegex *regexp.Regexp) [][]string {
+			t.Helper()
+
+			pages := [][]string{}
+			for path != "" {
+				body := user.get(path)
+
+				ids := []string{}
+				for _, match := range linkRegex.FindAllStringSubmatch(body, -1) {
+					ids = append(ids, match[1])
+				}
+				pages = append(pages, ids)
+
+				path = ""
+				if match := nextPageRegex.FindStringSubmatch(body); match != nil {
+					path = html.UnescapeString(match[1])
+				}
+			}
+
+			return pages
+		}
+
+		contactLinkRegex := regexp.MustCompile(`<a href="/contacts/view\?id=(\d+)"`)
+		journalLinkRegex := regexp.MustCompile(`<a href="/journal/view\?id=(\d+)"`)
+
+		contactIDs := []string{}
+		for i := 0; i < 30; i++ {
+			contactIDs = append(contactIDs, user.createContact(fmt.Sprintf("Pat %02d", i), fmt.Sprintf("Doe %02d", 29-i), fmt.Sprintf("pat%v@example.com", i)))
+		}
+
+		contactPages := pages("/contacts", contactLinkRegex)
+		if len(contactPages) != 2 || len(contactPages[0]) != 25 || len(contactPages[1]) != 5 {
+			t.Fatalf("expected 30 contacts on two pages, got %v", contactPages)
+		}
+
+		if strings.Join(append(contactPages[0], contactPages[1]...), " ") != strings.Join(contactIDs, " ") {
+			t.Errorf("expected contacts to be sorted by first name, got %v", contactPages)
+		}
+
+		contactPages = pages("/contacts?sort=last_name", contactLinkRegex)
+		if first := contactPages[0][0]; first != contactIDs[29] {
+			t.Errorf("expected the contact with the first last name to be listed first, got %v", first)
+		}
+
+		user.post("/contacts/update", url.Values{
+			"id":         {contactIDs[3]},
+			"first_name": {"Pat 03"},
+			"last_name":  {"Doe 26"},
+			"email":      {"pat3@example.com"},
+			"nickname":   {""},
+			"pronouns":   {"they/them"},
+			"birthday":   {time.Now().Format("2006-01-02")},
+			"address":    {""},
+			"notes":      {""},
+		}, http.StatusFound)
+		user.post("/debts", url.Values{
+			"contact_id":  {contactIDs[5]},
+			"you_owe":     {"1"},
+			"amount":      {"2"},
+			"currency":    {"EUR"},
+			"description": {"Snacks"},
+		}, http.StatusFound)
+		user.post("/activities", url.Values{
+			"contact_id":  {contactIDs[7]},
+			"name":        {"Walk"},
+			"date":        {"2024-01-02"},
+			"description": {"Around the block"},
+		}, http.StatusFound)
+
+		if ids := pages("/contacts?birthday_this_month=1", contactLinkRegex); len(ids) != 1 || strings.Join(ids[0], " ") != contactIDs[3] {
+			t.Errorf("expected only the contact with a birthday this month, got %v", ids)
+		}
+
+		if ids := pages("/contacts?has_debts=1", contactLinkRegex); len(ids) != 1 || strings.Join(ids[0], " ") != contactIDs[5] {
+			t.Errorf("expected only the contact with debts, got %v", ids)
+		}
+
+		if ids := pages("/contacts?sort=last_contacted", contactLinkRegex); ids[0][0] != contactIDs[7] {
+			t.Errorf("expected the most recently contacted contact to be listed first, got %v", ids)
+		}
+
+		if ids := pages("/contacts?sort=birthday", contactLinkRegex); ids[0][0] != contactIDs[3] {
+			t.Errorf("expected the only contact with a birthday to be listed first, got %v", ids)
+		}
+
+		user.do(http.MethodGet, "/contacts?sort=age", nil, nil, http.StatusUnprocessableEntity)
+		user.do(http.MethodGet, "/contacts?after=invalid", nil, nil, http.StatusUnprocessableEntity)
+
+		for _, id := range contactIDs {
+			user.post("/contacts/delete", url.Values{"id": {id}}, http.StatusFound)
+		}
+
+		journalEntryIDs := []string{}
+		for i := 0; i < 27; i++ {
+			journalEntryIDs = append(journalEntryIDs, redirectedID(t, user.post("/journal", url.Values{
+				"title":  {fmt.Sprintf("Day %02d", i)},
+				"body":   {"Nothing special"},
+				"rating": {strconv.Itoa(i%3 + 1)},
+			}, http.StatusFound)))
+		}
+
+		// Entries created in the same second are ordered by their IDs
+		journalPages := pages("/journal?order=oldest", journalLinkRegex)
+		if len(journalPages) != 2 || strings.Join(append(journalPages[0], journalPages[1]...), " ") != strings.Join(journalEntryIDs, " ") {
+			t.Errorf("expected 27 journal entries on two pages, oldest first, got %v", journalPages)
+		}
+
+		journalPages = pages("/journal", journalLinkRegex)
+		if len(journalPages) != 2 || journalPages[0][0] != journalEntryIDs[26] {
+			t.Errorf("expected 27 journal entries on two pages, newest first, got %v", journalPages)
+		}
+
+		if ids := pages("/journal?rating=3", journalLinkRegex); len(ids) != 1 || len(ids[0]) != 9 {
+			t.Errorf("expected 9 great journal entries, got %v", ids)
+		}
+
+		today := time.Now().UTC().Format("2006-01-02")
+		if ids := pages("/journal?from="+today+"&to="+today, journalLinkRegex); len(ids) != 2 {
+			t.Errorf("expected the journal entries of today, got %v", ids)
+		}
+
+		if ids := pages("/journal?to=2000-01-01", journalLinkRegex); len(ids) != 1 || len(ids[0]) != 0 {
+			t.Errorf("expected no journal entries before 2000, got %v", ids)
+		}
+
+		user.do(http.MethodGet, "/journal?rating=4", nil, nil, http.StatusUnprocessableEntity)
+		user.do(http.MethodGet, "/journal?from=yesterday", nil, nil, http.StatusUnprocessableEntity)
+
+		for _, id := range journalEntryIDs {
+			user.post("/journal/delete", url.Values{"id": {id}}, http.StatusFound)
+		}
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		user.t = t
+
+		contactLinkRegex := regexp.MustCompile(`<a href="/contacts/view\?id=(\d+)"`)
+		journalLinkRegex := regexp.MustCompile(`<a href="/journal/view\?id=(\d+)"`)
+
+		ids := func(body string, linkRegex *regexp.Regexp) string {
+			t.Helper()
+
+			ids := []string{}
+			for _, match := range linkRegex.FindAllStringSubmatch(body, -1) {
+				ids = append(ids, match[1])
+			}
+
+			return strings.Join(ids, " ")
+		}
+
+		sibling := redirectedID(t, user.post("/contacts", url.Values{
+			"first_name": {"Sam"},
+			"last_name":  {"Doe"},
+			"email":      {"sam@example.com"},
+			"nickname":   {""},
+			"pronouns":   {"they/them"},
+			"tags":       {" Family ,Climbing  Club, family,,"},
+		}, http.StatusFound))
+		colleague := redirectedID(t, user.post("/contacts", url.Values{
+			"first_name": {"Kim"},
+			"last_name":  {"Doe"},
+			"email":      {"kim@example.com"},
+			"nickname":   {""},
+			"pronouns":   {"they/them"},
+			"tags":       {"work"},
+		}, http.StatusFound))
+
+		if body := user.get("/contacts/view?id=" + sibling); !strings.Contains(body, `<a href="/contacts?tag=climbing%20club">climbing club</a>, <a href="/contacts?tag=family">family</a>`) {
+			t.Error("expected the contact to link to its normalized tags")
+		}
+
+		if got := ids(user.get("/contacts?tag=family"), contactLinkRegex); got != sibling {
+			t.Errorf("expected only the contact tagged with family, got %v", got)
+		}
+
+		if got := ids(user.get("/contacts?tag=Climbing+Club"), contactLinkRegex); got != sibling {
+			t.Errorf("expected the tag filter to ignore case and whitespace, got %v", got)
+		}
+
+		body := user.get("/contacts")
+		assertContains(t, body, "Tags: climbing club, family")
+		if !strings.Contains(body, `<option value="work" >work</option>`) {
+			t.Error("expected the tag filter to list the tags")
+		}
+
+		// Tags are namespaced
+		otherUser.t = t
+		if strings.Contains(otherUser.get("/contacts"), `<option value="work"`) {
+			t.Error("expected the tag filter not to list the tags of other users")
+		}
+		if got := ids(otherUser.get("/contacts?tag=work"), contactLinkRegex); got != "" {
+			t.Errorf("expected other users not to see tagged contacts, got %v", got)
+		}
+
+		user.post("/contacts/update", url.Values{
+			"id":         {colleague},
+			"first_name": {"Kim"},
+			"last_name":  {"Doe"},
+			"email":      {"kim@example.com"},
+			"nickname":   {""},
+			"pronouns":   {"they/them"},
+			"birthday":   {""},
+			"address":    {""},
+			"notes":      {""},
+			"tags":       {"family"},
+		}, http.StatusFound)
+
+		if !strings.Contains(user.get("/contacts/edit?id="+colleague), `value="family"`) {
+			t.Error("expected the edit form to contain the contact's tags")
+		}
+
+		if got := ids(user.get("/contacts?tag=family"), contactLinkRegex); got != sibling+" "+colleague && got != colleague+" "+sibling {
+			t.Errorf("expected both contacts to be tagged with family, got %v", got)
+		}
+
+		// Tags which are no longer used are removed
+		if strings.Contains(user.get("/contacts"), `<option value="work"`) {
+			t.Error("expected unused tags to be removed")
+		}
+
+		journalEntry := redirectedID(t, user.post("/journal", url.Values{
+			"title":  {"Road trip"},
+			"body":   {"Drove to the coast"},
+			"rating": {"3"},
+			"tags":   {"travel"},
+		}, http.StatusFound))
+		untaggedJournalEntry := redirectedID(t, user.post("/journal", url.Values{
+			"title":  {"Checkup"},
+			"body":   {"All good"},
+			"rating": {"2"},
+		}, http.StatusFound))
+
+		if got := ids(user.get("/journal?tag=travel"), journalLinkRegex); got != journalEntry {
+			t.Errorf("expected only the journal entry tagged with travel, got %v", got)
+		}
+
+		user.post("/journal/update", url.Values{
+			"id":     {untaggedJournalEntry},
+			"title":  {"Checkup"},
+			"body":   {"All good"},
+			"rating": {"2"},
+			"tags":   {"health, family"},
+		}, http.StatusFound)
+
+		if !strings.Contains(user.get("/journal/view?id="+untaggedJournalEntry), `<a href="/journal?tag=family">family</a>, <a href="/journal?tag=health">health</a>`) {
+			t.Error("expected the journal entry to link to its tags")
+		}
+
+		if !strings.Contains(user.get("/journal/edit?id="+untaggedJournalEntry), `value="family, health"`) {
+			t.Error("expected the edit form to contain the journal entry's tags")
+		}
+
+		// Contacts and journal entries share their tags
+		if got := ids(user.get("/journal?tag=family"), journalLinkRegex); got != untaggedJournalEntry {
+			t.Errorf("expected only the journal entry tagged with family, got %v", got)
+		}
+
+		for _, id := range []string{sibling, colleague} {
+			user.post("/contacts/delete", url.Values{"id": {id}}, http.StatusFound)
+		}
+
+		for _, id := range []string{journalEntry, untaggedJournalEntry} {
+			user.post("/journal/delete", url.Values{"id": {id}}, http.StatusFound)
+		}
+
+		body = user.get("/contacts")
+		for _, tag := range []string{"family", "climbing club", "travel", "health"} {
+			if strings.Contains(body, `<option value="`+tag+`"`) {
+				t.Errorf("expected tag %q to be removed with the last contact or journal entry using it", tag)
+			}
+		}
+	})
+
+	t.Run("Calendar", func(t *testing.T) {
+		user.t = t
+
+		contactID := user.createContact("Robin", "Doe", "robin@example.com")
+		user.post("/activities", url.Values{
+			"contact_id":  {contactID},
+			"name":        {"Concert"},
+			"date":        {"2024-06-07"},
+			"description": {"Front row"},
+		}, http.StatusFound)
+
+		user.get("/calendar")
+
+		feedURL, err := url.Parse(findSecret(t, readBody(t, user.post("/calendar", nil, http.StatusOK))))
+		if err != nil {
+			t.Fatalf("could not parse feed URL: %v", err)
+		}
+
+		feed := user.get(feedURL.RequestURI())
+		assertContains(t, feed, "BEGIN:VCALENDAR")
+		assertContains(t, feed, "Concert")
+
+		user.post("/calendar/delete", nil, http.StatusFound)
+		user.do(http.MethodGet, feedURL.RequestURI(), nil, nil, http.StatusNotFound)
+
+		user.post("/contacts/delete", url.Values{"id": {contactID}}, http.StatusFound)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		user.t = t
+		otherUser.t = t
+
+		contactID := user.createContact("Morgan", "Doe", "morgan@example.com")
+		user.post("/debts", url.Values{
+			"contact_id":  {contactID},
+			"you_owe":     {"1"},
+			"amount":      {"9"},
+			"currency":    {"EUR"},
+			"description": {"Pottery class"},
+		}, http.StatusFound)
+		user.post("/activities", url.Values{
+			"contact_id":  {contactID},
+			"name":        {"Pottery"},
+			"date":        {"2024-10-11"},
+			"description": {"Made a <vase>"},
+		}, http.StatusFound)
+		journalEntryID := redirectedID(t, user.post("/journal", url.Values{
+			"title":  {"Pottery with Morgan"},
+			"body":   {"We made a <vase>."},
+			"rating": {"3"},
+		}, http.StatusFound))
+
+		user.get("/search")
+
+		results := user.get("/search?q=pottery")
+		assertContains(t, results, "/journal/view?id="+journalEntryID)
+		assertNotContains(t, results, "/contacts/view?id="+contactID)
+
+		// Matches are highlighted, but the rest of the headline is escaped
+		if !strings.Contains(results, "<mark>Pottery</mark>") {
+			t.Error("expected search results to highlight the matches")
+		}
+
+		if strings.Contains(user.get("/search?q=vase"), "<vase>") {
+			t.Error("expected search results to escape the headlines")
+		}
+
+		assertContains(t, user.get("/search?q=morgan@example.com"), "/contacts/view?id="+contactID)
+		assertNotContains(t, otherUser.get("/search?q=pottery"), "Pottery")
+
+		authorization := user.createPersonalAccessToken("Search", "search:read")
+
+		var apiResults []struct {
+			EntityName string `json:"entityName"`
+			ID         int32  `json:"id"`
+			ContactID  *int32 `json:"contactId"`
+			Title      string `json:"title"`
+		}
+		user.api(http.MethodGet, "/api/v1/search?q=pottery", authorization, nil, http.StatusOK, &apiResults)
+
+		entityNames := []string{}
+		for _, result := range apiResults {
+			entityNames = append(entityNames, result.EntityName)
+
+			if result.EntityName == "journalEntry" && result.ContactID != nil {
+				t.Error("expected journal entries not to have a contact")
+			} else if result.EntityName != "journalEntry" && (result.ContactID == nil || strconv.Itoa(int(*result.ContactID)) != contactID) {
+				t.Errorf("expected %v to belong to the contact", result.EntityName)
+			}
+		}
+		sort.Strings(entityNames)
+
+		if strings.Join(entityNames, " ") != "activity debt journalEntry" {
+			t.Errorf("expected an activity, a debt and a journal entry, got %v", entityNames)
+		}
+
+		user.api(http.MethodGet, "/api/v1/search?q=pottery&limit=1", authorization, nil, http.StatusOK, &apiResults)
+		if len(apiResults) != 1 {
+			t.Errorf("expected one result, got %v", len(apiResults))
+		}
+
+		user.api(http.MethodGet, "/api/v1/search", authorization, nil, http.StatusUnprocessableEntity, nil)
+		user.api(http.MethodGet, "/api/v1/search?q=pottery&limit=0", authorization, nil, http.StatusUnprocessableEntity, nil)
+
+		user.post("/contacts/delete", url.Values{"id": {contactID}}, http.StatusFound)
+		user.post("/journal/delete", url.Values{"id": {journalEntryID}}, http.StatusFound)
+
+		user.api(http.MethodGet, "/api/v1/search?q=pottery", authorization, nil, http.StatusOK, &apiResults)
+		if len(apiResults) != 0 {
+			t.Errorf("expected deleted records not to be found, got %v", len(apiResults))
+		}
+
+		personalAccessTokens, err := s.persister.GetPersonalAccessTokens(context.Background(), testEmail)
+		if err != nil || len(personalAccessTokens) != 1 {
+			t.Fatalf("could not get personal access token: %v", err)
+		}
+
+		user.post("/personalaccesstokens/delete", url.Values{"id": {strconv.Itoa(int(personalAccessTokens[0].ID))}}, http.StatusFound)
+	})
+
+	t.Run("AppPasswordsAndDAV", func(t *testing.T) {
+		user.t = t
+
+		contactID := user.createContact("Charlie", "Doe", "charlie@example.com")
+		user.post("/activities", url.Values{
+			"contact_id":  {contactID},
+			"name":        {"Picnic"},
+			"date":        {"2024-07-08"},
+			"description": {"In the park"},
+		}, http.StatusFound)
+
+		activities, err := s.persister.GetActivities(context.Background(), mustAtoi(t, contactID), testEmail)
+		if err != nil || len(activities) != 1 {
+			t.Fatalf("could not get activity: %v", err)
+		}
+		activityID := strconv.Itoa(int(activities[0].ID))
+
+		password := findSecret(t, readBody(t, user.post("/apppasswords", url.Values{"name": {"Phone"}}, http.StatusOK)))
+		assertContains(t, user.get("/apppasswords"), "Phone")
+
+		davHeader := http.Header{
+			"Authorization": {"Basic " + basicAuth(testEmail, password)},
+			"Depth":         {"1"},
+		}
+
+		user.do(http.MethodGet, "/.well-known/carddav", nil, nil, http.StatusMovedPermanently)
+		user.do("PROPFIND", "/carddav", nil, davHeader, http.StatusMultiStatus)
+		assertContains(t, readBody(t, user.do("PROPFIND", "/carddav/contacts/", nil, davHeader, http.StatusMultiStatus)), "/carddav/contacts/"+contactID+".vcf")
+		assertContains(t, readBody(t, user.do(http.MethodGet, "/carddav/contacts/"+contactID+".vcf", nil, davHeader, http.StatusOK)), "Charlie")
+
+		// Contacts created by a client keep the name and UID it chose, so updating
+		// them doesn't create another contact
+		card := "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:a3f1c2d4-client\r\nN:Doe;Dana;;;\r\nFN:Dana Doe\r\nEMAIL:dana@example.com\r\nEND:VCARD\r\n"
+		cardETag := user.do(http.MethodPut, "/carddav/contacts/a3f1c2d4-client.vcf", strings.NewReader(card), davHeader, http.StatusCreated).Header.Get("ETag")
+
+		cardIfMatchHeader := davHeader.Clone()
+		cardIfMatchHeader.Set("If-Match", cardETag)
+		user.do(http.MethodPut, "/carddav/contacts/a3f1c2d4-client.vcf", strings.NewReader(strings.ReplaceAll(card, "Dana", "Dani")), cardIfMatchHeader, http.StatusNoContent)
+
+		// Updates based on an earlier version don't overwrite the changes since
+		user.do(http.MethodPut, "/carddav/contacts/a3f1c2d4-client.vcf", strings.NewReader(strings.ReplaceAll(card, "Dana", "Dany")), cardIfMatchHeader, http.StatusPreconditionFailed)
+		user.do(http.MethodDelete, "/carddav/contacts/a3f1c2d4-client.vcf", nil, cardIfMatchHeader, http.StatusPreconditionFailed)
+
+		contacts, err := s.persister.GetContacts(context.Background(), testEmail)
+		if err != nil {
+			t.Fatalf("could not get contacts: %v", err)
+		}
+
+		created := []models.Contact{}
+		for _, contact := range contacts {
+			if contact.LastName == "Doe" && strings.HasPrefix(contact.FirstName, "Dan") {
+				created = append(created, contact)
+			}
+		}
+		if len(created) != 1 || created[0].FirstName != "Dani" {
+			t.Fatalf("expected updating a contact created with a client-chosen name to keep one contact, got %v", created)
+		}
+
+		// The version is checked again while writing, so a contact which changed
+		// after it was checked by the handler isn't overwritten either
+		if ok, err := s.persister.UpdateContactIfVersion(context.Background(), created[0].ID, created[0].Version-1, "Dany", "Doe", "", "", testEmail, nil, "", models.ContactDetails{}); err != nil || ok {
+			t.Errorf("expected updating an earlier version of a contact to fail, got %v: %v", ok, err)
+		}
+
+		if ok, err := s.persister.DeleteContactIfVersion(context.Background(), created[0].ID, created[0].Version-1, testEmail); err != nil || ok {
+			t.Errorf("expected deleting an earlier version of a contact to fail, got %v: %v", ok, err)
+		}
+
+		assertContains(t, readBody(t, user.do("PROPFIND", "/carddav/contacts/", nil, davHeader, http.StatusMultiStatus)), "/carddav/contacts/a3f1c2d4-client.vcf")
+
+		createdCard := readBody(t, user.do(http.MethodGet, "/carddav/contacts/a3f1c2d4-client.vcf", nil, davHeader, http.StatusOK))
+		assertContains(t, createdCard, "Dani")
+		assertContains(t, createdCard, "UID:a3f1c2d4-client")
+
+		// Names of contacts created by the server can't be used for new contacts
+		user.do(http.MethodPut, "/carddav/contacts/2147483647.vcf", strings.NewReader(card), davHeader, http.StatusConflict)
+
+		user.do(http.MethodDelete, "/carddav/contacts/a3f1c2d4-client.vcf", nil, davHeader, http.StatusNoContent)
+		user.do(http.MethodGet, "/carddav/contacts/a3f1c2d4-client.vcf", nil, davHeader, http.StatusNotFound)
+
+		user.do(http.MethodGet, "/.well-known/caldav", nil, nil, http.StatusMovedPermanently)
+		user.do("PROPFIND", "/caldav", nil, davHeader, http.StatusMultiStatus)
+		assertContains(t, readBody(t, user.do("PROPFIND", "/caldav/activities/", nil, davHeader, http.StatusMultiStatus)), "/caldav/activities/"+activityID+".ics")
+		assertContains(t, readBody(t, user.do(http.MethodGet, "/caldav/activities/"+activityID+".ics", nil, davHeader, http.StatusOK)), "Picnic")
+
+		// Activities created by a client keep the name and UID it chose, so
+		// rescheduling them doesn't create another activity
+		event := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:b7e2f9a1-client\r\nSUMMARY:Hike\r\nDTSTART;VALUE=DATE:20240810\r\nATTENDEE;CN=Charlie Doe:mailto:charlie@example.com\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+		eventETag := user.do(http.MethodPut, "/caldav/activities/b7e2f9a1-client.ics", strings.NewReader(event), davHeader, http.StatusCreated).Header.Get("ETag")
+
+		eventIfMatchHeader := davHeader.Clone()
+		eventIfMatchHeader.Set("If-Match", eventETag)
+		user.do(http.MethodPut, "/caldav/activities/b7e2f9a1-client.ics", strings.NewReader(strings.Replace(event, "20240810", "20240817", 1)), eventIfMatchHeader, http.StatusNoContent)
+
+		// Updates based on an earlier version don't overwrite the changes since
+		user.do(http.MethodPut, "/caldav/activities/b7e2f9a1-client.ics", strings.NewReader(strings.Replace(event, "20240810", "20240824", 1)), eventIfMatchHeader, http.StatusPreconditionFailed)
+		user.do(http.MethodDelete, "/caldav/activities/b7e2f9a1-client.ics", nil, eventIfMatchHeader, http.StatusPreconditionFailed)
+
+		activities, err = s.persister.GetActivities(context.Background(), mustAtoi(t, contactID), testEmail)
+		if err != nil || len(activities) != 2 {
+			t.Fatalf("expected rescheduling an activity created with a client-chosen name to keep one activity, got %v activities: %v", len(activities), err)
+		}
+
+		calendar, err := s.persister.GetActivitiesCalendar(context.Background(), testEmail)
+		if err != nil {
+			t.Fatalf("could not get activities calendar: %v", err)
+		}
+
+		var hike *models.GetActivitiesCalendarForNamespaceRow
+		for _, activity := range calendar {
+			if activity.Name == "Hike" {
+				hike = &activity
+			}
+		}
+		if hike == nil {
+			t.Fatalf("expected the activity created by the client to be in the calendar, got %v", calendar)
+		}
+
+		// Like for contacts, the version is checked again while writing
+		if ok, err := s.persister.UpdateActivityIfVersion(context.Background(), hike.ID, hike.Version-1, hike.ContactID, testEmail, "Hike", hike.Date, "", nil); err != nil || ok {
+			t.Errorf("expected updating an earlier version of an activity to fail, got %v: %v", ok, err)
+		}
+
+		if ok, err := s.persister.DeleteActivityIfVersion(context.Background(), hike.ID, hike.Version-1, hike.ContactID, testEmail); err != nil || ok {
+			t.Errorf("expected deleting an earlier version of an activity to fail, got %v: %v", ok, err)
+		}
+
+		assertContains(t, readBody(t, user.do("PROPFIND", "/caldav/activities/", nil, davHeader, http.StatusMultiStatus)), "/caldav/activities/b7e2f9a1-client.ics")
+
+		createdEvent := readBody(t, user.do(http.MethodGet, "/caldav/activities/b7e2f9a1-client.ics", nil, davHeader, http.StatusOK))
+		assertContains(t, createdEvent, "20240817")
+		assertContains(t, createdEvent, "UID:b7e2f9a1-client")
+
+		// Names of activities created by the server can't be used for new activities
+		user.do(http.MethodPut, "/caldav/activities/2147483647.ics", strings.NewReader(event), davHeader, http.StatusConflict)
+
+		user.do(http.MethodDelete, "/caldav/activities/b7e2f9a1-client.ics", nil, davHeader, http.StatusNoContent)
+		user.do(http.MethodGet, "/caldav/activities/b7e2f9a1-client.ics", nil, davHeader, http.StatusNotFound)
+
+		// App passwords only give access to their user's data
+		otherDAVHeader := http.Header{
+			"Authorization": {"Basic " + basicAuth(testOtherEmail, password)},
+		}
+		user.do("PROPFIND", "/carddav/", nil, otherDAVHeader, http.StatusUnauthorized)
+
+		// App passwords have no scopes, so they can't be used for the JSON API
+		user.api(http.MethodGet, "/api/v1/contacts", davHeader.Get("Authorization"), nil, http.StatusUnauthorized, nil)
+		user.api(http.MethodDelete, "/api/v1/userdata", davHeader.Get("Authorization"), nil, http.StatusUnauthorized, nil)
+
+		appPasswords, err := s.persister.GetAppPasswords(context.Background(), testEmail)
+		if err != nil || len(appPasswords) != 1 {
+			t.Fatalf("could not get app password: %v", err)
+		}
+
+		user.post("/apppasswords/delete", url.Values{"id": {strconv.Itoa(int(appPasswords[0].ID))}}, http.StatusFound)
+		user.do("PROPFIND", "/carddav/", nil, davHeader, http.StatusUnauthorized)
+
+		user.post("/contacts/delete", url.Values{"id": {contactID}}, http.StatusFound)
+	})
+
+	t.Run("API", func(t *testing.T) {
+		user.t = t
+
+		authorization := user.createPersonalAccessToken(
+			"Script",
+			"contacts:read", "contacts:write",
+			"debts:read", "debts:write",
+			"activities:read", "activities:write",
+			"journal:read", "journal:write",
+			"userdata:read", "userdata:write",
+		)
+
+		user.api(http.MethodGet, "/api/v1/journal", "", nil, http.StatusUnauthorized, nil)
+		user.api(http.MethodGet, "/api/v1/does-not-exist", authorization, nil, http.StatusNotFound, nil)
+
+		var journalEntry struct {
+			ID    int32  `json:"id"`
+			Title string `json:"title"`
+		}
+		user.api(http.MethodPost, "/api/v1/journal", authorization, map[string]any{"title": "API entry", "body": "Body", "rating": 2}, http.StatusCreated, &journalEntry)
+		journalEntryPath := fmt.Sprintf("/api/v1/journal/%v", journalEntry.ID)
+
+		user.api(http.MethodPut, journalEntryPath, authorization, map[string]any{"title": "API entry 2", "body": "Body", "rating": 3}, http.StatusOK, &journalEntry)
+		user.api(http.MethodGet, journalEntryPath, authorization, nil, http.StatusOK, &journalEntry)
+		if journalEntry.Title != "API entry 2" {
+			t.Errorf("expected updated journal entry, got %q", journalEntry.Title)
+		}
+
+		// IDs which overflow `int32` must not wrap around to other rows
+		user.api(http.MethodGet, fmt.Sprintf("/api/v1/journal/%v", int64(journalEntry.ID)+1<<32), authorization, nil, http.StatusNotFound, nil)
+		user.api(http.MethodGet, "/api/v1/journal/invalid", authorization, nil, http.StatusUnprocessableEntity, nil)
+
+		var journalEntries []any
+		user.api(http.MethodGet, "/api/v1/journal", authorization, nil, http.StatusOK, &journalEntries)
+		if len(journalEntries) != 1 {
+			t.Errorf("expected one journal entry, got %v", len(journalEntries))
+		}
+
+		user.api(http.MethodDelete, journalEntryPath, authorization, nil, http.StatusNoContent, nil)
+		user.api(http.MethodGet, journalEntryPath, authorization, nil, http.StatusNotFound, nil)
+
+		var contact struct {
+			ID        int32  `json:"id"`
+			FirstName string `json:"firstName"`
+			Phones    []struct {
+				Label  string `json:"label"`
+				Number string `json:"number"`
+			} `json:"phones"`
+		}
+		apiContact := map[string]any{
+			"firstName": "Jo",
+			"lastName":  "Doe",
+			"emails":    []map[string]any{{"label": "home", "email": "jo@example.com"}},
+			"phones":    []map[string]any{{"label": "mobile", "number": "0049 151 123456"}},
+			"pronouns":  "they/them",
+		}
+		user.api(http.MethodPost, "/api/v1/contacts", authorization, map[string]any{"firstName": "Jo", "lastName": "Doe", "phones": []map[string]any{{"number": "0151"}}, "pronouns": "they/them"}, http.StatusUnprocessableEntity, nil)
+		user.api(http.MethodPost, "/api/v1/contacts", authorization, apiContact, http.StatusCreated, &contact)
+		if len(contact.Phones) != 1 || contact.Phones[0].Number != "+49151123456" {
+			t.Errorf("expected a normalized phone number, got %v", contact.Phones)
+		}
+		contactPath := fmt.Sprintf("/api/v1/contacts/%v", contact.ID)
+
+		apiContact["firstName"] = "Joe"
+		user.api(http.MethodPut, contactPath, authorization, apiContact, http.StatusOK, &contact)
+		user.api(http.MethodGet, contactPath, authorization, nil, http.StatusOK, &contact)
+		if contact.FirstName != "Joe" {
+			t.Errorf("expected updated contact, got %q", contact.FirstName)
+		}
+
+		var contacts []any
+		user.api(http.MethodGet, "/api/v1/contacts", authorization, nil, http.StatusOK, &contacts)
+		if len(contacts) != 1 {
+			t.Errorf("expected one contact, got %v", len(contacts))
+		}
+
+		var debt struct {
+			ID       int32   `json:"id"`
+			Amount   float64 `json:"amount"`
+			Currency string  `json:"currency"`
+			Paid     float64 `json:"paid"`
+			Settled  bool    `json:"settled"`
+			Payments []any   `json:"payments"`
+		}
+		// Currencies must be ISO 4217 codes, and amounts can't be more precise
+		// than the minor unit of their currency
+		for _, invalid := range []map[string]any{
+			{"amount": 5, "currency": "Euro"},
+			{"amount": 5.001, "currency": "EUR"},
+			{"amount": 5.5, "currency": "JPY"},
+		} {
+			user.api(http.MethodPost, contactPath+"/debts", authorization, invalid, http.StatusUnprocessableEntity, nil)
+		}
+
+		user.api(http.MethodPost, contactPath+"/debts", authorization, map[string]any{"amount": 5, "currency": "eur", "description": "Coffee"}, http.StatusCreated, &debt)
+		debtPath := fmt.Sprintf("%v/debts/%v", contactPath, debt.ID)
+
+		user.api(http.MethodPut, debtPath, authorization, map[string]any{"amount": -7, "currency": "EUR", "description": "Cake"}, http.StatusOK, &debt)
+		user.api(http.MethodGet, debtPath, authorization, nil, http.StatusOK, &debt)
+		if debt.Amount != -7 {
+			t.Errorf("expected updated debt, got %v", debt.Amount)
+		}
+
+		var debts []any
+		user.api(http.MethodGet, contactPath+"/debts", authorization, nil, http.StatusOK, &debts)
+		if len(debts) != 1 {
+			t.Errorf("expected one debt, got %v", len(debts))
+		}
+
+		// Payments can't exceed what is left of the debt or predate it
+		for _, payment := range []map[string]any{{"amount": 0}, {"amount": 8}, {"amount": 0.001}, {"amount": 2, "date": "2000-01-01"}} {
+			user.api(http.MethodPost, debtPath+"/payments", authorization, payment, http.StatusUnprocessableEntity, nil)
+		}
+
+		user.api(http.MethodPost, debtPath+"/payments", authorization, map[string]any{"amount": 2, "description": "Cash"}, http.StatusCreated, &debt)
+		if debt.Paid != 2 || debt.Settled || len(debt.Payments) != 1 {
+			t.Errorf("expected a partially paid debt, got %+v", debt)
+		}
+
+		// Debts can't be moved after their payments
+		user.api(http.MethodPut, debtPath, authorization, map[string]any{"amount": -7, "currency": "EUR", "description": "Cake", "date": "2999-01-01"}, http.StatusUnprocessableEntity, nil)
+
+		// Deleting a debt settles the rest of it, but keeps it in the history
+		user.api(http.MethodDelete, debtPath, authorization, nil, http.StatusNoContent, nil)
+		user.api(http.MethodDelete, debtPath, authorization, nil, http.StatusNoContent, nil)
+		user.api(http.MethodGet, debtPath, authorization, nil, http.StatusOK, &debt)
+		if debt.Paid != 7 || !debt.Settled || len(debt.Payments) != 2 || debt.Currency != "EUR" {
+			t.Errorf("expected a settled debt, got %+v", debt)
+		}
+
+		var activity struct {
+			ID   int32  `json:"id"`
+			Name string `json:"name"`
+		}
+		user.api(http.MethodPost, contactPath+"/activities", authorization, map[string]any{"name": "Chess", "date": "2024-08-09", "description": "Lost"}, http.StatusCreated, &activity)
+		activityPath := fmt.Sprintf("%v/activities/%v", contactPath, activity.ID)
+
+		user.api(http.MethodPut, activityPath, authorization, map[string]any{"name": "Go", "date": "2024-08-10", "description": "Won"}, http.StatusOK, &activity)
+		user.api(http.MethodGet, activityPath, authorization, nil, http.StatusOK, &activity)
+		if activity.Name != "Go" {
+			t.Errorf("expected updated activity, got %q", activity.Name)
+		}
+
+		var activities []any
+		user.api(http.MethodGet, contactPath+"/activities", authorization, nil, http.StatusOK, &activities)
+		if len(activities) != 1 {
+			t.Errorf("expected one activity, got %v", len(activities))
+		}
+
+		user.api(http.MethodDelete, activityPath, authorization, nil, http.StatusNoContent, nil)
+
+		// The user data can be exported and imported again through the API
+		export := readBody(t, user.do(http.MethodGet, "/api/v1/userdata", nil, http.Header{"Authorization": {authorization}}, http.StatusOK))
+
+		var userDataImport struct {
+			Token    string `json:"token"`
+			Contacts int    `json:"contacts"`
+		}
+		res := user.do(http.MethodPost, "/api/v1/userdata", strings.NewReader(export), http.Header{"Authorization": {authorization}}, http.StatusOK)
+		if err := json.NewDecoder(res.Body).Decode(&userDataImport); err != nil {
+			t.Fatalf("could not decode import: %v", err)
+		}
+
+		if userDataImport.Contacts != 1 {
+			t.Errorf("expected one contact to be staged, got %v", userDataImport.Contacts)
+		}
+
+		user.api(http.MethodPost, "/api/v1/userdata/cancel", authorization, map[string]any{"token": userDataImport.Token}, http.StatusNoContent, nil)
+		user.api(http.MethodPost, "/api/v1/userdata/confirm", authorization, map[string]any{"token": userDataImport.Token}, http.StatusNotFound, nil)
+
+		res = user.do(http.MethodPost, "/api/v1/userdata", strings.NewReader(export), http.Header{"Authorization": {authorization}}, http.StatusOK)
+		if err := json.NewDecoder(res.Body).Decode(&userDataImport); err != nil {
+			t.Fatalf("could not decode import: %v", err)
+		}
+
+		// Confirming an import twice at once only imports it once
+		statuses := make([]int, 8)
+		errs := make([]error, len(statuses))
+
+		var wg sync.WaitGroup
+		for i := range statuses {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				req, err := http.NewRequest(http.MethodPost, s.url+"/api/v1/userdata/confirm", strings.NewReader(`{"token":"`+userDataImport.Token+`"}`))
+				if err != nil {
+					errs[i] = err
+
+					return
+				}
+				req.Header.Set("Authorization", authorization)
+				req.Header.Set("Content-Type", "application/json")
+
+				res, err := user.client.Do(req)
+				if err != nil {
+					errs[i] = err
+
+					return
+				}
+				defer res.Body.Close()
+
+				statuses[i] = res.StatusCode
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				t.Fatalf("could not confirm import: %v", err)
+			}
+		}
+
+		if confirmed := slices.DeleteFunc(slices.Clone(statuses), func(status int) bool {
+			return status == http.StatusNotFound
+		}); !slices.Equal(confirmed, []int{http.StatusNoContent}) {
+			t.Errorf("expected exactly one confirmation to succeed and the others to not find the import, got %v", statuses)
+		}
+
+		user.api(http.MethodGet, "/api/v1/contacts", authorization, nil, http.StatusOK, &contacts)
+		if len(contacts) != 2 {
+			t.Errorf("expected two contacts after the import, got %v", len(contacts))
+		}
+
+		user.api(http.MethodDelete, contactPath, authorization, nil, http.StatusNoContent, nil)
+		user.api(http.MethodDelete, "/api/v1/userdata", authorization, nil, http.StatusNoContent, nil)
+
+		// Deleting the user data also deletes the personal access tokens and sessions
+		user.api(http.MethodGet, "/api/v1/contacts", authorization, nil, http.StatusUnauthorized, nil)
+		user.do(http.MethodGet, "/contacts", nil, nil, http.StatusFound)
+
+		user.signIn()
+	})
+
+	t.Run("PersonalAccessTokens", func(t *testing.T) {
+		user.t = t
+
+		user.get("/personalaccesstokens")
+
+		token := findSecret(t, readBody(t, user.post("/personalaccesstokens", url.Values{
+			"name":   {"Reader"},
+			"scopes": {"contacts:read"},
+		}, http.StatusOK)))
+		assertContains(t, user.get("/personalaccesstokens"), "Reader")
+
+		authorization := "Bearer " + token
+
+		user.api(http.MethodGet, "/api/v1/contacts", authorization, nil, http.StatusOK, nil)
+		user.api(http.MethodGet, "/api/v1/journal", authorization, nil, http.StatusForbidden, nil)
+		user.api(http.MethodPost, "/api/v1/contacts", authorization, map[string]any{"firstName": "Jo", "lastName": "Doe", "pronouns": "they/them"}, http.StatusForbidden, nil)
+
+		personalAccessTokens, err := s.persister.GetPersonalAccessTokens(context.Background(), testEmail)
+		if err != nil || len(personalAccessTokens) != 1 {
+			t.Fatalf("could not get personal access token: %v", err)
+		}
+
+		user.post("/personalaccesstokens/delete", url.Values{"id": {strconv.Itoa(int(personalAccessTokens[0].ID))}}, http.StatusFound)
+		user.api(http.MethodGet, "/api/v1/contacts", authorization, nil, http.StatusUnauthorized, nil)
+	})
+
+	t.Run("UserDataRoundTrip", func(t *testing.T) {
+		user.t = t
+
+		contactID := user.createContact("Dana", "Doe", "dana@example.com")
+		user.post("/contacts/update", url.Values{
+			"id":         {contactID},
+			"first_name": {"Dana"},
+			"last_name":  {"Doe"},
+			"email":      {"dana@example.com"},
+			"nickname":   {""},
+			"pronouns":   {"she/her"},
+			"birthday":   {"1985-04-05"},
+			"address":    {""},
+			"notes":      {"Neighbor"},
+			"tags":       {"family, climbing club"},
+		}, http.StatusFound)
+		otherContactID := user.createContact("Eli", "Doe", "eli@example.com")
+
+		user.post("/debts", url.Values{
+			"contact_id":  {contactID},
+			"you_owe":     {"1"},
+			"amount":      {"3.5"},
+			"currency":    {"EUR"},
+			"description": {"Bus ticket"},
+		}, http.StatusFound)
+		user.post("/debts", url.Values{
+			"contact_id":  {otherContactID},
+			"you_owe":     {"0"},
+			"amount":      {"8"},
+			"currency":    {"USD"},
+			"description": {"Book"},
+			"date":        {"2024-09-01"},
+		}, http.StatusFound)
+
+		otherDebts, err := s.persister.GetDebts(context.Background(), mustAtoi(t, otherContactID), testEmail)
+		if err != nil {
+			t.Fatalf("could not get debts: %v", err)
+		}
+
+		user.post("/debts/settle", url.Values{
+			"id":          {strconv.Itoa(int(otherDebts[0].ID))},
+			"contact_id":  {otherContactID},
+			"amount":      {"3"},
+			"date":        {"2024-09-02"},
+			"description": {"Bank transfer"},
+		}, http.StatusFound)
+		user.post("/activities", url.Values{
+			"contact_id":     {otherContactID},
+			"participant_id": {contactID},
+			"name":           {"Cinema"},
+			"date":           {"2024-09-10"},
+			"description":    {"Great movie"},
+		}, http.StatusFound)
+		user.post("/contacts/relationships", url.Values{
+			"contact_id":         {otherContactID},
+			"related_contact_id": {contactID},
+			"type":               {"introduced_by"},
+		}, http.StatusFound)
+		groupID := redirectedID(t, user.post("/groups", url.Values{"name": {"Neighbors"}}, http.StatusFound))
+		user.post("/groups/members", url.Values{"id": {groupID}, "contact_id": {contactID}}, http.StatusFound)
+		user.post("/groups/members", url.Values{"id": {groupID}, "contact_id": {otherContactID}}, http.StatusFound)
+		user.post("/journal", url.Values{
+			"title":  {"Moving day"},
+			"body":   {"Boxes everywhere"},
+			"rating": {"1"},
+			"tags":   {"home"},
+		}, http.StatusFound)
+
+		export := user.get("/userdata")
+		expected := userDataRecords(t, export)
+
+		if !strings.Contains(export, `"tags":["climbing club","family"]`) || !strings.Contains(export, `"tags":["home"]`) {
+			t.Error("expected the export to contain the tags")
+		}
+
+		if !strings.Contains(export, `"payments":[{"amount":3.00,"date":"2024-09-02T00:00:00Z","description":"Bank transfer"}]`) {
+			t.Error("expected the export to contain the debt payments")
+		}
+
+		if len(expected) != 9 {
+			t.Fatalf("expected a manifest and 8 records in the export, got %v", expected)
+		}
+
+		// Deleting the user data also signs out
+		user.post("/userdata/delete", nil, http.StatusFound)
+		user.do(http.MethodGet, "/contacts", nil, nil, http.StatusFound)
+
+		user.signIn()
+
+		if records := userDataRecords(t, user.get("/userdata")); len(records) != 1 {
+			t.Fatalf("expected only a manifest after deleting the user data, got %v", records)
+		}
+
+		// Cancelled imports don't change anything
+		token := tokenRegex.FindStringSubmatch(readBody(t, user.upload("/userdata", "userData", []byte(export), http.StatusOK)))
+		if token == nil {
+			t.Fatal("import page doesn't contain a token")
+		}
+
+		user.post("/userdata/cancel", url.Values{"token": {token[1]}}, http.StatusFound)
+		user.post("/userdata/confirm", url.Values{"token": {token[1]}}, http.StatusNotFound)
+
+		if records := userDataRecords(t, user.get("/userdata")); len(records) != 1 {
+			t.Fatalf("expected a cancelled import not to create records, got %v", records)
+		}
+
+		token = tokenRegex.FindStringSubmatch(readBody(t, user.upload("/userdata", "userData", []byte(export), http.StatusOK)))
+		if token == nil {
+			t.Fatal("import page doesn't contain a token")
+		}
+
+		// Imports can only be confirmed by the user who staged them
+		otherUser.t = t
+		otherUser.post("/userdata/confirm", url.Values{"token": {token[1]}}, http.StatusNotFound)
+
+		token = tokenRegex.FindStringSubmatch(readBody(t, user.upload("/userdata", "userData", []byte(export), http.StatusOK)))
+		if token == nil {
+			t.Fatal("import page doesn't contain a token")
+		}
+
+		user.post("/userdata/confirm", url.Values{"token": {token[1]}}, http.StatusFound)
+
+		actual := userDataRecords(t, user.get("/userdata"))
+		if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("import didn't restore the export:\nexpected:\n%v\n\nactual:\n%v", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+		}
+
+		if records := userDataRecords(t, otherUser.get("/userdata")); len(records) != 1 {
+			t.Errorf("expected the import not to create records for other users, got %v", records)
+		}
+
+		if !strings.Contains(export, `"amount":8.00,"currency":"USD"`) {
+			t.Fatal("expected the export to contain the amount as an exact decimal")
+		}
+
+		// Amounts of the current version can't be more precise than the minor
+		// unit of their currency, like in the forms and the API
+		imprecise := strings.Replace(export, `"amount":8.00,"currency":"USD"`, `"amount":8.001,"currency":"USD"`, 1)
+		assertContains(t, readBody(t, user.upload("/userdata", "userData", []byte(imprecise), http.StatusOK)), "Invalid debt: invalid amount")
+
+		// Uploads which are too large are rejected before the CSRF token is read
+		// from the form, as well as when it is sent as a header
+		oversized := bytes.Repeat([]byte(" "), 33*1024*1024)
+		user.upload("/userdata", "userData", oversized, http.StatusRequestEntityTooLarge)
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+
+		file, err := writer.CreateFormFile("userData", "upload")
+		if err != nil {
+			t.Fatalf("could not write form: %v", err)
+		}
+
+		if _, err := file.Write(oversized); err != nil {
+			t.Fatalf("could not write form: %v", err)
+		}
+
+		if err := writer.Close(); err != nil {
+			t.Fatalf("could not write form: %v", err)
+		}
+
+		user.do(http.MethodPost, "/userdata", &body, http.Header{
+			"Content-Type": {writer.FormDataContentType()},
+			"X-Csrf-Token": {user.csrfToken()},
+		}, http.StatusRequestEntityTooLarge)
+
+		// Only five imports can be pending at a time; two are still staged from
+		// above, so a fourth one after them is rejected until one is cancelled
+		pending := []string{}
+		for range 3 {
+			token = tokenRegex.FindStringSubmatch(readBody(t, user.upload("/userdata", "userData", []byte(export), http.StatusOK)))
+			if token == nil {
+				t.Fatal("import page doesn't contain a token")
+			}
+
+			pending = append(pending, token[1])
+		}
+
+		user.upload("/userdata", "userData", []byte(export), http.StatusTooManyRequests)
+
+		user.post("/userdata/cancel", url.Values{"token": {pending[0]}}, http.StatusFound)
+
+		user.upload("/userdata", "userData", []byte(export), http.StatusOK)
+
+		// Earlier versions exported amounts as floats and accepted any currency,
+		// so the amounts are rounded to cents and the currencies are kept
+		user.post("/userdata/delete", nil, http.StatusFound)
+		user.signIn()
+
+		legacy := strings.Replace(export, `"amount":8.00,"currency":"USD"`, `"amount":7.999999999999999,"currency":"Dollar"`, 1)
+		legacy = strings.Replace(legacy, fmt.Sprintf(`"version":%v`, controllers.UserDataVersion), `"version":4`, 1)
+
+		token = tokenRegex.FindStringSubmatch(readBody(t, user.upload("/userdata", "userData", []byte(legacy), http.StatusOK)))
+		if token == nil {
+			t.Fatal("import page doesn't contain a token")
+		}
+
+		user.post("/userdata/confirm", url.Values{"token": {token[1]}}, http.StatusFound)
+
+		if imported := user.get("/userdata"); !strings.Contains(imported, `"amount":8.00,"currency":"Dollar"`) {
+			t.Errorf("expected the legacy amount to be rounded to cents, got %v", imported)
+		}
+	})
+
+	t.Run("LegacyUserData", func(t *testing.T) {
+		user.t = t
+
+		user.post("/userdata/delete", nil, http.StatusFound)
+		user.signIn()
+
+		// Exports of earlier versions are upgraded record by record
+		legacy := strings.Join([]string{
+			`{"entityName":"manifest","version":2,"exportedAt":"2024-06-01T12:00:00Z","source":"example.com"}`,
+			`{"entityName":"contact","id":1,"firstName":"Lee","lastName":"Doe","pronouns":"they/them","birthday":{"Time":"0001-01-01T00:00:00Z","Valid":false},"emails":[],"phones":[],"addresses":[],"websites":[]}`,
+			`{"entityName":"activity","id":2,"name":"Bowling","date":"2024-05-04T00:00:00Z","description":"Strike","contactId":{"Int32":1,"Valid":true}}`,
+			`{"entityName":"debt","id":3,"amount":12.5,"currency":"USD","description":"Tickets","contactId":{"Int32":1,"Valid":true}}`,
+		}, "\n")
+
+		token := tokenRegex.FindStringSubmatch(readBody(t, user.upload("/userdata", "userData", []byte(legacy), http.StatusOK)))
+		if token == nil {
+			t.Fatal("import page doesn't contain a token")
+		}
+
+		user.post("/userdata/confirm", url.Values{"token": {token[1]}}, http.StatusFound)
+
+		records := userDataRecords(t, user.get("/userdata"))
+		for _, expected := range []string{
+			"activity Bowling 2024-05-04T00:00:00Z Strike with Lee Doe",
+			// Debts which predate the ledger are dated when they were exported
+			"debt 12.5 USD Tickets 2024-06-01T00:00:00Z [] for Lee Doe",
+		} {
+			if !slices.Contains(records, expected) {
+				t.Errorf("expected the import to contain %q, got %v", expected, records)
+			}
+		}
+
+		user.post("/userdata/delete", nil, http.StatusFound)
+		user.signIn()
+
+		// Exports without a manifest don't say when their debts were added, so
+		// they are dated on the day they are imported
+		unversioned := strings.Join(strings.Split(legacy, "\n")[1:], "\n")
+		token = tokenRegex.FindStringSubmatch(readBody(t, user.upload("/userdata", "userData", []byte(unversioned), http.StatusOK)))
+		if token == nil {
+			t.Fatal("import page doesn't contain a token")
+		}
+
+		user.post("/userdata/confirm", url.Values{"token": {token[1]}}, http.StatusFound)
+
+		now := time.Now().UTC()
+		expected := "debt 12.5 USD Tickets " + time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Format(time.RFC3339) + " [] for Lee Doe"
+		if records := userDataRecords(t, user.get("/userdata")); !slices.Contains(records, expected) {
+			t.Errorf("expected the import to contain %q, got %v", expected, records)
+		}
+
+		user.post("/userdata/delete", nil, http.StatusFound)
+		user.signIn()
+	})
+
+	t.Run("Sessions", func(t *testing.T) {
+		user.t = t
+
+		sessions, err := s.persister.GetSessions(context.Background(), testEmail)
+		if err != nil {
+			t.Fatalf("could not get sessions: %v", err)
+		}
+
+		if len(sessions) != 1 {
+			t.Fatalf("expected one session, got %v", len(sessions))
+		}
+		firstDeviceSessionID := sessions[0].ID
+
+		secondDevice := s.signIn(t, testEmail)
+
+		sessions, err = s.persister.GetSessions(context.Background(), testEmail)
+		if err != nil {
+			t.Fatalf("could not get sessions: %v", err)
+		}
+
+		if len(sessions) != 2 {
+			t.Fatalf("expected two sessions, got %v", len(sessions))
+		}
+
+		user.get("/sessions")
+
+		// Sessions which were last seen within the same second are listed in
+		// any order, so the second device's session is selected by its ID
+		secondDeviceSessionID := sessions[0].ID
+		if secondDeviceSessionID == firstDeviceSessionID {
+			secondDeviceSessionID = sessions[1].ID
+		}
+
+		user.post("/sessions/delete", url.Values{"id": {strconv.Itoa(int(secondDeviceSessionID))}}, http.StatusFound)
+
+		secondDevice.do(http.MethodGet, "/contacts", nil, nil, http.StatusFound)
+		user.get("/contacts")
+
+		user.post("/sessions/delete/all", nil, http.StatusFound)
+		user.do(http.MethodGet, "/contacts", nil, nil, http.StatusFound)
+
+		user.signIn()
+
+		// Signing out through the OIDC provider's redirect clears the cookies
+		user.get("/authorize")
+		user.do(http.MethodGet, "/contacts", nil, nil, http.StatusFound)
+
+		user.signIn()
+
+		user.do(http.MethodPost, "/authorize/backchannel", strings.NewReader(url.Values{"logout_token": {"invalid"}}.Encode()), http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+		}, http.StatusBadRequest)
+		user.get("/contacts")
+	})
+
+	t.Run("AllRoutesAreCovered", func(t *testing.T) {
+		s.patternsLock.Lock()
+		defer s.patternsLock.Unlock()
+
+		for _, pattern := range registeredPatterns(t) {
+			if _, ok := s.patterns[pattern]; !ok {
+				t.Errorf("route %q isn't covered by the end-to-end tests", pattern)
+			}
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 
 	senbaraForms "github.com/pojntfx/senbara/senbara-forms"
 	"github.com/pojntfx/senbara/senbara-forms/pkg/controllers"
@@ -13,7 +14,7 @@ import (
 )
 
 var (
-	p *persisters.Persister
+	p persisters.Persister
 	c *controllers.Controller
 )
 
@@ -22,10 +23,16 @@ func SenbaraFormsHandler(
 	r *http.Request,
 	c *controllers.Controller,
 ) {
+	c.CSRF(newSenbaraFormsMux(c)).ServeHTTP(w, r)
+}
+
+func newSenbaraFormsMux(c *controllers.Controller) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(static.FS))))
 
+	mux.HandleFunc("GET /search", c.HandleSearch)
+
 	mux.HandleFunc("GET /journal", c.HandleJournal)
 	mux.HandleFunc("GET /journal/add", c.HandleAddJournal)
 	mux.HandleFunc("GET /journal/edit", c.HandleEditJournal)
@@ -39,10 +46,28 @@ func SenbaraFormsHandler(
 	mux.HandleFunc("GET /contacts/add", c.HandleAddContact)
 	mux.HandleFunc("GET /contacts/edit", c.HandleEditContact)
 	mux.HandleFunc("GET /contacts/view", c.HandleViewContact)
+	mux.HandleFunc("GET /contacts/vcard", c.HandleContactsVCard)
+	mux.HandleFunc("GET /contacts/graph", c.HandleContactsGraph)
 
 	mux.HandleFunc("POST /contacts", c.HandleCreateContact)
 	mux.HandleFunc("POST /contacts/delete", c.HandleDeleteContact)
 	mux.HandleFunc("POST /contacts/update", c.HandleUpdateContact)
+	mux.HandleFunc("POST /contacts/vcard", c.HandleCreateContactsVCard)
+	mux.HandleFunc("POST /contacts/relationships", c.HandleCreateContactRelationship)
+	mux.HandleFunc("POST /contacts/relationships/delete", c.HandleDeleteContactRelationship)
+
+	mux.HandleFunc("GET /groups", c.HandleGroups)
+	mux.HandleFunc("GET /groups/view", c.HandleViewGroup)
+	mux.HandleFunc("GET /groups/activities/add", c.HandleAddGroupActivity)
+	mux.HandleFunc("GET /groups/debts/add", c.HandleAddGroupDebt)
+
+	mux.HandleFunc("POST /groups", c.HandleCreateGroup)
+	mux.HandleFunc("POST /groups/update", c.HandleUpdateGroup)
+	mux.HandleFunc("POST /groups/delete", c.HandleDeleteGroup)
+	mux.HandleFunc("POST /groups/members", c.HandleCreateGroupMember)
+	mux.HandleFunc("POST /groups/members/delete", c.HandleDeleteGroupMember)
+	mux.HandleFunc("POST /groups/activities", c.HandleCreateGroupActivity)
+	mux.HandleFunc("POST /groups/debts", c.HandleCreateGroupDebt)
 
 	mux.HandleFunc("GET /debts/add", c.HandleAddDebt)
 	mux.HandleFunc("GET /debts/edit", c.HandleEditDebt)
@@ -62,26 +87,110 @@ func SenbaraFormsHandler(
 	mux.HandleFunc("GET /userdata", c.HandleUserData)
 
 	mux.HandleFunc("POST /userdata", c.HandleCreateUserData)
+	mux.HandleFunc("POST /userdata/confirm", c.HandleConfirmUserData)
+	mux.HandleFunc("POST /userdata/cancel", c.HandleCancelUserData)
 	mux.HandleFunc("POST /userdata/delete", c.HandleDeleteUserData)
 
+	mux.HandleFunc("GET /apppasswords", c.HandleAppPasswords)
+
+	mux.HandleFunc("POST /apppasswords", c.HandleCreateAppPassword)
+	mux.HandleFunc("POST /apppasswords/delete", c.HandleDeleteAppPassword)
+
+	mux.HandleFunc("GET /personalaccesstokens", c.HandlePersonalAccessTokens)
+
+	mux.HandleFunc("POST /personalaccesstokens", c.HandleCreatePersonalAccessToken)
+	mux.HandleFunc("POST /personalaccesstokens/delete", c.HandleDeletePersonalAccessToken)
+
+	mux.HandleFunc("GET /sessions", c.HandleSessions)
+
+	mux.HandleFunc("POST /sessions/delete", c.HandleDeleteSession)
+	mux.HandleFunc("POST /sessions/delete/all", c.HandleDeleteSessions)
+
+	mux.HandleFunc("GET /calendar", c.HandleCalendar)
+	mux.HandleFunc("GET /calendar/feed.ics", c.HandleCalendarFeed)
+
+	mux.HandleFunc("POST /calendar", c.HandleCreateCalendarFeed)
+	mux.HandleFunc("POST /calendar/delete", c.HandleDeleteCalendarFeed)
+
+	mux.HandleFunc("/.well-known/carddav", c.HandleWellKnownCardDAV)
+	mux.HandleFunc("/carddav", c.HandleCardDAV)
+	mux.HandleFunc("/carddav/", c.HandleCardDAV)
+
+	mux.HandleFunc("/.well-known/caldav", c.HandleWellKnownCalDAV)
+	mux.HandleFunc("/caldav", c.HandleCalDAV)
+	mux.HandleFunc("/caldav/", c.HandleCalDAV)
+
+	mux.HandleFunc("GET /api/v1/journal", c.HandleAPIJournal)
+	mux.HandleFunc("GET /api/v1/journal/{id}", c.HandleAPIJournalEntry)
+
+	mux.HandleFunc("POST /api/v1/journal", c.HandleAPICreateJournalEntry)
+	mux.HandleFunc("PUT /api/v1/journal/{id}", c.HandleAPIUpdateJournalEntry)
+	mux.HandleFunc("DELETE /api/v1/journal/{id}", c.HandleAPIDeleteJournalEntry)
+
+	mux.HandleFunc("GET /api/v1/contacts", c.HandleAPIContacts)
+	mux.HandleFunc("GET /api/v1/contacts/{id}", c.HandleAPIContact)
+
+	mux.HandleFunc("POST /api/v1/contacts", c.HandleAPICreateContact)
+	mux.HandleFunc("PUT /api/v1/contacts/{id}", c.HandleAPIUpdateContact)
+	mux.HandleFunc("DELETE /api/v1/contacts/{id}", c.HandleAPIDeleteContact)
+
+	mux.HandleFunc("GET /api/v1/contacts/{contactID}/debts", c.HandleAPIDebts)
+	mux.HandleFunc("GET /api/v1/contacts/{contactID}/debts/{id}", c.HandleAPIDebt)
+
+	mux.HandleFunc("POST /api/v1/contacts/{contactID}/debts", c.HandleAPICreateDebt)
+	mux.HandleFunc("POST /api/v1/contacts/{contactID}/debts/{id}/payments", c.HandleAPICreateDebtPayment)
+	mux.HandleFunc("PUT /api/v1/contacts/{contactID}/debts/{id}", c.HandleAPIUpdateDebt)
+	mux.HandleFunc("DELETE /api/v1/contacts/{contactID}/debts/{id}", c.HandleAPISettleDebt)
+
+	mux.HandleFunc("GET /api/v1/contacts/{contactID}/activities", c.HandleAPIActivities)
+	mux.HandleFunc("GET /api/v1/contacts/{contactID}/activities/{id}", c.HandleAPIActivity)
+
+	mux.HandleFunc("POST /api/v1/contacts/{contactID}/activities", c.HandleAPICreateActivity)
+	mux.HandleFunc("PUT /api/v1/contacts/{contactID}/activities/{id}", c.HandleAPIUpdateActivity)
+	mux.HandleFunc("DELETE /api/v1/contacts/{contactID}/activities/{id}", c.HandleAPIDeleteActivity)
+
+	mux.HandleFunc("GET /api/v1/userdata", c.HandleAPIUserData)
+
+	mux.HandleFunc("POST /api/v1/userdata", c.HandleAPICreateUserData)
+	mux.HandleFunc("POST /api/v1/userdata/confirm", c.HandleAPIConfirmUserData)
+	mux.HandleFunc("POST /api/v1/userdata/cancel", c.HandleAPICancelUserData)
+	mux.HandleFunc("DELETE /api/v1/userdata", c.HandleAPIDeleteUserData)
+
+	mux.HandleFunc("GET /api/v1/search", c.HandleAPISearch)
+
+	mux.HandleFunc("/api/", c.HandleAPINotFound)
+
+	mux.HandleFunc("GET /.well-known/openapi.json", c.HandleOpenAPI)
+
 	mux.HandleFunc("GET /authorize", c.HandleAuthorize)
+	mux.HandleFunc("POST /authorize/backchannel", c.HandleBackChannelLogout)
 
 	mux.Handle("GET /code/", http.StripPrefix("/code/", http.FileServer(http.FS(senbaraForms.FS))))
 
 	mux.HandleFunc("/", c.HandleIndex)
 
-	mux.ServeHTTP(w, r)
+	return mux
 }
 
 func Handler(w http.ResponseWriter, r *http.Request) {
 	r.URL.Path = r.URL.Query().Get("path")
 
 	if p == nil {
-		p = persisters.NewPersister(os.Getenv("POSTGRES_URL"))
+		databaseURL := os.Getenv("DATABASE_URL")
+		if databaseURL == "" {
+			databaseURL = os.Getenv("POSTGRES_URL")
+		}
 
-		if err := p.Init(); err != nil {
+		persister, err := persisters.NewPersister(databaseURL)
+		if err != nil {
 			panic(err)
 		}
+
+		if err := persister.Init(); err != nil {
+			panic(err)
+		}
+
+		p = persister
 	}
 
 	if c == nil {
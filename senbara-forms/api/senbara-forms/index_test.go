@@ -0,0 +1,364 @@
+package senbaraForms
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/controllers"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/openapi"
+)
+
+// Catch-all patterns which only exist to answer unknown routes
+var fallbackPatterns = map[string]struct{}{
+	"/api/": {},
+}
+
+var pathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+type openAPIParameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+}
+
+type openAPIOperation struct {
+	OperationID string              `json:"operationId"`
+	Security    []map[string][]any  `json:"security"`
+	Parameters  []openAPIParameter  `json:"parameters"`
+	Responses   map[string]struct{} `json:"responses"`
+}
+
+type openAPISchema struct {
+	Properties map[string]json.RawMessage `json:"properties"`
+	Required   []string                   `json:"required"`
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPIDocument(t *testing.T) openAPIDocument {
+	t.Helper()
+
+	var doc openAPIDocument
+	if err := json.Unmarshal(openapi.Spec, &doc); err != nil {
+		t.Fatalf("could not parse OpenAPI document: %v", err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Fatalf("expected an OpenAPI 3 document, got version %q", doc.OpenAPI)
+	}
+
+	return doc
+}
+
+// registeredPatterns returns the patterns of all routes registered in
+// `newSenbaraFormsMux`, since `http.ServeMux` doesn't expose them
+func registeredPatterns(t *testing.T) []string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "index.go", nil, 0)
+	if err != nil {
+		t.Fatalf("could not parse routes: %v", err)
+	}
+
+	patterns := []string{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (selector.Sel.Name != "Handle" && selector.Sel.Name != "HandleFunc") {
+			return true
+		}
+
+		if ident, ok := selector.X.(*ast.Ident); !ok || ident.Name != "mux" {
+			return true
+		}
+
+		literal, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || literal.Kind != token.STRING {
+			t.Fatalf("expected route pattern to be a string literal at %v", fset.Position(call.Pos()))
+		}
+
+		pattern, err := strconv.Unquote(literal.Value)
+		if err != nil {
+			t.Fatalf("could not unquote route pattern: %v", err)
+		}
+
+		patterns = append(patterns, pattern)
+
+		return true
+	})
+
+	if len(patterns) == 0 {
+		t.Fatal("could not find any registered routes")
+	}
+
+	return patterns
+}
+
+func newTestMux() *http.ServeMux {
+	return newSenbaraFormsMux(controllers.NewController(nil, "", "", "", "", ""))
+}
+
+func newOpenAPIRequest(method, path string) *http.Request {
+	return httptest.NewRequest(strings.ToUpper(method), pathParamRegex.ReplaceAllString(path, "1"), nil)
+}
+
+func TestOpenAPIDocumentsAllRoutes(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	mux := newTestMux()
+
+	documented := map[string]struct{}{}
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			_, pattern := mux.Handler(newOpenAPIRequest(method, path))
+			if pattern == "" {
+				t.Errorf("%v %v (%v) is documented but has no handler", strings.ToUpper(method), path, operation.OperationID)
+
+				continue
+			}
+
+			// The index is registered as a catch-all, so only the documented `/`
+			// may resolve to it
+			if _, ok := fallbackPatterns[pattern]; ok || (pattern == "/" && path != "/") {
+				t.Errorf("%v %v (%v) is documented but only matches the catch-all route %q", strings.ToUpper(method), path, operation.OperationID, pattern)
+
+				continue
+			}
+
+			documented[pattern] = struct{}{}
+		}
+	}
+
+	for _, pattern := range registeredPatterns(t) {
+		if _, ok := fallbackPatterns[pattern]; ok {
+			continue
+		}
+
+		if _, ok := documented[pattern]; !ok {
+			t.Errorf("route %q is registered but not documented", pattern)
+		}
+	}
+}
+
+func TestOpenAPIPathParameters(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	mux := newTestMux()
+
+	for path, operations := range doc.Paths {
+		expected := []string{}
+		for _, match := range pathParamRegex.FindAllStringSubmatch(path, -1) {
+			expected = append(expected, match[1])
+		}
+		sort.Strings(expected)
+
+		for method, operation := range operations {
+			actual := []string{}
+			for _, parameter := range operation.Parameters {
+				if parameter.In != "path" {
+					continue
+				}
+
+				if !parameter.Required {
+					t.Errorf("path parameter %q of %v %v must be required", parameter.Name, strings.ToUpper(method), path)
+				}
+
+				actual = append(actual, parameter.Name)
+			}
+			sort.Strings(actual)
+
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("%v %v documents path parameters %v, expected %v", strings.ToUpper(method), path, actual, expected)
+			}
+
+			// Wildcards must have the same names as in the route so that
+			// `r.PathValue` finds them
+			_, pattern := mux.Handler(newOpenAPIRequest(method, path))
+			for _, match := range pathParamRegex.FindAllStringSubmatch(pattern, -1) {
+				name := strings.TrimSuffix(match[1], "...")
+				if !strings.Contains(path, "{"+name+"}") {
+					t.Errorf("%v %v doesn't document the wildcard %q of route %q", strings.ToUpper(method), path, name, pattern)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPIOperationIDsAreUnique(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+
+	operationIDs := map[string]string{}
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			if operation.OperationID == "" {
+				t.Errorf("%v %v has no operation ID", strings.ToUpper(method), path)
+
+				continue
+			}
+
+			if existing, ok := operationIDs[operation.OperationID]; ok {
+				t.Errorf("operation ID %q of %v %v is already used by %v", operation.OperationID, strings.ToUpper(method), path, existing)
+			}
+
+			operationIDs[operation.OperationID] = strings.ToUpper(method) + " " + path
+		}
+	}
+}
+
+func TestOpenAPISchemasMatchModels(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+
+	for name, model := range map[string]any{
+		"Error":               models.APIError{},
+		"JournalEntry":        models.APIJournalEntry{},
+		"Contact":             models.APIContact{},
+		"Debt":                models.APIDebt{},
+		"DebtPayment":         models.APIDebtPayment{},
+		"Activity":            models.APIActivity{},
+		"Manifest":            models.ExportedManifest{},
+		"UserDataImport":      models.APIUserDataImport{},
+		"UserDataImportToken": models.APIUserDataImportToken{},
+	} {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %q is not documented", name)
+
+			continue
+		}
+
+		expected := jsonFieldNames(reflect.TypeOf(model))
+
+		actual := []string{}
+		for property := range schema.Properties {
+			actual = append(actual, property)
+		}
+		sort.Strings(actual)
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("schema %q documents properties %v, but the model has %v", name, actual, expected)
+		}
+
+		for _, required := range schema.Required {
+			if _, ok := schema.Properties[required]; !ok {
+				t.Errorf("schema %q requires undocumented property %q", name, required)
+			}
+		}
+	}
+}
+
+func jsonFieldNames(typ reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		if field.Anonymous {
+			names = append(names, jsonFieldNames(field.Type)...)
+
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func TestOpenAPIUnauthorizedResponses(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+	mux := newTestMux()
+
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			basicAuth := false
+			for _, requirement := range operation.Security {
+				if _, ok := requirement["basicAuth"]; ok {
+					basicAuth = true
+				}
+			}
+
+			if !basicAuth {
+				continue
+			}
+
+			if _, ok := operation.Responses["401"]; !ok {
+				t.Errorf("%v %v requires basic auth but doesn't document a 401 response", strings.ToUpper(method), path)
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, newOpenAPIRequest(method, path))
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("%v %v returned status %v without credentials, expected %v", strings.ToUpper(method), path, rec.Code, http.StatusUnauthorized)
+
+				continue
+			}
+
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("%v %v didn't ask for credentials", strings.ToUpper(method), path)
+			}
+
+			if !strings.HasPrefix(path, "/api/") {
+				continue
+			}
+
+			if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("%v %v returned content type %q, expected JSON", strings.ToUpper(method), path, contentType)
+
+				continue
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Errorf("%v %v returned invalid JSON: %v", strings.ToUpper(method), path, err)
+
+				continue
+			}
+
+			for _, required := range doc.Components.Schemas["Error"].Required {
+				if _, ok := body[required]; !ok {
+					t.Errorf("%v %v returned an error without the required property %q", strings.ToUpper(method), path, required)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPIIsServed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v", http.StatusOK, rec.Code)
+	}
+
+	if !bytes.Equal(rec.Body.Bytes(), openapi.Spec) {
+		t.Fatal("served OpenAPI document doesn't match the embedded one")
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	senbaraForms "github.com/pojntfx/senbara/senbara-forms/api/senbara-forms"
 	"github.com/pojntfx/senbara/senbara-forms/pkg/controllers"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/devauth"
 	"github.com/pojntfx/senbara/senbara-forms/pkg/persisters"
 )
 
@@ -22,16 +23,23 @@ var (
 	errMissingOIDCRedirectURL = errors.New("missing OIDC redirect URL")
 	errMissingPrivacyURL      = errors.New("missing privacy policy URL")
 	errMissingImprintURL      = errors.New("missing imprint URL")
+
+	errDevAuthWithOIDCIssuer = errors.New("can't use the development OIDC provider together with an OIDC issuer")
+	errDevAuthNotLoopback    = errors.New("development OIDC provider must listen on a loopback address")
 )
 
 func main() {
 	laddr := flag.String("laddr", ":1337", "Listen address (port can also be set with `PORT` env variable)")
 	pgaddr := flag.String("pgaddr", "postgresql://postgres@localhost:5432/senbara_forms?sslmode=disable", "Database address (can also be set using `POSTGRES_URL` env variable)")
+	databaseURL := flag.String("database-url", "", "Database URL; the scheme selects the database (postgres://, postgresql://, sqlite://, file: or memory:) (i.e. sqlite:///var/lib/senbara-forms/senbara-forms.db) (defaults to --pgaddr) (can also be set using `DATABASE_URL` env variable)")
 	oidcIssuer := flag.String("oidc-issuer", "", "OIDC Issuer (i.e. https://pojntfx.eu.auth0.com/) (can also be set using the OIDC_ISSUER env variable)")
 	oidcClientID := flag.String("oidc-client-id", "", "OIDC Client ID (i.e. myoidcclientid) (can also be set using the OIDC_CLIENT_ID env variable)")
 	oidcRedirectURL := flag.String("oidc-redirect-url", "http://localhost:1337/authorize", "OIDC redirect URL (can also be set using the OIDC_REDIRECT_URL env variable)")
 	privacyURL := flag.String("privacy-url", "", "Privacy policy URL (can also be set using the PRIVACY_URL env variable)")
 	imprintURL := flag.String("imprint-url", "", "Imprint URL (can also be set using the IMPRINT_URL env variable)")
+	devAuth := flag.Bool("dev-auth", false, "Sign in with an embedded OIDC provider that doesn't check passwords instead of --oidc-issuer (only for local development and tests, never use this in production)")
+	devAuthLaddr := flag.String("dev-auth-laddr", "localhost:1338", "Listen address for the embedded OIDC provider if --dev-auth is set (must be a loopback address)")
+	devAuthEmails := flag.String("dev-auth-emails", "jean.doe@example.com", "Comma-separated list of verified emails the embedded OIDC provider can sign in as if --dev-auth is set")
 
 	flag.Parse()
 
@@ -61,6 +69,16 @@ func main() {
 		*pgaddr = v
 	}
 
+	if v := os.Getenv("DATABASE_URL"); v != "" {
+		log.Println("Using database URL from DATABASE_URL env variable")
+
+		*databaseURL = v
+	}
+
+	if strings.TrimSpace(*databaseURL) == "" {
+		*databaseURL = *pgaddr
+	}
+
 	if v := os.Getenv("OIDC_ISSUER"); v != "" {
 		log.Println("Using OIDC issuer from OIDC_ISSUER env variable")
 
@@ -91,6 +109,47 @@ func main() {
 		*imprintURL = v
 	}
 
+	// The development OIDC provider is deliberately not configurable with env
+	// variables, which are how deployments are usually configured
+	if *devAuth {
+		if strings.TrimSpace(*oidcIssuer) != "" {
+			panic(errDevAuthWithOIDCIssuer)
+		}
+
+		if !devauth.IsLoopback(*devAuthLaddr) {
+			panic(errDevAuthNotLoopback)
+		}
+
+		if strings.TrimSpace(*oidcClientID) == "" {
+			*oidcClientID = "senbara-forms-dev"
+		}
+
+		lis, err := net.Listen("tcp", *devAuthLaddr)
+		if err != nil {
+			panic(err)
+		}
+
+		*oidcIssuer = "http://" + lis.Addr().String()
+
+		emails := []string{}
+		for _, email := range strings.Split(*devAuthEmails, ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				emails = append(emails, email)
+			}
+		}
+
+		issuer, err := devauth.NewIssuer(*oidcIssuer, *oidcClientID, *oidcRedirectURL, emails)
+		if err != nil {
+			panic(err)
+		}
+
+		log.Println("WARNING: Using the development OIDC provider, which signs in as", emails, "without a password, on", *oidcIssuer)
+
+		go func() {
+			panic(http.Serve(lis, issuer))
+		}()
+	}
+
 	if strings.TrimSpace(*oidcIssuer) == "" {
 		panic(errMissingOIDCIssuer)
 	}
@@ -111,7 +170,10 @@ func main() {
 		panic(errMissingImprintURL)
 	}
 
-	p := persisters.NewPersister(*pgaddr)
+	p, err := persisters.NewPersister(*databaseURL)
+	if err != nil {
+		panic(err)
+	}
 
 	if err := p.Init(); err != nil {
 		panic(err)
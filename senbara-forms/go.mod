@@ -4,8 +4,10 @@ go 1.23.3
 
 require (
 	github.com/coreos/go-oidc/v3 v3.11.0
+	github.com/go-jose/go-jose/v4 v4.0.2
 	github.com/leonelquinteros/gotext v1.7.0
 	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.24
 	github.com/pressly/goose/v3 v3.23.0
 	github.com/yuin/goldmark v1.7.8
 	golang.org/x/oauth2 v0.24.0
@@ -13,7 +15,6 @@ require (
 )
 
 require (
-	github.com/go-jose/go-jose/v4 v4.0.2 // indirect
 	github.com/mfridman/interpolate v0.0.2 // indirect
 	github.com/sethvargo/go-retry v0.3.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -13,7 +14,32 @@ import (
 
 type activityData struct {
 	pageData
-	Entry models.GetActivityAndContactRow
+	Entry         models.GetActivityAndContactRow
+	Participants  []models.GetActivityParticipantsRow
+	OtherContacts []activityParticipantOption
+}
+
+// activityParticipantOption is a contact which can be picked as a participant
+// of an activity
+type activityParticipantOption struct {
+	models.Contact
+	Selected bool
+}
+
+// parseActivityParticipants returns the contact an activity is managed from
+// followed by the other participants picked in the form
+func parseActivityParticipants(r *http.Request, contactID int32) ([]int32, error) {
+	contactIDs := []int32{contactID}
+	for _, rparticipantID := range r.Form["participant_id"] {
+		participantID, err := strconv.Atoi(rparticipantID)
+		if err != nil {
+			return nil, err
+		}
+
+		contactIDs = append(contactIDs, int32(participantID))
+	}
+
+	return contactIDs, nil
 }
 
 func (b *Controller) HandleAddActivity(w http.ResponseWriter, r *http.Request) {
@@ -55,6 +81,15 @@ func (b *Controller) HandleAddActivity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	otherContacts, err := b.otherContacts(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	if err := b.tpl.ExecuteTemplate(w, "activities_add.html", contactData{
 		pageData: pageData{
 			userData: userData,
@@ -63,7 +98,8 @@ func (b *Controller) HandleAddActivity(w http.ResponseWriter, r *http.Request) {
 			PrivacyURL: b.privacyURL,
 			ImprintURL: b.imprintURL,
 		},
-		Entry: contact,
+		Entry:         contact,
+		OtherContacts: otherContacts,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -138,6 +174,15 @@ func (b *Controller) HandleCreateActivity(w http.ResponseWriter, r *http.Request
 
 	description := r.FormValue("description")
 
+	contactIDs, err := parseActivityParticipants(r, int32(contactID))
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
 	if _, err := b.persister.CreateActivity(
 		r.Context(),
 
@@ -145,7 +190,7 @@ func (b *Controller) HandleCreateActivity(w http.ResponseWriter, r *http.Request
 		date,
 		description,
 
-		int32(contactID),
+		contactIDs,
 		userData.Email,
 	); err != nil {
 		log.Println(errCouldNotInsertIntoDB, err)
@@ -315,6 +360,15 @@ func (b *Controller) HandleUpdateActivity(w http.ResponseWriter, r *http.Request
 
 	description := r.FormValue("description")
 
+	contactIDs, err := parseActivityParticipants(r, int32(contactID))
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
 	if err := b.persister.UpdateActivity(
 		r.Context(),
 
@@ -326,6 +380,8 @@ func (b *Controller) HandleUpdateActivity(w http.ResponseWriter, r *http.Request
 		name,
 		date,
 		description,
+
+		contactIDs,
 	); err != nil {
 		log.Println(errCouldNotUpdateInDB, err)
 
@@ -394,6 +450,34 @@ func (b *Controller) HandleEditActivity(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	participants, err := b.persister.GetActivityParticipants(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	otherContacts, err := b.otherContacts(r.Context(), int32(contactID), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	otherContactOptions := []activityParticipantOption{}
+	for _, contact := range otherContacts {
+		otherContactOptions = append(otherContactOptions, activityParticipantOption{
+			Contact: contact,
+			Selected: slices.ContainsFunc(participants, func(participant models.GetActivityParticipantsRow) bool {
+				return participant.ID == contact.ID
+			}),
+		})
+	}
+
 	if err := b.tpl.ExecuteTemplate(w, "activities_edit.html", activityData{
 		pageData: pageData{
 			userData: userData,
@@ -402,7 +486,8 @@ func (b *Controller) HandleEditActivity(w http.ResponseWriter, r *http.Request)
 			PrivacyURL: b.privacyURL,
 			ImprintURL: b.imprintURL,
 		},
-		Entry: activityAndContact,
+		Entry:         activityAndContact,
+		OtherContacts: otherContactOptions,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -469,6 +554,15 @@ func (b *Controller) HandleViewActivity(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	participants, err := b.persister.GetActivityParticipants(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	if err := b.tpl.ExecuteTemplate(w, "activities_view.html", activityData{
 		pageData: pageData{
 			userData: userData,
@@ -479,7 +573,8 @@ func (b *Controller) HandleViewActivity(w http.ResponseWriter, r *http.Request)
 
 			BackURL: fmt.Sprintf("/contacts/view?id=%v", contactID),
 		},
-		Entry: activityAndContact,
+		Entry:        activityAndContact,
+		Participants: participants,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
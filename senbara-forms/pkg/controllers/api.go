@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+const (
+	apiContentType = "application/json"
+
+	maxAPIRequestBodySize = 1024 * 1024
+)
+
+var (
+	errInvalidRequestBody = errors.New("could not use invalid request body")
+	errInvalidPathParam   = errors.New("could not use invalid path parameter")
+)
+
+// authorizeAPI authorizes API clients with personal access tokens and never
+// redirects to the OIDC flow. App passwords can't be used, since they have no
+// scopes and are only meant for the CardDAV and CalDAV servers.
+func (b *Controller) authorizeAPI(w http.ResponseWriter, r *http.Request) (string, int, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="Senbara Forms"`)
+
+		return "", http.StatusUnauthorized, errUnauthorized
+	}
+
+	return b.authorizePersonalAccessToken(w, r, token)
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", apiContentType)
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+	}
+}
+
+// writeAPIError is the JSON equivalent of `http.Error`
+func writeAPIError(w http.ResponseWriter, err error, status int) {
+	writeAPIJSON(w, status, models.APIError{
+		Status: status,
+		Error:  err.Error(),
+	})
+}
+
+func decodeAPIRequest(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(v)
+}
+
+func parseAPIPathID(r *http.Request, name string) (int32, error) {
+	rid := r.PathValue(name)
+	if strings.TrimSpace(rid) == "" {
+		return -1, errInvalidPathParam
+	}
+
+	id, err := strconv.ParseInt(rid, 10, 32)
+	if err != nil {
+		// IDs which don't fit into the `int32` columns can't reference any row
+		if errors.Is(err, strconv.ErrRange) {
+			return -1, errors.Join(errNotFound, err)
+		}
+
+		return -1, errors.Join(errInvalidPathParam, err)
+	}
+
+	return int32(id), nil
+}
+
+func writeAPIPathIDError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNotFound) {
+		writeAPIError(w, errNotFound, http.StatusNotFound)
+
+		return
+	}
+
+	writeAPIError(w, errInvalidPathParam, http.StatusUnprocessableEntity)
+}
+
+func (b *Controller) HandleAPINotFound(w http.ResponseWriter, r *http.Request) {
+	writeAPIError(w, errNotFound, http.StatusNotFound)
+}
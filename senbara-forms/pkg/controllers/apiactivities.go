@@ -0,0 +1,405 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func activityAndContactToAPI(activity models.GetActivityAndContactRow, participants []models.GetActivityParticipantsRow) models.APIActivity {
+	contactIDs := []int32{}
+	for _, participant := range participants {
+		contactIDs = append(contactIDs, participant.ID)
+	}
+
+	return models.APIActivity{
+		ID:          activity.ActivityID,
+		ContactID:   activity.ContactID,
+		ContactIDs:  contactIDs,
+		Name:        activity.Name,
+		Date:        activity.Date.Format("2006-01-02"),
+		Description: activity.Description,
+	}
+}
+
+// getAPIActivity returns an activity of a contact with all of its participants
+func (b *Controller) getAPIActivity(ctx context.Context, id, contactID int32, namespace string) (models.APIActivity, error) {
+	activity, err := b.persister.GetActivityAndContact(ctx, id, contactID, namespace)
+	if err != nil {
+		return models.APIActivity{}, err
+	}
+
+	participants, err := b.persister.GetActivityParticipants(ctx, id, namespace)
+	if err != nil {
+		return models.APIActivity{}, err
+	}
+
+	return activityAndContactToAPI(activity, participants), nil
+}
+
+// apiActivityParticipants returns the contact in the path followed by the
+// other participants in the request body; it returns `nil` if the request
+// doesn't list any participants
+func apiActivityParticipants(contactID int32, activity models.APIActivity) []int32 {
+	if activity.ContactIDs == nil {
+		return nil
+	}
+
+	return append([]int32{contactID}, activity.ContactIDs...)
+}
+
+// parseAPIActivity validates an activity with the same rules as the activity
+// forms and returns its date
+func parseAPIActivity(activity models.APIActivity) (time.Time, error) {
+	if strings.TrimSpace(activity.Name) == "" {
+		return time.Time{}, errInvalidRequestBody
+	}
+
+	date, err := time.Parse("2006-01-02", activity.Date)
+	if err != nil {
+		return time.Time{}, errors.Join(errInvalidRequestBody, err)
+	}
+
+	return date, nil
+}
+
+func (b *Controller) HandleAPIActivities(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	if _, err := b.persister.GetContact(r.Context(), contactID, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	activities, err := b.persister.GetActivities(r.Context(), contactID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	apiActivities := []models.APIActivity{}
+	for _, activity := range activities {
+		apiActivity, err := b.getAPIActivity(r.Context(), activity.ID, contactID, namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+			return
+		}
+
+		apiActivities = append(apiActivities, apiActivity)
+	}
+
+	writeAPIJSON(w, http.StatusOK, apiActivities)
+}
+
+func (b *Controller) HandleAPIActivity(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	activity, err := b.getAPIActivity(r.Context(), id, contactID, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, activity)
+}
+
+func (b *Controller) HandleAPICreateActivity(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	var apiActivity models.APIActivity
+	if err := decodeAPIRequest(w, r, &apiActivity); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	date, err := parseAPIActivity(apiActivity)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := b.persister.CreateActivity(
+		r.Context(),
+
+		apiActivity.Name,
+		date,
+		apiActivity.Description,
+
+		append([]int32{contactID}, apiActivity.ContactIDs...),
+		namespace,
+	)
+	if err != nil {
+		// Activities are only inserted if all participants exist in the namespace
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		writeAPIError(w, errCouldNotInsertIntoDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	created, err := b.getAPIActivity(r.Context(), id, contactID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/contacts/%v/activities/%v", contactID, id))
+
+	writeAPIJSON(w, http.StatusCreated, created)
+}
+
+func (b *Controller) HandleAPIUpdateActivity(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	var apiActivity models.APIActivity
+	if err := decodeAPIRequest(w, r, &apiActivity); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	date, err := parseAPIActivity(apiActivity)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if _, err := b.persister.GetActivityAndContact(r.Context(), id, contactID, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.persister.UpdateActivity(
+		r.Context(),
+
+		id,
+
+		contactID,
+		namespace,
+
+		apiActivity.Name,
+		date,
+		apiActivity.Description,
+
+		apiActivityParticipants(contactID, apiActivity),
+	); err != nil {
+		// Participants are only replaced if all of them exist in the namespace
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotUpdateInDB, err)
+
+		writeAPIError(w, errCouldNotUpdateInDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	updated, err := b.getAPIActivity(r.Context(), id, contactID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, updated)
+}
+
+func (b *Controller) HandleAPIDeleteActivity(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	if _, err := b.persister.GetActivityAndContact(r.Context(), id, contactID, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.persister.DeleteActivity(r.Context(), id, contactID, namespace); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		writeAPIError(w, errCouldNotDeleteFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
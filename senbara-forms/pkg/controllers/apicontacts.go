@@ -0,0 +1,414 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func contactToAPI(contact models.Contact, details models.ContactDetails) models.APIContact {
+	apiContact := models.APIContact{
+		ID:        contact.ID,
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		Nickname:  contact.Nickname,
+		Pronouns:  contact.Pronouns,
+		Notes:     contact.Notes,
+
+		Emails:    []models.APIContactEmail{},
+		Phones:    []models.APIContactPhone{},
+		Addresses: []models.APIContactAddress{},
+		Websites:  []models.APIContactWebsite{},
+	}
+
+	if contact.Birthday.Valid {
+		birthday := contact.Birthday.Time.Format("2006-01-02")
+
+		apiContact.Birthday = &birthday
+	}
+
+	for _, email := range details.Emails {
+		apiContact.Emails = append(apiContact.Emails, models.APIContactEmail{
+			Label: email.Label,
+			Email: email.Email,
+		})
+	}
+
+	for _, phone := range details.Phones {
+		apiContact.Phones = append(apiContact.Phones, models.APIContactPhone{
+			Label:  phone.Label,
+			Number: phone.Number,
+		})
+	}
+
+	for _, address := range details.Addresses {
+		apiContact.Addresses = append(apiContact.Addresses, models.APIContactAddress{
+			Label:   address.Label,
+			Address: address.Address,
+		})
+	}
+
+	for _, website := range details.Websites {
+		apiContact.Websites = append(apiContact.Websites, models.APIContactWebsite{
+			Label: website.Label,
+			URL:   website.Url,
+		})
+	}
+
+	return apiContact
+}
+
+// apiToContact validates a contact with the same rules as the contact forms
+func apiToContact(apiContact models.APIContact) (models.Contact, models.ContactDetails, error) {
+	if strings.TrimSpace(apiContact.FirstName) == "" ||
+		strings.TrimSpace(apiContact.LastName) == "" ||
+		strings.TrimSpace(apiContact.Pronouns) == "" {
+		return models.Contact{}, models.ContactDetails{}, errInvalidRequestBody
+	}
+
+	contact := models.Contact{
+		FirstName: apiContact.FirstName,
+		LastName:  apiContact.LastName,
+		Nickname:  apiContact.Nickname,
+		Pronouns:  apiContact.Pronouns,
+		Notes:     apiContact.Notes,
+	}
+
+	if apiContact.Birthday != nil && strings.TrimSpace(*apiContact.Birthday) != "" {
+		birthday, err := time.Parse("2006-01-02", *apiContact.Birthday)
+		if err != nil {
+			return models.Contact{}, models.ContactDetails{}, errors.Join(errInvalidRequestBody, err)
+		}
+
+		contact.Birthday = sql.NullTime{
+			Time:  birthday,
+			Valid: true,
+		}
+	}
+
+	details := models.ContactDetails{}
+	for _, email := range apiContact.Emails {
+		details.Emails = append(details.Emails, models.ContactEmail{
+			Label: email.Label,
+			Email: email.Email,
+		})
+	}
+
+	for _, phone := range apiContact.Phones {
+		details.Phones = append(details.Phones, models.ContactPhone{
+			Label:  phone.Label,
+			Number: phone.Number,
+		})
+	}
+
+	for _, address := range apiContact.Addresses {
+		details.Addresses = append(details.Addresses, models.ContactAddress{
+			Label:   address.Label,
+			Address: address.Address,
+		})
+	}
+
+	for _, website := range apiContact.Websites {
+		details.Websites = append(details.Websites, models.ContactWebsite{
+			Label: website.Label,
+			Url:   website.URL,
+		})
+	}
+
+	details, err := normalizeContactDetails(details)
+	if err != nil {
+		return models.Contact{}, models.ContactDetails{}, errors.Join(errInvalidRequestBody, err)
+	}
+
+	return contact, details, nil
+}
+
+func (b *Controller) HandleAPIContacts(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contacts, err := b.persister.GetContacts(r.Context(), namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	details, err := b.persister.GetContactDetailsForNamespace(r.Context(), namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	apiContacts := []models.APIContact{}
+	for _, contact := range contacts {
+		apiContacts = append(apiContacts, contactToAPI(contact, details[contact.ID]))
+	}
+
+	writeAPIJSON(w, http.StatusOK, apiContacts)
+}
+
+func (b *Controller) HandleAPIContact(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	contact, err := b.persister.GetContact(r.Context(), id, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	details, err := b.persister.GetContactDetails(r.Context(), id, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, contactToAPI(contact, details))
+}
+
+func (b *Controller) HandleAPICreateContact(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	var apiContact models.APIContact
+	if err := decodeAPIRequest(w, r, &apiContact); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	contact, details, err := apiToContact(apiContact)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	ids, err := b.persister.CreateContacts(r.Context(), []models.Contact{contact}, []models.ContactDetails{details}, namespace)
+	if err != nil || len(ids) != 1 {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		writeAPIError(w, errCouldNotInsertIntoDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	created, err := b.persister.GetContact(r.Context(), ids[0], namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	createdDetails, err := b.persister.GetContactDetails(r.Context(), created.ID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/contacts/%v", created.ID))
+
+	writeAPIJSON(w, http.StatusCreated, contactToAPI(created, createdDetails))
+}
+
+func (b *Controller) HandleAPIUpdateContact(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	var apiContact models.APIContact
+	if err := decodeAPIRequest(w, r, &apiContact); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	contact, details, err := apiToContact(apiContact)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	// Updates of missing rows don't fail, so the contact is fetched first to
+	// be able to distinguish them
+	if _, err := b.persister.GetContact(r.Context(), id, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	var birthday *time.Time
+	if contact.Birthday.Valid {
+		birthday = &contact.Birthday.Time
+	}
+
+	if err := b.persister.UpdateContact(
+		r.Context(),
+		id,
+		contact.FirstName,
+		contact.LastName,
+		contact.Nickname,
+		contact.Pronouns,
+		namespace,
+		birthday,
+		contact.Notes,
+		details,
+	); err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		writeAPIError(w, errCouldNotUpdateInDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	updated, err := b.persister.GetContact(r.Context(), id, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	updatedDetails, err := b.persister.GetContactDetails(r.Context(), id, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, contactToAPI(updated, updatedDetails))
+}
+
+func (b *Controller) HandleAPIDeleteContact(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	if _, err := b.persister.GetContact(r.Context(), id, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.persister.DeleteContact(r.Context(), id, namespace); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		writeAPIError(w, errCouldNotDeleteFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,573 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/money"
+)
+
+// apiDebtPayments returns the payments of a debt, oldest first
+func apiDebtPayments(debtID int32, currency string, payments []models.DebtPayment) []models.APIDebtPayment {
+	apiPayments := []models.APIDebtPayment{}
+	for _, payment := range payments {
+		if payment.DebtID != debtID {
+			continue
+		}
+
+		apiPayments = append(apiPayments, models.APIDebtPayment{
+			ID:          payment.ID,
+			Amount:      json.Number(money.FormatAmount(payment.Amount, currency)),
+			Date:        payment.Date.Format("2006-01-02"),
+			Description: payment.Description,
+		})
+	}
+
+	return apiPayments
+}
+
+func debtAndContactToAPI(debt models.GetDebtAndContactRow, payments []models.DebtPayment) models.APIDebt {
+	return models.APIDebt{
+		ID:          debt.DebtID,
+		ContactID:   debt.ContactID,
+		Amount:      json.Number(money.FormatAmount(debt.Amount, debt.Currency)),
+		Currency:    debt.Currency,
+		Description: debt.Description,
+		Date:        debt.Date.Format("2006-01-02"),
+		Paid:        json.Number(money.FormatAmount(debt.Paid, debt.Currency)),
+		Settled:     debtRemaining(debt.Amount, debt.Paid) <= 0,
+		Payments:    apiDebtPayments(debt.DebtID, debt.Currency, payments),
+	}
+}
+
+// getAPIDebt returns a debt of a contact with all of its payments
+func (b *Controller) getAPIDebt(ctx context.Context, id, contactID int32, namespace string) (models.APIDebt, error) {
+	debt, err := b.persister.GetDebtAndContact(ctx, id, contactID, namespace)
+	if err != nil {
+		return models.APIDebt{}, err
+	}
+
+	payments, err := b.persister.GetDebtPayments(ctx, contactID, namespace)
+	if err != nil {
+		return models.APIDebt{}, err
+	}
+
+	return debtAndContactToAPI(debt, payments), nil
+}
+
+// parseAPIDebt validates a debt with the same rules as the debt forms and
+// returns its amount in minor units, its ISO 4217 currency and its date, which
+// defaults to today. The sign of the amount is kept as-is: negative amounts are
+// owed to the contact.
+func parseAPIDebt(debt models.APIDebt) (int64, string, time.Time, error) {
+	currency, err := money.ParseCurrency(debt.Currency)
+	if err != nil {
+		return 0, "", time.Time{}, errors.Join(errInvalidRequestBody, err)
+	}
+
+	amount, err := money.ParseAmount(string(debt.Amount), currency)
+	if err != nil {
+		return 0, "", time.Time{}, errors.Join(errInvalidRequestBody, err)
+	}
+
+	date, err := parseDebtDate(debt.Date)
+	if err != nil {
+		return 0, "", time.Time{}, errors.Join(errInvalidRequestBody, err)
+	}
+
+	return amount, currency, date, nil
+}
+
+func (b *Controller) HandleAPIDebts(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	if _, err := b.persister.GetContact(r.Context(), contactID, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	debts, err := b.persister.GetDebts(r.Context(), contactID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	payments, err := b.persister.GetDebtPayments(r.Context(), contactID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	apiDebts := []models.APIDebt{}
+	for _, debt := range debts {
+		apiDebts = append(apiDebts, models.APIDebt{
+			ID:          debt.ID,
+			ContactID:   contactID,
+			Amount:      json.Number(money.FormatAmount(debt.Amount, debt.Currency)),
+			Currency:    debt.Currency,
+			Description: debt.Description,
+			Date:        debt.Date.Format("2006-01-02"),
+			Paid:        json.Number(money.FormatAmount(debt.Paid, debt.Currency)),
+			Settled:     debtRemaining(debt.Amount, debt.Paid) <= 0,
+			Payments:    apiDebtPayments(debt.ID, debt.Currency, payments),
+		})
+	}
+
+	writeAPIJSON(w, http.StatusOK, apiDebts)
+}
+
+func (b *Controller) HandleAPIDebt(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	debt, err := b.getAPIDebt(r.Context(), id, contactID, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, debt)
+}
+
+func (b *Controller) HandleAPICreateDebt(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	var apiDebt models.APIDebt
+	if err := decodeAPIRequest(w, r, &apiDebt); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	amount, currency, date, err := parseAPIDebt(apiDebt)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := b.persister.CreateDebt(
+		r.Context(),
+
+		amount,
+		currency,
+		apiDebt.Description,
+		date,
+
+		contactID,
+		namespace,
+	)
+	if err != nil {
+		// Debts are only inserted if the contact exists in the namespace
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		writeAPIError(w, errCouldNotInsertIntoDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	created, err := b.getAPIDebt(r.Context(), id, contactID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/contacts/%v/debts/%v", contactID, id))
+
+	writeAPIJSON(w, http.StatusCreated, created)
+}
+
+func (b *Controller) HandleAPIUpdateDebt(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	var apiDebt models.APIDebt
+	if err := decodeAPIRequest(w, r, &apiDebt); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	amount, currency, date, err := parseAPIDebt(apiDebt)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	debt, err := b.persister.GetDebtAndContact(r.Context(), id, contactID, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	// Debts can't be reduced below what has already been paid back, or change
+	// to a currency with another minor unit than the one of their payments
+	if debt.Paid > money.Abs(amount) || (debt.Paid > 0 && money.Scale(debt.Currency) != money.Scale(currency)) {
+		log.Println(errInvalidRequestBody)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	// Like new payments, existing ones can't predate the debt
+	paidBefore, err := b.hasDebtPaymentsBefore(r.Context(), id, contactID, date, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	if paidBefore {
+		log.Println(errInvalidRequestBody)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.UpdateDebt(
+		r.Context(),
+
+		id,
+
+		contactID,
+		namespace,
+
+		amount,
+		currency,
+		apiDebt.Description,
+		date,
+	); err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		writeAPIError(w, errCouldNotUpdateInDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	updated, err := b.getAPIDebt(r.Context(), id, contactID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, updated)
+}
+
+func (b *Controller) HandleAPISettleDebt(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	debt, err := b.persister.GetDebtAndContact(r.Context(), id, contactID, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	// Settled debts are kept in the history, so settling them again does nothing
+	remaining := debtRemaining(debt.Amount, debt.Paid)
+	if remaining <= 0 {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	if _, err := b.persister.SettleDebt(r.Context(), id, contactID, namespace, remaining, today(), ""); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		writeAPIError(w, errCouldNotInsertIntoDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Controller) HandleAPICreateDebtPayment(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	contactID, err := parseAPIPathID(r, "contactID")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	var apiPayment models.APIDebtPayment
+	if err := decodeAPIRequest(w, r, &apiPayment); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	date, err := parseDebtDate(apiPayment.Date)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	debt, err := b.persister.GetDebtAndContact(r.Context(), id, contactID, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	amount, err := money.ParseAmount(string(apiPayment.Amount), debt.Currency)
+	if err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	// Payments can't exceed what is left of the debt or predate it
+	if amount <= 0 || amount > debtRemaining(debt.Amount, debt.Paid) || date.Before(debt.Date) {
+		log.Println(errInvalidRequestBody)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if _, err := b.persister.SettleDebt(
+		r.Context(),
+
+		id,
+
+		contactID,
+		namespace,
+
+		amount,
+		date,
+		apiPayment.Description,
+	); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		writeAPIError(w, errCouldNotInsertIntoDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	updated, err := b.getAPIDebt(r.Context(), id, contactID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/contacts/%v/debts/%v", contactID, id))
+
+	writeAPIJSON(w, http.StatusCreated, updated)
+}
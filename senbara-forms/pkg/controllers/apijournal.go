@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func journalEntryToAPI(journalEntry models.JournalEntry) models.APIJournalEntry {
+	return models.APIJournalEntry{
+		ID:     journalEntry.ID,
+		Title:  journalEntry.Title,
+		Date:   journalEntry.Date,
+		Body:   journalEntry.Body,
+		Rating: journalEntry.Rating,
+	}
+}
+
+// validateAPIJournalEntry validates a journal entry with the same rules as the
+// journal forms
+func validateAPIJournalEntry(journalEntry models.APIJournalEntry) error {
+	if strings.TrimSpace(journalEntry.Title) == "" || strings.TrimSpace(journalEntry.Body) == "" {
+		return errInvalidRequestBody
+	}
+
+	return nil
+}
+
+func (b *Controller) HandleAPIJournal(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	journalEntries, err := b.persister.GetJournalEntries(r.Context(), namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	apiJournalEntries := []models.APIJournalEntry{}
+	for _, journalEntry := range journalEntries {
+		apiJournalEntries = append(apiJournalEntries, journalEntryToAPI(journalEntry))
+	}
+
+	writeAPIJSON(w, http.StatusOK, apiJournalEntries)
+}
+
+func (b *Controller) HandleAPIJournalEntry(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	journalEntry, err := b.persister.GetJournalEntry(r.Context(), id, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, journalEntryToAPI(journalEntry))
+}
+
+func (b *Controller) HandleAPICreateJournalEntry(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	var apiJournalEntry models.APIJournalEntry
+	if err := decodeAPIRequest(w, r, &apiJournalEntry); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	if err := validateAPIJournalEntry(apiJournalEntry); err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := b.persister.CreateJournalEntry(
+		r.Context(),
+		apiJournalEntry.Title,
+		apiJournalEntry.Body,
+		apiJournalEntry.Rating,
+		namespace,
+	)
+	if err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		writeAPIError(w, errCouldNotInsertIntoDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	created, err := b.persister.GetJournalEntry(r.Context(), id, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/journal/%v", id))
+
+	writeAPIJSON(w, http.StatusCreated, journalEntryToAPI(created))
+}
+
+func (b *Controller) HandleAPIUpdateJournalEntry(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	var apiJournalEntry models.APIJournalEntry
+	if err := decodeAPIRequest(w, r, &apiJournalEntry); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	if err := validateAPIJournalEntry(apiJournalEntry); err != nil {
+		log.Println(err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if _, err := b.persister.GetJournalEntry(r.Context(), id, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.persister.UpdateJournalEntry(
+		r.Context(),
+		id,
+		apiJournalEntry.Title,
+		apiJournalEntry.Body,
+		apiJournalEntry.Rating,
+		namespace,
+	); err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		writeAPIError(w, errCouldNotUpdateInDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	updated, err := b.persister.GetJournalEntry(r.Context(), id, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, journalEntryToAPI(updated))
+}
+
+func (b *Controller) HandleAPIDeleteJournalEntry(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	id, err := parseAPIPathID(r, "id")
+	if err != nil {
+		log.Println(err)
+
+		writeAPIPathIDError(w, err)
+
+		return
+	}
+
+	if _, err := b.persister.GetJournalEntry(r.Context(), id, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, errNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.persister.DeleteJournalEntry(r.Context(), id, namespace); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		writeAPIError(w, errCouldNotDeleteFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
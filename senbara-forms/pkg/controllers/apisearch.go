@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func searchResultToAPI(result models.SearchDocumentsRow) models.APISearchResult {
+	apiResult := models.APISearchResult{
+		EntityName: result.EntityName,
+		ID:         result.EntityID,
+		Rank:       result.Rank,
+		Title:      string(highlightSearchHeadline(result.TitleHeadline)),
+		Snippet:    string(highlightSearchHeadline(result.BodyHeadline)),
+	}
+
+	if result.ContactID.Valid {
+		apiResult.ContactID = &result.ContactID.Int32
+	}
+
+	return apiResult
+}
+
+func (b *Controller) HandleAPISearch(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		log.Println(errInvalidQueryParam)
+
+		writeAPIError(w, errInvalidQueryParam, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	limit := searchResultLimit
+	if rlimit := r.URL.Query().Get("limit"); strings.TrimSpace(rlimit) != "" {
+		limit, err = strconv.Atoi(rlimit)
+		if err != nil || limit < 1 || limit > searchResultLimit {
+			log.Println(errInvalidQueryParam, err)
+
+			writeAPIError(w, errInvalidQueryParam, http.StatusUnprocessableEntity)
+
+			return
+		}
+	}
+
+	results, err := b.persister.Search(r.Context(), query, int32(limit), namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	apiResults := []models.APISearchResult{}
+	for _, result := range results {
+		apiResults = append(apiResults, searchResultToAPI(result))
+	}
+
+	writeAPIJSON(w, http.StatusOK, apiResults)
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func (b *Controller) HandleAPIUserData(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	if err := b.encodeUserData(w, r, namespace); err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// HandleAPICreateUserData stages the JSONL user data export in the request body;
+// like with the HTML form, it is only imported once it has been confirmed
+func (b *Controller) HandleAPICreateUserData(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	staged, err := b.stageUserData(r.Context(), namespace, http.MaxBytesReader(w, r.Body, maxUserDataSize))
+	if err != nil {
+		if isUserDataTooLarge(err) {
+			log.Println(errUserDataTooLarge, err)
+
+			writeAPIError(w, errUserDataTooLarge, http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		if errors.Is(err, errUnsupportedUserDataVersion) {
+			log.Println(err)
+
+			writeAPIError(w, errUnsupportedUserDataVersion, http.StatusUnprocessableEntity)
+
+			return
+		}
+
+		log.Println(errCouldNotReadRequest, err)
+
+		writeAPIError(w, errCouldNotReadRequest, http.StatusBadRequest)
+
+		return
+	}
+
+	token, err := b.storeStagedUserData(r.Context(), staged)
+	if err != nil {
+		if errors.Is(err, errTooManyStagedUserData) {
+			log.Println(err)
+
+			writeAPIError(w, errTooManyStagedUserData, http.StatusTooManyRequests)
+
+			return
+		}
+
+		log.Println(errCouldNotStageUserData, err)
+
+		writeAPIError(w, errCouldNotStageUserData, http.StatusInternalServerError)
+
+		return
+	}
+
+	skipped := []models.APISkippedUserDataRecord{}
+	for _, record := range staged.skipped {
+		skipped = append(skipped, models.APISkippedUserDataRecord{
+			Line:       record.Line,
+			EntityName: record.EntityName,
+			Reason:     record.Reason,
+		})
+	}
+
+	duplicates := []models.APIDuplicateContact{}
+	for _, duplicate := range staged.duplicates {
+		duplicates = append(duplicates, models.APIDuplicateContact{
+			Line:       duplicate.Line,
+			FirstName:  duplicate.FirstName,
+			LastName:   duplicate.LastName,
+			Email:      duplicate.Email,
+			ExistingID: duplicate.ExistingID,
+		})
+	}
+
+	writeAPIJSON(w, http.StatusOK, models.APIUserDataImport{
+		Token: token,
+
+		Manifest: staged.manifest,
+
+		JournalEntries: len(staged.journalEntries),
+		Contacts:       len(staged.contacts),
+		Debts:          len(staged.debts),
+		Activities:     len(staged.activities),
+		Relationships:  len(staged.relationships),
+		Groups:         len(staged.groups),
+
+		Skipped:    skipped,
+		Duplicates: duplicates,
+	})
+}
+
+func (b *Controller) HandleAPIConfirmUserData(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	var importToken models.APIUserDataImportToken
+	if err := decodeAPIRequest(w, r, &importToken); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	if strings.TrimSpace(importToken.Token) == "" {
+		log.Println(errInvalidRequestBody)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	staged, err := b.loadStagedUserData(r.Context(), importToken.Token, namespace)
+	if err != nil {
+		if errors.Is(err, errUnknownStagedUserData) {
+			log.Println(err)
+
+			writeAPIError(w, errUnknownStagedUserData, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.createStagedUserData(r.Context(), importToken.Token, staged); err != nil {
+		log.Println(err)
+
+		if errors.Is(err, errUnknownStagedUserData) {
+			writeAPIError(w, errUnknownStagedUserData, http.StatusNotFound)
+
+			return
+		}
+
+		writeAPIError(w, errCouldNotInsertIntoDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Controller) HandleAPICancelUserData(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	var importToken models.APIUserDataImportToken
+	if err := decodeAPIRequest(w, r, &importToken); err != nil {
+		log.Println(errInvalidRequestBody, err)
+
+		writeAPIError(w, errInvalidRequestBody, http.StatusBadRequest)
+
+		return
+	}
+
+	if _, err := b.loadStagedUserData(r.Context(), importToken.Token, namespace); err != nil {
+		if errors.Is(err, errUnknownStagedUserData) {
+			log.Println(err)
+
+			writeAPIError(w, errUnknownStagedUserData, http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		writeAPIError(w, errCouldNotFetchFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.deleteStagedUserData(r.Context(), importToken.Token, namespace); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		writeAPIError(w, errCouldNotDeleteFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Controller) HandleAPIDeleteUserData(w http.ResponseWriter, r *http.Request) {
+	namespace, status, err := b.authorizeAPI(w, r)
+	if err != nil {
+		log.Println(err)
+
+		writeAPIError(w, err, status)
+
+		return
+	}
+
+	if err := b.persister.DeleteUserData(r.Context(), namespace); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		writeAPIError(w, errCouldNotDeleteFromDB, http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
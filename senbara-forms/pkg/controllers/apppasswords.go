@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+type appPasswordsData struct {
+	pageData
+	Entries     []models.GetAppPasswordsRow
+	NewPassword string
+}
+
+// hashSecret hashes an app password or token for storage. They are random and
+// high-entropy, so a fast hash is sufficient to protect them at rest.
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func generateAppPassword() (string, error) {
+	rawPassword := make([]byte, 20)
+	if _, err := rand.Read(rawPassword); err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(rawPassword)), nil
+}
+
+// authorizeAppPassword authorizes non-browser clients which can't use the OIDC
+// flow in `authorize` with HTTP basic auth, using the user's email as the
+// username and one of their app passwords as the password
+func (b *Controller) authorizeAppPassword(w http.ResponseWriter, r *http.Request) (string, bool, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Senbara Forms", charset="UTF-8"`)
+
+		return "", false, nil
+	}
+
+	namespace, err := b.persister.GetAppPasswordNamespace(r.Context(), hashSecret(password))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Senbara Forms", charset="UTF-8"`)
+
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	if namespace != username {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Senbara Forms", charset="UTF-8"`)
+
+		return "", false, nil
+	}
+
+	return namespace, true, nil
+}
+
+func (b *Controller) renderAppPasswords(w http.ResponseWriter, r *http.Request, userData userData, newPassword string) {
+	appPasswords, err := b.persister.GetAppPasswords(r.Context(), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.tpl.ExecuteTemplate(w, "apppasswords.html", appPasswordsData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       "App Passwords",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+		},
+		Entries:     appPasswords,
+		NewPassword: newPassword,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (b *Controller) HandleAppPasswords(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	b.renderAppPasswords(w, r, userData, "")
+}
+
+func (b *Controller) HandleCreateAppPassword(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	name := r.FormValue("name")
+	if strings.TrimSpace(name) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	password, err := generateAppPassword()
+	if err != nil {
+		log.Println(errCouldNotGenerateAppPassword, err)
+
+		http.Error(w, errCouldNotGenerateAppPassword.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if _, err := b.persister.CreateAppPassword(
+		r.Context(),
+		name,
+		hashSecret(password),
+		userData.Email,
+	); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// The password is only shown once since only its hash is stored
+	b.renderAppPasswords(w, r, userData, password)
+}
+
+func (b *Controller) HandleDeleteAppPassword(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.DeleteAppPassword(r.Context(), int32(id), userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, "/apppasswords", http.StatusFound)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"database/sql"
 	"errors"
 	"log"
 	"net/http"
@@ -24,17 +25,54 @@ type pageData struct {
 type userData struct {
 	Email     string
 	LogoutURL string
+	CSRFToken string
+	SessionID int32
 
 	Locale *gotext.Locale
 }
 
+// logoutURL returns the URL of the OIDC provider's RP-initiated logout. If the
+// provider doesn't advertise an `end_session_endpoint`, users are only signed
+// out of Senbara Forms by `HandleAuthorize`.
+func (b *Controller) logoutURL(idToken string) (string, error) {
+	if b.endSessionEndpoint == "" {
+		return b.oidcRedirectURL, nil
+	}
+
+	logoutURL, err := url.Parse(b.endSessionEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	q := logoutURL.Query()
+	q.Set("id_token_hint", idToken)
+	q.Set("client_id", b.oidcClientID)
+	q.Set("post_logout_redirect_uri", b.oidcRedirectURL)
+	logoutURL.RawQuery = q.Encode()
+
+	return logoutURL.String(), nil
+}
+
 func (b *Controller) authorize(w http.ResponseWriter, r *http.Request) (bool, userData, int, error) {
 	locale, err := b.localize(r)
 	if err != nil {
 		return false, userData{}, http.StatusInternalServerError, errors.Join(errCouldNotLocalize, err)
 	}
 
-	rt, err := r.Cookie(refreshTokenKey)
+	if token, ok := bearerToken(r); ok {
+		namespace, status, err := b.authorizePersonalAccessToken(w, r, token)
+		if err != nil {
+			return false, userData{}, status, err
+		}
+
+		return false, userData{
+			Email: namespace,
+
+			Locale: locale,
+		}, http.StatusOK, nil
+	}
+
+	sc, err := r.Cookie(sessionKey)
 	if err != nil {
 		if errors.Is(err, http.ErrNoCookie) {
 			http.Redirect(w, r, b.config.AuthCodeURL(b.oidcRedirectURL), http.StatusFound)
@@ -44,24 +82,24 @@ func (b *Controller) authorize(w http.ResponseWriter, r *http.Request) (bool, us
 
 		return false, userData{}, http.StatusUnauthorized, errors.Join(errCouldNotLogin, err)
 	}
-	refreshToken := rt.Value
 
-	it, err := r.Cookie(idTokenKey)
+	session, err := b.persister.GetSession(r.Context(), hashSecret(sc.Value))
 	if err != nil {
-		if errors.Is(err, http.ErrNoCookie) {
+		// The session was signed out or revoked
+		if errors.Is(err, sql.ErrNoRows) {
 			http.Redirect(w, r, b.config.AuthCodeURL(b.oidcRedirectURL), http.StatusFound)
 
 			return true, userData{}, http.StatusTemporaryRedirect, nil
 		}
 
-		return false, userData{}, http.StatusUnauthorized, errors.Join(errCouldNotLogin, err)
+		return false, userData{}, http.StatusInternalServerError, errors.Join(errCouldNotLogin, err)
 	}
-	idToken := it.Value
+	idToken := session.IDToken
 
 	id, err := b.verifier.Verify(r.Context(), idToken)
 	if err != nil {
 		oauth2Token, err := b.config.TokenSource(r.Context(), &oauth2.Token{
-			RefreshToken: refreshToken,
+			RefreshToken: session.RefreshToken,
 		}).Token()
 		if err != nil {
 			http.Redirect(w, r, b.config.AuthCodeURL(b.oidcRedirectURL), http.StatusFound)
@@ -84,27 +122,14 @@ func (b *Controller) authorize(w http.ResponseWriter, r *http.Request) (bool, us
 			return true, userData{}, http.StatusOK, nil
 		}
 
-		if refreshToken = oauth2Token.RefreshToken; refreshToken != "" {
-			http.SetCookie(w, &http.Cookie{
-				Name:     refreshTokenKey,
-				Value:    refreshToken,
-				Expires:  time.Now().Add(time.Hour * 24 * 365),
-				HttpOnly: true,
-				Secure:   true,
-				SameSite: http.SameSiteStrictMode,
-				Path:     "/",
-			})
+		refreshToken := session.RefreshToken
+		if oauth2Token.RefreshToken != "" {
+			refreshToken = oauth2Token.RefreshToken
 		}
 
-		http.SetCookie(w, &http.Cookie{
-			Name:     idTokenKey,
-			Value:    idToken,
-			Expires:  oauth2Token.Expiry,
-			HttpOnly: true,
-			Secure:   true,
-			SameSite: http.SameSiteStrictMode,
-			Path:     "/",
-		})
+		if err := b.persister.UpdateSessionTokens(r.Context(), session.ID, refreshToken, idToken); err != nil {
+			return false, userData{}, http.StatusInternalServerError, errors.Join(errCouldNotLogin, err)
+		}
 	}
 
 	var claims struct {
@@ -119,21 +144,24 @@ func (b *Controller) authorize(w http.ResponseWriter, r *http.Request) (bool, us
 		return false, userData{}, http.StatusUnauthorized, errors.Join(errCouldNotLogin, errEmailNotVerified)
 	}
 
-	logoutURL, err := url.Parse(b.oidcIssuer)
-	if err != nil {
-		return false, userData{}, http.StatusUnauthorized, errors.Join(errCouldNotLogin, err)
+	if claims.Email != session.Namespace {
+		return false, userData{}, http.StatusUnauthorized, errors.Join(errCouldNotLogin, errSessionEmailChanged)
 	}
 
-	q := logoutURL.Query()
-	q.Set("id_token_hint", idToken)
-	q.Set("post_logout_redirect_uri", b.oidcRedirectURL)
-	logoutURL.RawQuery = q.Encode()
+	if err := b.persister.UpdateSessionLastSeen(r.Context(), session.ID, clientIP(r)); err != nil {
+		return false, userData{}, http.StatusInternalServerError, errors.Join(errCouldNotLogin, err)
+	}
 
-	logoutURL = logoutURL.JoinPath("oidc", "logout")
+	logoutURL, err := b.logoutURL(idToken)
+	if err != nil {
+		return false, userData{}, http.StatusUnauthorized, errors.Join(errCouldNotLogin, err)
+	}
 
 	return false, userData{
 		Email:     claims.Email,
-		LogoutURL: logoutURL.String(),
+		LogoutURL: logoutURL,
+		CSRFToken: csrfToken(r),
+		SessionID: session.ID,
 
 		Locale: locale,
 	}, http.StatusOK, nil
@@ -159,13 +187,15 @@ func (b *Controller) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
 	// Sign out
 	if authCode == "" {
 		http.SetCookie(w, &http.Cookie{
-			Name:   refreshTokenKey,
+			Name:   sessionKey,
 			Value:  "",
 			MaxAge: -1,
 		})
 
+		clearLegacyTokenCookies(w)
+
 		http.SetCookie(w, &http.Cookie{
-			Name:   idTokenKey,
+			Name:   csrfTokenKey,
 			Value:  "",
 			MaxAge: -1,
 		})
@@ -202,16 +232,6 @@ func (b *Controller) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     refreshTokenKey,
-		Value:    oauth2Token.RefreshToken,
-		Expires:  time.Now().Add(time.Hour * 24 * 365),
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-		Path:     "/",
-	})
-
 	idToken, ok := oauth2Token.Extra("id_token").(string)
 	if !ok {
 		log.Println(errCouldNotLogin, err)
@@ -221,16 +241,98 @@ func (b *Controller) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	id, err := b.verifier.Verify(r.Context(), idToken)
+	if err != nil {
+		log.Println(errCouldNotLogin, err)
+
+		http.Error(w, errCouldNotLogin.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		SessionID     string `json:"sid"`
+	}
+	if err := id.Claims(&claims); err != nil {
+		log.Println(errCouldNotLogin, err)
+
+		http.Error(w, errCouldNotLogin.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	if !claims.EmailVerified {
+		log.Println(errCouldNotLogin, errEmailNotVerified)
+
+		http.Error(w, errEmailNotVerified.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	sessionID, err := generateSessionID()
+	if err != nil {
+		log.Println(errCouldNotGenerateSessionID, err)
+
+		http.Error(w, errCouldNotGenerateSessionID.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// Only the session ID's hash is stored, so that the IDs can't be taken from
+	// the database to sign in
+	if _, err := b.persister.CreateSession(
+		r.Context(),
+
+		hashSecret(sessionID),
+		oauth2Token.RefreshToken,
+		idToken,
+		r.UserAgent(),
+		clientIP(r),
+		id.Subject,
+		claims.SessionID,
+
+		claims.Email,
+	); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// Searches use the text search configuration of the language the user
+	// signed in with, so that reading doesn't have to reindex the documents
+	if err := b.persister.UpdateSearchConfig(r.Context(), textSearchConfig(locale), claims.Email); err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		http.Error(w, errCouldNotUpdateInDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	http.SetCookie(w, &http.Cookie{
-		Name:     idTokenKey,
-		Value:    idToken,
-		Expires:  oauth2Token.Expiry,
+		Name:     sessionKey,
+		Value:    sessionID,
+		Expires:  time.Now().Add(time.Hour * 24 * 365),
 		HttpOnly: true,
 		Secure:   true,
 		SameSite: http.SameSiteStrictMode,
 		Path:     "/",
 	})
 
+	clearLegacyTokenCookies(w)
+
+	// Rotate the CSRF token so that tokens from before signing in can't be reused
+	if _, err := setCSRFToken(w); err != nil {
+		log.Println(errCouldNotGenerateCSRFToken, err)
+
+		http.Error(w, errCouldNotGenerateCSRFToken.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	if err := b.tpl.ExecuteTemplate(w, "redirect.html", redirectData{
 		pageData: pageData{
 			userData: userData{
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+var errInvalidLogoutToken = errors.New("invalid logout token")
+
+// HandleBackChannelLogout implements OpenID Connect Back-Channel Logout 1.0,
+// which the OIDC provider calls to end the sessions of users who signed out
+// there or whose sessions were revoked by an administrator
+func (b *Controller) HandleBackChannelLogout(w http.ResponseWriter, r *http.Request) {
+	// Logout responses must never be cached
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	logoutToken := r.PostFormValue("logout_token")
+	if strings.TrimSpace(logoutToken) == "" {
+		log.Println(errInvalidLogoutToken)
+
+		http.Error(w, errInvalidLogoutToken.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	// Logout tokens are signed by the provider for this client just like ID
+	// tokens, so the same verifier checks their signature, issuer and audience
+	token, err := b.verifier.Verify(r.Context(), logoutToken)
+	if err != nil {
+		log.Println(errInvalidLogoutToken, err)
+
+		http.Error(w, errInvalidLogoutToken.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var claims struct {
+		SessionID string                     `json:"sid"`
+		Events    map[string]json.RawMessage `json:"events"`
+		Nonce     *string                    `json:"nonce"`
+	}
+	if err := token.Claims(&claims); err != nil {
+		log.Println(errInvalidLogoutToken, err)
+
+		http.Error(w, errInvalidLogoutToken.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	// The event and the missing nonce prevent ID tokens from being used as
+	// logout tokens
+	if _, ok := claims.Events[backChannelLogoutEvent]; !ok || claims.Nonce != nil {
+		log.Println(errInvalidLogoutToken)
+
+		http.Error(w, errInvalidLogoutToken.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	switch {
+	case claims.SessionID != "":
+		if err := b.persister.DeleteSessionsForOIDCSession(r.Context(), claims.SessionID); err != nil {
+			log.Println(errCouldNotDeleteFromDB, err)
+
+			http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+	case token.Subject != "":
+		if err := b.persister.DeleteSessionsForSubject(r.Context(), token.Subject); err != nil {
+			log.Println(errCouldNotDeleteFromDB, err)
+
+			http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+	default:
+		log.Println(errInvalidLogoutToken)
+
+		http.Error(w, errInvalidLogoutToken.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
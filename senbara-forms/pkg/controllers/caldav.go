@@ -0,0 +1,822 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/icals"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+const (
+	calDAVRootPath     = "/caldav/"
+	calDAVCalendarPath = "/caldav/activities/"
+
+	calDAVContentType  = "text/calendar; charset=utf-8"
+	calDAVCapabilities = "1, 3, calendar-access"
+
+	davCollectionActivities = "activities"
+)
+
+var (
+	errInvalidICalendar  = errors.New("could not use invalid iCalendar object")
+	errNoMatchingContact = errors.New("could not find a contact with the email address of any of the event's attendees")
+)
+
+func activityETag(activity models.GetActivitiesCalendarForNamespaceRow) string {
+	return fmt.Sprintf(`"%v-%v"`, activity.ID, activity.Version)
+}
+
+func activitiesCTag(activities []models.GetActivitiesCalendarForNamespaceRow) string {
+	hash := sha256.New()
+	for _, activity := range activities {
+		_, _ = hash.Write([]byte(activityETag(activity)))
+	}
+
+	return fmt.Sprintf(`"%v"`, hex.EncodeToString(hash.Sum(nil))[:32])
+}
+
+// groupActivityCalendarRows groups the calendar rows, which contain an activity
+// once for each of its participants, by activity
+func groupActivityCalendarRows(rows []models.GetActivitiesCalendarForNamespaceRow) [][]models.GetActivitiesCalendarForNamespaceRow {
+	activities := [][]models.GetActivitiesCalendarForNamespaceRow{}
+	for _, row := range rows {
+		if last := len(activities) - 1; last >= 0 && activities[last][0].ID == row.ID {
+			activities[last] = append(activities[last], row)
+
+			continue
+		}
+
+		activities = append(activities, []models.GetActivitiesCalendarForNamespaceRow{row})
+	}
+
+	return activities
+}
+
+// activityHref returns the path of an activity, which is the name the client
+// chose if it created the activity
+func activityHref(id int32, resources map[int32]models.GetDAVResourcesRow) string {
+	if resource, ok := resources[id]; ok {
+		return davResourceHref(calDAVCalendarPath, resource.Name)
+	}
+
+	return fmt.Sprintf("%v%v.ics", calDAVCalendarPath, id)
+}
+
+// encodeActivityICalendar encodes an activity as an event with its
+// participants as the attendees, which is how the participants are matched
+// again when the event is updated; the event keeps `uid` if the client created
+// it, since clients match events by their UIDs
+func encodeActivityICalendar(uid string, participants []models.GetActivitiesCalendarForNamespaceRow) (string, error) {
+	activity := participants[0]
+
+	event := icals.Event{
+		UID:         fmt.Sprintf("activity-%v@senbara-forms", activity.ID),
+		Summary:     activity.Name,
+		Description: activity.Description,
+		Start:       activity.Date,
+	}
+	if uid != "" {
+		event.UID = uid
+	}
+
+	for _, participant := range participants {
+		if participant.Email == "" {
+			continue
+		}
+
+		event.Attendees = append(event.Attendees, icals.Attendee{
+			Name:  strings.TrimSpace(participant.FirstName + " " + participant.LastName),
+			Email: participant.Email,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := icals.EncodeObject(&buf, event); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func calDAVPrincipalResource(namespace string) davResource {
+	return davResource{
+		href: calDAVRootPath,
+		props: map[xml.Name]string{
+			{Space: davNamespace, Local: "resourcetype"}:                 "<d:collection/><d:principal/>",
+			{Space: davNamespace, Local: "displayname"}:                  escapeDAVText(namespace),
+			{Space: davNamespace, Local: "current-user-principal"}:       davHref(calDAVRootPath),
+			{Space: davNamespace, Local: "principal-URL"}:                davHref(calDAVRootPath),
+			{Space: calDAVNamespace, Local: "calendar-home-set"}:         davHref(calDAVRootPath),
+			{Space: calDAVNamespace, Local: "calendar-user-address-set"}: davHref("mailto:" + namespace),
+			{Space: davNamespace, Local: "current-user-privilege-set"}:   "<d:privilege><d:read/></d:privilege>",
+		},
+	}
+}
+
+func calDAVCalendarResource(activities []models.GetActivitiesCalendarForNamespaceRow) davResource {
+	ctag := activitiesCTag(activities)
+
+	return davResource{
+		href: calDAVCalendarPath,
+		props: map[xml.Name]string{
+			{Space: davNamespace, Local: "resourcetype"}:                        "<d:collection/><cal:calendar/>",
+			{Space: davNamespace, Local: "displayname"}:                         "Activities",
+			{Space: davNamespace, Local: "current-user-principal"}:              davHref(calDAVRootPath),
+			{Space: davNamespace, Local: "getetag"}:                             escapeDAVText(ctag),
+			{Space: calendarServerNamespace, Local: "getctag"}:                  escapeDAVText(ctag),
+			{Space: calDAVNamespace, Local: "supported-calendar-component-set"}: `<cal:comp name="VEVENT"/>`,
+			{Space: calDAVNamespace, Local: "supported-calendar-data"}:          `<cal:calendar-data content-type="text/calendar" version="2.0"/>`,
+			{Space: davNamespace, Local: "supported-report-set"}:                "<d:supported-report><d:report><cal:calendar-multiget/></d:report></d:supported-report><d:supported-report><d:report><cal:calendar-query/></d:report></d:supported-report>",
+			{Space: davNamespace, Local: "current-user-privilege-set"}:          "<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege><d:privilege><d:write-content/></d:privilege><d:privilege><d:write-properties/></d:privilege><d:privilege><d:bind/></d:privilege><d:privilege><d:unbind/></d:privilege>",
+		},
+	}
+}
+
+func calDAVActivityResource(href, uid string, participants []models.GetActivitiesCalendarForNamespaceRow, withCalendarData bool) (davResource, error) {
+	resource := davResource{
+		href: href,
+		props: map[xml.Name]string{
+			{Space: davNamespace, Local: "resourcetype"}:   "",
+			{Space: davNamespace, Local: "getetag"}:        escapeDAVText(activityETag(participants[0])),
+			{Space: davNamespace, Local: "getcontenttype"}: calDAVContentType,
+		},
+	}
+
+	if withCalendarData {
+		event, err := encodeActivityICalendar(uid, participants)
+		if err != nil {
+			return davResource{}, err
+		}
+
+		resource.props[xml.Name{Space: calDAVNamespace, Local: "calendar-data"}] = escapeDAVText(event)
+	}
+
+	return resource, nil
+}
+
+// parseActivityPath returns the resource name for a path in the calendar such
+// as `/caldav/activities/1.ics`
+func parseActivityPath(p string) (string, bool) {
+	if !strings.HasPrefix(p, calDAVCalendarPath) {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(p, calDAVCalendarPath)
+	if name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+
+	return name, true
+}
+
+// getActivityCalendar returns an activity once for each of its participants
+// and `sql.ErrNoRows` if it doesn't exist
+func (b *Controller) getActivityCalendar(r *http.Request, namespace string, id int32) ([]models.GetActivitiesCalendarForNamespaceRow, error) {
+	rows, err := b.persister.GetActivityCalendar(r.Context(), id, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	participants := []models.GetActivitiesCalendarForNamespaceRow{}
+	for _, row := range rows {
+		participants = append(participants, models.GetActivitiesCalendarForNamespaceRow(row))
+	}
+
+	return participants, nil
+}
+
+// matchAttendeeContacts returns the contacts with the email addresses of the
+// event's attendees; activities always have at least one participant, so
+// events without a matching attendee can't be stored
+func (b *Controller) matchAttendeeContacts(r *http.Request, namespace string, event icals.Event) ([]int32, error) {
+	contactIDs := []int32{}
+	for _, attendee := range event.Attendees {
+		email := strings.TrimSpace(attendee.Email)
+		if email == "" {
+			continue
+		}
+
+		contact, err := b.persister.GetContactByEmail(r.Context(), email, namespace)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		if !slices.Contains(contactIDs, contact.ID) {
+			contactIDs = append(contactIDs, contact.ID)
+		}
+	}
+
+	if len(contactIDs) == 0 {
+		return nil, errNoMatchingContact
+	}
+
+	return contactIDs, nil
+}
+
+func (b *Controller) HandleWellKnownCalDAV(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, calDAVRootPath, http.StatusMovedPermanently)
+}
+
+func (b *Controller) HandleCalDAV(w http.ResponseWriter, r *http.Request) {
+	namespace, ok, err := b.authorizeAppPassword(w, r)
+	if err != nil {
+		log.Println(errCouldNotLogin, err)
+
+		http.Error(w, errCouldNotLogin.Error(), http.StatusInternalServerError)
+
+		return
+	} else if !ok {
+		http.Error(w, errUnauthorized.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	switch r.URL.Path {
+	case strings.TrimSuffix(calDAVRootPath, "/"), calDAVRootPath:
+		switch r.Method {
+		case http.MethodOptions:
+			writeDAVOptions(w, calDAVCapabilities, "OPTIONS, PROPFIND")
+
+		case "PROPFIND":
+			b.handleCalDAVPropfindRoot(w, r, namespace)
+
+		default:
+			http.Error(w, errMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+
+	case strings.TrimSuffix(calDAVCalendarPath, "/"), calDAVCalendarPath:
+		switch r.Method {
+		case http.MethodOptions:
+			writeDAVOptions(w, calDAVCapabilities, "OPTIONS, PROPFIND, REPORT")
+
+		case "PROPFIND":
+			b.handleCalDAVPropfindCalendar(w, r, namespace)
+
+		case "REPORT":
+			b.handleCalDAVReport(w, r, namespace)
+
+		default:
+			http.Error(w, errMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+
+	default:
+		name, ok := parseActivityPath(r.URL.Path)
+		if !ok {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		id, uid, err := b.resolveDAVResourceID(r.Context(), davCollectionActivities, name, namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		switch r.Method {
+		case http.MethodOptions:
+			writeDAVOptions(w, calDAVCapabilities, "OPTIONS, PROPFIND, GET, HEAD, PUT, DELETE")
+
+		case "PROPFIND":
+			b.handleCalDAVPropfindActivity(w, r, namespace, name, id, uid)
+
+		case http.MethodGet, http.MethodHead:
+			b.handleCalDAVGetActivity(w, r, namespace, id, uid)
+
+		case http.MethodPut:
+			b.handleCalDAVPutActivity(w, r, namespace, name, id)
+
+		case http.MethodDelete:
+			b.handleCalDAVDeleteActivity(w, r, namespace, id)
+
+		default:
+			http.Error(w, errMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func (b *Controller) handleCalDAVPropfindRoot(w http.ResponseWriter, r *http.Request, namespace string) {
+	req, err := parseDAVRequest(r)
+	if err != nil {
+		log.Println(errCouldNotParseDAVRequest, err)
+
+		http.Error(w, errCouldNotParseDAVRequest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	resources := []davResource{calDAVPrincipalResource(namespace)}
+
+	if r.Header.Get("Depth") != "0" {
+		activities, err := b.persister.GetActivitiesCalendar(r.Context(), namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		resources = append(resources, calDAVCalendarResource(activities))
+	}
+
+	writeDAVMultistatus(w, resources, req.requestedProps())
+}
+
+func (b *Controller) handleCalDAVPropfindCalendar(w http.ResponseWriter, r *http.Request, namespace string) {
+	req, err := parseDAVRequest(r)
+	if err != nil {
+		log.Println(errCouldNotParseDAVRequest, err)
+
+		http.Error(w, errCouldNotParseDAVRequest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	activities, err := b.persister.GetActivitiesCalendar(r.Context(), namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	resources := []davResource{calDAVCalendarResource(activities)}
+
+	if r.Header.Get("Depth") != "0" {
+		davResources, err := b.getDAVResources(r.Context(), davCollectionActivities, namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		for _, participants := range groupActivityCalendarRows(activities) {
+			resource, err := calDAVActivityResource(activityHref(participants[0].ID, davResources), davResources[participants[0].ID].Uid, participants, false)
+			if err != nil {
+				log.Println(errCouldNotWriteResponse, err)
+
+				http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			resources = append(resources, resource)
+		}
+	}
+
+	writeDAVMultistatus(w, resources, req.requestedProps())
+}
+
+func (b *Controller) handleCalDAVPropfindActivity(w http.ResponseWriter, r *http.Request, namespace, name string, id int32, uid string) {
+	req, err := parseDAVRequest(r)
+	if err != nil {
+		log.Println(errCouldNotParseDAVRequest, err)
+
+		http.Error(w, errCouldNotParseDAVRequest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	participants, err := b.getActivityCalendar(r, namespace, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	resource, err := calDAVActivityResource(davResourceHref(calDAVCalendarPath, name), uid, participants, false)
+	if err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+
+		http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	writeDAVMultistatus(w, []davResource{resource}, req.requestedProps())
+}
+
+func (b *Controller) handleCalDAVReport(w http.ResponseWriter, r *http.Request, namespace string) {
+	req, err := parseDAVRequest(r)
+	if err != nil {
+		log.Println(errCouldNotParseDAVRequest, err)
+
+		http.Error(w, errCouldNotParseDAVRequest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	resources := []davResource{}
+
+	switch req.XMLName {
+	case xml.Name{Space: calDAVNamespace, Local: "calendar-multiget"}:
+		for _, href := range req.Hrefs {
+			// Hrefs can be absolute URLs or paths
+			hrefPath := strings.TrimSpace(href)
+			if u, err := url.Parse(hrefPath); err == nil {
+				hrefPath = u.Path
+			}
+
+			name, ok := parseActivityPath(hrefPath)
+			if !ok {
+				resources = append(resources, davResource{
+					href:   href,
+					status: http.StatusNotFound,
+				})
+
+				continue
+			}
+
+			id, uid, err := b.resolveDAVResourceID(r.Context(), davCollectionActivities, name, namespace)
+			if err != nil {
+				log.Println(errCouldNotFetchFromDB, err)
+
+				http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+				return
+			} else if id == -1 {
+				resources = append(resources, davResource{
+					href:   href,
+					status: http.StatusNotFound,
+				})
+
+				continue
+			}
+
+			participants, err := b.getActivityCalendar(r, namespace, id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					resources = append(resources, davResource{
+						href:   href,
+						status: http.StatusNotFound,
+					})
+
+					continue
+				}
+
+				log.Println(errCouldNotFetchFromDB, err)
+
+				http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			resource, err := calDAVActivityResource(href, uid, participants, true)
+			if err != nil {
+				log.Println(errCouldNotWriteResponse, err)
+
+				http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			resources = append(resources, resource)
+		}
+
+	case xml.Name{Space: calDAVNamespace, Local: "calendar-query"}:
+		// Filters such as time ranges aren't supported, so all activities are
+		// returned and the client is expected to filter them itself
+		activities, err := b.persister.GetActivitiesCalendar(r.Context(), namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		davResources, err := b.getDAVResources(r.Context(), davCollectionActivities, namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		for _, participants := range groupActivityCalendarRows(activities) {
+			resource, err := calDAVActivityResource(activityHref(participants[0].ID, davResources), davResources[participants[0].ID].Uid, participants, true)
+			if err != nil {
+				log.Println(errCouldNotWriteResponse, err)
+
+				http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			resources = append(resources, resource)
+		}
+
+	default:
+		log.Println(errUnsupportedReport, req.XMLName)
+
+		http.Error(w, errUnsupportedReport.Error(), http.StatusForbidden)
+
+		return
+	}
+
+	writeDAVMultistatus(w, resources, req.requestedProps())
+}
+
+func (b *Controller) handleCalDAVGetActivity(w http.ResponseWriter, r *http.Request, namespace string, id int32, uid string) {
+	participants, err := b.getActivityCalendar(r, namespace, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	event, err := encodeActivityICalendar(uid, participants)
+	if err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+
+		http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", calDAVContentType)
+	w.Header().Set("ETag", activityETag(participants[0]))
+
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Length", strconv.Itoa(len(event)))
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	if _, err := io.WriteString(w, event); err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+	}
+}
+
+func (b *Controller) handleCalDAVPutActivity(w http.ResponseWriter, r *http.Request, namespace, name string, id int32) {
+	events, err := icals.Decode(r.Body)
+	if err != nil || len(events) != 1 || strings.TrimSpace(events[0].Summary) == "" {
+		log.Println(errInvalidICalendar, err)
+
+		http.Error(w, errInvalidICalendar.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	event := events[0]
+
+	contactIDs, err := b.matchAttendeeContacts(r, namespace, event)
+	if err != nil {
+		if errors.Is(err, errNoMatchingContact) {
+			log.Println(errNoMatchingContact)
+
+			http.Error(w, errNoMatchingContact.Error(), http.StatusForbidden)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	var existing []models.GetActivitiesCalendarForNamespaceRow
+	if id != -1 {
+		existing, err = b.getActivityCalendar(r, namespace, id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	if len(existing) == 0 {
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+			http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+			return
+		}
+
+		// New activities can't be created with the name of another activity,
+		// since the server names the activities it creates after their IDs
+		if davResourceID(name) != -1 {
+			log.Println(errReservedDAVResourceName)
+
+			http.Error(w, errReservedDAVResourceName.Error(), http.StatusConflict)
+
+			return
+		}
+
+		activityID, err := b.persister.CreateActivity(
+			r.Context(),
+			event.Summary,
+			event.Start,
+			event.Description,
+			contactIDs,
+			namespace,
+		)
+		if err != nil {
+			log.Println(errCouldNotInsertIntoDB, err)
+
+			http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		// The client keeps addressing the activity by the name it chose, so
+		// later PUTs to it update the activity instead of creating another one
+		if err := b.persister.UpsertDAVResource(r.Context(), davCollectionActivities, name, activityID, event.UID, namespace); err != nil {
+			log.Println(errCouldNotInsertIntoDB, err)
+
+			if err := b.persister.DeleteActivity(r.Context(), activityID, contactIDs[0], namespace); err != nil {
+				log.Println(errCouldNotDeleteFromDB, err)
+			}
+
+			http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		created, err := b.getActivityCalendar(r, namespace, activityID)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("ETag", activityETag(created[0]))
+		w.WriteHeader(http.StatusCreated)
+
+		return
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch == "*" {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && ifMatch != "*" && ifMatch != activityETag(existing[0]) {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	// Changing the attendees changes the participants; participants without an
+	// email address can't be attendees, so they are kept
+	for _, participant := range existing {
+		if participant.Email == "" {
+			contactIDs = append(contactIDs, participant.ContactID)
+		}
+	}
+
+	// The version is checked again while updating the activity, since it could
+	// have changed since it was fetched above
+	updated := true
+	if ifMatch == "" || ifMatch == "*" {
+		err = b.persister.UpdateActivity(
+			r.Context(),
+			existing[0].ID,
+			existing[0].ContactID,
+			namespace,
+			event.Summary,
+			event.Start,
+			event.Description,
+			contactIDs,
+		)
+	} else {
+		updated, err = b.persister.UpdateActivityIfVersion(
+			r.Context(),
+			existing[0].ID,
+			existing[0].Version,
+			existing[0].ContactID,
+			namespace,
+			event.Summary,
+			event.Start,
+			event.Description,
+			contactIDs,
+		)
+	}
+	if err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		http.Error(w, errCouldNotUpdateInDB.Error(), http.StatusInternalServerError)
+
+		return
+	} else if !updated {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	updatedActivity, err := b.getActivityCalendar(r, namespace, existing[0].ID)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("ETag", activityETag(updatedActivity[0]))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Controller) handleCalDAVDeleteActivity(w http.ResponseWriter, r *http.Request, namespace string, id int32) {
+	participants, err := b.getActivityCalendar(r, namespace, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && ifMatch != "*" && ifMatch != activityETag(participants[0]) {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	// Like for updates, the version is checked again while deleting the activity
+	deleted := true
+	if ifMatch == "" || ifMatch == "*" {
+		err = b.persister.DeleteActivity(r.Context(), participants[0].ID, participants[0].ContactID, namespace)
+	} else {
+		deleted, err = b.persister.DeleteActivityIfVersion(r.Context(), participants[0].ID, participants[0].Version, participants[0].ContactID, namespace)
+	}
+	if err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	} else if !deleted {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	// The activity has already been deleted, so a failure here only leaves a
+	// name which resolves to a missing activity
+	if err := b.persister.DeleteDAVResources(r.Context(), davCollectionActivities, participants[0].ID, namespace); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
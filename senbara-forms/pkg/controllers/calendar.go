@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/icals"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+type calendarData struct {
+	pageData
+	Feed    *models.GetCalendarFeedRow
+	FeedURL string
+}
+
+func generateCalendarFeedToken() (string, error) {
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(rawToken), nil
+}
+
+// absoluteURL returns the URL of `p` on this server as seen by the client, which
+// is required for links that are used outside of the browser
+func absoluteURL(r *http.Request, p string) string {
+	scheme := "https"
+	if r.TLS == nil {
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+			scheme = proto
+		} else {
+			scheme = "http"
+		}
+	}
+
+	return fmt.Sprintf("%v://%v%v", scheme, r.Host, p)
+}
+
+func (b *Controller) renderCalendar(w http.ResponseWriter, r *http.Request, userData userData, feedURL string) {
+	var feed *models.GetCalendarFeedRow
+	f, err := b.persister.GetCalendarFeed(r.Context(), userData.Email)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+	} else {
+		feed = &f
+	}
+
+	if err := b.tpl.ExecuteTemplate(w, "calendar.html", calendarData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       "Calendar",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+		},
+		Feed:    feed,
+		FeedURL: feedURL,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (b *Controller) HandleCalendar(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	b.renderCalendar(w, r, userData, "")
+}
+
+func (b *Controller) HandleCreateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	token, err := generateCalendarFeedToken()
+	if err != nil {
+		log.Println(errCouldNotGenerateToken, err)
+
+		http.Error(w, errCouldNotGenerateToken.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// Creating a new feed replaces the previous one, which revokes its URL
+	if err := b.persister.UpsertCalendarFeed(r.Context(), hashSecret(token), userData.Email); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// The feed URL is only shown once since only the token's hash is stored
+	b.renderCalendar(w, r, userData, absoluteURL(r, "/calendar/feed.ics?token="+token))
+}
+
+func (b *Controller) HandleDeleteCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := b.persister.DeleteCalendarFeed(r.Context(), userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, "/calendar", http.StatusFound)
+}
+
+func (b *Controller) HandleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	// Calendar clients can't sign in, so the feed is authorized by the secret
+	// token in its URL instead of `authorize`
+	token := r.URL.Query().Get("token")
+	if strings.TrimSpace(token) == "" {
+		log.Println(errInvalidQueryParam)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	namespace, err := b.persister.GetCalendarFeedNamespace(r.Context(), hashSecret(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	contacts, err := b.persister.GetContacts(r.Context(), namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	activities, err := b.persister.GetActivitiesCalendar(r.Context(), namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// UIDs are derived from the IDs of the rows so that calendar clients update
+	// existing events instead of duplicating them
+	events := []icals.Event{}
+	for _, contact := range contacts {
+		if !contact.Birthday.Valid {
+			continue
+		}
+
+		events = append(events, icals.Event{
+			UID:     fmt.Sprintf("contact-%v-birthday@senbara-forms", contact.ID),
+			Summary: fmt.Sprintf("%v %v's birthday", contact.FirstName, contact.LastName),
+			Start:   contact.Birthday.Time,
+			RRule:   "FREQ=YEARLY",
+		})
+	}
+
+	for _, participants := range groupActivityCalendarRows(activities) {
+		names := []string{}
+		for _, participant := range participants {
+			names = append(names, fmt.Sprintf("%v %v", participant.FirstName, participant.LastName))
+		}
+
+		events = append(events, icals.Event{
+			UID:         fmt.Sprintf("activity-%v@senbara-forms", participants[0].ID),
+			Summary:     fmt.Sprintf("%v with %v", participants[0].Name, strings.Join(names, ", ")),
+			Description: participants[0].Description,
+			Start:       participants[0].Date,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="senbara-forms.ics"`)
+
+	if err := icals.Encode(w, "Senbara Forms", events...); err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+
+		http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,740 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/vcards"
+)
+
+const (
+	cardDAVRootPath        = "/carddav/"
+	cardDAVAddressBookPath = "/carddav/contacts/"
+
+	cardDAVContentType  = "text/vcard; charset=utf-8"
+	cardDAVCapabilities = "1, 3, addressbook"
+
+	davCollectionContacts = "contacts"
+)
+
+var (
+	errInvalidVCard = errors.New("could not use invalid vCard")
+)
+
+func contactETag(contact models.Contact) string {
+	return fmt.Sprintf(`"%v-%v"`, contact.ID, contact.Version)
+}
+
+func contactsCTag(contacts []models.Contact) string {
+	hash := sha256.New()
+	for _, contact := range contacts {
+		_, _ = hash.Write([]byte(contactETag(contact)))
+	}
+
+	return fmt.Sprintf(`"%v"`, hex.EncodeToString(hash.Sum(nil))[:32])
+}
+
+// contactHref returns the path of a contact, which is the name the client
+// chose if it created the contact
+func contactHref(id int32, resources map[int32]models.GetDAVResourcesRow) string {
+	if resource, ok := resources[id]; ok {
+		return davResourceHref(cardDAVAddressBookPath, resource.Name)
+	}
+
+	return fmt.Sprintf("%v%v.vcf", cardDAVAddressBookPath, id)
+}
+
+// encodeContactVCard encodes a contact with the UID the client chose if it
+// created the contact, since clients identify contacts by their UIDs and would
+// otherwise see a different contact than the one they created
+func encodeContactVCard(uid string, contact models.Contact, details models.ContactDetails) (string, error) {
+	card := contactToVCard(contact, details)
+	if uid != "" {
+		card.UID = uid
+	}
+
+	var buf bytes.Buffer
+	if err := vcards.Encode(&buf, vcards.Version3, card); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func cardDAVPrincipalResource(namespace string) davResource {
+	return davResource{
+		href: cardDAVRootPath,
+		props: map[xml.Name]string{
+			{Space: davNamespace, Local: "resourcetype"}:               "<d:collection/><d:principal/>",
+			{Space: davNamespace, Local: "displayname"}:                escapeDAVText(namespace),
+			{Space: davNamespace, Local: "current-user-principal"}:     davHref(cardDAVRootPath),
+			{Space: davNamespace, Local: "principal-URL"}:              davHref(cardDAVRootPath),
+			{Space: cardDAVNamespace, Local: "addressbook-home-set"}:   davHref(cardDAVRootPath),
+			{Space: davNamespace, Local: "current-user-privilege-set"}: "<d:privilege><d:read/></d:privilege>",
+		},
+	}
+}
+
+func cardDAVAddressBookResource(contacts []models.Contact) davResource {
+	ctag := contactsCTag(contacts)
+
+	return davResource{
+		href: cardDAVAddressBookPath,
+		props: map[xml.Name]string{
+			{Space: davNamespace, Local: "resourcetype"}:               "<d:collection/><card:addressbook/>",
+			{Space: davNamespace, Local: "displayname"}:                "Contacts",
+			{Space: davNamespace, Local: "current-user-principal"}:     davHref(cardDAVRootPath),
+			{Space: davNamespace, Local: "getetag"}:                    escapeDAVText(ctag),
+			{Space: calendarServerNamespace, Local: "getctag"}:         escapeDAVText(ctag),
+			{Space: cardDAVNamespace, Local: "supported-address-data"}: `<card:address-data-type content-type="text/vcard" version="3.0"/>`,
+			{Space: davNamespace, Local: "supported-report-set"}:       "<d:supported-report><d:report><card:addressbook-multiget/></d:report></d:supported-report><d:supported-report><d:report><card:addressbook-query/></d:report></d:supported-report>",
+			{Space: davNamespace, Local: "current-user-privilege-set"}: "<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege><d:privilege><d:write-content/></d:privilege><d:privilege><d:write-properties/></d:privilege><d:privilege><d:bind/></d:privilege><d:privilege><d:unbind/></d:privilege>",
+		},
+	}
+}
+
+// cardDAVContactResource returns the resource for a contact; the details are
+// only used for the vCard in the address data
+func cardDAVContactResource(href, uid string, contact models.Contact, details models.ContactDetails, withAddressData bool) (davResource, error) {
+	resource := davResource{
+		href: href,
+		props: map[xml.Name]string{
+			{Space: davNamespace, Local: "resourcetype"}:   "",
+			{Space: davNamespace, Local: "getetag"}:        escapeDAVText(contactETag(contact)),
+			{Space: davNamespace, Local: "getcontenttype"}: cardDAVContentType,
+		},
+	}
+
+	if withAddressData {
+		card, err := encodeContactVCard(uid, contact, details)
+		if err != nil {
+			return davResource{}, err
+		}
+
+		resource.props[xml.Name{Space: cardDAVNamespace, Local: "address-data"}] = escapeDAVText(card)
+	}
+
+	return resource, nil
+}
+
+// parseContactPath returns the resource name for a path in the address book
+// such as `/carddav/contacts/1.vcf`
+func parseContactPath(p string) (string, bool) {
+	if !strings.HasPrefix(p, cardDAVAddressBookPath) {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(p, cardDAVAddressBookPath)
+	if name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+
+	return name, true
+}
+
+func (b *Controller) HandleWellKnownCardDAV(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, cardDAVRootPath, http.StatusMovedPermanently)
+}
+
+func (b *Controller) HandleCardDAV(w http.ResponseWriter, r *http.Request) {
+	namespace, ok, err := b.authorizeAppPassword(w, r)
+	if err != nil {
+		log.Println(errCouldNotLogin, err)
+
+		http.Error(w, errCouldNotLogin.Error(), http.StatusInternalServerError)
+
+		return
+	} else if !ok {
+		http.Error(w, errUnauthorized.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	switch r.URL.Path {
+	case strings.TrimSuffix(cardDAVRootPath, "/"), cardDAVRootPath:
+		switch r.Method {
+		case http.MethodOptions:
+			writeDAVOptions(w, cardDAVCapabilities, "OPTIONS, PROPFIND")
+
+		case "PROPFIND":
+			b.handleCardDAVPropfindRoot(w, r, namespace)
+
+		default:
+			http.Error(w, errMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+
+	case strings.TrimSuffix(cardDAVAddressBookPath, "/"), cardDAVAddressBookPath:
+		switch r.Method {
+		case http.MethodOptions:
+			writeDAVOptions(w, cardDAVCapabilities, "OPTIONS, PROPFIND, REPORT")
+
+		case "PROPFIND":
+			b.handleCardDAVPropfindAddressBook(w, r, namespace)
+
+		case "REPORT":
+			b.handleCardDAVReport(w, r, namespace)
+
+		default:
+			http.Error(w, errMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+
+	default:
+		name, ok := parseContactPath(r.URL.Path)
+		if !ok {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		id, uid, err := b.resolveDAVResourceID(r.Context(), davCollectionContacts, name, namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		switch r.Method {
+		case http.MethodOptions:
+			writeDAVOptions(w, cardDAVCapabilities, "OPTIONS, PROPFIND, GET, HEAD, PUT, DELETE")
+
+		case "PROPFIND":
+			b.handleCardDAVPropfindContact(w, r, namespace, name, id, uid)
+
+		case http.MethodGet, http.MethodHead:
+			b.handleCardDAVGetContact(w, r, namespace, id, uid)
+
+		case http.MethodPut:
+			b.handleCardDAVPutContact(w, r, namespace, name, id)
+
+		case http.MethodDelete:
+			b.handleCardDAVDeleteContact(w, r, namespace, id)
+
+		default:
+			http.Error(w, errMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func (b *Controller) handleCardDAVPropfindRoot(w http.ResponseWriter, r *http.Request, namespace string) {
+	req, err := parseDAVRequest(r)
+	if err != nil {
+		log.Println(errCouldNotParseDAVRequest, err)
+
+		http.Error(w, errCouldNotParseDAVRequest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	resources := []davResource{cardDAVPrincipalResource(namespace)}
+
+	if r.Header.Get("Depth") != "0" {
+		contacts, err := b.persister.GetContacts(r.Context(), namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		resources = append(resources, cardDAVAddressBookResource(contacts))
+	}
+
+	writeDAVMultistatus(w, resources, req.requestedProps())
+}
+
+func (b *Controller) handleCardDAVPropfindAddressBook(w http.ResponseWriter, r *http.Request, namespace string) {
+	req, err := parseDAVRequest(r)
+	if err != nil {
+		log.Println(errCouldNotParseDAVRequest, err)
+
+		http.Error(w, errCouldNotParseDAVRequest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	contacts, err := b.persister.GetContacts(r.Context(), namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	resources := []davResource{cardDAVAddressBookResource(contacts)}
+
+	if r.Header.Get("Depth") != "0" {
+		davResources, err := b.getDAVResources(r.Context(), davCollectionContacts, namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		for _, contact := range contacts {
+			resource, err := cardDAVContactResource(contactHref(contact.ID, davResources), davResources[contact.ID].Uid, contact, models.ContactDetails{}, false)
+			if err != nil {
+				log.Println(errCouldNotWriteResponse, err)
+
+				http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			resources = append(resources, resource)
+		}
+	}
+
+	writeDAVMultistatus(w, resources, req.requestedProps())
+}
+
+func (b *Controller) handleCardDAVPropfindContact(w http.ResponseWriter, r *http.Request, namespace, name string, id int32, uid string) {
+	req, err := parseDAVRequest(r)
+	if err != nil {
+		log.Println(errCouldNotParseDAVRequest, err)
+
+		http.Error(w, errCouldNotParseDAVRequest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	contact, err := b.persister.GetContact(r.Context(), id, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	resource, err := cardDAVContactResource(davResourceHref(cardDAVAddressBookPath, name), uid, contact, models.ContactDetails{}, false)
+	if err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+
+		http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	writeDAVMultistatus(w, []davResource{resource}, req.requestedProps())
+}
+
+func (b *Controller) handleCardDAVReport(w http.ResponseWriter, r *http.Request, namespace string) {
+	req, err := parseDAVRequest(r)
+	if err != nil {
+		log.Println(errCouldNotParseDAVRequest, err)
+
+		http.Error(w, errCouldNotParseDAVRequest.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	resources := []davResource{}
+
+	switch req.XMLName {
+	case xml.Name{Space: cardDAVNamespace, Local: "addressbook-multiget"}:
+		for _, href := range req.Hrefs {
+			// Hrefs can be absolute URLs or paths
+			hrefPath := strings.TrimSpace(href)
+			if u, err := url.Parse(hrefPath); err == nil {
+				hrefPath = u.Path
+			}
+
+			name, ok := parseContactPath(hrefPath)
+			if !ok {
+				resources = append(resources, davResource{
+					href:   href,
+					status: http.StatusNotFound,
+				})
+
+				continue
+			}
+
+			id, uid, err := b.resolveDAVResourceID(r.Context(), davCollectionContacts, name, namespace)
+			if err != nil {
+				log.Println(errCouldNotFetchFromDB, err)
+
+				http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+				return
+			} else if id == -1 {
+				resources = append(resources, davResource{
+					href:   href,
+					status: http.StatusNotFound,
+				})
+
+				continue
+			}
+
+			contact, err := b.persister.GetContact(r.Context(), id, namespace)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					resources = append(resources, davResource{
+						href:   href,
+						status: http.StatusNotFound,
+					})
+
+					continue
+				}
+
+				log.Println(errCouldNotFetchFromDB, err)
+
+				http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			details, err := b.persister.GetContactDetails(r.Context(), id, namespace)
+			if err != nil {
+				log.Println(errCouldNotFetchFromDB, err)
+
+				http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			resource, err := cardDAVContactResource(href, uid, contact, details, true)
+			if err != nil {
+				log.Println(errCouldNotWriteResponse, err)
+
+				http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			resources = append(resources, resource)
+		}
+
+	case xml.Name{Space: cardDAVNamespace, Local: "addressbook-query"}:
+		// Filters aren't supported, so all contacts are returned and the client is
+		// expected to filter them itself
+		contacts, err := b.persister.GetContacts(r.Context(), namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		details, err := b.persister.GetContactDetailsForNamespace(r.Context(), namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		davResources, err := b.getDAVResources(r.Context(), davCollectionContacts, namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		for _, contact := range contacts {
+			resource, err := cardDAVContactResource(contactHref(contact.ID, davResources), davResources[contact.ID].Uid, contact, details[contact.ID], true)
+			if err != nil {
+				log.Println(errCouldNotWriteResponse, err)
+
+				http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+				return
+			}
+
+			resources = append(resources, resource)
+		}
+
+	default:
+		log.Println(errUnsupportedReport, req.XMLName)
+
+		http.Error(w, errUnsupportedReport.Error(), http.StatusForbidden)
+
+		return
+	}
+
+	writeDAVMultistatus(w, resources, req.requestedProps())
+}
+
+func (b *Controller) handleCardDAVGetContact(w http.ResponseWriter, r *http.Request, namespace string, id int32, uid string) {
+	contact, err := b.persister.GetContact(r.Context(), id, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	details, err := b.persister.GetContactDetails(r.Context(), id, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	card, err := encodeContactVCard(uid, contact, details)
+	if err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+
+		http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", cardDAVContentType)
+	w.Header().Set("ETag", contactETag(contact))
+
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Length", strconv.Itoa(len(card)))
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	if _, err := io.WriteString(w, card); err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+	}
+}
+
+func (b *Controller) handleCardDAVPutContact(w http.ResponseWriter, r *http.Request, namespace, name string, id int32) {
+	cards, err := vcards.Decode(r.Body)
+	if err != nil || len(cards) != 1 {
+		log.Println(errInvalidVCard, err)
+
+		http.Error(w, errInvalidVCard.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	contact, details := vCardToContact(cards[0])
+
+	var existing *models.Contact
+	if id != -1 {
+		c, err := b.persister.GetContact(r.Context(), id, namespace)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		} else if err == nil {
+			existing = &c
+		}
+	}
+
+	if existing == nil {
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+			http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+			return
+		}
+
+		// New contacts can't be created with the name of another contact, since
+		// the server names the contacts it creates after their IDs
+		if davResourceID(name) != -1 {
+			log.Println(errReservedDAVResourceName)
+
+			http.Error(w, errReservedDAVResourceName.Error(), http.StatusConflict)
+
+			return
+		}
+
+		ids, err := b.persister.CreateContacts(r.Context(), []models.Contact{contact}, []models.ContactDetails{details}, namespace)
+		if err != nil || len(ids) != 1 {
+			log.Println(errCouldNotInsertIntoDB, err)
+
+			http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		// The client keeps addressing the contact by the name it chose, so
+		// later PUTs to it update the contact instead of creating another one,
+		// and identifying it by the UID it chose
+		if err := b.persister.UpsertDAVResource(r.Context(), davCollectionContacts, name, ids[0], cards[0].UID, namespace); err != nil {
+			log.Println(errCouldNotInsertIntoDB, err)
+
+			if err := b.persister.DeleteContact(r.Context(), ids[0], namespace); err != nil {
+				log.Println(errCouldNotDeleteFromDB, err)
+			}
+
+			http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		created, err := b.persister.GetContact(r.Context(), ids[0], namespace)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("ETag", contactETag(created))
+		w.WriteHeader(http.StatusCreated)
+
+		return
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch == "*" {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && ifMatch != "*" && ifMatch != contactETag(*existing) {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	var birthday *time.Time
+	if contact.Birthday.Valid {
+		birthday = &contact.Birthday.Time
+	}
+
+	// The version is checked again while updating the contact, since it could
+	// have changed since it was fetched above
+	updated := true
+	if ifMatch == "" || ifMatch == "*" {
+		err = b.persister.UpdateContact(
+			r.Context(),
+			existing.ID,
+			contact.FirstName,
+			contact.LastName,
+			contact.Nickname,
+			contact.Pronouns,
+			namespace,
+			birthday,
+			contact.Notes,
+			details,
+		)
+	} else {
+		updated, err = b.persister.UpdateContactIfVersion(
+			r.Context(),
+			existing.ID,
+			existing.Version,
+			contact.FirstName,
+			contact.LastName,
+			contact.Nickname,
+			contact.Pronouns,
+			namespace,
+			birthday,
+			contact.Notes,
+			details,
+		)
+	}
+	if err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		http.Error(w, errCouldNotUpdateInDB.Error(), http.StatusInternalServerError)
+
+		return
+	} else if !updated {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	updatedContact, err := b.persister.GetContact(r.Context(), existing.ID, namespace)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("ETag", contactETag(updatedContact))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Controller) handleCardDAVDeleteContact(w http.ResponseWriter, r *http.Request, namespace string, id int32) {
+	contact, err := b.persister.GetContact(r.Context(), id, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, errNotFound.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && ifMatch != "*" && ifMatch != contactETag(contact) {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	// Like for updates, the version is checked again while deleting the contact
+	deleted := true
+	if ifMatch == "" || ifMatch == "*" {
+		err = b.persister.DeleteContact(r.Context(), contact.ID, namespace)
+	} else {
+		deleted, err = b.persister.DeleteContactIfVersion(r.Context(), contact.ID, contact.Version, namespace)
+	}
+	if err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	} else if !deleted {
+		http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+
+		return
+	}
+
+	// The contact has already been deleted, so a failure here only leaves a
+	// name which resolves to a missing contact
+	if err := b.persister.DeleteDAVResources(r.Context(), davCollectionContacts, contact.ID, namespace); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+var (
+	errInvalidContactLabel = errors.New("invalid contact detail label")
+	errInvalidEmail        = errors.New("invalid email address")
+	errInvalidPhoneNumber  = errors.New("invalid phone number, expected E.164 format like +4915112345678")
+	errInvalidWebsite      = errors.New("invalid website")
+)
+
+const (
+	contactLabelHome   = "home"
+	contactLabelWork   = "work"
+	contactLabelMobile = "mobile"
+	contactLabelOther  = "other"
+)
+
+var (
+	contactEmailLabels   = []string{contactLabelHome, contactLabelWork, contactLabelOther}
+	contactPhoneLabels   = []string{contactLabelMobile, contactLabelHome, contactLabelWork, contactLabelOther}
+	contactAddressLabels = []string{contactLabelHome, contactLabelWork, contactLabelOther}
+	contactWebsiteLabels = []string{contactLabelHome, contactLabelWork, contactLabelOther}
+
+	e164PhoneNumber = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+)
+
+// contactDetailLabels are the labels the add and edit forms offer for each
+// kind of contact detail
+type contactDetailLabels struct {
+	Email   []string
+	Phone   []string
+	Address []string
+	Website []string
+}
+
+var contactLabels = contactDetailLabels{
+	Email:   contactEmailLabels,
+	Phone:   contactPhoneLabels,
+	Address: contactAddressLabels,
+	Website: contactWebsiteLabels,
+}
+
+func normalizeContactLabel(label string, labels []string) (string, error) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" {
+		return contactLabelOther, nil
+	}
+
+	if !slices.Contains(labels, label) {
+		return "", errors.Join(errInvalidContactLabel, fmt.Errorf("label %v", label))
+	}
+
+	return label, nil
+}
+
+// normalizePhoneNumber removes the spaces, dashes, dots and parentheses phone
+// numbers are often formatted with and replaces a leading international call
+// prefix of `00` with `+`, so that i.e. "+49 (151) 123-456" becomes
+// "+49151123456", and fails if the result isn't in E.164 format
+func normalizePhoneNumber(number string) (string, error) {
+	normalized := strings.NewReplacer(
+		" ", "",
+		"-", "",
+		".", "",
+		"(", "",
+		")", "",
+		"/", "",
+	).Replace(strings.TrimSpace(number))
+
+	if rest, ok := strings.CutPrefix(normalized, "00"); ok {
+		normalized = "+" + rest
+	}
+
+	if !e164PhoneNumber.MatchString(normalized) {
+		return "", errors.Join(errInvalidPhoneNumber, fmt.Errorf("phone number %v", number))
+	}
+
+	return normalized, nil
+}
+
+// normalizeWebsite adds `https://` to websites without a scheme and fails if
+// the website isn't an HTTP or HTTPS URL
+func normalizeWebsite(website string) (string, error) {
+	website = strings.TrimSpace(website)
+	if !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+
+	u, err := url.Parse(website)
+	if err != nil {
+		return "", errors.Join(errInvalidWebsite, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.Join(errInvalidWebsite, fmt.Errorf("website %v", website))
+	}
+
+	return u.String(), nil
+}
+
+// normalizeContactDetails validates the details of a contact with the same rules
+// for the forms and the API, dropping the details without a value and
+// labelling the ones without a label as "other"
+func normalizeContactDetails(details models.ContactDetails) (models.ContactDetails, error) {
+	normalized := models.ContactDetails{}
+
+	for _, email := range details.Emails {
+		email.Email = strings.TrimSpace(email.Email)
+		if email.Email == "" {
+			continue
+		}
+
+		if _, err := mail.ParseAddress(email.Email); err != nil {
+			return models.ContactDetails{}, errors.Join(errInvalidEmail, err)
+		}
+
+		label, err := normalizeContactLabel(email.Label, contactEmailLabels)
+		if err != nil {
+			return models.ContactDetails{}, err
+		}
+		email.Label = label
+
+		normalized.Emails = append(normalized.Emails, email)
+	}
+
+	for _, phone := range details.Phones {
+		if strings.TrimSpace(phone.Number) == "" {
+			continue
+		}
+
+		number, err := normalizePhoneNumber(phone.Number)
+		if err != nil {
+			return models.ContactDetails{}, err
+		}
+		phone.Number = number
+
+		label, err := normalizeContactLabel(phone.Label, contactPhoneLabels)
+		if err != nil {
+			return models.ContactDetails{}, err
+		}
+		phone.Label = label
+
+		normalized.Phones = append(normalized.Phones, phone)
+	}
+
+	for _, address := range details.Addresses {
+		address.Address = strings.TrimSpace(address.Address)
+		if address.Address == "" {
+			continue
+		}
+
+		label, err := normalizeContactLabel(address.Label, contactAddressLabels)
+		if err != nil {
+			return models.ContactDetails{}, err
+		}
+		address.Label = label
+
+		normalized.Addresses = append(normalized.Addresses, address)
+	}
+
+	for _, website := range details.Websites {
+		if strings.TrimSpace(website.Url) == "" {
+			continue
+		}
+
+		u, err := normalizeWebsite(website.Url)
+		if err != nil {
+			return models.ContactDetails{}, err
+		}
+		website.Url = u
+
+		label, err := normalizeContactLabel(website.Label, contactWebsiteLabels)
+		if err != nil {
+			return models.ContactDetails{}, err
+		}
+		website.Label = label
+
+		normalized.Websites = append(normalized.Websites, website)
+	}
+
+	return normalized, nil
+}
+
+// parseContactDetails parses the details of the add and edit forms, which have
+// a label and a value field for every email address, phone number, postal
+// address and website, i.e. `phone_label` and `phone`
+func parseContactDetails(form url.Values) (models.ContactDetails, error) {
+	details := models.ContactDetails{}
+
+	emails, emailLabels := form["email"], form["email_label"]
+	for i, email := range emails {
+		details.Emails = append(details.Emails, models.ContactEmail{
+			Label: formValueAt(emailLabels, i),
+			Email: email,
+		})
+	}
+
+	phones, phoneLabels := form["phone"], form["phone_label"]
+	for i, phone := range phones {
+		details.Phones = append(details.Phones, models.ContactPhone{
+			Label:  formValueAt(phoneLabels, i),
+			Number: phone,
+		})
+	}
+
+	addresses, addressLabels := form["address"], form["address_label"]
+	for i, address := range addresses {
+		details.Addresses = append(details.Addresses, models.ContactAddress{
+			Label:   formValueAt(addressLabels, i),
+			Address: address,
+		})
+	}
+
+	websites, websiteLabels := form["website"], form["website_label"]
+	for i, website := range websites {
+		details.Websites = append(details.Websites, models.ContactWebsite{
+			Label: formValueAt(websiteLabels, i),
+			Url:   website,
+		})
+	}
+
+	return normalizeContactDetails(details)
+}
+
+func formValueAt(values []string, i int) string {
+	if i >= len(values) {
+		return ""
+	}
+
+	return values[i]
+}
+
+// normalizeExportedContactDetails validates the details of an imported contact
+// with the same rules as the forms and the API
+func normalizeExportedContactDetails(contact models.ExportedContact) (models.ExportedContact, error) {
+	details := models.ContactDetails{}
+	for _, email := range contact.Emails {
+		details.Emails = append(details.Emails, models.ContactEmail{
+			Label: email.Label,
+			Email: email.Email,
+		})
+	}
+
+	for _, phone := range contact.Phones {
+		details.Phones = append(details.Phones, models.ContactPhone{
+			Label:  phone.Label,
+			Number: phone.Number,
+		})
+	}
+
+	for _, address := range contact.Addresses {
+		details.Addresses = append(details.Addresses, models.ContactAddress{
+			Label:   address.Label,
+			Address: address.Address,
+		})
+	}
+
+	for _, website := range contact.Websites {
+		details.Websites = append(details.Websites, models.ContactWebsite{
+			Label: website.Label,
+			Url:   website.URL,
+		})
+	}
+
+	details, err := normalizeContactDetails(details)
+	if err != nil {
+		return models.ExportedContact{}, err
+	}
+
+	contact.Emails = []models.ExportedContactEmail{}
+	for _, email := range details.Emails {
+		contact.Emails = append(contact.Emails, models.ExportedContactEmail{
+			Label: email.Label,
+			Email: email.Email,
+		})
+	}
+
+	contact.Phones = []models.ExportedContactPhone{}
+	for _, phone := range details.Phones {
+		contact.Phones = append(contact.Phones, models.ExportedContactPhone{
+			Label:  phone.Label,
+			Number: phone.Number,
+		})
+	}
+
+	contact.Addresses = []models.ExportedContactAddress{}
+	for _, address := range details.Addresses {
+		contact.Addresses = append(contact.Addresses, models.ExportedContactAddress{
+			Label:   address.Label,
+			Address: address.Address,
+		})
+	}
+
+	contact.Websites = []models.ExportedContactWebsite{}
+	for _, website := range details.Websites {
+		contact.Websites = append(contact.Websites, models.ExportedContactWebsite{
+			Label: website.Label,
+			URL:   website.Url,
+		})
+	}
+
+	return contact, nil
+}
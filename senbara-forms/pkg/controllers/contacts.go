@@ -4,24 +4,50 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"net/mail"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/persisters"
 )
 
+var contactsSorts = []string{
+	persisters.ContactsSortFirstName,
+	persisters.ContactsSortLastName,
+	persisters.ContactsSortBirthday,
+	persisters.ContactsSortLastContacted,
+}
+
 type contactsData struct {
 	pageData
-	Entries []models.Contact
+	Entries []models.ListContactsRow
+
+	Sort              string
+	HasDebts          bool
+	BirthdayThisMonth bool
+	Tag               string
+	Tags              []models.Tag
+
+	FirstPage bool
+	NextURL   string
 }
 
 type contactData struct {
 	pageData
 	Entry      models.Contact
-	Debts      []models.GetDebtsRow
+	Details    models.ContactDetails
+	Labels     contactDetailLabels
+	Tags       []string
+	Debts      debtLedger
+	DebtsTab   string
 	Activities []models.GetActivitiesRow
+
+	Relationships     []contactRelationship
+	RelationshipTypes []relationshipType
+	OtherContacts     []models.Contact
+	Editing           bool
 }
 
 func (b *Controller) HandleContacts(w http.ResponseWriter, r *http.Request) {
@@ -36,7 +62,57 @@ func (b *Controller) HandleContacts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	contacts, err := b.persister.GetContacts(r.Context(), userData.Email)
+	sort := r.FormValue("sort")
+	if strings.TrimSpace(sort) == "" {
+		sort = persisters.ContactsSortFirstName
+	} else if !slices.Contains(contactsSorts, sort) {
+		log.Println(errInvalidQueryParam)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	hasDebts := r.FormValue("has_debts") != ""
+
+	birthdayThisMonth := r.FormValue("birthday_this_month") != ""
+
+	birthdayMonth := int32(0)
+	if birthdayThisMonth {
+		birthdayMonth = int32(time.Now().Month())
+	}
+
+	tag := normalizeTag(r.FormValue("tag"))
+
+	afterKey, afterID, err := decodeCursor(r.FormValue("after"))
+	if err != nil {
+		log.Println(errInvalidQueryParam, err)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	// Fetching one more contact than fits on the page tells whether there is
+	// a next page
+	contacts, err := b.persister.ListContacts(r.Context(), userData.Email, sort, hasDebts, birthdayMonth, tag, afterKey, afterID, pageSize+1)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	nextURL := ""
+	if len(contacts) > pageSize {
+		contacts = contacts[:pageSize]
+
+		last := contacts[len(contacts)-1]
+		nextURL = nextPageURL(r.URL, encodeCursor(last.SortKey, last.ID))
+	}
+
+	tags, err := b.persister.GetTags(r.Context(), userData.Email)
 	if err != nil {
 		log.Println(errCouldNotFetchFromDB, err)
 
@@ -54,6 +130,15 @@ func (b *Controller) HandleContacts(w http.ResponseWriter, r *http.Request) {
 			ImprintURL: b.imprintURL,
 		},
 		Entries: contacts,
+
+		Sort:              sort,
+		HasDebts:          hasDebts,
+		BirthdayThisMonth: birthdayThisMonth,
+		Tag:               tag,
+		Tags:              tags,
+
+		FirstPage: afterID == 0,
+		NextURL:   nextURL,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -75,12 +160,15 @@ func (b *Controller) HandleAddContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := b.tpl.ExecuteTemplate(w, "contacts_add.html", pageData{
-		userData: userData,
+	if err := b.tpl.ExecuteTemplate(w, "contacts_add.html", contactData{
+		pageData: pageData{
+			userData: userData,
 
-		Page:       "Add Contact",
-		PrivacyURL: b.privacyURL,
-		ImprintURL: b.imprintURL,
+			Page:       "Add Contact",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+		},
+		Labels: contactLabels,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -128,34 +216,36 @@ func (b *Controller) HandleCreateContact(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	email := r.FormValue("email")
-	if _, err := mail.ParseAddress(email); err != nil {
-		log.Println(err)
+	nickname := r.FormValue("nickname")
+
+	pronouns := r.FormValue("pronouns")
+	if strings.TrimSpace(pronouns) == "" {
+		log.Println(errInvalidForm)
 
 		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
 
 		return
 	}
 
-	nickname := r.FormValue("nickname")
-
-	pronouns := r.FormValue("pronouns")
-	if strings.TrimSpace(pronouns) == "" {
-		log.Println(errInvalidForm)
+	details, err := parseContactDetails(r.PostForm)
+	if err != nil {
+		log.Println(err)
 
 		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
 
 		return
 	}
 
+	tags := parseTags(r.FormValue("tags"))
+
 	id, err := b.persister.CreateContact(
 		r.Context(),
 		firstName,
 		lastName,
 		nickname,
-		email,
 		pronouns,
 		userData.Email,
+		details,
 	)
 	if err != nil {
 		log.Println(errCouldNotInsertIntoDB, err)
@@ -165,6 +255,14 @@ func (b *Controller) HandleCreateContact(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := b.persister.UpdateContactTags(r.Context(), id, tags, userData.Email); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	http.Redirect(w, r, fmt.Sprintf("/contacts/view?id=%v", id), http.StatusFound)
 }
 
@@ -256,6 +354,24 @@ func (b *Controller) HandleViewContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	details, err := b.persister.GetContactDetails(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	tags, err := b.persister.GetContactTags(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	debts, err := b.persister.GetDebts(r.Context(), int32(id), userData.Email)
 	if err != nil {
 		log.Println(errCouldNotFetchFromDB, err)
@@ -265,6 +381,20 @@ func (b *Controller) HandleViewContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	debtPayments, err := b.persister.GetDebtPayments(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	debtsTab := r.FormValue("debts")
+	if debtsTab != debtsTabHistory {
+		debtsTab = ""
+	}
+
 	activities, err := b.persister.GetActivities(r.Context(), int32(id), userData.Email)
 	if err != nil {
 		log.Println(errCouldNotFetchFromDB, err)
@@ -274,6 +404,24 @@ func (b *Controller) HandleViewContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	relationships, err := b.persister.GetContactRelationships(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	otherContacts, err := b.otherContacts(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	if err := b.tpl.ExecuteTemplate(w, "contacts_view.html", contactData{
 		pageData: pageData{
 			userData: userData,
@@ -285,8 +433,15 @@ func (b *Controller) HandleViewContact(w http.ResponseWriter, r *http.Request) {
 			BackURL: "/contacts",
 		},
 		Entry:      contact,
-		Debts:      debts,
+		Details:    details,
+		Tags:       tags,
+		Debts:      contactDebtLedger(debts, debtPayments),
+		DebtsTab:   debtsTab,
 		Activities: activities,
+
+		Relationships:     contactRelationships(int32(id), relationships),
+		RelationshipTypes: relationshipTypes,
+		OtherContacts:     otherContacts,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -352,15 +507,6 @@ func (b *Controller) HandleUpdateContact(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	email := r.FormValue("email")
-	if _, err := mail.ParseAddress(email); err != nil {
-		log.Println(err)
-
-		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
-
-		return
-	}
-
 	nickname := r.FormValue("nickname")
 
 	pronouns := r.FormValue("pronouns")
@@ -388,22 +534,30 @@ func (b *Controller) HandleUpdateContact(w http.ResponseWriter, r *http.Request)
 		birthday = &b
 	}
 
-	address := r.FormValue("address")
+	details, err := parseContactDetails(r.PostForm)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
 
 	notes := r.FormValue("notes")
 
+	tags := parseTags(r.FormValue("tags"))
+
 	if err := b.persister.UpdateContact(
 		r.Context(),
 		int32(id),
 		firstName,
 		lastName,
 		nickname,
-		email,
 		pronouns,
 		userData.Email,
 		birthday,
-		address,
 		notes,
+		details,
 	); err != nil {
 		log.Println(errCouldNotUpdateInDB, err)
 
@@ -412,6 +566,14 @@ func (b *Controller) HandleUpdateContact(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := b.persister.UpdateContactTags(r.Context(), int32(id), tags, userData.Email); err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		http.Error(w, errCouldNotUpdateInDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	http.Redirect(w, r, "/contacts/view?id="+rid, http.StatusFound)
 }
 
@@ -454,6 +616,42 @@ func (b *Controller) HandleEditContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	details, err := b.persister.GetContactDetails(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	tags, err := b.persister.GetContactTags(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	relationships, err := b.persister.GetContactRelationships(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	otherContacts, err := b.otherContacts(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	if err := b.tpl.ExecuteTemplate(w, "contacts_edit.html", contactData{
 		pageData: pageData{
 			userData: userData,
@@ -462,7 +660,15 @@ func (b *Controller) HandleEditContact(w http.ResponseWriter, r *http.Request) {
 			PrivacyURL: b.privacyURL,
 			ImprintURL: b.imprintURL,
 		},
-		Entry: contact,
+		Entry:   contact,
+		Details: details,
+		Labels:  contactLabels,
+		Tags:    tags,
+
+		Relationships:     contactRelationships(int32(id), relationships),
+		RelationshipTypes: relationshipTypes,
+		OtherContacts:     otherContacts,
+		Editing:           true,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	csrfTokenKey    = "csrf_token"
+	csrfTokenHeader = "X-CSRF-Token"
+
+	// The largest form is the user data upload
+	maxFormSize = maxUserDataSize
+)
+
+var (
+	errInvalidCSRFToken          = errors.New("invalid CSRF token")
+	errCouldNotGenerateCSRFToken = errors.New("could not generate CSRF token")
+	errFormTooLarge              = errors.New("form too large")
+)
+
+// Routes below these prefixes never use the session cookies, so they can't be
+// the target of a CSRF attack; they are authorized with app passwords or, for
+// back-channel logouts, with tokens signed by the OIDC provider instead
+var csrfExemptPrefixes = []string{
+	"/api/",
+	"/carddav",
+	"/caldav",
+	"/.well-known/",
+	"/authorize/backchannel",
+}
+
+type csrfTokenContextKey struct{}
+
+func generateCSRFToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+// setCSRFToken creates a new CSRF token for the current session. The cookie has
+// no expiry, so the token is discarded together with the browser session.
+func setCSRFToken(w http.ResponseWriter) (string, error) {
+	token, err := generateCSRFToken()
+	if err != nil {
+		return "", err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfTokenKey,
+		Value:    token,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/",
+	})
+
+	return token, nil
+}
+
+// csrfToken returns the CSRF token that `CSRF` attached to the request, which
+// templates need to embed into their forms
+func csrfToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfTokenContextKey{}).(string)
+
+	return token
+}
+
+func isCSRFExempt(r *http.Request) bool {
+	// Browsers never send bearer tokens on their own, so a request with one can't
+	// have been forged by another site
+	if _, ok := bearerToken(r); ok {
+		return true
+	}
+
+	for _, prefix := range csrfExemptPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CSRF makes sure that every `POST` request was submitted from a form this app
+// rendered by comparing the `csrf_token` form value or `X-CSRF-Token` header
+// with the token in the session's cookie
+func (b *Controller) CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isCSRFExempt(r) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		token := ""
+		if cookie, err := r.Cookie(csrfTokenKey); err == nil {
+			token = cookie.Value
+		}
+
+		if r.Method == http.MethodPost {
+			// Parsing the form for the token reads the whole body, so it needs to
+			// be limited here since the handlers can't limit it afterwards
+			r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+
+			submitted := r.Header.Get(csrfTokenHeader)
+			if submitted == "" {
+				if err := r.ParseMultipartForm(maxFormSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+					var maxBytesErr *http.MaxBytesError
+					if errors.As(err, &maxBytesErr) {
+						log.Println(errFormTooLarge, err)
+
+						http.Error(w, errFormTooLarge.Error(), http.StatusRequestEntityTooLarge)
+
+						return
+					}
+
+					log.Println(errCouldNotParseForm, err)
+
+					http.Error(w, errCouldNotParseForm.Error(), http.StatusBadRequest)
+
+					return
+				}
+
+				submitted = r.PostFormValue(csrfTokenKey)
+			}
+
+			if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(submitted)) != 1 {
+				log.Println(errInvalidCSRFToken)
+
+				b.renderCSRFError(w, r)
+
+				return
+			}
+		}
+
+		if token == "" {
+			var err error
+			token, err = setCSRFToken(w)
+			if err != nil {
+				log.Println(errCouldNotGenerateCSRFToken, err)
+
+				http.Error(w, errCouldNotGenerateCSRFToken.Error(), http.StatusInternalServerError)
+
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfTokenContextKey{}, token)))
+	})
+}
+
+func (b *Controller) renderCSRFError(w http.ResponseWriter, r *http.Request) {
+	locale, err := b.localize(r)
+	if err != nil {
+		log.Println(errCouldNotLocalize, err)
+
+		http.Error(w, errCouldNotLocalize.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusForbidden)
+
+	if err := b.tpl.ExecuteTemplate(w, "csrf.html", pageData{
+		userData: userData{
+			Locale: locale,
+		},
+
+		Page:       "Invalid form submission",
+		PrivacyURL: b.privacyURL,
+		ImprintURL: b.imprintURL,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+const (
+	davNamespace            = "DAV:"
+	cardDAVNamespace        = "urn:ietf:params:xml:ns:carddav"
+	calDAVNamespace         = "urn:ietf:params:xml:ns:caldav"
+	calendarServerNamespace = "http://calendarserver.org/ns/"
+)
+
+var (
+	errCouldNotParseDAVRequest = errors.New("could not parse DAV request")
+	errUnsupportedReport       = errors.New("unsupported report")
+	errReservedDAVResourceName = errors.New("could not create a resource with a name reserved for resources created by the server")
+)
+
+var davNamespacePrefixes = map[string]string{
+	davNamespace:            "d",
+	cardDAVNamespace:        "card",
+	calDAVNamespace:         "cal",
+	calendarServerNamespace: "cs",
+}
+
+type davRequest struct {
+	XMLName xml.Name
+
+	AllProp *struct{} `xml:"DAV: allprop"`
+	Prop    *struct {
+		Props []struct {
+			XMLName xml.Name
+		} `xml:",any"`
+	} `xml:"DAV: prop"`
+
+	Hrefs []string `xml:"DAV: href"`
+}
+
+// requestedProps returns the names of the properties requested by a PROPFIND or
+// REPORT request, or `nil` if all properties were requested
+func (d davRequest) requestedProps() []xml.Name {
+	if d.AllProp != nil || d.Prop == nil {
+		return nil
+	}
+
+	names := []xml.Name{}
+	for _, prop := range d.Prop.Props {
+		names = append(names, prop.XMLName)
+	}
+
+	return names
+}
+
+type davResource struct {
+	href   string
+	status int
+
+	// Maps property names to their inner XML
+	props map[xml.Name]string
+}
+
+func parseDAVRequest(r *http.Request) (davRequest, error) {
+	var req davRequest
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return req, err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return req, nil
+	}
+
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return req, err
+	}
+
+	return req, nil
+}
+
+func writeDAVElement(buf *bytes.Buffer, name xml.Name, value string) {
+	prefix, ok := davNamespacePrefixes[name.Space]
+	if !ok {
+		buf.WriteString("<x:" + name.Local + ` xmlns:x="`)
+		_ = xml.EscapeText(buf, []byte(name.Space))
+		buf.WriteString(`"`)
+
+		prefix = "x"
+	} else {
+		buf.WriteString("<" + prefix + ":" + name.Local)
+	}
+
+	if value == "" {
+		buf.WriteString("/>")
+
+		return
+	}
+
+	buf.WriteString(">" + value + "</" + prefix + ":" + name.Local + ">")
+}
+
+func writeDAVPropstat(buf *bytes.Buffer, props map[xml.Name]string, names []xml.Name, status int) {
+	if len(names) == 0 {
+		return
+	}
+
+	buf.WriteString("<d:propstat><d:prop>")
+	for _, name := range names {
+		writeDAVElement(buf, name, props[name])
+	}
+	buf.WriteString(fmt.Sprintf("</d:prop><d:status>HTTP/1.1 %v %v</d:status></d:propstat>", status, http.StatusText(status)))
+}
+
+func writeDAVMultistatus(w http.ResponseWriter, resources []davResource, requested []xml.Name) {
+	var buf bytes.Buffer
+
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:card="` + cardDAVNamespace + `" xmlns:cal="` + calDAVNamespace + `" xmlns:cs="` + calendarServerNamespace + `">`)
+
+	for _, resource := range resources {
+		buf.WriteString("<d:response><d:href>")
+		_ = xml.EscapeText(&buf, []byte(resource.href))
+		buf.WriteString("</d:href>")
+
+		if resource.status != 0 {
+			buf.WriteString(fmt.Sprintf("<d:status>HTTP/1.1 %v %v</d:status></d:response>", resource.status, http.StatusText(resource.status)))
+
+			continue
+		}
+
+		names := requested
+		if names == nil {
+			names = []xml.Name{}
+			for name := range resource.props {
+				names = append(names, name)
+			}
+
+			sort.Slice(names, func(i, j int) bool {
+				if names[i].Space == names[j].Space {
+					return names[i].Local < names[j].Local
+				}
+
+				return names[i].Space < names[j].Space
+			})
+		}
+
+		var (
+			found   = []xml.Name{}
+			missing = []xml.Name{}
+		)
+		for _, name := range names {
+			if _, ok := resource.props[name]; ok {
+				found = append(found, name)
+			} else {
+				missing = append(missing, name)
+			}
+		}
+
+		writeDAVPropstat(&buf, resource.props, found, http.StatusOK)
+		writeDAVPropstat(&buf, resource.props, missing, http.StatusNotFound)
+
+		buf.WriteString("</d:response>")
+	}
+
+	buf.WriteString("</d:multistatus>")
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+	}
+}
+
+func escapeDAVText(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+
+	return buf.String()
+}
+
+func davHref(href string) string {
+	return "<d:href>" + escapeDAVText(href) + "</d:href>"
+}
+
+// davResourceID returns the ID in a resource name such as `1.vcf`, which is
+// how resources created by the server are named, or -1 for names chosen by a
+// client
+func davResourceID(name string) int32 {
+	id, err := strconv.ParseInt(strings.TrimSuffix(name, path.Ext(name)), 10, 32)
+	if err != nil {
+		return -1
+	}
+
+	return int32(id)
+}
+
+func davResourceHref(collectionPath, name string) string {
+	return (&url.URL{Path: collectionPath + name}).EscapedPath()
+}
+
+// resolveDAVResourceID returns the ID of the resource with a name and the UID
+// the client chose for it; resources created by a client (such as
+// `${uuid}.vcf`) keep the name and UID it chose, since the client keeps
+// addressing and identifying them by these. Unknown names return -1, and
+// resources created by the server have no UID.
+func (b *Controller) resolveDAVResourceID(ctx context.Context, collection, name, namespace string) (int32, string, error) {
+	if id := davResourceID(name); id != -1 {
+		return id, "", nil
+	}
+
+	resource, err := b.persister.GetDAVResource(ctx, collection, name, namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, "", nil
+		}
+
+		return -1, "", err
+	}
+
+	return resource.ResourceID, resource.Uid, nil
+}
+
+// getDAVResources returns the names and UIDs clients chose for the resources
+// they created in a collection by the resources' IDs
+func (b *Controller) getDAVResources(ctx context.Context, collection, namespace string) (map[int32]models.GetDAVResourcesRow, error) {
+	rows, err := b.persister.GetDAVResources(ctx, collection, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := map[int32]models.GetDAVResourcesRow{}
+	for _, row := range rows {
+		resources[row.ResourceID] = row
+	}
+
+	return resources, nil
+}
+
+func writeDAVOptions(w http.ResponseWriter, capabilities, allow string) {
+	w.Header().Set("DAV", capabilities)
+	w.Header().Set("Allow", allow)
+	w.WriteHeader(http.StatusOK)
+}
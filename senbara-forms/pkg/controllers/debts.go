@@ -1,14 +1,30 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/money"
+)
+
+var (
+	errMissingCurrency     = errors.New("missing currency")
+	errMissingDate         = errors.New("missing date")
+	errPaymentsExceedDebt  = errors.New("payments exceed the debt")
+	errPaymentPredatesDebt = errors.New("payment predates the debt")
+)
+
+const (
+	debtsTabHistory = "history"
 )
 
 type debtData struct {
@@ -16,6 +32,217 @@ type debtData struct {
 	Entry models.GetDebtAndContactRow
 }
 
+type contactDebt struct {
+	models.GetDebtsRow
+	Remaining int64
+	Payments  []models.DebtPayment
+	SettledOn time.Time
+}
+
+type debtBalance struct {
+	Currency string
+	Amount   int64
+}
+
+// debtTransaction is a debt or a payment in the ledger of a contact. Amounts
+// and balances are in minor units of the currency and negative if you owe the
+// contact, like debts.
+type debtTransaction struct {
+	Date        time.Time
+	Description string
+	Payment     bool
+	Amount      int64
+	Currency    string
+	Balance     int64
+}
+
+type debtLedger struct {
+	Open         []contactDebt
+	Settled      []contactDebt
+	Balances     []debtBalance
+	Transactions []debtTransaction
+}
+
+// debtRemaining returns the absolute amount of a debt that hasn't been paid
+// back yet
+func debtRemaining(amount, paid int64) int64 {
+	return money.Abs(amount) - paid
+}
+
+// today returns the current date at midnight UTC, like dates parsed from forms
+func today() time.Time {
+	now := time.Now()
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseDebtDate parses the date of a debt or payment, which defaults to today
+func parseDebtDate(rdate string) (time.Time, error) {
+	if strings.TrimSpace(rdate) == "" {
+		return today(), nil
+	}
+
+	return time.Parse("2006-01-02", rdate)
+}
+
+// hasDebtPaymentsBefore returns whether a debt has payments dated before
+// `date`, which the debt's date can't be moved after
+func (b *Controller) hasDebtPaymentsBefore(ctx context.Context, id, contactID int32, date time.Time, namespace string) (bool, error) {
+	payments, err := b.persister.GetDebtPayments(ctx, contactID, namespace)
+	if err != nil {
+		return false, err
+	}
+
+	for _, payment := range payments {
+		if payment.DebtID == id && payment.Date.Before(date) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// normalizeExportedDebt validates an imported debt and formats its amounts
+// and those of its payments as exact decimals in its currency. Currencies which
+// aren't ISO 4217 codes are kept as they are, since earlier versions accepted
+// any currency.
+func normalizeExportedDebt(debt models.ExportedDebt) (models.ExportedDebt, error) {
+	if debt.Date.IsZero() {
+		return models.ExportedDebt{}, errMissingDate
+	}
+
+	if currency, err := money.ParseCurrency(debt.Currency); err == nil {
+		debt.Currency = currency
+	} else if strings.TrimSpace(debt.Currency) == "" {
+		return models.ExportedDebt{}, errMissingCurrency
+	}
+
+	amount, err := money.ParseAmount(string(debt.Amount), debt.Currency)
+	if err != nil {
+		return models.ExportedDebt{}, err
+	}
+	debt.Amount = json.Number(money.FormatAmount(amount, debt.Currency))
+
+	paid := int64(0)
+	for i, payment := range debt.Payments {
+		if payment.Date.Before(debt.Date) {
+			return models.ExportedDebt{}, errPaymentPredatesDebt
+		}
+
+		paymentAmount, err := money.ParseAmount(string(payment.Amount), debt.Currency)
+		if err != nil {
+			return models.ExportedDebt{}, err
+		}
+		debt.Payments[i].Amount = json.Number(money.FormatAmount(paymentAmount, debt.Currency))
+
+		paid += paymentAmount
+	}
+
+	if debtRemaining(amount, paid) < 0 {
+		return models.ExportedDebt{}, errPaymentsExceedDebt
+	}
+
+	return debt, nil
+}
+
+// contactDebtLedger splits the debts of a contact into open and settled ones
+// and lists them together with their payments as transactions, oldest first,
+// with the running balance per currency
+func contactDebtLedger(debts []models.GetDebtsRow, payments []models.DebtPayment) debtLedger {
+	var (
+		ledger       = debtLedger{}
+		debtsByID    = map[int32]models.GetDebtsRow{}
+		paymentsByID = map[int32][]models.DebtPayment{}
+	)
+	for _, payment := range payments {
+		paymentsByID[payment.DebtID] = append(paymentsByID[payment.DebtID], payment)
+	}
+
+	for _, debt := range debts {
+		debtsByID[debt.ID] = debt
+
+		entry := contactDebt{
+			GetDebtsRow: debt,
+			Remaining:   debtRemaining(debt.Amount, debt.Paid),
+			Payments:    paymentsByID[debt.ID],
+		}
+
+		if entry.Remaining <= 0 {
+			// Payments are sorted by date, so the last one settled the debt
+			if len(entry.Payments) > 0 {
+				entry.SettledOn = entry.Payments[len(entry.Payments)-1].Date
+			}
+
+			ledger.Settled = append(ledger.Settled, entry)
+		} else {
+			ledger.Open = append(ledger.Open, entry)
+		}
+
+		ledger.Transactions = append(ledger.Transactions, debtTransaction{
+			Date:        debt.Date,
+			Description: debt.Description,
+			Amount:      debt.Amount,
+			Currency:    debt.Currency,
+		})
+	}
+
+	for _, payment := range payments {
+		debt, ok := debtsByID[payment.DebtID]
+		if !ok {
+			continue
+		}
+
+		description := payment.Description
+		if strings.TrimSpace(description) == "" {
+			description = debt.Description
+		}
+
+		// Payments reduce the debt, so they have the opposite sign
+		amount := payment.Amount
+		if debt.Amount > 0 {
+			amount = -amount
+		}
+
+		ledger.Transactions = append(ledger.Transactions, debtTransaction{
+			Date:        payment.Date,
+			Description: description,
+			Payment:     true,
+			Amount:      amount,
+			Currency:    debt.Currency,
+		})
+	}
+
+	// Debts and payments are already sorted by date, so a stable sort keeps
+	// debts before the payments made on the same day
+	sort.SliceStable(ledger.Transactions, func(i, j int) bool {
+		return ledger.Transactions[i].Date.Before(ledger.Transactions[j].Date)
+	})
+
+	balances := map[string]int64{}
+	for i, transaction := range ledger.Transactions {
+		balances[transaction.Currency] += transaction.Amount
+
+		ledger.Transactions[i].Balance = balances[transaction.Currency]
+	}
+
+	for currency, amount := range balances {
+		if amount == 0 {
+			continue
+		}
+
+		ledger.Balances = append(ledger.Balances, debtBalance{
+			Currency: currency,
+			Amount:   amount,
+		})
+	}
+
+	sort.Slice(ledger.Balances, func(i, j int) bool {
+		return ledger.Balances[i].Currency < ledger.Balances[j].Currency
+	})
+
+	return ledger
+}
+
 func (b *Controller) HandleAddDebt(w http.ResponseWriter, r *http.Request) {
 	redirected, userData, status, err := b.authorize(w, r)
 	if err != nil {
@@ -129,8 +356,17 @@ func (b *Controller) HandleCreateDebt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	currency, err := money.ParseCurrency(r.FormValue("currency"))
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
 	ramount := r.FormValue("amount")
-	if strings.TrimSpace(rcontactID) == "" {
+	if strings.TrimSpace(ramount) == "" {
 		log.Println(errInvalidForm)
 
 		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
@@ -138,7 +374,7 @@ func (b *Controller) HandleCreateDebt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	amount, err := strconv.ParseFloat(ramount, 64)
+	amount, err := money.ParseAmount(ramount, currency)
 	if err != nil {
 		log.Println(errInvalidForm)
 
@@ -148,13 +384,15 @@ func (b *Controller) HandleCreateDebt(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if youOwe == 1 {
-		amount = -math.Abs(amount)
+		amount = -money.Abs(amount)
 	} else {
-		amount = math.Abs(amount)
+		amount = money.Abs(amount)
 	}
 
-	currency := r.FormValue("currency")
-	if strings.TrimSpace(currency) == "" {
+	description := r.FormValue("description")
+
+	date, err := parseDebtDate(r.FormValue("date"))
+	if err != nil {
 		log.Println(errInvalidForm)
 
 		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
@@ -162,14 +400,13 @@ func (b *Controller) HandleCreateDebt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	description := r.FormValue("description")
-
 	if _, err := b.persister.CreateDebt(
 		r.Context(),
 
 		amount,
 		currency,
 		description,
+		date,
 
 		int32(contactID),
 		userData.Email,
@@ -240,17 +477,65 @@ func (b *Controller) HandleSettleDebt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := b.persister.SettleDebt(
+	debt, err := b.persister.GetDebtAndContact(r.Context(), int32(id), int32(contactID), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// Debts are settled in full unless a partial amount is given
+	remaining := debtRemaining(debt.Amount, debt.Paid)
+
+	amount := remaining
+	if ramount := r.FormValue("amount"); strings.TrimSpace(ramount) != "" {
+		amount, err = money.ParseAmount(ramount, debt.Currency)
+		if err != nil {
+			log.Println(errInvalidForm)
+
+			http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+	}
+
+	if amount <= 0 || amount > remaining {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	// Debts can't be paid back before they were added
+	date, err := parseDebtDate(r.FormValue("date"))
+	if err != nil || date.Before(debt.Date) {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	description := r.FormValue("description")
+
+	if _, err := b.persister.SettleDebt(
 		r.Context(),
 
 		int32(id),
 
 		int32(contactID),
 		userData.Email,
+
+		amount,
+		date,
+		description,
 	); err != nil {
-		log.Println(errCouldNotUpdateInDB, err)
+		log.Println(errCouldNotInsertIntoDB, err)
 
-		http.Error(w, errCouldNotUpdateInDB.Error(), http.StatusInternalServerError)
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
 
 		return
 	}
@@ -332,8 +617,17 @@ func (b *Controller) HandleUpdateDebt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	currency, err := money.ParseCurrency(r.FormValue("currency"))
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
 	ramount := r.FormValue("amount")
-	if strings.TrimSpace(rcontactID) == "" {
+	if strings.TrimSpace(ramount) == "" {
 		log.Println(errInvalidForm)
 
 		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
@@ -341,7 +635,7 @@ func (b *Controller) HandleUpdateDebt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	amount, err := strconv.ParseFloat(ramount, 64)
+	amount, err := money.ParseAmount(ramount, currency)
 	if err != nil {
 		log.Println(errInvalidForm)
 
@@ -351,13 +645,15 @@ func (b *Controller) HandleUpdateDebt(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if youOwe == 1 {
-		amount = -math.Abs(amount)
+		amount = -money.Abs(amount)
 	} else {
-		amount = math.Abs(amount)
+		amount = money.Abs(amount)
 	}
 
-	currency := r.FormValue("currency")
-	if strings.TrimSpace(currency) == "" {
+	description := r.FormValue("description")
+
+	date, err := parseDebtDate(r.FormValue("date"))
+	if err != nil {
 		log.Println(errInvalidForm)
 
 		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
@@ -365,7 +661,43 @@ func (b *Controller) HandleUpdateDebt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	description := r.FormValue("description")
+	debt, err := b.persister.GetDebtAndContact(r.Context(), int32(id), int32(contactID), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// Debts can't be reduced below what has already been paid back, and
+	// payments are in minor units of the currency, so it can only change to one
+	// with the same minor unit once part of a debt has been paid back
+	if debt.Paid > money.Abs(amount) || (debt.Paid > 0 && money.Scale(debt.Currency) != money.Scale(currency)) {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	// Like new payments, existing ones can't predate the debt
+	paidBefore, err := b.hasDebtPaymentsBefore(r.Context(), int32(id), int32(contactID), date, userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if paidBefore {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
 
 	if err := b.persister.UpdateDebt(
 		r.Context(),
@@ -378,6 +710,7 @@ func (b *Controller) HandleUpdateDebt(w http.ResponseWriter, r *http.Request) {
 		amount,
 		currency,
 		description,
+		date,
 	); err != nil {
 		log.Println(errCouldNotUpdateInDB, err)
 
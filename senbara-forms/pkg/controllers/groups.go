@@ -0,0 +1,916 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/money"
+)
+
+var (
+	errEmptyGroup    = errors.New("group has no members")
+	errInvalidShares = errors.New("invalid debt shares")
+)
+
+const (
+	debtSplitEven   = "even"
+	debtSplitCustom = "custom"
+
+	maxShareLength = 32
+)
+
+type groupsData struct {
+	pageData
+	Entries []models.GetContactGroupsRow
+}
+
+type groupData struct {
+	pageData
+	Entry         models.ContactGroup
+	Members       []models.GetContactGroupMembersRow
+	OtherContacts []models.Contact
+}
+
+// parseShare parses a relative share of a debt such as `1.5`; only plain
+// decimals are accepted, since exponents could make the shares arbitrarily large
+func parseShare(share string) (*big.Rat, error) {
+	whole, fraction, _ := strings.Cut(share, ".")
+	if whole == "" || len(share) > maxShareLength {
+		return nil, errors.Join(errInvalidShares, fmt.Errorf("share %v", share))
+	}
+
+	for _, digit := range whole + fraction {
+		if digit < '0' || digit > '9' {
+			return nil, errors.Join(errInvalidShares, fmt.Errorf("share %v", share))
+		}
+	}
+
+	exact, ok := new(big.Rat).SetString(share)
+	if !ok {
+		return nil, errors.Join(errInvalidShares, fmt.Errorf("share %v", share))
+	}
+
+	return exact, nil
+}
+
+// splitAmount splits an amount in minor units of its currency into parts which
+// are proportional to the shares. The minor units lost to rounding go to the
+// parts with the largest remainders, so the parts always add up to the amount.
+func splitAmount(amount int64, shares []*big.Rat) ([]int64, error) {
+	total := new(big.Rat)
+	for _, share := range shares {
+		if share.Sign() < 0 {
+			return nil, errors.Join(errInvalidShares, fmt.Errorf("share %v", share.RatString()))
+		}
+
+		total.Add(total, share)
+	}
+
+	if total.Sign() <= 0 {
+		return nil, errors.Join(errInvalidShares, errors.New("shares must add up to more than zero"))
+	}
+
+	minorUnits := money.Abs(amount)
+
+	var (
+		parts      = make([]int64, len(shares))
+		remainders = make([]*big.Rat, len(shares))
+		assigned   = int64(0)
+	)
+	for i, share := range shares {
+		exact := new(big.Rat).Mul(new(big.Rat).SetInt64(minorUnits), share)
+		exact.Quo(exact, total)
+
+		// Both are positive, so the truncated quotient is the floor
+		part := new(big.Int).Quo(exact.Num(), exact.Denom())
+
+		parts[i] = part.Int64()
+		remainders[i] = exact.Sub(exact, new(big.Rat).SetInt(part))
+		assigned += parts[i]
+	}
+
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return remainders[b].Cmp(remainders[a])
+	})
+
+	for i := 0; assigned < minorUnits; i++ {
+		parts[order[i%len(order)]]++
+		assigned++
+	}
+
+	for i := range parts {
+		if amount < 0 {
+			parts[i] = -parts[i]
+		}
+	}
+
+	return parts, nil
+}
+
+func (b *Controller) HandleGroups(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	groups, err := b.persister.GetContactGroups(r.Context(), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.tpl.ExecuteTemplate(w, "groups.html", groupsData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       "Groups",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+
+			BackURL: "/contacts",
+		},
+		Entries: groups,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (b *Controller) HandleCreateGroup(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := b.persister.CreateContactGroup(r.Context(), name, userData.Email)
+	if err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/groups/view?id=%v", id), http.StatusFound)
+}
+
+func (b *Controller) HandleViewGroup(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	rid := r.URL.Query().Get("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidQueryParam)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidQueryParam)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	group, err := b.persister.GetContactGroup(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	members, err := b.persister.GetContactGroupMembers(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	contacts, err := b.persister.GetContacts(r.Context(), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	otherContacts := slices.DeleteFunc(contacts, func(contact models.Contact) bool {
+		return slices.ContainsFunc(members, func(member models.GetContactGroupMembersRow) bool {
+			return member.ID == contact.ID
+		})
+	})
+
+	if err := b.tpl.ExecuteTemplate(w, "groups_view.html", groupData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       group.Name,
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+
+			BackURL: "/groups",
+		},
+		Entry:         group,
+		Members:       members,
+		OtherContacts: otherContacts,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (b *Controller) HandleUpdateGroup(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.UpdateContactGroup(r.Context(), int32(id), name, userData.Email); err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		http.Error(w, errCouldNotUpdateInDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/groups/view?id=%v", id), http.StatusFound)
+}
+
+func (b *Controller) HandleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.DeleteContactGroup(r.Context(), int32(id), userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, "/groups", http.StatusFound)
+}
+
+func (b *Controller) HandleCreateGroupMember(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	rcontactID := r.FormValue("contact_id")
+	if strings.TrimSpace(rcontactID) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	contactID, err := strconv.Atoi(rcontactID)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.CreateContactGroupMember(r.Context(), int32(id), int32(contactID), userData.Email); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/groups/view?id=%v", id), http.StatusFound)
+}
+
+func (b *Controller) HandleDeleteGroupMember(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	rcontactID := r.FormValue("contact_id")
+	if strings.TrimSpace(rcontactID) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	contactID, err := strconv.Atoi(rcontactID)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.DeleteContactGroupMember(r.Context(), int32(id), int32(contactID), userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/groups/view?id=%v", id), http.StatusFound)
+}
+
+func (b *Controller) HandleAddGroupActivity(w http.ResponseWriter, r *http.Request) {
+	b.renderGroupForm(w, r, "groups_activities_add.html", "Add Group Activity")
+}
+
+func (b *Controller) HandleAddGroupDebt(w http.ResponseWriter, r *http.Request) {
+	b.renderGroupForm(w, r, "groups_debts_add.html", "Add Group Debt")
+}
+
+// renderGroupForm renders a form which adds a record for the members of the
+// group in the `id` query parameter
+func (b *Controller) renderGroupForm(w http.ResponseWriter, r *http.Request, name, page string) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidQueryParam)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidQueryParam)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	group, err := b.persister.GetContactGroup(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	members, err := b.persister.GetContactGroupMembers(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.tpl.ExecuteTemplate(w, name, groupData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       page,
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+
+			BackURL: fmt.Sprintf("/groups/view?id=%v", id),
+		},
+		Entry:   group,
+		Members: members,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (b *Controller) HandleCreateGroupActivity(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	name := r.FormValue("name")
+	if strings.TrimSpace(name) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	rdate := r.FormValue("date")
+	if strings.TrimSpace(rdate) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", rdate)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	description := r.FormValue("description")
+
+	members, err := b.persister.GetContactGroupMembers(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if len(members) == 0 {
+		log.Println(errInvalidForm, errEmptyGroup)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	contactIDs := []int32{}
+	for _, member := range members {
+		contactIDs = append(contactIDs, member.ID)
+	}
+
+	if _, err := b.persister.CreateActivity(
+		r.Context(),
+
+		name,
+		date,
+		description,
+
+		contactIDs,
+		userData.Email,
+	); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/groups/view?id=%v", id), http.StatusFound)
+}
+
+func (b *Controller) HandleCreateGroupDebt(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	ryouOwe := r.FormValue("you_owe")
+	if strings.TrimSpace(ryouOwe) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	youOwe, err := strconv.Atoi(ryouOwe)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	currency, err := money.ParseCurrency(r.FormValue("currency"))
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	ramount := r.FormValue("amount")
+	if strings.TrimSpace(ramount) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	amount, err := money.ParseAmount(ramount, currency)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if youOwe == 1 {
+		amount = -money.Abs(amount)
+	} else {
+		amount = money.Abs(amount)
+	}
+
+	description := r.FormValue("description")
+
+	date, err := parseDebtDate(r.FormValue("date"))
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	split := r.FormValue("split")
+	if split != debtSplitEven && split != debtSplitCustom {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	members, err := b.persister.GetContactGroupMembers(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if len(members) == 0 {
+		log.Println(errInvalidForm, errEmptyGroup)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	// Custom shares are relative, i.e. shares of 2 and 1 split the amount into
+	// two thirds and one third; members without a share don't take part
+	shares := []*big.Rat{}
+	for _, member := range members {
+		if split == debtSplitEven {
+			shares = append(shares, big.NewRat(1, 1))
+
+			continue
+		}
+
+		rshare := strings.TrimSpace(r.FormValue(fmt.Sprintf("share_%v", member.ID)))
+		if rshare == "" {
+			shares = append(shares, new(big.Rat))
+
+			continue
+		}
+
+		share, err := parseShare(rshare)
+		if err != nil {
+			log.Println(errInvalidForm, err)
+
+			http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+
+		shares = append(shares, share)
+	}
+
+	parts, err := splitAmount(amount, shares)
+	if err != nil {
+		log.Println(errInvalidForm, err)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	var (
+		amounts    = []int64{}
+		contactIDs = []int32{}
+	)
+	for i, part := range parts {
+		// Members whose part rounds to zero don't owe anything
+		if part == 0 {
+			continue
+		}
+
+		amounts = append(amounts, part)
+		contactIDs = append(contactIDs, members[i].ID)
+	}
+
+	if _, err := b.persister.CreateDebts(
+		r.Context(),
+
+		amounts,
+		currency,
+		description,
+		date,
+
+		contactIDs,
+		userData.Email,
+	); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/groups/view?id=%v", id), http.StatusFound)
+}
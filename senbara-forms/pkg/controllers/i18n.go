@@ -27,3 +27,65 @@ func (b *Controller) localize(r *http.Request) (*gotext.Locale, error) {
 
 	return locale, nil
 }
+
+// localeTag returns the language tag of a locale, or English if it can't be
+// parsed
+func localeTag(locale *gotext.Locale) language.Tag {
+	tag, err := language.Parse(strings.ReplaceAll(locale.GetLanguage(), "_", "-"))
+	if err != nil {
+		return language.English
+	}
+
+	return tag
+}
+
+// textSearchConfigs maps languages to the text search configurations Postgres
+// ships with, which use the language's stop words and stemming
+var textSearchConfigs = map[string]string{
+	"ar": "arabic",
+	"ca": "catalan",
+	"da": "danish",
+	"de": "german",
+	"el": "greek",
+	"en": "english",
+	"es": "spanish",
+	"eu": "basque",
+	"fi": "finnish",
+	"fr": "french",
+	"ga": "irish",
+	"hi": "hindi",
+	"hu": "hungarian",
+	"hy": "armenian",
+	"id": "indonesian",
+	"it": "italian",
+	"lt": "lithuanian",
+	"ne": "nepali",
+	"nl": "dutch",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sr": "serbian",
+	"sv": "swedish",
+	"ta": "tamil",
+	"tr": "turkish",
+	"yi": "yiddish",
+}
+
+// textSearchConfig returns the text search configuration for a locale, or the
+// language-agnostic `simple` configuration if there is none for its language
+func textSearchConfig(locale *gotext.Locale) string {
+	tag, err := language.Parse(strings.ReplaceAll(locale.GetLanguage(), "_", "-"))
+	if err != nil {
+		return "simple"
+	}
+
+	base, _ := tag.Base()
+
+	config, ok := textSearchConfigs[base.String()]
+	if !ok {
+		return "simple"
+	}
+
+	return config
+}
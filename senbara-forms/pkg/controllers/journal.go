@@ -6,18 +6,35 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
 )
 
+const (
+	journalOrderNewest = "newest"
+	journalOrderOldest = "oldest"
+)
+
 type journalData struct {
 	pageData
-	Entries []models.JournalEntry
+	Entries []models.ListJournalEntriesRow
+
+	Order  string
+	Rating int
+	From   string
+	To     string
+	Tag    string
+	Tags   []models.Tag
+
+	FirstPage bool
+	NextURL   string
 }
 
 type journalEntryData struct {
 	pageData
 	Entry models.JournalEntry
+	Tags  []string
 }
 
 func (b *Controller) HandleJournal(w http.ResponseWriter, r *http.Request) {
@@ -32,7 +49,102 @@ func (b *Controller) HandleJournal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	journalEntries, err := b.persister.GetJournalEntries(r.Context(), userData.Email)
+	order := r.FormValue("order")
+	if strings.TrimSpace(order) == "" {
+		order = journalOrderNewest
+	} else if order != journalOrderNewest && order != journalOrderOldest {
+		log.Println(errInvalidQueryParam)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	rating := 0
+	if rrating := r.FormValue("rating"); strings.TrimSpace(rrating) != "" {
+		rating, err = strconv.Atoi(rrating)
+		if err != nil || rating < 1 || rating > 3 {
+			log.Println(errInvalidQueryParam)
+
+			http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+	}
+
+	var from *time.Time
+	if rfrom := r.FormValue("from"); strings.TrimSpace(rfrom) != "" {
+		f, err := time.Parse("2006-01-02", rfrom)
+		if err != nil {
+			log.Println(errInvalidQueryParam)
+
+			http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+
+		from = &f
+	}
+
+	// The range includes the entries of the day it ends on
+	var to *time.Time
+	if rto := r.FormValue("to"); strings.TrimSpace(rto) != "" {
+		t, err := time.Parse("2006-01-02", rto)
+		if err != nil {
+			log.Println(errInvalidQueryParam)
+
+			http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+
+	tag := normalizeTag(r.FormValue("tag"))
+
+	rafterDate, afterID, err := decodeCursor(r.FormValue("after"))
+	if err != nil {
+		log.Println(errInvalidQueryParam, err)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	var afterDate time.Time
+	if afterID != 0 {
+		afterDate, err = time.Parse(time.RFC3339Nano, rafterDate)
+		if err != nil {
+			log.Println(errInvalidQueryParam, err)
+
+			http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+	}
+
+	// Fetching one more entry than fits on the page tells whether there is a
+	// next page
+	journalEntries, err := b.persister.ListJournalEntries(r.Context(), userData.Email, order == journalOrderOldest, int32(rating), from, to, tag, afterDate, afterID, pageSize+1)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	nextURL := ""
+	if len(journalEntries) > pageSize {
+		journalEntries = journalEntries[:pageSize]
+
+		last := journalEntries[len(journalEntries)-1]
+		nextURL = nextPageURL(r.URL, encodeCursor(last.Date.Format(time.RFC3339Nano), last.ID))
+	}
+
+	tags, err := b.persister.GetTags(r.Context(), userData.Email)
 	if err != nil {
 		log.Println(errCouldNotFetchFromDB, err)
 
@@ -50,6 +162,16 @@ func (b *Controller) HandleJournal(w http.ResponseWriter, r *http.Request) {
 			ImprintURL: b.imprintURL,
 		},
 		Entries: journalEntries,
+
+		Order:  order,
+		Rating: rating,
+		From:   r.FormValue("from"),
+		To:     r.FormValue("to"),
+		Tag:    tag,
+		Tags:   tags,
+
+		FirstPage: afterID == 0,
+		NextURL:   nextURL,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -142,6 +264,8 @@ func (b *Controller) HandleCreateJournal(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	tags := parseTags(r.FormValue("tags"))
+
 	id, err := b.persister.CreateJournalEntry(r.Context(), title, body, int32(rating), userData.Email)
 	if err != nil {
 		log.Println(errCouldNotInsertIntoDB, err)
@@ -151,6 +275,14 @@ func (b *Controller) HandleCreateJournal(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := b.persister.UpdateJournalEntryTags(r.Context(), id, tags, userData.Email); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	http.Redirect(w, r, fmt.Sprintf("/journal/view?id=%v", id), http.StatusFound)
 }
 
@@ -242,6 +374,15 @@ func (b *Controller) HandleEditJournal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tags, err := b.persister.GetJournalEntryTags(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	if err := b.tpl.ExecuteTemplate(w, "journal_edit.html", journalEntryData{
 		pageData: pageData{
 			userData: userData,
@@ -251,6 +392,7 @@ func (b *Controller) HandleEditJournal(w http.ResponseWriter, r *http.Request) {
 			ImprintURL: b.imprintURL,
 		},
 		Entry: journalEntry,
+		Tags:  tags,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -334,6 +476,8 @@ func (b *Controller) HandleUpdateJournal(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	tags := parseTags(r.FormValue("tags"))
+
 	if err := b.persister.UpdateJournalEntry(r.Context(), int32(id), title, body, int32(rating), userData.Email); err != nil {
 		log.Println(errCouldNotUpdateInDB, err)
 
@@ -342,6 +486,14 @@ func (b *Controller) HandleUpdateJournal(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := b.persister.UpdateJournalEntryTags(r.Context(), int32(id), tags, userData.Email); err != nil {
+		log.Println(errCouldNotUpdateInDB, err)
+
+		http.Error(w, errCouldNotUpdateInDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	http.Redirect(w, r, "/journal/view?id="+rid, http.StatusFound)
 }
 
@@ -384,6 +536,15 @@ func (b *Controller) HandleViewJournal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tags, err := b.persister.GetJournalEntryTags(r.Context(), int32(id), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
 	if err := b.tpl.ExecuteTemplate(w, "journal_view.html", journalEntryData{
 		pageData: pageData{
 			userData: userData,
@@ -395,6 +556,7 @@ func (b *Controller) HandleViewJournal(w http.ResponseWriter, r *http.Request) {
 			BackURL: "/journal",
 		},
 		Entry: journalEntry,
+		Tags:  tags,
 	}); err != nil {
 		log.Println(errCouldNotRenderTemplate, err)
 
@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/openapi"
+)
+
+func (b *Controller) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", apiContentType)
+
+	if _, err := w.Write(openapi.Spec); err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+	}
+}
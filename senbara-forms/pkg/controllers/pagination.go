@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	pageSize = 25
+)
+
+var (
+	errInvalidCursor = errors.New("could not use invalid cursor")
+)
+
+// encodeCursor encodes the sort key and ID of the last record of a page into
+// an opaque value for the `after` query parameter of the next page
+func encodeCursor(key string, id int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(int(id)) + ":" + key))
+}
+
+// decodeCursor decodes a cursor created by `encodeCursor`; an empty cursor
+// decodes to an ID of 0, which the persisters treat as the first page
+func decodeCursor(cursor string) (string, int32, error) {
+	if strings.TrimSpace(cursor) == "" {
+		return "", 0, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, errors.Join(errInvalidCursor, err)
+	}
+
+	rid, key, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", 0, errInvalidCursor
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil || id <= 0 {
+		return "", 0, errInvalidCursor
+	}
+
+	return key, int32(id), nil
+}
+
+// nextPageURL returns the URL of the page after the cursor with the same
+// sorting and filters as the current one
+func nextPageURL(u *url.URL, cursor string) string {
+	q := u.Query()
+	q.Set("after", cursor)
+
+	return (&url.URL{
+		Path:     u.Path,
+		RawQuery: q.Encode(),
+	}).String()
+}
@@ -0,0 +1,362 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+const (
+	scopeRead  = "read"
+	scopeWrite = "write"
+)
+
+var personalAccessTokenScopes = []string{
+	"contacts:" + scopeRead,
+	"contacts:" + scopeWrite,
+	"debts:" + scopeRead,
+	"debts:" + scopeWrite,
+	"activities:" + scopeRead,
+	"activities:" + scopeWrite,
+	"journal:" + scopeRead,
+	"journal:" + scopeWrite,
+	"userdata:" + scopeRead,
+	"userdata:" + scopeWrite,
+	"search:" + scopeRead,
+}
+
+// personalAccessTokenResources maps path prefixes to the resource whose scopes
+// are required to access them. More specific prefixes must come first.
+var personalAccessTokenResources = []struct {
+	prefix   string
+	resource string
+}{
+	{"/api/v1/contacts/*/debts", "debts"},
+	{"/api/v1/contacts/*/activities", "activities"},
+	{"/api/v1/contacts", "contacts"},
+	{"/api/v1/journal", "journal"},
+	{"/api/v1/userdata", "userdata"},
+	{"/api/v1/search", "search"},
+
+	{"/contacts", "contacts"},
+	{"/groups/activities", "activities"},
+	{"/groups/debts", "debts"},
+	{"/groups", "contacts"},
+	{"/debts", "debts"},
+	{"/activities", "activities"},
+	{"/journal", "journal"},
+	{"/userdata", "userdata"},
+	{"/search", "search"},
+}
+
+var (
+	errInsufficientScope          = errors.New("personal access token lacks the required scope")
+	errExpiredPersonalAccessToken = errors.New("personal access token expired")
+	errInvalidPersonalAccessToken = errors.New("invalid personal access token")
+)
+
+type personalAccessTokensData struct {
+	pageData
+	Entries  []models.GetPersonalAccessTokensRow
+	Scopes   []string
+	NewToken string
+	Now      time.Time
+}
+
+func generatePersonalAccessToken() (string, error) {
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(rawToken), nil
+}
+
+// requiredPersonalAccessTokenScope returns the scope a personal access token
+// needs for a request, i.e. `contacts:read` for `GET /contacts`. Routes which
+// aren't listed, such as the management of app passwords and tokens, can't be
+// accessed with personal access tokens at all.
+func requiredPersonalAccessTokenScope(r *http.Request) (string, bool) {
+	segments := strings.Split(r.URL.Path, "/")
+
+	for _, candidate := range personalAccessTokenResources {
+		prefix := strings.Split(candidate.prefix, "/")
+		if len(segments) < len(prefix) {
+			continue
+		}
+
+		matches := true
+		for i, segment := range prefix {
+			if segment != "*" && segment != segments[i] {
+				matches = false
+
+				break
+			}
+		}
+
+		if !matches {
+			continue
+		}
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return candidate.resource + ":" + scopeRead, true
+		}
+
+		return candidate.resource + ":" + scopeWrite, true
+	}
+
+	return "", false
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// authorizePersonalAccessToken authorizes requests with an `Authorization:
+// Bearer` header. Unlike `authorize`, it never redirects, so that scripts get a
+// clear status code instead of the OIDC provider's sign in page.
+func (b *Controller) authorizePersonalAccessToken(w http.ResponseWriter, r *http.Request, token string) (string, int, error) {
+	pat, err := b.persister.GetPersonalAccessToken(r.Context(), hashSecret(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="Senbara Forms", error="invalid_token"`)
+
+			return "", http.StatusUnauthorized, errInvalidPersonalAccessToken
+		}
+
+		return "", http.StatusInternalServerError, errors.Join(errCouldNotLogin, err)
+	}
+
+	if pat.ExpiresAt.Valid && !time.Now().Before(pat.ExpiresAt.Time) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="Senbara Forms", error="invalid_token"`)
+
+		return "", http.StatusUnauthorized, errExpiredPersonalAccessToken
+	}
+
+	scope, ok := requiredPersonalAccessTokenScope(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="Senbara Forms", error="insufficient_scope"`)
+
+		return "", http.StatusForbidden, errInsufficientScope
+	}
+
+	if !slices.Contains(strings.Fields(pat.Scopes), scope) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="Senbara Forms", error="insufficient_scope", scope=%q`, scope))
+
+		return "", http.StatusForbidden, errInsufficientScope
+	}
+
+	return pat.Namespace, http.StatusOK, nil
+}
+
+func (b *Controller) renderPersonalAccessTokens(w http.ResponseWriter, r *http.Request, userData userData, newToken string) {
+	personalAccessTokens, err := b.persister.GetPersonalAccessTokens(r.Context(), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.tpl.ExecuteTemplate(w, "personalaccesstokens.html", personalAccessTokensData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       "Personal Access Tokens",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+		},
+		Entries:  personalAccessTokens,
+		Scopes:   personalAccessTokenScopes,
+		NewToken: newToken,
+		Now:      time.Now(),
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (b *Controller) HandlePersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	b.renderPersonalAccessTokens(w, r, userData, "")
+}
+
+func (b *Controller) HandleCreatePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	name := r.FormValue("name")
+	if strings.TrimSpace(name) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	scopes := r.Form["scopes"]
+	if len(scopes) == 0 {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(personalAccessTokenScopes, scope) {
+			log.Println(errInvalidForm)
+
+			http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+	}
+
+	rexpiresAt := r.FormValue("expires_at")
+
+	var expiresAt *time.Time
+	if strings.TrimSpace(rexpiresAt) != "" {
+		e, err := time.Parse("2006-01-02", rexpiresAt)
+		if err != nil {
+			log.Println(errInvalidForm)
+
+			http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+
+		if !e.After(time.Now()) {
+			log.Println(errInvalidForm)
+
+			http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+
+		expiresAt = &e
+	}
+
+	token, err := generatePersonalAccessToken()
+	if err != nil {
+		log.Println(errCouldNotGenerateToken, err)
+
+		http.Error(w, errCouldNotGenerateToken.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if _, err := b.persister.CreatePersonalAccessToken(
+		r.Context(),
+
+		name,
+		hashSecret(token),
+		strings.Join(scopes, " "),
+		expiresAt,
+
+		userData.Email,
+	); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	// The token is only shown once since only its hash is stored
+	b.renderPersonalAccessTokens(w, r, userData, token)
+}
+
+func (b *Controller) HandleDeletePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.DeletePersonalAccessToken(r.Context(), int32(id), userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, "/personalaccesstokens", http.StatusFound)
+}
@@ -0,0 +1,497 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+var (
+	errInvalidRelationshipType  = errors.New("invalid relationship type")
+	errMissingRelationshipLabel = errors.New("missing label for custom relationship")
+)
+
+const (
+	relationshipTypeSpouse       = "spouse"
+	relationshipTypeChildOf      = "child_of"
+	relationshipTypeWorksWith    = "works_with"
+	relationshipTypeIntroducedBy = "introduced_by"
+	relationshipTypeCustom       = "custom"
+)
+
+// relationshipType describes how a relationship reads on the contact which has
+// it (`Label`) and on the related contact (`InverseLabel`)
+type relationshipType struct {
+	Name         string
+	Label        string
+	InverseLabel string
+}
+
+var relationshipTypes = []relationshipType{
+	{relationshipTypeSpouse, "Spouse of", "Spouse of"},
+	{relationshipTypeChildOf, "Child of", "Parent of"},
+	{relationshipTypeWorksWith, "Works with", "Works with"},
+	{relationshipTypeIntroducedBy, "Introduced by", "Introduced"},
+	{relationshipTypeCustom, "Custom", "Custom"},
+}
+
+// normalizeRelationship validates the type of a relationship and returns the
+// label, which is only kept for custom relationships
+func normalizeRelationship(name, label string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !slices.ContainsFunc(relationshipTypes, func(t relationshipType) bool {
+		return t.Name == name
+	}) {
+		return "", "", errors.Join(errInvalidRelationshipType, fmt.Errorf("type %v", name))
+	}
+
+	if name != relationshipTypeCustom {
+		return name, "", nil
+	}
+
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return "", "", errMissingRelationshipLabel
+	}
+
+	return name, label, nil
+}
+
+// relationshipLabel returns how a relationship reads on the contact which has it
+// or, if `inverse` is set, on the related contact; custom relationships read
+// the same on both
+func relationshipLabel(name, label string, inverse bool) string {
+	if name == relationshipTypeCustom {
+		return label
+	}
+
+	for _, t := range relationshipTypes {
+		if t.Name == name {
+			if inverse {
+				return t.InverseLabel
+			}
+
+			return t.Label
+		}
+	}
+
+	return name
+}
+
+// contactRelationship is a relationship from the point of view of one of its
+// two contacts
+type contactRelationship struct {
+	ID         int32
+	ContactID  int32
+	FirstName  string
+	LastName   string
+	Label      string
+	Reciprocal bool
+}
+
+func contactRelationships(contactID int32, rows []models.GetContactRelationshipsRow) []contactRelationship {
+	relationships := []contactRelationship{}
+	for _, row := range rows {
+		if row.ContactID == contactID {
+			relationships = append(relationships, contactRelationship{
+				ID:         row.ID,
+				ContactID:  row.RelatedContactID,
+				FirstName:  row.RelatedFirstName,
+				LastName:   row.RelatedLastName,
+				Label:      relationshipLabel(row.Type, row.Label, false),
+				Reciprocal: row.Reciprocal,
+			})
+
+			continue
+		}
+
+		relationships = append(relationships, contactRelationship{
+			ID:         row.ID,
+			ContactID:  row.ContactID,
+			FirstName:  row.FirstName,
+			LastName:   row.LastName,
+			Label:      relationshipLabel(row.Type, row.Label, true),
+			Reciprocal: row.Reciprocal,
+		})
+	}
+
+	return relationships
+}
+
+// otherContacts returns the contacts a contact can be related to or share an
+// activity with
+func (b *Controller) otherContacts(ctx context.Context, contactID int32, namespace string) ([]models.Contact, error) {
+	contacts, err := b.persister.GetContacts(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(contacts, func(contact models.Contact) bool {
+		return contact.ID == contactID
+	}), nil
+}
+
+// contactRelationshipRedirect returns to the page the relationship was managed
+// from, which is either the contact's view or edit page
+func contactRelationshipRedirect(r *http.Request, contactID int) string {
+	if r.FormValue("return") == "edit" {
+		return fmt.Sprintf("/contacts/edit?id=%v", contactID)
+	}
+
+	return fmt.Sprintf("/contacts/view?id=%v", contactID)
+}
+
+func (b *Controller) HandleCreateContactRelationship(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rcontactID := r.FormValue("contact_id")
+	if strings.TrimSpace(rcontactID) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	contactID, err := strconv.Atoi(rcontactID)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	rrelatedContactID := r.FormValue("related_contact_id")
+	if strings.TrimSpace(rrelatedContactID) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	relatedContactID, err := strconv.Atoi(rrelatedContactID)
+	if err != nil || relatedContactID == contactID {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	relationshipType, label, err := normalizeRelationship(r.FormValue("type"), r.FormValue("label"))
+	if err != nil {
+		log.Println(errInvalidForm, err)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	reciprocal := r.FormValue("reciprocal") != ""
+
+	if _, err := b.persister.CreateContactRelationship(
+		r.Context(),
+
+		int32(contactID),
+		int32(relatedContactID),
+
+		relationshipType,
+		label,
+		reciprocal,
+
+		userData.Email,
+	); err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, contactRelationshipRedirect(r, contactID), http.StatusFound)
+}
+
+func (b *Controller) HandleDeleteContactRelationship(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	rcontactID := r.FormValue("contact_id")
+	if strings.TrimSpace(rcontactID) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	contactID, err := strconv.Atoi(rcontactID)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.DeleteContactRelationship(r.Context(), int32(id), userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.Redirect(w, r, contactRelationshipRedirect(r, contactID), http.StatusFound)
+}
+
+const (
+	graphNodeRadius    = 8
+	graphNodeSpacing   = 120
+	graphMinRadius     = 150
+	graphLabelMargin   = 160
+	graphLabelDistance = 16
+)
+
+type graphNode struct {
+	ID        int32
+	FirstName string
+	LastName  string
+
+	X, Y           float64
+	LabelX, LabelY float64
+	LabelAnchor    string
+}
+
+type graphEdge struct {
+	X1, Y1, X2, Y2 float64
+	LabelX, LabelY float64
+	Label          string
+	Reciprocal     bool
+}
+
+type contactsGraphData struct {
+	pageData
+
+	Width  float64
+	Height float64
+	Nodes  []graphNode
+	Edges  []graphEdge
+}
+
+// layoutContactsGraph places the contacts which have relationships on a circle
+// and connects them with an edge per relationship; edges stop at the border of
+// the nodes so that the arrow heads of one-way relationships stay visible
+func layoutContactsGraph(contacts []models.Contact, relationships []models.ContactRelationship) (float64, []graphNode, []graphEdge) {
+	related := map[int32]struct{}{}
+	for _, relationship := range relationships {
+		related[relationship.ContactID] = struct{}{}
+		related[relationship.RelatedContactID] = struct{}{}
+	}
+
+	nodes := []graphNode{}
+	for _, contact := range contacts {
+		if _, ok := related[contact.ID]; !ok {
+			continue
+		}
+
+		nodes = append(nodes, graphNode{
+			ID:        contact.ID,
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+		})
+	}
+
+	slices.SortFunc(nodes, func(a, b graphNode) int {
+		if c := strings.Compare(strings.ToLower(a.LastName), strings.ToLower(b.LastName)); c != 0 {
+			return c
+		}
+
+		if c := strings.Compare(strings.ToLower(a.FirstName), strings.ToLower(b.FirstName)); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	radius := math.Max(graphMinRadius, float64(len(nodes))*graphNodeSpacing/(2*math.Pi))
+	center := radius + graphLabelMargin
+
+	positions := map[int32]int{}
+	for i := range nodes {
+		angle := 2*math.Pi*float64(i)/float64(len(nodes)) - math.Pi/2
+		cos, sin := math.Cos(angle), math.Sin(angle)
+
+		nodes[i].X = center + radius*cos
+		nodes[i].Y = center + radius*sin
+		nodes[i].LabelX = center + (radius+graphLabelDistance)*cos
+		nodes[i].LabelY = center + (radius+graphLabelDistance)*sin + 4
+
+		switch {
+		case cos > 0.1:
+			nodes[i].LabelAnchor = "start"
+		case cos < -0.1:
+			nodes[i].LabelAnchor = "end"
+		default:
+			nodes[i].LabelAnchor = "middle"
+		}
+
+		positions[nodes[i].ID] = i
+	}
+
+	edges := []graphEdge{}
+	for _, relationship := range relationships {
+		from, ok := positions[relationship.ContactID]
+		if !ok {
+			continue
+		}
+
+		to, ok := positions[relationship.RelatedContactID]
+		if !ok {
+			continue
+		}
+
+		x1, y1, x2, y2 := nodes[from].X, nodes[from].Y, nodes[to].X, nodes[to].Y
+
+		length := math.Hypot(x2-x1, y2-y1)
+		if length == 0 {
+			continue
+		}
+		dx, dy := (x2-x1)/length*graphNodeRadius, (y2-y1)/length*graphNodeRadius
+
+		label := relationshipLabel(relationship.Type, relationship.Label, false)
+		if inverseLabel := relationshipLabel(relationship.Type, relationship.Label, true); relationship.Reciprocal && inverseLabel != label {
+			label += " / " + inverseLabel
+		}
+
+		edges = append(edges, graphEdge{
+			X1:         x1 + dx,
+			Y1:         y1 + dy,
+			X2:         x2 - dx,
+			Y2:         y2 - dy,
+			LabelX:     (x1 + x2) / 2,
+			LabelY:     (y1+y2)/2 - 4,
+			Label:      label,
+			Reciprocal: relationship.Reciprocal,
+		})
+	}
+
+	return 2 * center, nodes, edges
+}
+
+func (b *Controller) HandleContactsGraph(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	contacts, err := b.persister.GetContacts(r.Context(), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	relationships, err := b.persister.GetContactRelationshipsForNamespace(r.Context(), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	size, nodes, edges := layoutContactsGraph(contacts, relationships)
+
+	if err := b.tpl.ExecuteTemplate(w, "contacts_graph.html", contactsGraphData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       "Relationships",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+
+			BackURL: "/contacts",
+		},
+
+		Width:  size,
+		Height: size,
+		Nodes:  nodes,
+		Edges:  edges,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
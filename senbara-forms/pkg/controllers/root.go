@@ -6,10 +6,11 @@ import (
 	"errors"
 	"html/template"
 	"log"
-	"math"
 	"net/http"
 
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/leonelquinteros/gotext"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/money"
 	"github.com/pojntfx/senbara/senbara-forms/pkg/persisters"
 	"github.com/pojntfx/senbara/senbara-forms/pkg/templates"
 	"github.com/yuin/goldmark"
@@ -18,31 +19,44 @@ import (
 )
 
 var (
-	errCouldNotRenderTemplate   = errors.New("could not render template")
-	errCouldNotFetchFromDB      = errors.New("could not fetch from DB")
-	errCouldNotParseForm        = errors.New("could not parse form")
-	errInvalidForm              = errors.New("could not use invalid form")
-	errCouldNotInsertIntoDB     = errors.New("could not insert into DB")
-	errCouldNotDeleteFromDB     = errors.New("could not delete from DB")
-	errCouldNotUpdateInDB       = errors.New("could not update in DB")
-	errInvalidQueryParam        = errors.New("could not use invalid query parameter")
-	errCouldNotLogin            = errors.New("could not login")
-	errEmailNotVerified         = errors.New("email not verified")
-	errCouldNotLocalize         = errors.New("could not localize")
-	errCouldNotWriteResponse    = errors.New("could not write response")
-	errCouldNotReadRequest      = errors.New("could not read request")
-	errUnknownEntityName        = errors.New("unknown entity name")
-	errCouldNotStartTransaction = errors.New("could not start transaction")
+	errCouldNotRenderTemplate      = errors.New("could not render template")
+	errCouldNotFetchFromDB         = errors.New("could not fetch from DB")
+	errCouldNotParseForm           = errors.New("could not parse form")
+	errInvalidForm                 = errors.New("could not use invalid form")
+	errCouldNotInsertIntoDB        = errors.New("could not insert into DB")
+	errCouldNotDeleteFromDB        = errors.New("could not delete from DB")
+	errCouldNotUpdateInDB          = errors.New("could not update in DB")
+	errInvalidQueryParam           = errors.New("could not use invalid query parameter")
+	errCouldNotLogin               = errors.New("could not login")
+	errEmailNotVerified            = errors.New("email not verified")
+	errCouldNotLocalize            = errors.New("could not localize")
+	errCouldNotWriteResponse       = errors.New("could not write response")
+	errCouldNotReadRequest         = errors.New("could not read request")
+	errUnknownEntityName           = errors.New("unknown entity name")
+	errCouldNotStartTransaction    = errors.New("could not start transaction")
+	errCouldNotStageUserData       = errors.New("could not stage user data")
+	errUnknownStagedUserData       = errors.New("unknown or expired staged user data")
+	errTooManyStagedUserData       = errors.New("too many staged user data imports")
+	errUserDataTooLarge            = errors.New("user data too large")
+	errCouldNotGenerateAppPassword = errors.New("could not generate app password")
+	errUnauthorized                = errors.New("unauthorized")
+	errMethodNotAllowed            = errors.New("method not allowed")
+	errNotFound                    = errors.New("not found")
+	errPreconditionFailed          = errors.New("precondition failed")
+	errCouldNotGenerateToken       = errors.New("could not generate token")
 )
 
 const (
-	idTokenKey      = "id_token"
-	refreshTokenKey = "refresh_token"
+	sessionKey = "session_id"
+
+	// Cookies which held the OIDC tokens before sessions were stored in the DB
+	legacyIDTokenKey      = "id_token"
+	legacyRefreshTokenKey = "refresh_token"
 )
 
 type Controller struct {
 	tpl       *template.Template
-	persister *persisters.Persister
+	persister persisters.Persister
 
 	oidcIssuer      string
 	oidcClientID    string
@@ -51,12 +65,13 @@ type Controller struct {
 	privacyURL string
 	imprintURL string
 
-	config   *oauth2.Config
-	verifier *oidc.IDTokenVerifier
+	config             *oauth2.Config
+	verifier           *oidc.IDTokenVerifier
+	endSessionEndpoint string
 }
 
 func NewController(
-	persister *persisters.Persister,
+	persister persisters.Persister,
 
 	oidcIssuer,
 	oidcClientID,
@@ -98,9 +113,12 @@ func (b *Controller) Init(ctx context.Context) error {
 
 			return template.HTML(buf.String())
 		},
-		"Abs": func(number float64) float64 {
-			return math.Abs(number)
+		"Abs": money.Abs,
+		"FormatAmount": func(locale *gotext.Locale, amount int64, currency string) string {
+			return money.FormatLocalized(localeTag(locale), amount, currency)
 		},
+		"AmountValue": money.FormatAmount,
+		"Highlight":   highlightSearchHeadline,
 	}).ParseFS(templates.FS, "*.html")
 	if err != nil {
 		return err
@@ -124,6 +142,21 @@ func (b *Controller) Init(ctx context.Context) error {
 		ClientID: b.oidcClientID,
 	})
 
+	// `go-oidc` doesn't expose the endpoints for logging out, so they have to be
+	// read from the discovery document
+	var providerClaims struct {
+		EndSessionEndpoint string `json:"end_session_endpoint"`
+	}
+	if err := provider.Claims(&providerClaims); err != nil {
+		return err
+	}
+
+	if providerClaims.EndSessionEndpoint == "" {
+		log.Println("OIDC provider has no end_session_endpoint, signing out will only end local sessions")
+	}
+
+	b.endSessionEndpoint = providerClaims.EndSessionEndpoint
+
 	return nil
 }
 
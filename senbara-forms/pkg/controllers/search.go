@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/persisters"
+)
+
+const (
+	searchResultLimit = 50
+)
+
+type searchData struct {
+	pageData
+	Query   string
+	Results []models.SearchDocumentsRow
+}
+
+// highlightSearchHeadline escapes a search result's headline and marks the
+// matches the persister highlighted
+func highlightSearchHeadline(headline string) template.HTML {
+	return template.HTML(strings.NewReplacer(
+		persisters.SearchHighlightStart, "<mark>",
+		persisters.SearchHighlightStop, "</mark>",
+	).Replace(template.HTMLEscapeString(headline)))
+}
+
+func (b *Controller) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	query := strings.TrimSpace(r.FormValue("q"))
+
+	results := []models.SearchDocumentsRow{}
+	if query != "" {
+		results, err = b.persister.Search(r.Context(), query, searchResultLimit, userData.Email)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	if err := b.tpl.ExecuteTemplate(w, "search.html", searchData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       "Search",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+		},
+		Query:   query,
+		Results: results,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+var (
+	errCouldNotGenerateSessionID = errors.New("could not generate session ID")
+	errSessionEmailChanged       = errors.New("session belongs to a different email")
+)
+
+type sessionsData struct {
+	pageData
+	Entries []models.GetSessionsRow
+}
+
+func generateSessionID() (string, error) {
+	rawSessionID := make([]byte, 32)
+	if _, err := rand.Read(rawSessionID); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(rawSessionID), nil
+}
+
+// clientIP returns the IP address a request was sent from; it is only
+// displayed to the user, so the first `X-Forwarded-For` address is trusted
+func clientIP(r *http.Request) string {
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		ip, _, _ := strings.Cut(forwardedFor, ",")
+
+		return strings.TrimSpace(ip)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func clearLegacyTokenCookies(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   legacyRefreshTokenKey,
+		Value:  "",
+		MaxAge: -1,
+	})
+
+	http.SetCookie(w, &http.Cookie{
+		Name:   legacyIDTokenKey,
+		Value:  "",
+		MaxAge: -1,
+	})
+}
+
+func (b *Controller) HandleSessions(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	sessions, err := b.persister.GetSessions(r.Context(), userData.Email)
+	if err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := b.tpl.ExecuteTemplate(w, "sessions.html", sessionsData{
+		pageData: pageData{
+			userData: userData,
+
+			Page:       "Sessions",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+		},
+		Entries: sessions,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
+
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// HandleDeleteSession signs out of a session. If it is the current one, the
+// user is also signed out of the OIDC provider.
+func (b *Controller) HandleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	rid := r.FormValue("id")
+	if strings.TrimSpace(rid) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	id, err := strconv.Atoi(rid)
+	if err != nil {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.persister.DeleteSession(r.Context(), int32(id), userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if int32(id) != userData.SessionID {
+		http.Redirect(w, r, "/sessions", http.StatusFound)
+
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:   sessionKey,
+		Value:  "",
+		MaxAge: -1,
+		Path:   "/",
+	})
+
+	http.Redirect(w, r, userData.LogoutURL, http.StatusFound)
+}
+
+func (b *Controller) HandleDeleteSessions(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := b.persister.DeleteSessions(r.Context(), userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:   sessionKey,
+		Value:  "",
+		MaxAge: -1,
+		Path:   "/",
+	})
+
+	http.Redirect(w, r, userData.LogoutURL, http.StatusFound)
+}
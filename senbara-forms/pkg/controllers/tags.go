@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"slices"
+	"strings"
+)
+
+// normalizeTag trims and lowercases a tag and collapses its whitespace so that
+// i.e. " Climbing  Club" and "climbing club" are the same tag
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// parseTags parses the comma-separated tags of the add and edit forms,
+// dropping empty and duplicate tags
+func parseTags(rtags string) []string {
+	return normalizeTags(strings.Split(rtags, ","))
+}
+
+func normalizeTags(rtags []string) []string {
+	tags := []string{}
+	for _, rtag := range rtags {
+		tag := normalizeTag(rtag)
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
@@ -1,20 +1,40 @@
 package controllers
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
 )
 
 const (
+	EntityNameExportedManifest     = "manifest"
 	EntityNameExportedJournalEntry = "journalEntry"
 	EntityNameExportedContact      = "contact"
 	EntityNameExportedDebt         = "debt"
 	EntityNameExportedActivity     = "activity"
+	EntityNameExportedRelationship = "relationship"
+	EntityNameExportedGroup        = "group"
+)
+
+const (
+	stagedUserDataTTL             = time.Hour
+	maxStagedUserDataPerNamespace = 5
+	maxUserDataSize               = 32 * 1024 * 1024
+	maxUserDataLineSize           = 16 * 1024 * 1024
 )
 
 func (b *Controller) HandleUserData(w http.ResponseWriter, r *http.Request) {
@@ -29,16 +49,40 @@ func (b *Controller) HandleUserData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := b.encodeUserData(w, r, userData.Email); err != nil {
+		log.Println(errCouldNotFetchFromDB, err)
+
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// encodeUserData streams all of the namespace's data to `w` as JSONL, starting
+// with the manifest
+func (b *Controller) encodeUserData(w http.ResponseWriter, r *http.Request, namespace string) error {
 	w.Header().Set("Content-Type", "application/jsonl")
 	w.Header().Set("Content-Disposition", `attachment; filename="senbara-forms-userdata.jsonl"`)
 
 	encoder := json.NewEncoder(w)
 
-	if err := b.persister.GetUserData(
+	return b.persister.GetUserData(
 		r.Context(),
 
-		userData.Email,
+		namespace,
+
+		func(manifest models.ExportedManifest) error {
+			manifest.ExportedEntityIdentifier.EntityName = EntityNameExportedManifest
+			manifest.Version = UserDataVersion
+			manifest.ExportedAt = time.Now().UTC()
+			manifest.Source = r.Host
 
+			if err := encoder.Encode(manifest); err != nil {
+				return errors.Join(errCouldNotWriteResponse, err)
+			}
+
+			return nil
+		},
 		func(journalEntry models.ExportedJournalEntry) error {
 			journalEntry.ExportedEntityIdentifier.EntityName = EntityNameExportedJournalEntry
 
@@ -75,13 +119,657 @@ func (b *Controller) HandleUserData(w http.ResponseWriter, r *http.Request) {
 
 			return nil
 		},
-	); err != nil {
-		log.Println(errCouldNotFetchFromDB, err)
+		func(relationship models.ExportedRelationship) error {
+			relationship.ExportedEntityIdentifier.EntityName = EntityNameExportedRelationship
 
-		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+			if err := encoder.Encode(relationship); err != nil {
+				return errors.Join(errCouldNotWriteResponse, err)
+			}
 
-		return
+			return nil
+		},
+		func(group models.ExportedGroup) error {
+			group.ExportedEntityIdentifier.EntityName = EntityNameExportedGroup
+
+			if err := encoder.Encode(group); err != nil {
+				return errors.Join(errCouldNotWriteResponse, err)
+			}
+
+			return nil
+		},
+	)
+}
+
+type skippedUserDataRecord struct {
+	Line       int
+	EntityName string
+	Reason     string
+}
+
+type duplicateContact struct {
+	Line       int
+	FirstName  string
+	LastName   string
+	Email      string
+	ExistingID int32
+}
+
+type stagedDebt struct {
+	line int
+	debt models.ExportedDebt
+}
+
+type stagedActivity struct {
+	line     int
+	activity models.ExportedActivity
+}
+
+type stagedRelationship struct {
+	line         int
+	relationship models.ExportedRelationship
+}
+
+type stagedGroup struct {
+	line  int
+	group models.ExportedGroup
+}
+
+type stagedUserData struct {
+	namespace string
+
+	manifest models.ExportedManifest
+
+	journalEntries []models.ExportedJournalEntry
+	contacts       []models.ExportedContact
+	debts          []models.ExportedDebt
+	activities     []models.ExportedActivity
+	relationships  []models.ExportedRelationship
+	groups         []models.ExportedGroup
+
+	skipped    []skippedUserDataRecord
+	duplicates []duplicateContact
+}
+
+type userDataImportData struct {
+	pageData
+
+	Token string
+
+	Manifest models.ExportedManifest
+
+	JournalEntries int
+	Contacts       int
+	Debts          int
+	Activities     int
+	Relationships  int
+	Groups         int
+
+	Skipped    []skippedUserDataRecord
+	Duplicates []duplicateContact
+}
+
+// isUserDataTooLarge checks if an upload exceeded `maxUserDataSize`
+func isUserDataTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+
+	return errors.As(err, &maxBytesErr)
+}
+
+func (b *Controller) stageUserData(ctx context.Context, namespace string, r io.Reader) (*stagedUserData, error) {
+	staged := &stagedUserData{
+		namespace: namespace,
+	}
+
+	var (
+		debts         = []stagedDebt{}
+		activities    = []stagedActivity{}
+		relationships = []stagedRelationship{}
+		groups        = []stagedGroup{}
+
+		contactLines = map[int32]int{}
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxUserDataLineSize)
+
+	var (
+		lineNumber  = 0
+		firstRecord = true
+	)
+	for scanner.Scan() {
+		lineNumber++
+
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var entityIdentifier models.ExportedEntityIdentifier
+		if err := json.Unmarshal(line, &entityIdentifier); err != nil {
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:   lineNumber,
+				Reason: "Malformed line",
+			})
+
+			continue
+		}
+
+		// Exports without a manifest in their first record predate versioning and
+		// are treated as version 0
+		if entityIdentifier.EntityName == EntityNameExportedManifest {
+			if !firstRecord {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     "Manifest is not the first record",
+				})
+
+				continue
+			}
+
+			if err := json.Unmarshal(line, &staged.manifest); err != nil {
+				return nil, err
+			}
+
+			if staged.manifest.Version > UserDataVersion {
+				return nil, errors.Join(errUnsupportedUserDataVersion, fmt.Errorf("version %v", staged.manifest.Version))
+			}
+
+			firstRecord = false
+
+			continue
+		}
+		firstRecord = false
+
+		line, err := upgradeUserDataRecord(staged.manifest, entityIdentifier.EntityName, line)
+		if err != nil {
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:       lineNumber,
+				EntityName: entityIdentifier.EntityName,
+				Reason:     "Could not upgrade record to the current version",
+			})
+
+			continue
+		}
+
+		switch entityIdentifier.EntityName {
+		case EntityNameExportedJournalEntry:
+			var journalEntry models.ExportedJournalEntry
+			if err := json.Unmarshal(line, &journalEntry); err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     "Malformed journal entry",
+				})
+
+				continue
+			}
+
+			journalEntry.Tags = normalizeTags(journalEntry.Tags)
+
+			staged.journalEntries = append(staged.journalEntries, journalEntry)
+
+		case EntityNameExportedContact:
+			var contact models.ExportedContact
+			if err := json.Unmarshal(line, &contact); err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     "Malformed contact",
+				})
+
+				continue
+			}
+
+			if _, ok := contactLines[contact.ID]; ok {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     fmt.Sprintf("Contact ID %v is used more than once", contact.ID),
+				})
+
+				continue
+			}
+
+			contact, err = normalizeExportedContactDetails(contact)
+			if err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     fmt.Sprintf("Invalid contact details: %v", err),
+				})
+
+				continue
+			}
+
+			contact.Tags = normalizeTags(contact.Tags)
+
+			contactLines[contact.ID] = lineNumber
+			staged.contacts = append(staged.contacts, contact)
+
+		case EntityNameExportedDebt:
+			var debt models.ExportedDebt
+			if err := json.Unmarshal(line, &debt); err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     "Malformed debt",
+				})
+
+				continue
+			}
+
+			debt, err = normalizeExportedDebt(debt)
+			if err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     fmt.Sprintf("Invalid debt: %v", err),
+				})
+
+				continue
+			}
+
+			debts = append(debts, stagedDebt{lineNumber, debt})
+
+		case EntityNameExportedActivity:
+			var activity models.ExportedActivity
+			if err := json.Unmarshal(line, &activity); err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     "Malformed activity",
+				})
+
+				continue
+			}
+
+			activities = append(activities, stagedActivity{lineNumber, activity})
+
+		case EntityNameExportedRelationship:
+			var relationship models.ExportedRelationship
+			if err := json.Unmarshal(line, &relationship); err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     "Malformed relationship",
+				})
+
+				continue
+			}
+
+			relationshipType, label, err := normalizeRelationship(relationship.Type, relationship.Label)
+			if err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     fmt.Sprintf("Invalid relationship: %v", err),
+				})
+
+				continue
+			}
+			relationship.Type = relationshipType
+			relationship.Label = label
+
+			relationships = append(relationships, stagedRelationship{lineNumber, relationship})
+
+		case EntityNameExportedGroup:
+			var group models.ExportedGroup
+			if err := json.Unmarshal(line, &group); err != nil {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     "Malformed group",
+				})
+
+				continue
+			}
+
+			group.Name = strings.TrimSpace(group.Name)
+			if group.Name == "" {
+				staged.skipped = append(staged.skipped, skippedUserDataRecord{
+					Line:       lineNumber,
+					EntityName: entityIdentifier.EntityName,
+					Reason:     "Invalid group: missing name",
+				})
+
+				continue
+			}
+
+			groups = append(groups, stagedGroup{lineNumber, group})
+
+		default:
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:       lineNumber,
+				EntityName: entityIdentifier.EntityName,
+				Reason:     errUnknownEntityName.Error(),
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Debts, activities, relationships and groups can reference contacts which
+	// are defined later in the file, so dangling contact IDs can only be
+	// detected once all lines have been read
+	for _, debt := range debts {
+		if _, ok := contactLines[debt.debt.ContactID.Int32]; !debt.debt.ContactID.Valid || !ok {
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:       debt.line,
+				EntityName: EntityNameExportedDebt,
+				Reason:     fmt.Sprintf("Contact ID %v is not part of the user data", debt.debt.ContactID.Int32),
+			})
+
+			continue
+		}
+
+		staged.debts = append(staged.debts, debt.debt)
+	}
+
+	for _, activity := range activities {
+		var (
+			missingContactID = activity.activity.ContactID.Int32
+			missing          = len(activity.activity.ContactIDs) == 0
+		)
+		for _, contactID := range activity.activity.ContactIDs {
+			if _, ok := contactLines[contactID]; !ok {
+				missingContactID, missing = contactID, true
+
+				break
+			}
+		}
+
+		if missing {
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:       activity.line,
+				EntityName: EntityNameExportedActivity,
+				Reason:     fmt.Sprintf("Contact ID %v is not part of the user data", missingContactID),
+			})
+
+			continue
+		}
+
+		staged.activities = append(staged.activities, activity.activity)
+	}
+
+	for _, relationship := range relationships {
+		contactID, relatedContactID := relationship.relationship.ContactID, relationship.relationship.RelatedContactID
+
+		if _, ok := contactLines[contactID.Int32]; !contactID.Valid || !ok {
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:       relationship.line,
+				EntityName: EntityNameExportedRelationship,
+				Reason:     fmt.Sprintf("Contact ID %v is not part of the user data", contactID.Int32),
+			})
+
+			continue
+		}
+
+		if _, ok := contactLines[relatedContactID.Int32]; !relatedContactID.Valid || !ok {
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:       relationship.line,
+				EntityName: EntityNameExportedRelationship,
+				Reason:     fmt.Sprintf("Contact ID %v is not part of the user data", relatedContactID.Int32),
+			})
+
+			continue
+		}
+
+		if contactID.Int32 == relatedContactID.Int32 {
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:       relationship.line,
+				EntityName: EntityNameExportedRelationship,
+				Reason:     fmt.Sprintf("Contact ID %v can't be related to itself", contactID.Int32),
+			})
+
+			continue
+		}
+
+		staged.relationships = append(staged.relationships, relationship.relationship)
+	}
+
+	for _, group := range groups {
+		var (
+			missingContactID int32
+			missing          bool
+		)
+		for _, contactID := range group.group.ContactIDs {
+			if _, ok := contactLines[contactID]; !ok {
+				missingContactID, missing = contactID, true
+
+				break
+			}
+		}
+
+		if missing {
+			staged.skipped = append(staged.skipped, skippedUserDataRecord{
+				Line:       group.line,
+				EntityName: EntityNameExportedGroup,
+				Reason:     fmt.Sprintf("Contact ID %v is not part of the user data", missingContactID),
+			})
+
+			continue
+		}
+
+		staged.groups = append(staged.groups, group.group)
+	}
+
+	sort.SliceStable(staged.skipped, func(i, j int) bool {
+		return staged.skipped[i].Line < staged.skipped[j].Line
+	})
+
+	existingContacts, err := b.persister.GetContacts(ctx, namespace)
+	if err != nil {
+		return nil, err
 	}
+
+	existingDetails, err := b.persister.GetContactDetailsForNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, contact := range staged.contacts {
+		for _, existingContact := range existingContacts {
+			sameEmail := ""
+			for _, email := range contact.Emails {
+				for _, existingEmail := range existingDetails[existingContact.ID].Emails {
+					if strings.TrimSpace(email.Email) != "" && strings.EqualFold(email.Email, existingEmail.Email) {
+						sameEmail = email.Email
+
+						break
+					}
+				}
+
+				if sameEmail != "" {
+					break
+				}
+			}
+
+			sameName := strings.EqualFold(contact.FirstName, existingContact.FirstName) && strings.EqualFold(contact.LastName, existingContact.LastName)
+
+			if sameEmail != "" || sameName {
+				email := sameEmail
+				if email == "" && len(contact.Emails) > 0 {
+					email = contact.Emails[0].Email
+				}
+
+				staged.duplicates = append(staged.duplicates, duplicateContact{
+					Line:       contactLines[contact.ID],
+					FirstName:  contact.FirstName,
+					LastName:   contact.LastName,
+					Email:      email,
+					ExistingID: existingContact.ID,
+				})
+
+				break
+			}
+		}
+	}
+
+	return staged, nil
+}
+
+// persistedStagedUserData is what is stored in the DB between staging and
+// confirming an import; skipped records and duplicates are only shown once
+type persistedStagedUserData struct {
+	Manifest models.ExportedManifest `json:"manifest"`
+
+	JournalEntries []models.ExportedJournalEntry `json:"journalEntries"`
+	Contacts       []models.ExportedContact      `json:"contacts"`
+	Debts          []models.ExportedDebt         `json:"debts"`
+	Activities     []models.ExportedActivity     `json:"activities"`
+	Relationships  []models.ExportedRelationship `json:"relationships"`
+	Groups         []models.ExportedGroup        `json:"groups"`
+}
+
+// storeStagedUserData persists staged user data and returns the token to
+// confirm or cancel it with; only the token's hash is stored
+func (b *Controller) storeStagedUserData(ctx context.Context, staged *stagedUserData) (string, error) {
+	data, err := json.Marshal(persistedStagedUserData{
+		Manifest: staged.manifest,
+
+		JournalEntries: staged.journalEntries,
+		Contacts:       staged.contacts,
+		Debts:          staged.debts,
+		Activities:     staged.activities,
+		Relationships:  staged.relationships,
+		Groups:         staged.groups,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(rawToken)
+
+	created, err := b.persister.CreateStagedUserData(
+		ctx,
+
+		hashSecret(token),
+		data,
+		time.Now().UTC().Add(stagedUserDataTTL),
+		maxStagedUserDataPerNamespace,
+
+		staged.namespace,
+	)
+	if err != nil {
+		return "", err
+	} else if !created {
+		return "", errTooManyStagedUserData
+	}
+
+	return token, nil
+}
+
+// loadStagedUserData returns previously stored staged user data; it is kept
+// until it has been imported or cancelled with `deleteStagedUserData`
+func (b *Controller) loadStagedUserData(ctx context.Context, token, namespace string) (*stagedUserData, error) {
+	row, err := b.persister.GetStagedUserData(ctx, hashSecret(token), namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errUnknownStagedUserData
+		}
+
+		return nil, err
+	}
+
+	if !time.Now().Before(row.ExpiresAt) {
+		return nil, errUnknownStagedUserData
+	}
+
+	var persisted persistedStagedUserData
+	if err := json.Unmarshal(row.Data, &persisted); err != nil {
+		return nil, err
+	}
+
+	return &stagedUserData{
+		namespace: namespace,
+
+		manifest: persisted.Manifest,
+
+		journalEntries: persisted.JournalEntries,
+		contacts:       persisted.Contacts,
+		debts:          persisted.Debts,
+		activities:     persisted.Activities,
+		relationships:  persisted.Relationships,
+		groups:         persisted.Groups,
+	}, nil
+}
+
+func (b *Controller) deleteStagedUserData(ctx context.Context, token, namespace string) error {
+	return b.persister.DeleteStagedUserData(ctx, hashSecret(token), namespace)
+}
+
+// createStagedUserData imports previously staged user data and deletes it in a
+// single transaction, so that confirming it twice can't import it twice
+func (b *Controller) createStagedUserData(ctx context.Context, token string, staged *stagedUserData) error {
+	createJournalEntry,
+		createContact,
+		createDebt,
+		createActivity,
+		createRelationship,
+		createGroup,
+
+		commit,
+		rollback,
+
+		err := b.persister.CreateUserData(ctx, hashSecret(token), staged.namespace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errUnknownStagedUserData
+		}
+
+		return errors.Join(errCouldNotStartTransaction, err)
+	}
+	defer rollback()
+
+	for _, journalEntry := range staged.journalEntries {
+		if err := createJournalEntry(journalEntry); err != nil {
+			return errors.Join(errCouldNotInsertIntoDB, err)
+		}
+	}
+
+	for _, contact := range staged.contacts {
+		if err := createContact(contact); err != nil {
+			return errors.Join(errCouldNotInsertIntoDB, err)
+		}
+	}
+
+	for _, debt := range staged.debts {
+		if err := createDebt(debt); err != nil {
+			return errors.Join(errCouldNotInsertIntoDB, err)
+		}
+	}
+
+	for _, activity := range staged.activities {
+		if err := createActivity(activity); err != nil {
+			return errors.Join(errCouldNotInsertIntoDB, err)
+		}
+	}
+
+	for _, relationship := range staged.relationships {
+		if err := createRelationship(relationship); err != nil {
+			return errors.Join(errCouldNotInsertIntoDB, err)
+		}
+	}
+
+	for _, group := range staged.groups {
+		if err := createGroup(group); err != nil {
+			return errors.Join(errCouldNotInsertIntoDB, err)
+		}
+	}
+
+	if err := commit(); err != nil {
+		return errors.Join(errCouldNotInsertIntoDB, err)
+	}
+
+	return nil
 }
 
 func (b *Controller) HandleCreateUserData(w http.ResponseWriter, r *http.Request) {
@@ -96,8 +784,18 @@ func (b *Controller) HandleCreateUserData(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	r.Body = http.MaxBytesReader(w, r.Body, maxUserDataSize)
+
 	file, _, err := r.FormFile("userData")
 	if err != nil {
+		if isUserDataTooLarge(err) {
+			log.Println(errUserDataTooLarge, err)
+
+			http.Error(w, errUserDataTooLarge.Error(), http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
 		log.Println(errCouldNotReadRequest, err)
 
 		http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
@@ -106,133 +804,167 @@ func (b *Controller) HandleCreateUserData(w http.ResponseWriter, r *http.Request
 	}
 	defer file.Close()
 
-	decoder := json.NewDecoder(file)
+	staged, err := b.stageUserData(r.Context(), userData.Email, file)
+	if err != nil {
+		if errors.Is(err, errUnsupportedUserDataVersion) {
+			log.Println(err)
 
-	createJournalEntry,
-		createContact,
-		createDebt,
-		createActivity,
+			http.Error(w, errUnsupportedUserDataVersion.Error(), http.StatusUnprocessableEntity)
 
-		commit,
-		rollback,
+			return
+		}
 
-		err := b.persister.CreateUserData(r.Context(), userData.Email)
-	if err != nil {
-		log.Println(errCouldNotStartTransaction, err)
+		log.Println(errCouldNotReadRequest, err)
 
-		http.Error(w, errCouldNotStartTransaction.Error(), http.StatusInternalServerError)
+		http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
 
 		return
 	}
-	defer rollback()
-
-	for {
-		var b json.RawMessage
-		if err := decoder.Decode(&b); err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
 
-			log.Println(errCouldNotReadRequest, err)
+	token, err := b.storeStagedUserData(r.Context(), staged)
+	if err != nil {
+		if errors.Is(err, errTooManyStagedUserData) {
+			log.Println(err)
 
-			http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
+			http.Error(w, errTooManyStagedUserData.Error(), http.StatusTooManyRequests)
 
 			return
 		}
 
-		var entityIdentifier models.ExportedEntityIdentifier
-		if err := json.Unmarshal(b, &entityIdentifier); err != nil {
-			log.Println(errCouldNotReadRequest, err)
+		log.Println(errCouldNotStageUserData, err)
 
-			http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
+		http.Error(w, errCouldNotStageUserData.Error(), http.StatusInternalServerError)
 
-			return
-		}
+		return
+	}
 
-		switch entityIdentifier.EntityName {
-		case EntityNameExportedJournalEntry:
-			var journalEntry models.ExportedJournalEntry
-			if err := json.Unmarshal(b, &journalEntry); err != nil {
-				log.Println(errCouldNotReadRequest, err)
+	if err := b.tpl.ExecuteTemplate(w, "userdata_import.html", userDataImportData{
+		pageData: pageData{
+			userData: userData,
 
-				http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
+			Page:       "Import User Data",
+			PrivacyURL: b.privacyURL,
+			ImprintURL: b.imprintURL,
+		},
 
-				return
-			}
+		Token: token,
 
-			if err := createJournalEntry(journalEntry); err != nil {
-				log.Println(errCouldNotInsertIntoDB, err)
+		Manifest: staged.manifest,
 
-				http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+		JournalEntries: len(staged.journalEntries),
+		Contacts:       len(staged.contacts),
+		Debts:          len(staged.debts),
+		Activities:     len(staged.activities),
+		Relationships:  len(staged.relationships),
+		Groups:         len(staged.groups),
 
-				return
-			}
+		Skipped:    staged.skipped,
+		Duplicates: staged.duplicates,
+	}); err != nil {
+		log.Println(errCouldNotRenderTemplate, err)
 
-		case EntityNameExportedContact:
-			var contact models.ExportedContact
-			if err := json.Unmarshal(b, &contact); err != nil {
-				log.Println(errCouldNotReadRequest, err)
+		http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
 
-				http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
+		return
+	}
+}
 
-				return
-			}
+func (b *Controller) HandleConfirmUserData(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
 
-			if err := createContact(contact); err != nil {
-				log.Println(errCouldNotInsertIntoDB, err)
+		http.Error(w, err.Error(), status)
 
-				http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+		return
+	} else if redirected {
+		return
+	}
 
-				return
-			}
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
 
-		case EntityNameExportedDebt:
-			var debt models.ExportedDebt
-			if err := json.Unmarshal(b, &debt); err != nil {
-				log.Println(errCouldNotReadRequest, err)
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
 
-				http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
+		return
+	}
 
-				return
-			}
+	token := r.FormValue("token")
+	if strings.TrimSpace(token) == "" {
+		log.Println(errInvalidForm)
 
-			if err := createDebt(debt); err != nil {
-				log.Println(errCouldNotInsertIntoDB, err)
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
 
-				http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+		return
+	}
 
-				return
-			}
+	staged, err := b.loadStagedUserData(r.Context(), token, userData.Email)
+	if err != nil {
+		if errors.Is(err, errUnknownStagedUserData) {
+			log.Println(err)
 
-		case EntityNameExportedActivity:
-			var activity models.ExportedActivity
-			if err := json.Unmarshal(b, &activity); err != nil {
-				log.Println(errCouldNotReadRequest, err)
+			http.Error(w, errUnknownStagedUserData.Error(), http.StatusNotFound)
 
-				http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
+			return
+		}
 
-				return
-			}
+		log.Println(errCouldNotFetchFromDB, err)
 
-			if err := createActivity(activity); err != nil {
-				log.Println(errCouldNotInsertIntoDB, err)
+		http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
 
-				http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+		return
+	}
 
-				return
-			}
+	if err := b.createStagedUserData(r.Context(), token, staged); err != nil {
+		log.Println(err)
 
-		default:
-			log.Println("Skipping import error:", errUnknownEntityName, err)
+		if errors.Is(err, errUnknownStagedUserData) {
+			http.Error(w, errUnknownStagedUserData.Error(), http.StatusNotFound)
 
-			continue
+			return
 		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
 	}
 
-	if err := commit(); err != nil {
-		log.Println(errCouldNotInsertIntoDB, err)
+	http.Redirect(w, r, "/contacts", http.StatusFound)
+}
 
-		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+func (b *Controller) HandleCancelUserData(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	token := r.FormValue("token")
+	if strings.TrimSpace(token) == "" {
+		log.Println(errInvalidForm)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	if err := b.deleteStagedUserData(r.Context(), token, userData.Email); err != nil {
+		log.Println(errCouldNotDeleteFromDB, err)
+
+		http.Error(w, errCouldNotDeleteFromDB.Error(), http.StatusInternalServerError)
 
 		return
 	}
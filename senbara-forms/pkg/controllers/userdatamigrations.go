@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/money"
+)
+
+// UserDataVersion is the version of the user data export schema written by
+// `HandleUserData`. Bump it and append a converter to `userDataConverters`
+// whenever an `Exported*` struct changes in an incompatible way.
+const UserDataVersion = 5
+
+var (
+	errUnsupportedUserDataVersion = errors.New("unsupported user data version")
+)
+
+// userDataConverter upgrades a single exported record from version `n` to `n + 1`
+// in place, where `n` is its index in `userDataConverters`. `manifest` is the
+// manifest of the export, which is empty for exports without one.
+type userDataConverter func(manifest models.ExportedManifest, entityName string, record map[string]json.RawMessage) error
+
+var userDataConverters = []userDataConverter{
+	// 0 -> 1: Exports without a manifest, which includes exports from before the
+	// `add_description` and `add_personal_info` migrations
+	func(manifest models.ExportedManifest, entityName string, record map[string]json.RawMessage) error {
+		switch entityName {
+		case EntityNameExportedContact:
+			setDefaultUserDataField(record, "birthday", json.RawMessage(`{"Time":"0001-01-01T00:00:00Z","Valid":false}`))
+			setDefaultUserDataField(record, "address", json.RawMessage(`""`))
+			setDefaultUserDataField(record, "notes", json.RawMessage(`""`))
+
+		case EntityNameExportedDebt:
+			setDefaultUserDataField(record, "description", json.RawMessage(`""`))
+		}
+
+		return nil
+	},
+
+	// 1 -> 2: Contacts with a single `email` and `address` from before the
+	// `add_contact_details` migration
+	func(manifest models.ExportedManifest, entityName string, record map[string]json.RawMessage) error {
+		if entityName != EntityNameExportedContact {
+			return nil
+		}
+
+		for _, field := range []struct {
+			from  string
+			to    string
+			value string
+		}{
+			{"email", "emails", "email"},
+			{"address", "addresses", "address"},
+		} {
+			raw, ok := record[field.from]
+			if !ok {
+				continue
+			}
+			delete(record, field.from)
+
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+
+			details := []map[string]string{}
+			if strings.TrimSpace(value) != "" {
+				details = append(details, map[string]string{
+					"label":     "other",
+					field.value: value,
+				})
+			}
+
+			b, err := json.Marshal(details)
+			if err != nil {
+				return err
+			}
+
+			setDefaultUserDataField(record, field.to, b)
+		}
+
+		setDefaultUserDataField(record, "emails", json.RawMessage(`[]`))
+		setDefaultUserDataField(record, "phones", json.RawMessage(`[]`))
+		setDefaultUserDataField(record, "addresses", json.RawMessage(`[]`))
+		setDefaultUserDataField(record, "websites", json.RawMessage(`[]`))
+
+		return nil
+	},
+
+	// 2 -> 3: Activities with a single `contactId` from before the
+	// `add_activity_participants` migration
+	func(manifest models.ExportedManifest, entityName string, record map[string]json.RawMessage) error {
+		if entityName != EntityNameExportedActivity {
+			return nil
+		}
+
+		contactIDs := []int32{}
+		if raw, ok := record["contactId"]; ok {
+			var contactID sql.NullInt32
+			if err := json.Unmarshal(raw, &contactID); err != nil {
+				return err
+			}
+
+			if contactID.Valid {
+				contactIDs = append(contactIDs, contactID.Int32)
+			}
+		}
+
+		b, err := json.Marshal(contactIDs)
+		if err != nil {
+			return err
+		}
+
+		setDefaultUserDataField(record, "contactIds", b)
+
+		return nil
+	},
+
+	// 3 -> 4: Debts without a date or payments from before the
+	// `add_debt_payments` migration, which deleted settled debts. They are dated
+	// when they were exported, since that's the earliest date they are known to
+	// have existed at; exports without a manifest have no such date, so their
+	// debts are dated when they are imported like the migration dates them.
+	func(manifest models.ExportedManifest, entityName string, record map[string]json.RawMessage) error {
+		if entityName != EntityNameExportedDebt {
+			return nil
+		}
+
+		addedAt := manifest.ExportedAt.UTC()
+		if manifest.ExportedAt.IsZero() {
+			addedAt = time.Now().UTC()
+		}
+
+		b, err := json.Marshal(time.Date(addedAt.Year(), addedAt.Month(), addedAt.Day(), 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			return err
+		}
+
+		setDefaultUserDataField(record, "date", b)
+
+		setDefaultUserDataField(record, "payments", json.RawMessage(`[]`))
+
+		return nil
+	},
+
+	// 4 -> 5: Debts with float amounts from before the
+	// `store_amounts_in_minor_units` migration, which are rounded to the minor
+	// unit of their currency like the migration rounds them
+	func(manifest models.ExportedManifest, entityName string, record map[string]json.RawMessage) error {
+		if entityName != EntityNameExportedDebt {
+			return nil
+		}
+
+		var currency string
+		if raw, ok := record["currency"]; ok {
+			if err := json.Unmarshal(raw, &currency); err != nil {
+				return err
+			}
+		}
+
+		if raw, ok := record["amount"]; ok {
+			amount, err := exactUserDataAmount(raw, currency)
+			if err != nil {
+				return err
+			}
+
+			record["amount"] = amount
+		}
+
+		raw, ok := record["payments"]
+		if !ok {
+			return nil
+		}
+
+		var payments []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &payments); err != nil {
+			return err
+		}
+
+		for _, payment := range payments {
+			if raw, ok := payment["amount"]; ok {
+				amount, err := exactUserDataAmount(raw, currency)
+				if err != nil {
+					return err
+				}
+
+				payment["amount"] = amount
+			}
+		}
+
+		b, err := json.Marshal(payments)
+		if err != nil {
+			return err
+		}
+		record["payments"] = b
+
+		return nil
+	},
+}
+
+// exactUserDataAmount converts a float amount into an exact decimal in minor
+// units of the currency, i.e. `7.999999999999999` into `8.00` for USD
+func exactUserDataAmount(raw json.RawMessage, currency string) (json.RawMessage, error) {
+	var amount json.Number
+	if err := json.Unmarshal(raw, &amount); err != nil {
+		return nil, err
+	}
+
+	legacy, err := amount.Float64()
+	if err != nil {
+		return nil, err
+	}
+
+	minor, err := money.FromFloat(legacy, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(money.FormatAmount(minor, currency)), nil
+}
+
+func setDefaultUserDataField(record map[string]json.RawMessage, key string, value json.RawMessage) {
+	if _, ok := record[key]; !ok {
+		record[key] = value
+	}
+}
+
+func upgradeUserDataRecord(manifest models.ExportedManifest, entityName string, b []byte) ([]byte, error) {
+	version := manifest.Version
+	if version == UserDataVersion {
+		return b, nil
+	}
+
+	if version < 0 || version > UserDataVersion {
+		return nil, errors.Join(errUnsupportedUserDataVersion, fmt.Errorf("version %v", version))
+	}
+
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(b, &record); err != nil {
+		return nil, err
+	}
+
+	for v := version; v < UserDataVersion; v++ {
+		if err := userDataConverters[v](manifest, entityName, record); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(record)
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/vcards"
+)
+
+// contactLabelToVCardTypes returns the vCard `TYPE` values for the label of
+// a contact detail
+func contactLabelToVCardTypes(label string) []string {
+	switch label {
+	case contactLabelHome:
+		return []string{"home"}
+
+	case contactLabelWork:
+		return []string{"work"}
+
+	case contactLabelMobile:
+		return []string{"cell"}
+
+	default:
+		return nil
+	}
+}
+
+// vCardTypesToContactLabel returns the first label in `labels` which matches
+// one of the vCard `TYPE` values, or "other" if none of them match
+func vCardTypesToContactLabel(types []string, labels []string) string {
+	for _, t := range types {
+		label := ""
+		switch t {
+		case "home":
+			label = contactLabelHome
+
+		case "work":
+			label = contactLabelWork
+
+		case "cell", "mobile", "iphone":
+			label = contactLabelMobile
+		}
+
+		if slices.Contains(labels, label) {
+			return label
+		}
+	}
+
+	return contactLabelOther
+}
+
+func contactToVCard(contact models.Contact, details models.ContactDetails) vcards.Card {
+	card := vcards.Card{
+		UID:       fmt.Sprintf("urn:senbara-forms:contact:%v", contact.ID),
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		Nickname:  contact.Nickname,
+		Pronouns:  contact.Pronouns,
+		Notes:     contact.Notes,
+	}
+
+	if contact.Birthday.Valid {
+		birthday := contact.Birthday.Time
+		card.Birthday = &birthday
+	}
+
+	for _, email := range details.Emails {
+		card.Emails = append(card.Emails, vcards.Property{
+			Types: contactLabelToVCardTypes(email.Label),
+			Value: email.Email,
+		})
+	}
+
+	for _, phone := range details.Phones {
+		card.Phones = append(card.Phones, vcards.Property{
+			Types: contactLabelToVCardTypes(phone.Label),
+			Value: phone.Number,
+		})
+	}
+
+	for _, address := range details.Addresses {
+		card.Addresses = append(card.Addresses, vcards.Property{
+			Types: contactLabelToVCardTypes(address.Label),
+			Value: address.Address,
+		})
+	}
+
+	for _, website := range details.Websites {
+		card.Websites = append(card.Websites, vcards.Property{
+			Types: contactLabelToVCardTypes(website.Label),
+			Value: website.Url,
+		})
+	}
+
+	return card
+}
+
+// vCardToContact maps a vCard onto a contact and its details. Other apps don't
+// validate phone numbers and websites like the contact forms do, so instead of
+// rejecting a whole vCard, the ones which can't be normalized are dropped.
+func vCardToContact(card vcards.Card) (models.Contact, models.ContactDetails) {
+	contact := models.Contact{
+		FirstName: card.FirstName,
+		LastName:  card.LastName,
+		Nickname:  card.Nickname,
+		Pronouns:  card.Pronouns,
+		Notes:     card.Notes,
+	}
+
+	if card.Birthday != nil {
+		contact.Birthday = sql.NullTime{
+			Time:  *card.Birthday,
+			Valid: true,
+		}
+	}
+
+	details := models.ContactDetails{}
+	for _, email := range card.Emails {
+		if strings.TrimSpace(email.Value) == "" {
+			continue
+		}
+
+		details.Emails = append(details.Emails, models.ContactEmail{
+			Label: vCardTypesToContactLabel(email.Types, contactEmailLabels),
+			Email: strings.TrimSpace(email.Value),
+		})
+	}
+
+	for _, phone := range card.Phones {
+		number, err := normalizePhoneNumber(phone.Value)
+		if err != nil {
+			log.Println(err)
+
+			continue
+		}
+
+		details.Phones = append(details.Phones, models.ContactPhone{
+			Label:  vCardTypesToContactLabel(phone.Types, contactPhoneLabels),
+			Number: number,
+		})
+	}
+
+	for _, address := range card.Addresses {
+		if strings.TrimSpace(address.Value) == "" {
+			continue
+		}
+
+		details.Addresses = append(details.Addresses, models.ContactAddress{
+			Label:   vCardTypesToContactLabel(address.Types, contactAddressLabels),
+			Address: strings.TrimSpace(address.Value),
+		})
+	}
+
+	for _, website := range card.Websites {
+		u, err := normalizeWebsite(website.Value)
+		if err != nil {
+			log.Println(err)
+
+			continue
+		}
+
+		details.Websites = append(details.Websites, models.ContactWebsite{
+			Label: vCardTypesToContactLabel(website.Types, contactWebsiteLabels),
+			Url:   u,
+		})
+	}
+
+	return contact, details
+}
+
+func (b *Controller) HandleContactsVCard(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	version := r.URL.Query().Get("version")
+	if strings.TrimSpace(version) == "" {
+		version = vcards.Version4
+	}
+
+	if version != vcards.Version3 && version != vcards.Version4 {
+		log.Println(errInvalidQueryParam)
+
+		http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	var (
+		contacts = []models.Contact{}
+		details  = map[int32]models.ContactDetails{}
+		filename = "senbara-forms-contacts.vcf"
+	)
+	if rid := r.URL.Query().Get("id"); strings.TrimSpace(rid) != "" {
+		id, err := strconv.Atoi(rid)
+		if err != nil {
+			log.Println(errInvalidQueryParam)
+
+			http.Error(w, errInvalidQueryParam.Error(), http.StatusUnprocessableEntity)
+
+			return
+		}
+
+		contact, err := b.persister.GetContact(r.Context(), int32(id), userData.Email)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		contactDetails, err := b.persister.GetContactDetails(r.Context(), contact.ID, userData.Email)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		contacts = append(contacts, contact)
+		details[contact.ID] = contactDetails
+		filename = fmt.Sprintf("senbara-forms-contact-%v.vcf", contact.ID)
+	} else {
+		contacts, err = b.persister.GetContacts(r.Context(), userData.Email)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		details, err = b.persister.GetContactDetailsForNamespace(r.Context(), userData.Email)
+		if err != nil {
+			log.Println(errCouldNotFetchFromDB, err)
+
+			http.Error(w, errCouldNotFetchFromDB.Error(), http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	cards := []vcards.Card{}
+	for _, contact := range contacts {
+		cards = append(cards, contactToVCard(contact, details[contact.ID]))
+	}
+
+	w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%v"`, filename))
+
+	if err := vcards.Encode(w, version, cards...); err != nil {
+		log.Println(errCouldNotWriteResponse, err)
+
+		http.Error(w, errCouldNotWriteResponse.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+func (b *Controller) HandleCreateContactsVCard(w http.ResponseWriter, r *http.Request) {
+	redirected, userData, status, err := b.authorize(w, r)
+	if err != nil {
+		log.Println(err)
+
+		http.Error(w, err.Error(), status)
+
+		return
+	} else if redirected {
+		return
+	}
+
+	file, _, err := r.FormFile("vcard")
+	if err != nil {
+		log.Println(errCouldNotReadRequest, err)
+
+		http.Error(w, errCouldNotReadRequest.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	defer file.Close()
+
+	cards, err := vcards.Decode(file)
+	if err != nil {
+		log.Println(errInvalidForm, err)
+
+		http.Error(w, errInvalidForm.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	var (
+		contacts = []models.Contact{}
+		details  = []models.ContactDetails{}
+	)
+	for _, card := range cards {
+		contact, contactDetails := vCardToContact(card)
+
+		contacts = append(contacts, contact)
+		details = append(details, contactDetails)
+	}
+
+	ids, err := b.persister.CreateContacts(r.Context(), contacts, details, userData.Email)
+	if err != nil {
+		log.Println(errCouldNotInsertIntoDB, err)
+
+		http.Error(w, errCouldNotInsertIntoDB.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if len(ids) == 1 {
+		http.Redirect(w, r, fmt.Sprintf("/contacts/view?id=%v", ids[0]), http.StatusFound)
+
+		return
+	}
+
+	http.Redirect(w, r, "/contacts", http.StatusFound)
+}
@@ -0,0 +1,518 @@
+package devauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	keyID = "senbara-forms-dev"
+
+	authorizationCodeTTL = time.Minute
+	idTokenTTL           = time.Hour
+)
+
+var (
+	errNoEmails                = errors.New("at least one email is required")
+	errInvalidEmail            = errors.New("invalid email")
+	errUnknownClientID         = errors.New("unknown client ID")
+	errUnknownRedirectURI      = errors.New("unknown redirect URI")
+	errUnsupportedResponseType = errors.New("unsupported response type")
+	errCouldNotSignToken       = errors.New("could not sign token")
+	errCouldNotRenderTemplate  = errors.New("could not render template")
+	errCouldNotParseForm       = errors.New("could not parse form")
+	errNotLoopback             = errors.New("host is not a loopback address")
+)
+
+var signInTemplate = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1.0" />
+    <title>Sign in | Senbara Forms Development OIDC Provider</title>
+  </head>
+
+  <body>
+    <header>
+      <h1>Senbara Forms Development OIDC Provider</h1>
+    </header>
+
+    <main>
+      <p>
+        This provider signs in anyone without a password and must only be used
+        for local development and tests.
+      </p>
+
+      <form method="post">
+        <input type="hidden" name="client_id" value="{{ .ClientID }}" />
+        <input type="hidden" name="redirect_uri" value="{{ .RedirectURI }}" />
+        <input type="hidden" name="response_type" value="code" />
+        <input type="hidden" name="state" value="{{ .State }}" />
+        <input type="hidden" name="nonce" value="{{ .Nonce }}" />
+
+        <fieldset>
+          <legend>Sign in as</legend>
+
+          {{ range $i, $email := .Emails }}
+          <label>
+            <input type="radio" name="email" value="{{ $email }}" {{ if eq $i 0 }}checked{{ end }} />
+            {{ $email }}
+          </label>
+          {{ end }}
+        </fieldset>
+
+        <input type="submit" value="Sign in" />
+      </form>
+    </main>
+  </body>
+</html>
+`))
+
+type signInData struct {
+	ClientID    string
+	RedirectURI string
+	State       string
+	Nonce       string
+	Emails      []string
+}
+
+type grant struct {
+	email     string
+	nonce     string
+	sessionID string
+	expiresAt time.Time
+}
+
+// Issuer is an OIDC provider which signs ID tokens for a fixed set of verified
+// emails without asking for a password, which makes it possible to develop and
+// test without an external provider
+type Issuer struct {
+	issuer      string
+	clientID    string
+	redirectURL string
+	emails      []string
+
+	key *rsa.PrivateKey
+
+	grantsLock         sync.Mutex
+	authorizationCodes map[string]grant
+	refreshTokens      map[string]grant
+}
+
+// IsLoopback reports whether `host`, which may include a port, only accepts
+// connections from the local machine
+func IsLoopback(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func NewIssuer(
+	issuer,
+	clientID,
+	redirectURL string,
+
+	emails []string,
+) (*Issuer, error) {
+	if len(emails) == 0 {
+		return nil, errNoEmails
+	}
+
+	for _, email := range emails {
+		if !strings.Contains(email, "@") {
+			return nil, errors.Join(errInvalidEmail, errors.New(email))
+		}
+	}
+
+	// The redirect URL's host must not be reachable from other machines, which
+	// prevents this provider from being used for a deployment by accident
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsLoopback(u.Host) {
+		return nil, errors.Join(errNotLoopback, errors.New(u.Host))
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Issuer{
+		issuer:      strings.TrimSuffix(issuer, "/"),
+		clientID:    clientID,
+		redirectURL: redirectURL,
+		emails:      emails,
+
+		key: key,
+
+		authorizationCodes: map[string]grant{},
+		refreshTokens:      map[string]grant{},
+	}, nil
+}
+
+func (i *Issuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /.well-known/openid-configuration", i.handleDiscovery)
+	mux.HandleFunc("GET /jwks", i.handleJWKS)
+	mux.HandleFunc("GET /authorize", i.handleAuthorize)
+	mux.HandleFunc("POST /authorize", i.handleAuthorize)
+	mux.HandleFunc("POST /token", i.handleToken)
+	mux.HandleFunc("GET /logout", i.handleLogout)
+
+	mux.ServeHTTP(w, r)
+}
+
+func generateToken() (string, error) {
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(rawToken), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
+
+// writeOAuth2Error writes an error response as defined in RFC 6749, section 5.2
+func writeOAuth2Error(w http.ResponseWriter, code string, err error) {
+	log.Println(err)
+
+	writeJSON(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": err.Error(),
+	})
+}
+
+func (i *Issuer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                i.issuer,
+		"authorization_endpoint":                i.issuer + "/authorize",
+		"token_endpoint":                        i.issuer + "/token",
+		"jwks_uri":                              i.issuer + "/jwks",
+		"end_session_endpoint":                  i.issuer + "/logout",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{string(jose.RS256)},
+		"scopes_supported":                      []string{"openid", "offline_access", "email"},
+		"claims_supported":                      []string{"iss", "sub", "aud", "exp", "iat", "nonce", "sid", "email", "email_verified"},
+		"token_endpoint_auth_methods_supported": []string{"none", "client_secret_basic", "client_secret_post"},
+	})
+}
+
+func (i *Issuer) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{
+			{
+				Key:       i.key.Public(),
+				KeyID:     keyID,
+				Algorithm: string(jose.RS256),
+				Use:       "sig",
+			},
+		},
+	})
+}
+
+func (i *Issuer) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Println(errCouldNotParseForm, err)
+
+		http.Error(w, errCouldNotParseForm.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	// Errors about the client or redirect URI must not be redirected, see RFC
+	// 6749, section 4.1.2.1
+	if r.FormValue("client_id") != i.clientID {
+		log.Println(errUnknownClientID)
+
+		http.Error(w, errUnknownClientID.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	redirectURI := r.FormValue("redirect_uri")
+	if redirectURI != i.redirectURL {
+		log.Println(errUnknownRedirectURI)
+
+		http.Error(w, errUnknownRedirectURI.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if r.FormValue("response_type") != "code" {
+		log.Println(errUnsupportedResponseType)
+
+		http.Error(w, errUnsupportedResponseType.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		if err := signInTemplate.Execute(w, signInData{
+			ClientID:    i.clientID,
+			RedirectURI: redirectURI,
+			State:       r.FormValue("state"),
+			Nonce:       r.FormValue("nonce"),
+			Emails:      i.emails,
+		}); err != nil {
+			log.Println(errCouldNotRenderTemplate, err)
+
+			http.Error(w, errCouldNotRenderTemplate.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		return
+	}
+
+	email := r.FormValue("email")
+	if !slices.Contains(i.emails, email) {
+		log.Println(errInvalidEmail)
+
+		http.Error(w, errInvalidEmail.Error(), http.StatusUnprocessableEntity)
+
+		return
+	}
+
+	code, err := generateToken()
+	if err != nil {
+		log.Println(errCouldNotSignToken, err)
+
+		http.Error(w, errCouldNotSignToken.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	sessionID, err := generateToken()
+	if err != nil {
+		log.Println(errCouldNotSignToken, err)
+
+		http.Error(w, errCouldNotSignToken.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	i.grantsLock.Lock()
+	i.authorizationCodes[code] = grant{
+		email:     email,
+		nonce:     r.FormValue("nonce"),
+		sessionID: sessionID,
+		expiresAt: time.Now().Add(authorizationCodeTTL),
+	}
+	i.grantsLock.Unlock()
+
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		log.Println(errUnknownRedirectURI, err)
+
+		http.Error(w, errUnknownRedirectURI.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	q := u.Query()
+	q.Set("code", code)
+	if state := r.FormValue("state"); state != "" {
+		q.Set("state", state)
+	}
+	u.RawQuery = q.Encode()
+
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+func (i *Issuer) signIDToken(g grant) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: i.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":            i.issuer,
+		"sub":            g.email,
+		"aud":            i.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(idTokenTTL).Unix(),
+		"sid":            g.sessionID,
+		"email":          g.email,
+		"email_verified": true,
+	}
+	if g.nonce != "" {
+		claims["nonce"] = g.nonce
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	jws, err := signer.Sign(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return jws.CompactSerialize()
+}
+
+func (i *Issuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuth2Error(w, "invalid_request", errors.Join(errCouldNotParseForm, err))
+
+		return
+	}
+
+	// Public clients send their ID in the body, confidential ones with basic
+	// auth; the secret isn't checked since this provider trusts everyone anyway
+	clientID := r.PostFormValue("client_id")
+	if username, _, ok := r.BasicAuth(); ok {
+		clientID = username
+	}
+
+	if clientID != i.clientID {
+		writeOAuth2Error(w, "invalid_client", errUnknownClientID)
+
+		return
+	}
+
+	var (
+		g  grant
+		ok bool
+	)
+	i.grantsLock.Lock()
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		code := r.PostFormValue("code")
+
+		// Authorization codes can only be used once
+		g, ok = i.authorizationCodes[code]
+		delete(i.authorizationCodes, code)
+
+		ok = ok && time.Now().Before(g.expiresAt) && r.PostFormValue("redirect_uri") == i.redirectURL
+
+	case "refresh_token":
+		g, ok = i.refreshTokens[r.PostFormValue("refresh_token")]
+
+		// Nonces are only included in the ID token returned for the authorization code
+		g.nonce = ""
+
+	default:
+		i.grantsLock.Unlock()
+
+		writeOAuth2Error(w, "unsupported_grant_type", errors.New("unsupported grant type"))
+
+		return
+	}
+	i.grantsLock.Unlock()
+
+	if !ok {
+		writeOAuth2Error(w, "invalid_grant", errors.New("invalid or expired grant"))
+
+		return
+	}
+
+	idToken, err := i.signIDToken(g)
+	if err != nil {
+		log.Println(errCouldNotSignToken, err)
+
+		http.Error(w, errCouldNotSignToken.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	accessToken, err := generateToken()
+	if err != nil {
+		log.Println(errCouldNotSignToken, err)
+
+		http.Error(w, errCouldNotSignToken.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	refreshToken, err := generateToken()
+	if err != nil {
+		log.Println(errCouldNotSignToken, err)
+
+		http.Error(w, errCouldNotSignToken.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	i.grantsLock.Lock()
+	delete(i.refreshTokens, r.PostFormValue("refresh_token"))
+	i.refreshTokens[refreshToken] = g
+	i.grantsLock.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(idTokenTTL.Seconds()),
+		"refresh_token": refreshToken,
+		"id_token":      idToken,
+	})
+}
+
+// handleLogout implements RP-initiated logout. This provider doesn't keep
+// sessions of its own, so it only redirects back to the client.
+func (i *Issuer) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if clientID := r.URL.Query().Get("client_id"); clientID != "" && clientID != i.clientID {
+		log.Println(errUnknownClientID)
+
+		http.Error(w, errUnknownClientID.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	postLogoutRedirectURI := r.URL.Query().Get("post_logout_redirect_uri")
+	if postLogoutRedirectURI != "" && postLogoutRedirectURI != i.redirectURL {
+		log.Println(errUnknownRedirectURI)
+
+		http.Error(w, errUnknownRedirectURI.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if postLogoutRedirectURI == "" {
+		postLogoutRedirectURI = i.redirectURL
+	}
+
+	http.Redirect(w, r, postLogoutRedirectURI, http.StatusFound)
+}
@@ -0,0 +1,372 @@
+package icals
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	productID = "-//Senbara Forms//Senbara Forms//EN"
+
+	maxLineLength = 75
+)
+
+var (
+	ErrMissingStart          = errors.New("missing start date in VEVENT")
+	ErrUnterminatedEvent     = errors.New("unterminated VEVENT")
+	ErrUnterminatedCalendar  = errors.New("unterminated VCALENDAR")
+	errInvalidDateOrDateTime = errors.New("invalid date or date-time")
+)
+
+// Attendee is a participant of an event, identified by their email address
+type Attendee struct {
+	Name  string
+	Email string
+}
+
+// Event is the subset of a VEVENT which maps onto birthdays and activities
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+
+	// Events are all-day events, so only the date of `Start` is used
+	Start time.Time
+
+	// Recurrence rule such as `FREQ=YEARLY`, if any
+	RRule string
+
+	Attendees []Attendee
+}
+
+// Encode writes the events as a VCALENDAR with the given name to `w`
+func Encode(w io.Writer, name string, events ...Event) error {
+	return encode(
+		w,
+		[]string{
+			"METHOD:PUBLISH",
+			"X-WR-CALNAME:" + escape(name),
+		},
+		events...,
+	)
+}
+
+// EncodeObject writes a single event as a VCALENDAR to `w`. Unlike `Encode`,
+// the calendar has no method or name, as is required for CalDAV resources.
+func EncodeObject(w io.Writer, event Event) error {
+	return encode(w, []string{}, event)
+}
+
+func encode(w io.Writer, properties []string, events ...Event) error {
+	bw := bufio.NewWriter(w)
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	lines := append([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + productID,
+		"CALSCALE:GREGORIAN",
+	}, properties...)
+
+	for _, event := range events {
+		lines = append(
+			lines,
+			"BEGIN:VEVENT",
+			"UID:"+escape(event.UID),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+event.Start.Format("20060102"),
+			"DTEND;VALUE=DATE:"+event.Start.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+escape(event.Summary),
+		)
+
+		if event.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escape(event.Description))
+		}
+
+		if event.RRule != "" {
+			lines = append(lines, "RRULE:"+event.RRule)
+		}
+
+		for _, attendee := range event.Attendees {
+			params := ""
+			if attendee.Name != "" {
+				params = ";CN=" + quoteParam(attendee.Name)
+			}
+
+			lines = append(lines, "ATTENDEE"+params+":mailto:"+attendee.Email)
+		}
+
+		lines = append(lines, "TRANSP:TRANSPARENT", "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := bw.WriteString(fold(line)); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Decode reads all VEVENTs from the VCALENDARs in `r`. Only the properties in
+// `Event` are kept; all other properties and components are ignored.
+func Decode(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events = []Event{}
+
+		inCalendar = false
+		event      *Event
+		hasStart   bool
+	)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "BEGIN":
+			switch strings.ToUpper(value) {
+			case "VCALENDAR":
+				inCalendar = true
+
+			case "VEVENT":
+				if inCalendar {
+					event = &Event{}
+					hasStart = false
+				}
+			}
+
+			continue
+
+		case "END":
+			switch strings.ToUpper(value) {
+			case "VCALENDAR":
+				if event != nil {
+					return nil, ErrUnterminatedEvent
+				}
+
+				inCalendar = false
+
+			case "VEVENT":
+				if event == nil {
+					continue
+				}
+
+				if !hasStart {
+					return nil, ErrMissingStart
+				}
+
+				events = append(events, *event)
+				event = nil
+			}
+
+			continue
+		}
+
+		if event == nil {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			event.UID = unescape(value)
+
+		case "SUMMARY":
+			event.Summary = unescape(value)
+
+		case "DESCRIPTION":
+			event.Description = unescape(value)
+
+		case "DTSTART":
+			start, err := parseDateOrDateTime(value)
+			if err != nil {
+				return nil, errors.Join(ErrMissingStart, err)
+			}
+
+			event.Start = start
+			hasStart = true
+
+		case "RRULE":
+			event.RRule = value
+
+		case "ATTENDEE":
+			email, ok := strings.CutPrefix(value, "mailto:")
+			if !ok {
+				email, ok = strings.CutPrefix(value, "MAILTO:")
+			}
+
+			if !ok {
+				continue
+			}
+
+			event.Attendees = append(event.Attendees, Attendee{
+				Name:  getParam(params, "CN"),
+				Email: email,
+			})
+		}
+	}
+
+	if event != nil {
+		return nil, ErrUnterminatedEvent
+	}
+
+	if inCalendar {
+		return nil, ErrUnterminatedCalendar
+	}
+
+	return events, nil
+}
+
+// parseDateOrDateTime parses a `DATE` or `DATE-TIME` value. Since events are
+// all-day events, only the date of date-times is used.
+func parseDateOrDateTime(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errInvalidDateOrDateTime
+	}
+
+	return time.Parse("20060102", value[:8])
+}
+
+func parseLine(line string) (name, params, value string, ok bool) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+
+			break
+		}
+	}
+
+	if colon == -1 {
+		return "", "", "", false
+	}
+
+	name, params, _ = strings.Cut(line[:colon], ";")
+
+	return strings.ToUpper(name), params, line[colon+1:], true
+}
+
+func getParam(params, name string) string {
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(param, "=")
+		if ok && strings.EqualFold(key, name) {
+			return strings.Trim(value, `"`)
+		}
+	}
+
+	return ""
+}
+
+func quoteParam(value string) string {
+	value = strings.ReplaceAll(value, `"`, "'")
+
+	if strings.ContainsAny(value, ";:,") {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+func unescape(value string) string {
+	var (
+		b       strings.Builder
+		escaped = false
+	)
+	for _, r := range value {
+		if escaped {
+			switch r {
+			case 'n', 'N':
+				b.WriteRune('\n')
+			default:
+				b.WriteRune(r)
+			}
+
+			escaped = false
+
+			continue
+		}
+
+		if r == '\\' {
+			escaped = true
+
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lines := []string{}
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		",", `\,`,
+		";", `\;`,
+	).Replace(value)
+}
+
+func fold(line string) string {
+	var b strings.Builder
+
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > maxLineLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+
+		b.WriteRune(r)
+		length += size
+	}
+
+	b.WriteString("\r\n")
+
+	return b.String()
+}
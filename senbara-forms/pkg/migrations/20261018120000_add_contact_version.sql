@@ -0,0 +1,5 @@
+-- +goose Up
+alter table contacts
+add column version integer default 1 not null;
+-- +goose Down
+alter table contacts drop column version;
\ No newline at end of file
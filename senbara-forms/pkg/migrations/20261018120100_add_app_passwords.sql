@@ -0,0 +1,10 @@
+-- +goose Up
+create table app_passwords (
+    id serial primary key,
+    name text not null,
+    hash text not null unique,
+    created_at timestamp not null default now(),
+    namespace text not null
+);
+-- +goose Down
+drop table app_passwords;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- +goose Up
+create table calendar_feeds (
+    id serial primary key,
+    hash text not null unique,
+    created_at timestamp not null default now(),
+    namespace text not null unique
+);
+-- +goose Down
+drop table calendar_feeds;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- +goose Up
+create table personal_access_tokens (
+    id serial primary key,
+    name text not null,
+    hash text not null unique,
+    scopes text not null,
+    created_at timestamp not null default now(),
+    expires_at timestamp,
+    namespace text not null
+);
+-- +goose Down
+drop table personal_access_tokens;
\ No newline at end of file
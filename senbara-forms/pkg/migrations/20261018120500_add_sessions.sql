@@ -0,0 +1,14 @@
+-- +goose Up
+create table sessions (
+    id serial primary key,
+    hash text not null unique,
+    refresh_token text not null,
+    id_token text not null,
+    user_agent text not null,
+    ip text not null,
+    created_at timestamp not null default now(),
+    last_seen_at timestamp not null default now(),
+    namespace text not null
+);
+-- +goose Down
+drop table sessions;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- +goose Up
+alter table sessions
+add column subject text not null default '';
+alter table sessions
+add column oidc_session_id text not null default '';
+-- +goose Down
+alter table sessions drop column oidc_session_id;
+alter table sessions drop column subject;
\ No newline at end of file
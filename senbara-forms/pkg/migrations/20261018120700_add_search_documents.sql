@@ -0,0 +1,239 @@
+-- +goose Up
+create table search_documents (
+    entity_name text not null,
+    entity_id integer not null,
+    contact_id integer,
+    namespace text not null,
+    config regconfig not null default 'simple',
+    title text not null,
+    body text not null,
+    document tsvector generated always as (
+        setweight(to_tsvector(config, title), 'A') || setweight(to_tsvector(config, body), 'B')
+    ) stored,
+    primary key (entity_name, entity_id)
+);
+create index search_documents_namespace_idx on search_documents (namespace);
+create index search_documents_document_idx on search_documents using gin (document);
+-- New documents use the configuration the namespace was last signed in with
+-- +goose StatementBegin
+create function search_config_for_namespace(search_namespace text) returns regconfig as $$
+select coalesce(
+        (
+            select config
+            from search_documents
+            where namespace = search_namespace
+            limit 1
+        ), 'simple'::regconfig
+    );
+$$ language sql stable;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create function index_search_document(
+    document_entity_name text,
+    document_entity_id integer,
+    document_contact_id integer,
+    document_namespace text,
+    document_title text,
+    document_body text
+) returns void as $$
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+values (
+        document_entity_name,
+        document_entity_id,
+        document_contact_id,
+        document_namespace,
+        search_config_for_namespace(document_namespace),
+        document_title,
+        document_body
+    ) on conflict (entity_name, entity_id) do
+update
+set contact_id = excluded.contact_id,
+    namespace = excluded.namespace,
+    title = excluded.title,
+    body = excluded.body;
+$$ language sql;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create function index_journal_entry() returns trigger as $$ begin perform index_search_document(
+        'journalEntry',
+        new.id,
+        null,
+        new.namespace,
+        new.title,
+        new.body
+    );
+return new;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create function index_contact() returns trigger as $$ begin perform index_search_document(
+        'contact',
+        new.id,
+        new.id,
+        new.namespace,
+        concat_ws(' ', new.first_name, new.last_name, new.nickname),
+        concat_ws(' ', new.email, new.notes)
+    );
+return new;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create function index_debt() returns trigger as $$ begin perform index_search_document(
+        'debt',
+        new.id,
+        new.contact_id,
+        (
+            select namespace
+            from contacts
+            where id = new.contact_id
+        ),
+        new.description,
+        ''
+    );
+return new;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create function index_activity() returns trigger as $$ begin perform index_search_document(
+        'activity',
+        new.id,
+        new.contact_id,
+        (
+            select namespace
+            from contacts
+            where id = new.contact_id
+        ),
+        new.name,
+        new.description
+    );
+return new;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create function unindex_search_document() returns trigger as $$ begin
+delete from search_documents
+where entity_name = tg_argv[0]
+    and entity_id = old.id;
+return old;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+create trigger journal_entries_index
+after
+insert
+    or
+update on journal_entries for each row execute function index_journal_entry();
+create trigger journal_entries_unindex
+after delete on journal_entries for each row execute function unindex_search_document('journalEntry');
+create trigger contacts_index
+after
+insert
+    or
+update on contacts for each row execute function index_contact();
+create trigger contacts_unindex
+after delete on contacts for each row execute function unindex_search_document('contact');
+create trigger debts_index
+after
+insert
+    or
+update on debts for each row execute function index_debt();
+create trigger debts_unindex
+after delete on debts for each row execute function unindex_search_document('debt');
+create trigger activities_index
+after
+insert
+    or
+update on activities for each row execute function index_activity();
+create trigger activities_unindex
+after delete on activities for each row execute function unindex_search_document('activity');
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        title,
+        body
+    )
+select 'journalEntry',
+    id,
+    null,
+    namespace,
+    title,
+    body
+from journal_entries;
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        title,
+        body
+    )
+select 'contact',
+    id,
+    id,
+    namespace,
+    concat_ws(' ', first_name, last_name, nickname),
+    concat_ws(' ', email, notes)
+from contacts;
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        title,
+        body
+    )
+select 'debt',
+    debts.id,
+    debts.contact_id,
+    contacts.namespace,
+    debts.description,
+    ''
+from debts
+    join contacts on debts.contact_id = contacts.id;
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        title,
+        body
+    )
+select 'activity',
+    activities.id,
+    activities.contact_id,
+    contacts.namespace,
+    activities.name,
+    activities.description
+from activities
+    join contacts on activities.contact_id = contacts.id;
+-- +goose Down
+drop trigger activities_unindex on activities;
+drop trigger activities_index on activities;
+drop trigger debts_unindex on debts;
+drop trigger debts_index on debts;
+drop trigger contacts_unindex on contacts;
+drop trigger contacts_index on contacts;
+drop trigger journal_entries_unindex on journal_entries;
+drop trigger journal_entries_index on journal_entries;
+drop function unindex_search_document;
+drop function index_activity;
+drop function index_debt;
+drop function index_contact;
+drop function index_journal_entry;
+drop function index_search_document;
+drop function search_config_for_namespace;
+drop table search_documents;
\ No newline at end of file
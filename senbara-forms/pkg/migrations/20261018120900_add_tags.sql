@@ -0,0 +1,27 @@
+-- +goose Up
+create table tags (
+    id serial primary key,
+    name text not null,
+    namespace text not null,
+    unique (namespace, name)
+);
+create table contact_tags (
+    contact_id integer not null,
+    tag_id integer not null,
+    primary key (contact_id, tag_id),
+    foreign key (contact_id) references contacts (id),
+    foreign key (tag_id) references tags (id)
+);
+create table journal_entry_tags (
+    journal_entry_id integer not null,
+    tag_id integer not null,
+    primary key (journal_entry_id, tag_id),
+    foreign key (journal_entry_id) references journal_entries (id),
+    foreign key (tag_id) references tags (id)
+);
+create index contact_tags_tag_id_idx on contact_tags (tag_id);
+create index journal_entry_tags_tag_id_idx on journal_entry_tags (tag_id);
+-- +goose Down
+drop table journal_entry_tags;
+drop table contact_tags;
+drop table tags;
\ No newline at end of file
@@ -0,0 +1,139 @@
+-- +goose Up
+create table contact_emails (
+    id serial primary key,
+    contact_id integer not null,
+    label text not null,
+    email text not null,
+    foreign key (contact_id) references contacts (id)
+);
+create table contact_phones (
+    id serial primary key,
+    contact_id integer not null,
+    label text not null,
+    number text not null,
+    foreign key (contact_id) references contacts (id)
+);
+create table contact_addresses (
+    id serial primary key,
+    contact_id integer not null,
+    label text not null,
+    address text not null,
+    foreign key (contact_id) references contacts (id)
+);
+create table contact_websites (
+    id serial primary key,
+    contact_id integer not null,
+    label text not null,
+    url text not null,
+    foreign key (contact_id) references contacts (id)
+);
+create index contact_emails_contact_id_idx on contact_emails (contact_id);
+create index contact_emails_email_idx on contact_emails (lower(email));
+create index contact_phones_contact_id_idx on contact_phones (contact_id);
+create index contact_addresses_contact_id_idx on contact_addresses (contact_id);
+create index contact_websites_contact_id_idx on contact_websites (contact_id);
+insert into contact_emails (contact_id, label, email)
+select id,
+    'other',
+    email
+from contacts
+where email <> ''
+order by id;
+insert into contact_addresses (contact_id, label, address)
+select id,
+    'other',
+    address
+from contacts
+where address <> ''
+order by id;
+-- +goose StatementBegin
+create or replace function index_contact() returns trigger as $$ begin perform index_search_document(
+        'contact',
+        new.id,
+        new.id,
+        new.namespace,
+        concat_ws(' ', new.first_name, new.last_name, new.nickname),
+        concat_ws(
+            ' ',
+            (
+                select string_agg(email, ' ' order by id)
+                from contact_emails
+                where contact_id = new.id
+            ),
+            new.notes
+        )
+    );
+return new;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create function index_contact_emails() returns trigger as $$ begin
+update search_documents
+set body = concat_ws(
+        ' ',
+        (
+            select string_agg(contact_emails.email, ' ' order by contact_emails.id)
+            from contact_emails
+            where contact_emails.contact_id = contacts.id
+        ),
+        contacts.notes
+    )
+from contacts
+where search_documents.entity_name = 'contact'
+    and search_documents.entity_id = contacts.id
+    and contacts.id in (new.contact_id, old.contact_id);
+return null;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+create trigger contact_emails_index
+after
+insert
+    or
+update
+    or delete on contact_emails for each row execute function index_contact_emails();
+alter table contacts drop column email;
+alter table contacts drop column address;
+-- +goose Down
+alter table contacts
+add column email text not null default '';
+alter table contacts
+add column address text not null default '';
+update contacts
+set email = coalesce(
+        (
+            select email
+            from contact_emails
+            where contact_id = contacts.id
+            order by id
+            limit 1
+        ), ''
+    ), address = coalesce(
+        (
+            select address
+            from contact_addresses
+            where contact_id = contacts.id
+            order by id
+            limit 1
+        ), ''
+    );
+drop trigger contact_emails_index on contact_emails;
+drop function index_contact_emails;
+-- +goose StatementBegin
+create or replace function index_contact() returns trigger as $$ begin perform index_search_document(
+        'contact',
+        new.id,
+        new.id,
+        new.namespace,
+        concat_ws(' ', new.first_name, new.last_name, new.nickname),
+        concat_ws(' ', new.email, new.notes)
+    );
+return new;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+drop table contact_websites;
+drop table contact_addresses;
+drop table contact_phones;
+drop table contact_emails;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- +goose Up
+create table contact_relationships (
+    id serial primary key,
+    namespace text not null,
+    contact_id integer not null,
+    related_contact_id integer not null,
+    type text not null,
+    label text not null,
+    reciprocal boolean not null,
+    foreign key (contact_id) references contacts (id),
+    foreign key (related_contact_id) references contacts (id),
+    check (contact_id <> related_contact_id)
+);
+create index contact_relationships_namespace_idx on contact_relationships (namespace);
+create index contact_relationships_contact_id_idx on contact_relationships (contact_id);
+create index contact_relationships_related_contact_id_idx on contact_relationships (related_contact_id);
+-- +goose Down
+drop table contact_relationships;
\ No newline at end of file
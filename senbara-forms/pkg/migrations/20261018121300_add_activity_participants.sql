@@ -0,0 +1,105 @@
+-- +goose Up
+create table activity_participants (
+    activity_id integer not null,
+    contact_id integer not null,
+    primary key (activity_id, contact_id),
+    foreign key (activity_id) references activities (id),
+    foreign key (contact_id) references contacts (id)
+);
+create index activity_participants_contact_id_idx on activity_participants (contact_id);
+insert into activity_participants (activity_id, contact_id)
+select id,
+    contact_id
+from activities;
+-- Activities are no longer owned by a single contact, so they are scoped to
+-- the namespace of the contact they were created for
+alter table activities
+add column namespace text not null default '';
+update activities
+set namespace = contacts.namespace
+from contacts
+where contacts.id = activities.contact_id;
+alter table activities
+alter column namespace drop default;
+drop index activities_contact_id_date_idx;
+alter table activities drop column contact_id;
+create index activities_namespace_date_idx on activities (namespace, date);
+-- Search results for an activity link to its first participant
+-- +goose StatementBegin
+create or replace function index_activity() returns trigger as $$ begin perform index_search_document(
+        'activity',
+        new.id,
+        (
+            select min(contact_id)
+            from activity_participants
+            where activity_id = new.id
+        ),
+        new.namespace,
+        new.name,
+        new.description
+    );
+return new;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create function index_activity_participants() returns trigger as $$
+declare participant_activity_id integer;
+begin if tg_op = 'DELETE' then participant_activity_id := old.activity_id;
+else participant_activity_id := new.activity_id;
+end if;
+update search_documents
+set contact_id = (
+        select min(contact_id)
+        from activity_participants
+        where activity_id = participant_activity_id
+    )
+where entity_name = 'activity'
+    and entity_id = participant_activity_id;
+return null;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+create trigger activity_participants_index
+after
+insert
+    or delete on activity_participants for each row execute function index_activity_participants();
+-- +goose Down
+drop trigger activity_participants_index on activity_participants;
+drop function index_activity_participants;
+-- Activities can only belong to a single contact again, so shared activities
+-- are kept for their first participant
+alter table activities
+add column contact_id integer references contacts (id);
+update activities
+set contact_id = (
+        select min(contact_id)
+        from activity_participants
+        where activity_id = activities.id
+    );
+drop table activity_participants;
+-- +goose StatementBegin
+create or replace function index_activity() returns trigger as $$ begin perform index_search_document(
+        'activity',
+        new.id,
+        new.contact_id,
+        (
+            select namespace
+            from contacts
+            where id = new.contact_id
+        ),
+        new.name,
+        new.description
+    );
+return new;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+delete from activities
+where contact_id is null;
+alter table activities
+alter column contact_id
+set not null;
+drop index activities_namespace_date_idx;
+alter table activities drop column namespace;
+create index activities_contact_id_date_idx on activities (contact_id, date);
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- +goose Up
+alter table debts
+add column date date not null default current_date;
+create table debt_payments (
+    id serial primary key,
+    debt_id integer not null,
+    amount double precision not null,
+    date date not null,
+    description text default '' not null,
+    foreign key (debt_id) references debts (id)
+);
+create index debt_payments_debt_id_idx on debt_payments (debt_id);
+-- +goose Down
+update debts
+set amount = sign(debts.amount) * (abs(debts.amount) - payments.paid)
+from (
+        select debt_id,
+            sum(amount) as paid
+        from debt_payments
+        group by debt_id
+    ) as payments
+where payments.debt_id = debts.id;
+drop table debt_payments;
+delete from debts
+where abs(amount) < 0.005;
+alter table debts drop column date;
\ No newline at end of file
@@ -0,0 +1,121 @@
+-- +goose Up
+-- Amounts are stored as integers of the minor unit of their ISO 4217 currency,
+-- such as cents, so that sums are exact. Currencies without two digits after the
+-- decimal separator are listed here, which includes all of those of `pkg/money`.
+create temporary table currency_minor_units (
+    currency text primary key,
+    factor integer not null
+);
+insert into currency_minor_units (currency, factor)
+values ('BIF', 1),
+    ('CLP', 1),
+    ('DJF', 1),
+    ('GNF', 1),
+    ('ISK', 1),
+    ('JPY', 1),
+    ('KMF', 1),
+    ('KRW', 1),
+    ('PYG', 1),
+    ('RWF', 1),
+    ('UGX', 1),
+    ('UYI', 1),
+    ('VND', 1),
+    ('VUV', 1),
+    ('XAF', 1),
+    ('XOF', 1),
+    ('XPF', 1),
+    ('BHD', 1000),
+    ('IQD', 1000),
+    ('JOD', 1000),
+    ('KWD', 1000),
+    ('LYD', 1000),
+    ('OMR', 1000),
+    ('TND', 1000),
+    ('CLF', 10000),
+    ('UYW', 10000);
+-- Casting to `numeric` uses the shortest decimal representation of the floats,
+-- so amounts such as `0.3` become exactly `30` cents instead of drifting
+update debt_payments
+set amount = round(
+        debt_payments.amount::numeric * coalesce(
+            (
+                select currency_minor_units.factor
+                from debts
+                    join currency_minor_units on currency_minor_units.currency = upper(trim(debts.currency))
+                where debts.id = debt_payments.debt_id
+            ),
+            100
+        )
+    );
+update debts
+set amount = round(
+        debts.amount::numeric * coalesce(
+            (
+                select currency_minor_units.factor
+                from currency_minor_units
+                where currency_minor_units.currency = upper(trim(debts.currency))
+            ),
+            100
+        )
+    );
+alter table debt_payments
+alter column amount type bigint;
+alter table debts
+alter column amount type bigint;
+drop table currency_minor_units;
+-- +goose Down
+create temporary table currency_minor_units (
+    currency text primary key,
+    factor integer not null
+);
+insert into currency_minor_units (currency, factor)
+values ('BIF', 1),
+    ('CLP', 1),
+    ('DJF', 1),
+    ('GNF', 1),
+    ('ISK', 1),
+    ('JPY', 1),
+    ('KMF', 1),
+    ('KRW', 1),
+    ('PYG', 1),
+    ('RWF', 1),
+    ('UGX', 1),
+    ('UYI', 1),
+    ('VND', 1),
+    ('VUV', 1),
+    ('XAF', 1),
+    ('XOF', 1),
+    ('XPF', 1),
+    ('BHD', 1000),
+    ('IQD', 1000),
+    ('JOD', 1000),
+    ('KWD', 1000),
+    ('LYD', 1000),
+    ('OMR', 1000),
+    ('TND', 1000),
+    ('CLF', 10000),
+    ('UYW', 10000);
+alter table debt_payments
+alter column amount type double precision;
+alter table debts
+alter column amount type double precision;
+update debt_payments
+set amount = debt_payments.amount::numeric / coalesce(
+        (
+            select currency_minor_units.factor
+            from debts
+                join currency_minor_units on currency_minor_units.currency = upper(trim(debts.currency))
+            where debts.id = debt_payments.debt_id
+        ),
+        100
+    );
+update debts
+set amount = debts.amount::numeric / coalesce(
+        (
+            select currency_minor_units.factor
+            from currency_minor_units
+            where currency_minor_units.currency = upper(trim(debts.currency))
+        ),
+        100
+    );
+drop table currency_minor_units;
\ No newline at end of file
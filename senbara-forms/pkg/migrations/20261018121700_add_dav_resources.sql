@@ -0,0 +1,11 @@
+-- +goose Up
+create table dav_resources (
+    collection text not null,
+    name text not null,
+    resource_id integer not null,
+    uid text not null,
+    namespace text not null,
+    primary key (collection, name, namespace)
+);
+-- +goose Down
+drop table dav_resources;
\ No newline at end of file
@@ -0,0 +1,86 @@
+-- +goose Up
+create table journal_entries (
+    id integer primary key autoincrement,
+    title text not null,
+    date timestamp not null default current_timestamp,
+    body text not null,
+    rating integer default 2 not null check (
+        rating >= 1
+        and rating <= 3
+    ),
+    namespace text default '' not null
+);
+create table contacts (
+    id integer primary key autoincrement,
+    first_name text not null,
+    last_name text not null,
+    nickname text not null,
+    email text not null,
+    pronouns text not null,
+    namespace text not null,
+    birthday date,
+    address text default '' not null,
+    notes text default '' not null,
+    version integer default 1 not null
+);
+create table debts (
+    id integer primary key autoincrement,
+    amount real not null,
+    currency text not null,
+    contact_id integer not null,
+    description text default '' not null,
+    foreign key (contact_id) references contacts (id)
+);
+create table activities (
+    id integer primary key autoincrement,
+    name text not null,
+    date timestamp not null default current_timestamp,
+    contact_id integer not null,
+    description text not null,
+    version integer default 1 not null,
+    foreign key (contact_id) references contacts (id)
+);
+create table app_passwords (
+    id integer primary key autoincrement,
+    name text not null,
+    hash text not null unique,
+    created_at timestamp not null default current_timestamp,
+    namespace text not null
+);
+create table calendar_feeds (
+    id integer primary key autoincrement,
+    hash text not null unique,
+    created_at timestamp not null default current_timestamp,
+    namespace text not null unique
+);
+create table personal_access_tokens (
+    id integer primary key autoincrement,
+    name text not null,
+    hash text not null unique,
+    scopes text not null,
+    created_at timestamp not null default current_timestamp,
+    expires_at timestamp,
+    namespace text not null
+);
+create table sessions (
+    id integer primary key autoincrement,
+    hash text not null unique,
+    refresh_token text not null,
+    id_token text not null,
+    user_agent text not null,
+    ip text not null,
+    created_at timestamp not null default current_timestamp,
+    last_seen_at timestamp not null default current_timestamp,
+    namespace text not null,
+    subject text not null default '',
+    oidc_session_id text not null default ''
+);
+-- +goose Down
+drop table sessions;
+drop table personal_access_tokens;
+drop table calendar_feeds;
+drop table app_passwords;
+drop table activities;
+drop table debts;
+drop table contacts;
+drop table journal_entries;
\ No newline at end of file
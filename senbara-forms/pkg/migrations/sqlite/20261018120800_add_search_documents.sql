@@ -0,0 +1,300 @@
+-- +goose Up
+create table search_documents (
+    entity_name text not null,
+    entity_id integer not null,
+    contact_id integer,
+    namespace text not null,
+    config text not null default 'simple',
+    title text not null,
+    body text not null,
+    primary key (entity_name, entity_id)
+);
+create index search_documents_namespace_idx on search_documents (namespace);
+-- +goose StatementBegin
+create trigger journal_entries_index
+after
+insert on journal_entries begin
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+values (
+        'journalEntry',
+        new.id,
+        null,
+        new.namespace,
+        coalesce(
+            (
+                select config
+                from search_documents
+                where namespace = new.namespace
+                limit 1
+            ), 'simple'
+        ),
+        new.title,
+        new.body
+    );
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger journal_entries_reindex
+after
+update on journal_entries begin
+update search_documents
+set namespace = new.namespace,
+    title = new.title,
+    body = new.body
+where entity_name = 'journalEntry'
+    and entity_id = new.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger journal_entries_unindex
+after delete on journal_entries begin
+delete from search_documents
+where entity_name = 'journalEntry'
+    and entity_id = old.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger contacts_index
+after
+insert on contacts begin
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+values (
+        'contact',
+        new.id,
+        new.id,
+        new.namespace,
+        coalesce(
+            (
+                select config
+                from search_documents
+                where namespace = new.namespace
+                limit 1
+            ), 'simple'
+        ),
+        trim(
+            new.first_name || ' ' || new.last_name || ' ' || new.nickname
+        ),
+        trim(new.email || ' ' || new.notes)
+    );
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger contacts_reindex
+after
+update on contacts begin
+update search_documents
+set namespace = new.namespace,
+    title = trim(
+        new.first_name || ' ' || new.last_name || ' ' || new.nickname
+    ),
+    body = trim(new.email || ' ' || new.notes)
+where entity_name = 'contact'
+    and entity_id = new.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger contacts_unindex
+after delete on contacts begin
+delete from search_documents
+where entity_name = 'contact'
+    and entity_id = old.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger debts_index
+after
+insert on debts begin
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+select 'debt',
+    new.id,
+    new.contact_id,
+    contacts.namespace,
+    coalesce(
+        (
+            select config
+            from search_documents
+            where namespace = contacts.namespace
+            limit 1
+        ), 'simple'
+    ),
+    new.description,
+    ''
+from contacts
+where contacts.id = new.contact_id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger debts_reindex
+after
+update on debts begin
+update search_documents
+set contact_id = new.contact_id,
+    title = new.description
+where entity_name = 'debt'
+    and entity_id = new.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger debts_unindex
+after delete on debts begin
+delete from search_documents
+where entity_name = 'debt'
+    and entity_id = old.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activities_index
+after
+insert on activities begin
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+select 'activity',
+    new.id,
+    new.contact_id,
+    contacts.namespace,
+    coalesce(
+        (
+            select config
+            from search_documents
+            where namespace = contacts.namespace
+            limit 1
+        ), 'simple'
+    ),
+    new.name,
+    new.description
+from contacts
+where contacts.id = new.contact_id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activities_reindex
+after
+update on activities begin
+update search_documents
+set contact_id = new.contact_id,
+    namespace = (
+        select namespace
+        from contacts
+        where id = new.contact_id
+    ),
+    title = new.name,
+    body = new.description
+where entity_name = 'activity'
+    and entity_id = new.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activities_unindex
+after delete on activities begin
+delete from search_documents
+where entity_name = 'activity'
+    and entity_id = old.id;
+end;
+-- +goose StatementEnd
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        title,
+        body
+    )
+select 'journalEntry',
+    id,
+    null,
+    namespace,
+    title,
+    body
+from journal_entries;
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        title,
+        body
+    )
+select 'contact',
+    id,
+    id,
+    namespace,
+    trim(first_name || ' ' || last_name || ' ' || nickname),
+    trim(email || ' ' || notes)
+from contacts;
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        title,
+        body
+    )
+select 'debt',
+    debts.id,
+    debts.contact_id,
+    contacts.namespace,
+    debts.description,
+    ''
+from debts
+    join contacts on debts.contact_id = contacts.id;
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        title,
+        body
+    )
+select 'activity',
+    activities.id,
+    activities.contact_id,
+    contacts.namespace,
+    activities.name,
+    activities.description
+from activities
+    join contacts on activities.contact_id = contacts.id;
+-- +goose Down
+drop trigger activities_unindex;
+drop trigger activities_reindex;
+drop trigger activities_index;
+drop trigger debts_unindex;
+drop trigger debts_reindex;
+drop trigger debts_index;
+drop trigger contacts_unindex;
+drop trigger contacts_reindex;
+drop trigger contacts_index;
+drop trigger journal_entries_unindex;
+drop trigger journal_entries_reindex;
+drop trigger journal_entries_index;
+drop table search_documents;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- +goose Up
+create index contacts_namespace_first_name_idx on contacts (namespace, first_name, id);
+create index contacts_namespace_last_name_idx on contacts (namespace, last_name, id);
+create index journal_entries_namespace_date_idx on journal_entries (namespace, date, id);
+create index debts_contact_id_idx on debts (contact_id);
+create index activities_contact_id_date_idx on activities (contact_id, date);
+-- +goose Down
+drop index activities_contact_id_date_idx;
+drop index debts_contact_id_idx;
+drop index journal_entries_namespace_date_idx;
+drop index contacts_namespace_last_name_idx;
+drop index contacts_namespace_first_name_idx;
\ No newline at end of file
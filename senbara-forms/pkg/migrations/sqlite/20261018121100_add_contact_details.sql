@@ -0,0 +1,259 @@
+-- +goose Up
+create table contact_emails (
+    id integer primary key autoincrement,
+    contact_id integer not null,
+    label text not null,
+    email text not null,
+    foreign key (contact_id) references contacts (id)
+);
+create table contact_phones (
+    id integer primary key autoincrement,
+    contact_id integer not null,
+    label text not null,
+    number text not null,
+    foreign key (contact_id) references contacts (id)
+);
+create table contact_addresses (
+    id integer primary key autoincrement,
+    contact_id integer not null,
+    label text not null,
+    address text not null,
+    foreign key (contact_id) references contacts (id)
+);
+create table contact_websites (
+    id integer primary key autoincrement,
+    contact_id integer not null,
+    label text not null,
+    url text not null,
+    foreign key (contact_id) references contacts (id)
+);
+create index contact_emails_contact_id_idx on contact_emails (contact_id);
+create index contact_emails_email_idx on contact_emails (lower(email));
+create index contact_phones_contact_id_idx on contact_phones (contact_id);
+create index contact_addresses_contact_id_idx on contact_addresses (contact_id);
+create index contact_websites_contact_id_idx on contact_websites (contact_id);
+insert into contact_emails (contact_id, label, email)
+select id,
+    'other',
+    email
+from contacts
+where email <> ''
+order by id;
+insert into contact_addresses (contact_id, label, address)
+select id,
+    'other',
+    address
+from contacts
+where address <> ''
+order by id;
+-- SQLite can't drop columns that triggers use, so the contact triggers are
+-- recreated to index the email addresses from `contact_emails` instead
+drop trigger contacts_index;
+drop trigger contacts_reindex;
+alter table contacts drop column email;
+alter table contacts drop column address;
+-- +goose StatementBegin
+create trigger contacts_index
+after
+insert on contacts begin
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+values (
+        'contact',
+        new.id,
+        new.id,
+        new.namespace,
+        coalesce(
+            (
+                select config
+                from search_documents
+                where namespace = new.namespace
+                limit 1
+            ), 'simple'
+        ),
+        trim(
+            new.first_name || ' ' || new.last_name || ' ' || new.nickname
+        ),
+        trim(
+            coalesce(
+                (
+                    select group_concat(email, ' ')
+                    from (
+                            select email
+                            from contact_emails
+                            where contact_id = new.id
+                            order by id
+                        )
+                ),
+                ''
+            ) || ' ' || new.notes
+        )
+    );
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger contacts_reindex
+after
+update on contacts begin
+update search_documents
+set namespace = new.namespace,
+    title = trim(
+        new.first_name || ' ' || new.last_name || ' ' || new.nickname
+    ),
+    body = trim(
+        coalesce(
+            (
+                select group_concat(email, ' ')
+                from (
+                        select email
+                        from contact_emails
+                        where contact_id = new.id
+                        order by id
+                    )
+            ),
+            ''
+        ) || ' ' || new.notes
+    )
+where entity_name = 'contact'
+    and entity_id = new.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger contact_emails_index
+after
+insert on contact_emails begin
+update search_documents
+set body = trim(
+        coalesce(
+            (
+                select group_concat(email, ' ')
+                from (
+                        select email
+                        from contact_emails
+                        where contact_id = new.contact_id
+                        order by id
+                    )
+            ),
+            ''
+        ) || ' ' || (
+            select notes
+            from contacts
+            where id = new.contact_id
+        )
+    )
+where entity_name = 'contact'
+    and entity_id = new.contact_id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger contact_emails_unindex
+after delete on contact_emails begin
+update search_documents
+set body = trim(
+        coalesce(
+            (
+                select group_concat(email, ' ')
+                from (
+                        select email
+                        from contact_emails
+                        where contact_id = old.contact_id
+                        order by id
+                    )
+            ),
+            ''
+        ) || ' ' || (
+            select notes
+            from contacts
+            where id = old.contact_id
+        )
+    )
+where entity_name = 'contact'
+    and entity_id = old.contact_id;
+end;
+-- +goose StatementEnd
+-- +goose Down
+drop trigger contact_emails_unindex;
+drop trigger contact_emails_index;
+drop trigger contacts_reindex;
+drop trigger contacts_index;
+alter table contacts
+add column email text not null default '';
+alter table contacts
+add column address text not null default '';
+update contacts
+set email = coalesce(
+        (
+            select email
+            from contact_emails
+            where contact_id = contacts.id
+            order by id
+            limit 1
+        ), ''
+    ),
+    address = coalesce(
+        (
+            select address
+            from contact_addresses
+            where contact_id = contacts.id
+            order by id
+            limit 1
+        ), ''
+    );
+-- +goose StatementBegin
+create trigger contacts_index
+after
+insert on contacts begin
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+values (
+        'contact',
+        new.id,
+        new.id,
+        new.namespace,
+        coalesce(
+            (
+                select config
+                from search_documents
+                where namespace = new.namespace
+                limit 1
+            ), 'simple'
+        ),
+        trim(
+            new.first_name || ' ' || new.last_name || ' ' || new.nickname
+        ),
+        trim(new.email || ' ' || new.notes)
+    );
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger contacts_reindex
+after
+update on contacts begin
+update search_documents
+set namespace = new.namespace,
+    title = trim(
+        new.first_name || ' ' || new.last_name || ' ' || new.nickname
+    ),
+    body = trim(new.email || ' ' || new.notes)
+where entity_name = 'contact'
+    and entity_id = new.id;
+end;
+-- +goose StatementEnd
+drop table contact_websites;
+drop table contact_addresses;
+drop table contact_phones;
+drop table contact_emails;
\ No newline at end of file
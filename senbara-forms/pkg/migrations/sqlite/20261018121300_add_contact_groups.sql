@@ -0,0 +1,18 @@
+-- +goose Up
+create table contact_groups (
+    id integer primary key autoincrement,
+    namespace text not null,
+    name text not null
+);
+create table contact_group_members (
+    group_id integer not null,
+    contact_id integer not null,
+    primary key (group_id, contact_id),
+    foreign key (group_id) references contact_groups (id),
+    foreign key (contact_id) references contacts (id)
+);
+create index contact_groups_namespace_idx on contact_groups (namespace);
+create index contact_group_members_contact_id_idx on contact_group_members (contact_id);
+-- +goose Down
+drop table contact_group_members;
+drop table contact_groups;
\ No newline at end of file
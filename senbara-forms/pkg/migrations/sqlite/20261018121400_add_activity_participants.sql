@@ -0,0 +1,234 @@
+-- +goose Up
+-- Columns with foreign keys can't be dropped, so the activities are copied into
+-- a new table which is scoped to the namespace of the contact they were created for
+create table activities_with_namespace (
+    id integer primary key autoincrement,
+    name text not null,
+    date timestamp not null default current_timestamp,
+    description text not null,
+    version integer default 1 not null,
+    namespace text not null
+);
+insert into activities_with_namespace (
+        id,
+        name,
+        date,
+        description,
+        version,
+        namespace
+    )
+select activities.id,
+    activities.name,
+    activities.date,
+    activities.description,
+    activities.version,
+    contacts.namespace
+from activities
+    join contacts on contacts.id = activities.contact_id;
+create table activity_participants (
+    activity_id integer not null,
+    contact_id integer not null,
+    primary key (activity_id, contact_id),
+    foreign key (activity_id) references activities_with_namespace (id),
+    foreign key (contact_id) references contacts (id)
+);
+insert into activity_participants (activity_id, contact_id)
+select id,
+    contact_id
+from activities;
+drop table activities;
+alter table activities_with_namespace
+    rename to activities;
+create index activities_namespace_date_idx on activities (namespace, date);
+create index activity_participants_contact_id_idx on activity_participants (contact_id);
+-- Search results for an activity link to its first participant
+-- +goose StatementBegin
+create trigger activities_index
+after
+insert on activities begin
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+values (
+        'activity',
+        new.id,
+        (
+            select min(contact_id)
+            from activity_participants
+            where activity_id = new.id
+        ),
+        new.namespace,
+        coalesce(
+            (
+                select config
+                from search_documents
+                where namespace = new.namespace
+                limit 1
+            ), 'simple'
+        ),
+        new.name,
+        new.description
+    );
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activities_reindex
+after
+update on activities begin
+update search_documents
+set contact_id = (
+        select min(contact_id)
+        from activity_participants
+        where activity_id = new.id
+    ),
+    namespace = new.namespace,
+    title = new.name,
+    body = new.description
+where entity_name = 'activity'
+    and entity_id = new.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activities_unindex
+after delete on activities begin
+delete from search_documents
+where entity_name = 'activity'
+    and entity_id = old.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activity_participants_index
+after
+insert on activity_participants begin
+update search_documents
+set contact_id = (
+        select min(contact_id)
+        from activity_participants
+        where activity_id = new.activity_id
+    )
+where entity_name = 'activity'
+    and entity_id = new.activity_id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activity_participants_unindex
+after delete on activity_participants begin
+update search_documents
+set contact_id = (
+        select min(contact_id)
+        from activity_participants
+        where activity_id = old.activity_id
+    )
+where entity_name = 'activity'
+    and entity_id = old.activity_id;
+end;
+-- +goose StatementEnd
+-- +goose Down
+-- Activities can only belong to a single contact again, so shared activities
+-- are kept for their first participant
+create table activities_with_contact (
+    id integer primary key autoincrement,
+    name text not null,
+    date timestamp not null default current_timestamp,
+    contact_id integer not null,
+    description text not null,
+    version integer default 1 not null,
+    foreign key (contact_id) references contacts (id)
+);
+insert into activities_with_contact (
+        id,
+        name,
+        date,
+        contact_id,
+        description,
+        version
+    )
+select activities.id,
+    activities.name,
+    activities.date,
+    (
+        select min(contact_id)
+        from activity_participants
+        where activity_id = activities.id
+    ),
+    activities.description,
+    activities.version
+from activities
+where exists (
+        select 1
+        from activity_participants
+        where activity_id = activities.id
+    );
+drop table activity_participants;
+drop table activities;
+alter table activities_with_contact
+    rename to activities;
+create index activities_contact_id_date_idx on activities (contact_id, date);
+delete from search_documents
+where entity_name = 'activity'
+    and entity_id not in (
+        select id
+        from activities
+    );
+-- +goose StatementBegin
+create trigger activities_index
+after
+insert on activities begin
+insert into search_documents (
+        entity_name,
+        entity_id,
+        contact_id,
+        namespace,
+        config,
+        title,
+        body
+    )
+select 'activity',
+    new.id,
+    new.contact_id,
+    contacts.namespace,
+    coalesce(
+        (
+            select config
+            from search_documents
+            where namespace = contacts.namespace
+            limit 1
+        ), 'simple'
+    ),
+    new.name,
+    new.description
+from contacts
+where contacts.id = new.contact_id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activities_reindex
+after
+update on activities begin
+update search_documents
+set contact_id = new.contact_id,
+    namespace = (
+        select namespace
+        from contacts
+        where id = new.contact_id
+    ),
+    title = new.name,
+    body = new.description
+where entity_name = 'activity'
+    and entity_id = new.id;
+end;
+-- +goose StatementEnd
+-- +goose StatementBegin
+create trigger activities_unindex
+after delete on activities begin
+delete from search_documents
+where entity_name = 'activity'
+    and entity_id = old.id;
+end;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,37 @@
+-- +goose Up
+alter table debts
+add column date timestamp not null default '1970-01-01';
+update debts
+set date = date('now');
+create table debt_payments (
+    id integer primary key autoincrement,
+    debt_id integer not null,
+    amount real not null,
+    date timestamp not null,
+    description text default '' not null,
+    foreign key (debt_id) references debts (id)
+);
+create index debt_payments_debt_id_idx on debt_payments (debt_id);
+-- +goose Down
+update debts
+set amount = (
+        case
+            when amount < 0 then -1
+            else 1
+        end
+    ) * (
+        abs(amount) - (
+            select sum(debt_payments.amount)
+            from debt_payments
+            where debt_payments.debt_id = debts.id
+        )
+    )
+where exists (
+        select 1
+        from debt_payments
+        where debt_payments.debt_id = debts.id
+    );
+drop table debt_payments;
+delete from debts
+where abs(amount) < 0.005;
+alter table debts drop column date;
\ No newline at end of file
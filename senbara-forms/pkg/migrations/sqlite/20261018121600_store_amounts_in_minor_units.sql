@@ -0,0 +1,145 @@
+-- +goose Up
+-- Amounts are stored as integers of the minor unit of their ISO 4217 currency,
+-- such as cents, so that sums are exact. Currencies without two digits after the
+-- decimal separator are listed here, which includes all of those of `pkg/money`.
+create temporary table currency_minor_units (
+    currency text primary key,
+    factor integer not null
+);
+insert into currency_minor_units (currency, factor)
+values ('BIF', 1),
+    ('CLP', 1),
+    ('DJF', 1),
+    ('GNF', 1),
+    ('ISK', 1),
+    ('JPY', 1),
+    ('KMF', 1),
+    ('KRW', 1),
+    ('PYG', 1),
+    ('RWF', 1),
+    ('UGX', 1),
+    ('UYI', 1),
+    ('VND', 1),
+    ('VUV', 1),
+    ('XAF', 1),
+    ('XOF', 1),
+    ('XPF', 1),
+    ('BHD', 1000),
+    ('IQD', 1000),
+    ('JOD', 1000),
+    ('KWD', 1000),
+    ('LYD', 1000),
+    ('OMR', 1000),
+    ('TND', 1000),
+    ('CLF', 10000),
+    ('UYW', 10000);
+-- Columns can't change their type, so the amounts are converted into new
+-- columns which replace the old ones. Rounding to six digits first drops the
+-- error of the multiplication, so amounts such as `1.005` become `101` cents like
+-- they do with Postgres.
+alter table debt_payments
+add column amount_minor integer not null default 0;
+update debt_payments
+set amount_minor = cast(
+        round(
+            round(
+                debt_payments.amount * coalesce(
+                    (
+                        select currency_minor_units.factor
+                        from debts
+                            join currency_minor_units on currency_minor_units.currency = upper(trim(debts.currency))
+                        where debts.id = debt_payments.debt_id
+                    ),
+                    100
+                ),
+                6
+            )
+        ) as integer
+    );
+alter table debt_payments drop column amount;
+alter table debt_payments
+    rename column amount_minor to amount;
+alter table debts
+add column amount_minor integer not null default 0;
+update debts
+set amount_minor = cast(
+        round(
+            round(
+                debts.amount * coalesce(
+                    (
+                        select currency_minor_units.factor
+                        from currency_minor_units
+                        where currency_minor_units.currency = upper(trim(debts.currency))
+                    ),
+                    100
+                ),
+                6
+            )
+        ) as integer
+    );
+alter table debts drop column amount;
+alter table debts
+    rename column amount_minor to amount;
+drop table currency_minor_units;
+-- +goose Down
+create temporary table currency_minor_units (
+    currency text primary key,
+    factor integer not null
+);
+insert into currency_minor_units (currency, factor)
+values ('BIF', 1),
+    ('CLP', 1),
+    ('DJF', 1),
+    ('GNF', 1),
+    ('ISK', 1),
+    ('JPY', 1),
+    ('KMF', 1),
+    ('KRW', 1),
+    ('PYG', 1),
+    ('RWF', 1),
+    ('UGX', 1),
+    ('UYI', 1),
+    ('VND', 1),
+    ('VUV', 1),
+    ('XAF', 1),
+    ('XOF', 1),
+    ('XPF', 1),
+    ('BHD', 1000),
+    ('IQD', 1000),
+    ('JOD', 1000),
+    ('KWD', 1000),
+    ('LYD', 1000),
+    ('OMR', 1000),
+    ('TND', 1000),
+    ('CLF', 10000),
+    ('UYW', 10000);
+alter table debt_payments
+add column amount_float real not null default 0;
+update debt_payments
+set amount_float = debt_payments.amount * 1.0 / coalesce(
+        (
+                select currency_minor_units.factor
+                from debts
+                    join currency_minor_units on currency_minor_units.currency = upper(trim(debts.currency))
+                where debts.id = debt_payments.debt_id
+            ),
+        100
+    );
+alter table debt_payments drop column amount;
+alter table debt_payments
+    rename column amount_float to amount;
+alter table debts
+add column amount_float real not null default 0;
+update debts
+set amount_float = debts.amount * 1.0 / coalesce(
+        (
+                select currency_minor_units.factor
+                from currency_minor_units
+                where currency_minor_units.currency = upper(trim(debts.currency))
+            ),
+        100
+    );
+alter table debts drop column amount;
+alter table debts
+    rename column amount_float to amount;
+drop table currency_minor_units;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- +goose Up
+create table staged_user_data (
+    hash text primary key,
+    data blob not null,
+    expires_at timestamp not null,
+    namespace text not null
+);
+create index staged_user_data_namespace_idx on staged_user_data (namespace);
+-- +goose Down
+drop table staged_user_data;
\ No newline at end of file
@@ -3,14 +3,23 @@ package models
 import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
 
 type (
-	CreateActivityParams        = tables.CreateActivityParams
-	GetActivitiesParams         = tables.GetActivitiesParams
-	DeleteActivityParams        = tables.DeleteActivityParams
-	GetActivityAndContactParams = tables.GetActivityAndContactParams
-	UpdateActivityParams        = tables.UpdateActivityParams
+	CreateActivityParams            = tables.CreateActivityParams
+	CreateActivityParticipantParams = tables.CreateActivityParticipantParams
+	GetActivitiesParams             = tables.GetActivitiesParams
+	DeleteActivityParams            = tables.DeleteActivityParams
+	GetActivityAndContactParams     = tables.GetActivityAndContactParams
+	GetActivityParticipantsParams   = tables.GetActivityParticipantsParams
+	UpdateActivityParams            = tables.UpdateActivityParams
+	LockActivityVersionParams       = tables.LockActivityVersionParams
+	GetActivityCalendarParams       = tables.GetActivityCalendarParams
+
+	DeleteActivityParticipantsParams           = tables.DeleteActivityParticipantsParams
+	DeleteActivityParticipantsForContactParams = tables.DeleteActivityParticipantsForContactParams
 )
 
 type (
-	GetActivitiesRow         = tables.GetActivitiesRow
-	GetActivityAndContactRow = tables.GetActivityAndContactRow
+	GetActivitiesRow           = tables.GetActivitiesRow
+	GetActivityAndContactRow   = tables.GetActivityAndContactRow
+	GetActivityParticipantsRow = tables.GetActivityParticipantsRow
+	GetActivityCalendarRow     = tables.GetActivityCalendarRow
 )
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	APIError = struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	}
+)
+
+type (
+	APIJournalEntry = struct {
+		ID     int32     `json:"id"`
+		Title  string    `json:"title"`
+		Date   time.Time `json:"date"`
+		Body   string    `json:"body"`
+		Rating int32     `json:"rating"`
+	}
+
+	APIContact = struct {
+		ID        int32   `json:"id"`
+		FirstName string  `json:"firstName"`
+		LastName  string  `json:"lastName"`
+		Nickname  string  `json:"nickname"`
+		Pronouns  string  `json:"pronouns"`
+		Birthday  *string `json:"birthday"`
+		Notes     string  `json:"notes"`
+
+		Emails    []APIContactEmail   `json:"emails"`
+		Phones    []APIContactPhone   `json:"phones"`
+		Addresses []APIContactAddress `json:"addresses"`
+		Websites  []APIContactWebsite `json:"websites"`
+	}
+
+	APIContactEmail = struct {
+		Label string `json:"label"`
+		Email string `json:"email"`
+	}
+
+	APIContactPhone = struct {
+		Label  string `json:"label"`
+		Number string `json:"number"`
+	}
+
+	APIContactAddress = struct {
+		Label   string `json:"label"`
+		Address string `json:"address"`
+	}
+
+	APIContactWebsite = struct {
+		Label string `json:"label"`
+		URL   string `json:"url"`
+	}
+
+	APIDebt = struct {
+		ID          int32            `json:"id"`
+		ContactID   int32            `json:"contactId"`
+		Amount      json.Number      `json:"amount"`
+		Currency    string           `json:"currency"`
+		Description string           `json:"description"`
+		Date        string           `json:"date"`
+		Paid        json.Number      `json:"paid"`
+		Settled     bool             `json:"settled"`
+		Payments    []APIDebtPayment `json:"payments"`
+	}
+
+	APIDebtPayment = struct {
+		ID          int32       `json:"id"`
+		Amount      json.Number `json:"amount"`
+		Date        string      `json:"date"`
+		Description string      `json:"description"`
+	}
+
+	APIActivity = struct {
+		ID          int32   `json:"id"`
+		ContactID   int32   `json:"contactId"`
+		ContactIDs  []int32 `json:"contactIds"`
+		Name        string  `json:"name"`
+		Date        string  `json:"date"`
+		Description string  `json:"description"`
+	}
+
+	APISearchResult = struct {
+		EntityName string  `json:"entityName"`
+		ID         int32   `json:"id"`
+		ContactID  *int32  `json:"contactId"`
+		Rank       float32 `json:"rank"`
+		Title      string  `json:"title"`
+		Snippet    string  `json:"snippet"`
+	}
+)
+
+type (
+	APIUserDataImport = struct {
+		Token string `json:"token"`
+
+		Manifest ExportedManifest `json:"manifest"`
+
+		JournalEntries int `json:"journalEntries"`
+		Contacts       int `json:"contacts"`
+		Debts          int `json:"debts"`
+		Activities     int `json:"activities"`
+		Relationships  int `json:"relationships"`
+		Groups         int `json:"groups"`
+
+		Skipped    []APISkippedUserDataRecord `json:"skipped"`
+		Duplicates []APIDuplicateContact      `json:"duplicates"`
+	}
+
+	APISkippedUserDataRecord = struct {
+		Line       int    `json:"line"`
+		EntityName string `json:"entityName"`
+		Reason     string `json:"reason"`
+	}
+
+	APIDuplicateContact = struct {
+		Line       int    `json:"line"`
+		FirstName  string `json:"firstName"`
+		LastName   string `json:"lastName"`
+		Email      string `json:"email"`
+		ExistingID int32  `json:"existingId"`
+	}
+
+	APIUserDataImportToken = struct {
+		Token string `json:"token"`
+	}
+)
@@ -0,0 +1,12 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	CreateAppPasswordParams = tables.CreateAppPasswordParams
+	DeleteAppPasswordParams = tables.DeleteAppPasswordParams
+)
+
+type (
+	GetAppPasswordsRow = tables.GetAppPasswordsRow
+)
@@ -0,0 +1,12 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	UpsertCalendarFeedParams = tables.UpsertCalendarFeedParams
+)
+
+type (
+	GetCalendarFeedRow                   = tables.GetCalendarFeedRow
+	GetActivitiesCalendarForNamespaceRow = tables.GetActivitiesCalendarForNamespaceRow
+)
@@ -3,13 +3,45 @@ package models
 import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
 
 type (
-	CreateContactParams         = tables.CreateContactParams
-	GetContactParams            = tables.GetContactParams
-	DeleteContactParams         = tables.DeleteContactParams
-	DeleteDebtsForContactParams = tables.DeleteDebtsForContactParams
-	UpdateContactParams         = tables.UpdateContactParams
+	CreateContactParams                = tables.CreateContactParams
+	GetContactParams                   = tables.GetContactParams
+	DeleteContactParams                = tables.DeleteContactParams
+	DeleteDebtsForContactParams        = tables.DeleteDebtsForContactParams
+	DeleteDebtPaymentsForContactParams = tables.DeleteDebtPaymentsForContactParams
+	UpdateContactParams                = tables.UpdateContactParams
+	LockContactVersionParams           = tables.LockContactVersionParams
+	GetContactByEmailParams            = tables.GetContactByEmailParams
+	ListContactsParams                 = tables.ListContactsParams
+
+	GetContactEmailsParams       = tables.GetContactEmailsParams
+	CreateContactEmailParams     = tables.CreateContactEmailParams
+	DeleteContactEmailsParams    = tables.DeleteContactEmailsParams
+	GetContactPhonesParams       = tables.GetContactPhonesParams
+	CreateContactPhoneParams     = tables.CreateContactPhoneParams
+	DeleteContactPhonesParams    = tables.DeleteContactPhonesParams
+	GetContactAddressesParams    = tables.GetContactAddressesParams
+	CreateContactAddressParams   = tables.CreateContactAddressParams
+	DeleteContactAddressesParams = tables.DeleteContactAddressesParams
+	GetContactWebsitesParams     = tables.GetContactWebsitesParams
+	CreateContactWebsiteParams   = tables.CreateContactWebsiteParams
+	DeleteContactWebsitesParams  = tables.DeleteContactWebsitesParams
 )
 
 type (
-	Contact = tables.Contact
+	Contact         = tables.Contact
+	ListContactsRow = tables.ListContactsRow
+
+	ContactEmail   = tables.ContactEmail
+	ContactPhone   = tables.ContactPhone
+	ContactAddress = tables.ContactAddress
+	ContactWebsite = tables.ContactWebsite
+
+	// ContactDetails are the labelled email addresses, phone numbers, postal
+	// addresses and websites of a contact, in the order they were added in
+	ContactDetails = struct {
+		Emails    []ContactEmail
+		Phones    []ContactPhone
+		Addresses []ContactAddress
+		Websites  []ContactWebsite
+	}
 )
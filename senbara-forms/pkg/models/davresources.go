@@ -0,0 +1,15 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	GetDAVResourcesParams    = tables.GetDAVResourcesParams
+	GetDAVResourceParams     = tables.GetDAVResourceParams
+	UpsertDAVResourceParams  = tables.UpsertDAVResourceParams
+	DeleteDAVResourcesParams = tables.DeleteDAVResourcesParams
+)
+
+type (
+	GetDAVResourcesRow = tables.GetDAVResourcesRow
+	GetDAVResourceRow  = tables.GetDAVResourceRow
+)
@@ -5,7 +5,8 @@ import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
 type (
 	CreateDebtParams        = tables.CreateDebtParams
 	GetDebtsParams          = tables.GetDebtsParams
-	SettleDebtParams        = tables.SettleDebtParams
+	CreateDebtPaymentParams = tables.CreateDebtPaymentParams
+	GetDebtPaymentsParams   = tables.GetDebtPaymentsParams
 	GetDebtAndContactParams = tables.GetDebtAndContactParams
 	UpdateDebtParams        = tables.UpdateDebtParams
 )
@@ -13,4 +14,5 @@ type (
 type (
 	GetDebtsRow          = tables.GetDebtsRow
 	GetDebtAndContactRow = tables.GetDebtAndContactRow
+	DebtPayment          = tables.DebtPayment
 )
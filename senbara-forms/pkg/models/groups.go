@@ -0,0 +1,23 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	CreateContactGroupParams                  = tables.CreateContactGroupParams
+	GetContactGroupParams                     = tables.GetContactGroupParams
+	UpdateContactGroupParams                  = tables.UpdateContactGroupParams
+	DeleteContactGroupParams                  = tables.DeleteContactGroupParams
+	GetContactGroupMembersParams              = tables.GetContactGroupMembersParams
+	CreateContactGroupMemberParams            = tables.CreateContactGroupMemberParams
+	DeleteContactGroupMemberParams            = tables.DeleteContactGroupMemberParams
+	DeleteContactGroupMembersParams           = tables.DeleteContactGroupMembersParams
+	DeleteContactGroupMembersForContactParams = tables.DeleteContactGroupMembersForContactParams
+)
+
+type (
+	ContactGroup                                = tables.ContactGroup
+	ContactGroupMember                          = tables.ContactGroupMember
+	GetContactGroupsRow                         = tables.GetContactGroupsRow
+	GetContactGroupMembersRow                   = tables.GetContactGroupMembersRow
+	GetContactGroupMembersExportForNamespaceRow = tables.GetContactGroupMembersExportForNamespaceRow
+)
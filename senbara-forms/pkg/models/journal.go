@@ -7,8 +7,10 @@ type (
 	DeleteJournalEntryParams = tables.DeleteJournalEntryParams
 	GetJournalEntryParams    = tables.GetJournalEntryParams
 	UpdateJournalEntryParams = tables.UpdateJournalEntryParams
+	ListJournalEntriesParams = tables.ListJournalEntriesParams
 )
 
 type (
-	JournalEntry = tables.JournalEntry
+	JournalEntry          = tables.JournalEntry
+	ListJournalEntriesRow = tables.ListJournalEntriesRow
 )
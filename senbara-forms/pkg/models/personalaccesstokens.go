@@ -0,0 +1,13 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	CreatePersonalAccessTokenParams = tables.CreatePersonalAccessTokenParams
+	DeletePersonalAccessTokenParams = tables.DeletePersonalAccessTokenParams
+)
+
+type (
+	GetPersonalAccessTokensRow = tables.GetPersonalAccessTokensRow
+	GetPersonalAccessTokenRow  = tables.GetPersonalAccessTokenRow
+)
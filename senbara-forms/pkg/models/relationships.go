@@ -0,0 +1,15 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	CreateContactRelationshipParams            = tables.CreateContactRelationshipParams
+	GetContactRelationshipsParams              = tables.GetContactRelationshipsParams
+	DeleteContactRelationshipParams            = tables.DeleteContactRelationshipParams
+	DeleteContactRelationshipsForContactParams = tables.DeleteContactRelationshipsForContactParams
+)
+
+type (
+	ContactRelationship        = tables.ContactRelationship
+	GetContactRelationshipsRow = tables.GetContactRelationshipsRow
+)
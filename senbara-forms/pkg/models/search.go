@@ -0,0 +1,12 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	SearchDocumentsParams             = tables.SearchDocumentsParams
+	UpdateSearchDocumentsConfigParams = tables.UpdateSearchDocumentsConfigParams
+)
+
+type (
+	SearchDocumentsRow = tables.SearchDocumentsRow
+)
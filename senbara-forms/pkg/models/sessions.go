@@ -0,0 +1,15 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	CreateSessionParams         = tables.CreateSessionParams
+	DeleteSessionParams         = tables.DeleteSessionParams
+	UpdateSessionTokensParams   = tables.UpdateSessionTokensParams
+	UpdateSessionLastSeenParams = tables.UpdateSessionLastSeenParams
+)
+
+type (
+	GetSessionsRow = tables.GetSessionsRow
+	GetSessionRow  = tables.GetSessionRow
+)
@@ -0,0 +1,14 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	CreateStagedUserDataParams = tables.CreateStagedUserDataParams
+	GetStagedUserDataParams    = tables.GetStagedUserDataParams
+	DeleteStagedUserDataParams = tables.DeleteStagedUserDataParams
+	ClaimStagedUserDataParams  = tables.ClaimStagedUserDataParams
+)
+
+type (
+	GetStagedUserDataRow = tables.GetStagedUserDataRow
+)
@@ -0,0 +1,17 @@
+package models
+
+import "github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+
+type (
+	UpsertTagParams              = tables.UpsertTagParams
+	GetContactTagsParams         = tables.GetContactTagsParams
+	CreateContactTagParams       = tables.CreateContactTagParams
+	DeleteContactTagsParams      = tables.DeleteContactTagsParams
+	GetJournalEntryTagsParams    = tables.GetJournalEntryTagsParams
+	CreateJournalEntryTagParams  = tables.CreateJournalEntryTagParams
+	DeleteJournalEntryTagsParams = tables.DeleteJournalEntryTagsParams
+)
+
+type (
+	Tag = tables.Tag
+)
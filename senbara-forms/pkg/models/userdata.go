@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -11,6 +12,26 @@ type (
 	}
 )
 
+type (
+	ExportedCounts = struct {
+		JournalEntries int `json:"journalEntries"`
+		Contacts       int `json:"contacts"`
+		Debts          int `json:"debts"`
+		Activities     int `json:"activities"`
+		Relationships  int `json:"relationships"`
+		Groups         int `json:"groups"`
+	}
+
+	ExportedManifest = struct {
+		ExportedEntityIdentifier
+
+		Version    int            `json:"version"`
+		ExportedAt time.Time      `json:"exportedAt"`
+		Source     string         `json:"source"`
+		Counts     ExportedCounts `json:"counts"`
+	}
+)
+
 type (
 	ExportedJournalEntry = struct {
 		ExportedEntityIdentifier
@@ -21,6 +42,7 @@ type (
 		Body      string    `json:"body"`
 		Rating    int32     `json:"rating"`
 		Namespace string    `json:"namespace"`
+		Tags      []string  `json:"tags"`
 	}
 
 	ExportedContact = struct {
@@ -30,22 +52,54 @@ type (
 		FirstName string       `json:"firstName"`
 		LastName  string       `json:"lastName"`
 		Nickname  string       `json:"nickname"`
-		Email     string       `json:"email"`
 		Pronouns  string       `json:"pronouns"`
 		Namespace string       `json:"namespace"`
 		Birthday  sql.NullTime `json:"birthday"`
-		Address   string       `json:"address"`
 		Notes     string       `json:"notes"`
+		Tags      []string     `json:"tags"`
+
+		Emails    []ExportedContactEmail   `json:"emails"`
+		Phones    []ExportedContactPhone   `json:"phones"`
+		Addresses []ExportedContactAddress `json:"addresses"`
+		Websites  []ExportedContactWebsite `json:"websites"`
+	}
+
+	ExportedContactEmail = struct {
+		Label string `json:"label"`
+		Email string `json:"email"`
+	}
+
+	ExportedContactPhone = struct {
+		Label  string `json:"label"`
+		Number string `json:"number"`
+	}
+
+	ExportedContactAddress = struct {
+		Label   string `json:"label"`
+		Address string `json:"address"`
+	}
+
+	ExportedContactWebsite = struct {
+		Label string `json:"label"`
+		URL   string `json:"url"`
 	}
 
 	ExportedDebt = struct {
 		ExportedEntityIdentifier
 
-		ID          int32         `json:"id"`
-		Amount      float64       `json:"amount"`
-		Currency    string        `json:"currency"`
-		Description string        `json:"description"`
-		ContactID   sql.NullInt32 `json:"contactId"`
+		ID          int32                 `json:"id"`
+		Amount      json.Number           `json:"amount"`
+		Currency    string                `json:"currency"`
+		Description string                `json:"description"`
+		Date        time.Time             `json:"date"`
+		ContactID   sql.NullInt32         `json:"contactId"`
+		Payments    []ExportedDebtPayment `json:"payments"`
+	}
+
+	ExportedDebtPayment = struct {
+		Amount      json.Number `json:"amount"`
+		Date        time.Time   `json:"date"`
+		Description string      `json:"description"`
 	}
 
 	ExportedActivity = struct {
@@ -56,5 +110,25 @@ type (
 		Date        time.Time     `json:"date"`
 		Description string        `json:"description"`
 		ContactID   sql.NullInt32 `json:"contactId"`
+		ContactIDs  []int32       `json:"contactIds"`
+	}
+
+	ExportedRelationship = struct {
+		ExportedEntityIdentifier
+
+		ID               int32         `json:"id"`
+		ContactID        sql.NullInt32 `json:"contactId"`
+		RelatedContactID sql.NullInt32 `json:"relatedContactId"`
+		Type             string        `json:"type"`
+		Label            string        `json:"label"`
+		Reciprocal       bool          `json:"reciprocal"`
+	}
+
+	ExportedGroup = struct {
+		ExportedEntityIdentifier
+
+		ID         int32   `json:"id"`
+		Name       string  `json:"name"`
+		ContactIDs []int32 `json:"contactIds"`
 	}
 )
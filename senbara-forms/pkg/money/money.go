@@ -0,0 +1,201 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+var (
+	ErrInvalidCurrency = errors.New("invalid ISO 4217 currency code")
+	ErrInvalidAmount   = errors.New("invalid amount")
+)
+
+// minorUnits maps the active ISO 4217 currency codes to the number of digits
+// after the decimal separator of their minor unit. Amounts are stored as
+// integers of these minor units, so that sums are exact.
+var minorUnits = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2,
+	"AUD": 2, "AWG": 2, "AZN": 2, "BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2,
+	"BHD": 3, "BIF": 0, "BMD": 2, "BND": 2, "BOB": 2, "BOV": 2, "BRL": 2,
+	"BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2,
+	"CHE": 2, "CHF": 2, "CHW": 2, "CLF": 4, "CLP": 0, "CNY": 2, "COP": 2,
+	"COU": 2, "CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2, "DJF": 0, "DKK": 2,
+	"DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2,
+	"FKP": 2, "GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GNF": 0,
+	"GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2, "HTG": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "IQD": 3, "IRR": 2, "ISK": 0, "JMD": 2, "JOD": 3,
+	"JPY": 0, "KES": 2, "KGS": 2, "KHR": 2, "KMF": 0, "KPW": 2, "KRW": 0,
+	"KWD": 3, "KYD": 2, "KZT": 2, "LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2,
+	"LSL": 2, "LYD": 3, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2, "MMK": 2,
+	"MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2,
+	"MXV": 2, "MYR": 2, "MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2,
+	"NPR": 2, "NZD": 2, "OMR": 3, "PAB": 2, "PEN": 2, "PGK": 2, "PHP": 2,
+	"PKR": 2, "PLN": 2, "PYG": 0, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2,
+	"RWF": 0, "SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2,
+	"SHP": 2, "SLE": 2, "SOS": 2, "SRD": 2, "SSP": 2, "STN": 2, "SVC": 2,
+	"SYP": 2, "SZL": 2, "THB": 2, "TJS": 2, "TMT": 2, "TND": 3, "TOP": 2,
+	"TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2, "UGX": 0, "USD": 2,
+	"USN": 2, "UYI": 0, "UYU": 2, "UYW": 4, "UZS": 2, "VED": 2, "VES": 2,
+	"VND": 0, "VUV": 0, "WST": 2, "XAF": 0, "XCD": 2, "XCG": 2, "XOF": 0,
+	"XPF": 0, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWG": 2,
+}
+
+// defaultMinorUnits are the minor units of currencies which aren't ISO 4217
+// codes, which earlier versions accepted
+const defaultMinorUnits = 2
+
+// ParseCurrency validates an ISO 4217 currency code and returns it in upper
+// case
+func ParseCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := minorUnits[code]; !ok {
+		return "", ErrInvalidCurrency
+	}
+
+	return code, nil
+}
+
+// Scale returns the number of digits after the decimal separator of a
+// currency's minor unit
+func Scale(currency string) int {
+	scale, ok := minorUnits[strings.ToUpper(strings.TrimSpace(currency))]
+	if !ok {
+		return defaultMinorUnits
+	}
+
+	return scale
+}
+
+// ParseAmount parses a decimal amount such as `-12.50` into minor units of the
+// currency. Amounts with more digits after the decimal separator than the
+// currency has are rejected instead of being rounded.
+func ParseAmount(amount, currency string) (int64, error) {
+	amount = strings.TrimSpace(amount)
+
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(strings.TrimPrefix(amount, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	scale := Scale(currency)
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > scale {
+		return 0, ErrInvalidAmount
+	}
+
+	digits := whole + fraction + strings.Repeat("0", scale-len(fraction))
+	for _, digit := range digits {
+		if digit < '0' || digit > '9' {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+
+	if negative {
+		return -minor, nil
+	}
+
+	return minor, nil
+}
+
+// FromFloat converts an amount from the floats earlier versions stored into
+// minor units of the currency. The amount is rounded half away from zero in
+// its shortest decimal representation, so `1.005` becomes `101` cents.
+func FromFloat(amount float64, currency string) (int64, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, ErrInvalidAmount
+	}
+
+	exact, ok := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	if !ok {
+		return 0, ErrInvalidAmount
+	}
+
+	exact.Mul(exact, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Scale(currency))), nil)))
+
+	// Round half away from zero by adding or subtracting half a minor unit
+	// before truncating towards zero
+	half := big.NewRat(1, 2)
+	if exact.Sign() < 0 {
+		half.Neg(half)
+	}
+	exact.Add(exact, half)
+
+	minor := new(big.Int).Quo(exact.Num(), exact.Denom())
+	if !minor.IsInt64() {
+		return 0, ErrInvalidAmount
+	}
+
+	return minor.Int64(), nil
+}
+
+// FormatAmount formats minor units of a currency as a decimal such as
+// `-12.50`, which `ParseAmount` parses again
+func FormatAmount(amount int64, currency string) string {
+	scale := Scale(currency)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(absMinor(amount), 10)
+	if scale == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// FormatLocalized formats minor units of a currency with the digit grouping
+// and decimal separator of a language, followed by the currency code, such as
+// `1.234,50 EUR` for German
+func FormatLocalized(tag language.Tag, amount int64, currency string) string {
+	scale := Scale(currency)
+
+	// Floats represent all amounts up to 2^53 minor units exactly enough to
+	// round them back to `scale` digits when formatting
+	return message.NewPrinter(tag).Sprint(
+		number.Decimal(
+			float64(amount)/math.Pow10(scale),
+			number.Scale(scale),
+		),
+	) + " " + currency
+}
+
+// Abs returns the absolute value of an amount in minor units
+func Abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+
+	return amount
+}
+
+// absMinor returns the absolute value of an amount, which can't overflow for
+// the smallest `int64`
+func absMinor(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1
+	}
+
+	return uint64(amount)
+}
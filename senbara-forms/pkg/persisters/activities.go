@@ -2,43 +2,103 @@ package persisters
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/tables"
 )
 
-func (p *Persister) CreateActivity(
+// uniqueContactIDs removes duplicates from a list of contact IDs while keeping
+// their order
+func uniqueContactIDs(contactIDs []int32) []int32 {
+	seen := map[int32]struct{}{}
+
+	unique := []int32{}
+	for _, contactID := range contactIDs {
+		if _, ok := seen[contactID]; ok {
+			continue
+		}
+		seen[contactID] = struct{}{}
+
+		unique = append(unique, contactID)
+	}
+
+	return unique
+}
+
+func createActivityParticipants(ctx context.Context, qtx *tables.Queries, id int32, contactIDs []int32, namespace string) error {
+	for _, contactID := range uniqueContactIDs(contactIDs) {
+		if _, err := qtx.CreateActivityParticipant(ctx, models.CreateActivityParticipantParams{
+			ID:        id,
+			ID_2:      contactID,
+			Namespace: namespace,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateActivity creates an activity shared by all of the contacts in a single
+// transaction and returns `sql.ErrNoRows` if any of them belongs to another
+// namespace
+func (p *sqlPersister) CreateActivity(
 	ctx context.Context,
 
 	name string,
 	date time.Time,
 	description string,
 
-	contactID int32,
+	contactIDs []int32,
 	namespace string,
 ) (int32, error) {
-	return p.queries.CreateActivity(ctx, models.CreateActivityParams{
-		ID:          contactID,
-		Namespace:   namespace,
+	tx, err := p.db.Begin()
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	id, err := qtx.CreateActivity(ctx, models.CreateActivityParams{
 		Name:        name,
 		Date:        date,
 		Description: description,
+		Namespace:   namespace,
 	})
+	if err != nil {
+		return -1, err
+	}
+
+	if err := createActivityParticipants(ctx, qtx, id, contactIDs, namespace); err != nil {
+		return -1, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return -1, err
+	}
+
+	return id, nil
 }
 
-func (p *Persister) GetActivities(
+func (p *sqlPersister) GetActivities(
 	ctx context.Context,
 
 	contactID int32,
 	namespace string,
 ) ([]models.GetActivitiesRow, error) {
 	return p.queries.GetActivities(ctx, models.GetActivitiesParams{
-		ID:        contactID,
+		ContactID: contactID,
 		Namespace: namespace,
 	})
 }
 
-func (p *Persister) DeleteActivity(
+// DeleteActivity deletes an activity for all of its participants; it does
+// nothing if the contact doesn't participate in the activity
+func (p *sqlPersister) DeleteActivity(
 	ctx context.Context,
 
 	id int32,
@@ -46,15 +106,81 @@ func (p *Persister) DeleteActivity(
 	contactID int32,
 	namespace string,
 ) error {
-	return p.queries.DeleteActivity(ctx, models.DeleteActivityParams{
+	_, err := p.deleteActivity(ctx, id, sql.NullInt32{}, contactID, namespace)
+
+	return err
+}
+
+// DeleteActivityIfVersion deletes an activity like `DeleteActivity`, but only
+// if it still has `version`; it returns `false` if it doesn't
+func (p *sqlPersister) DeleteActivityIfVersion(
+	ctx context.Context,
+
+	id,
+	version int32,
+
+	contactID int32,
+	namespace string,
+) (bool, error) {
+	return p.deleteActivity(ctx, id, sql.NullInt32{Int32: version, Valid: true}, contactID, namespace)
+}
+
+func (p *sqlPersister) deleteActivity(
+	ctx context.Context,
+
+	id int32,
+	version sql.NullInt32,
+
+	contactID int32,
+	namespace string,
+) (bool, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	if _, err := qtx.GetActivityAndContact(ctx, models.GetActivityAndContactParams{
 		ID_2: id,
 
 		ID:        contactID,
 		Namespace: namespace,
-	})
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if ok, err := lockActivityVersion(ctx, qtx, id, version, namespace); err != nil || !ok {
+		return false, err
+	}
+
+	if err := qtx.DeleteActivityParticipants(ctx, models.DeleteActivityParticipantsParams{
+		ID:        id,
+		Namespace: namespace,
+	}); err != nil {
+		return false, err
+	}
+
+	if err := qtx.DeleteActivity(ctx, models.DeleteActivityParams{
+		ID:        id,
+		Namespace: namespace,
+	}); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
-func (p *Persister) GetActivityAndContact(
+func (p *sqlPersister) GetActivityAndContact(
 	ctx context.Context,
 
 	id int32,
@@ -70,7 +196,22 @@ func (p *Persister) GetActivityAndContact(
 	})
 }
 
-func (p *Persister) UpdateActivity(
+func (p *sqlPersister) GetActivityParticipants(
+	ctx context.Context,
+
+	id int32,
+	namespace string,
+) ([]models.GetActivityParticipantsRow, error) {
+	return p.queries.GetActivityParticipants(ctx, models.GetActivityParticipantsParams{
+		ActivityID: id,
+		Namespace:  namespace,
+	})
+}
+
+// UpdateActivity updates an activity which the contact participates in; if
+// contactIDs isn't nil, it also replaces the activity's participants and
+// returns `sql.ErrNoRows` if any of them belongs to another namespace
+func (p *sqlPersister) UpdateActivity(
 	ctx context.Context,
 
 	id int32,
@@ -81,15 +222,135 @@ func (p *Persister) UpdateActivity(
 	name string,
 	date time.Time,
 	description string,
+
+	contactIDs []int32,
 ) error {
-	return p.queries.UpdateActivity(ctx, models.UpdateActivityParams{
+	_, err := p.updateActivity(ctx, id, sql.NullInt32{}, contactID, namespace, name, date, description, contactIDs)
+
+	return err
+}
+
+// UpdateActivityIfVersion updates an activity like `UpdateActivity`, but only
+// if it still has `version`; it returns `false` if it doesn't
+func (p *sqlPersister) UpdateActivityIfVersion(
+	ctx context.Context,
+
+	id,
+	version int32,
+
+	contactID int32,
+	namespace string,
+
+	name string,
+	date time.Time,
+	description string,
+
+	contactIDs []int32,
+) (bool, error) {
+	return p.updateActivity(ctx, id, sql.NullInt32{Int32: version, Valid: true}, contactID, namespace, name, date, description, contactIDs)
+}
+
+func (p *sqlPersister) updateActivity(
+	ctx context.Context,
+
+	id int32,
+	version sql.NullInt32,
+
+	contactID int32,
+	namespace string,
+
+	name string,
+	date time.Time,
+	description string,
+
+	contactIDs []int32,
+) (bool, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	if _, err := qtx.GetActivityAndContact(ctx, models.GetActivityAndContactParams{
 		ID_2: id,
 
 		ID:        contactID,
 		Namespace: namespace,
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if ok, err := lockActivityVersion(ctx, qtx, id, version, namespace); err != nil || !ok {
+		return false, err
+	}
+
+	if err := qtx.UpdateActivity(ctx, models.UpdateActivityParams{
+		ID: id,
+
+		ContactID: contactID,
+		Namespace: namespace,
 
 		Name:        name,
 		Date:        date,
 		Description: description,
+	}); err != nil {
+		return false, err
+	}
+
+	if contactIDs != nil {
+		if err := qtx.DeleteActivityParticipants(ctx, models.DeleteActivityParticipantsParams{
+			ID:        id,
+			Namespace: namespace,
+		}); err != nil {
+			return false, err
+		}
+
+		if err := createActivityParticipants(ctx, qtx, id, contactIDs, namespace); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// lockActivityVersion checks that an activity still has `version` and locks it
+// for the rest of the transaction; it doesn't check anything if `version` isn't
+// valid
+func lockActivityVersion(ctx context.Context, qtx *tables.Queries, id int32, version sql.NullInt32, namespace string) (bool, error) {
+	if !version.Valid {
+		return true, nil
+	}
+
+	rows, err := qtx.LockActivityVersion(ctx, models.LockActivityVersionParams{
+		ID:        id,
+		Namespace: namespace,
+		Version:   version.Int32,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func (p *sqlPersister) GetActivityCalendar(
+	ctx context.Context,
+
+	id int32,
+	namespace string,
+) ([]models.GetActivityCalendarRow, error) {
+	return p.queries.GetActivityCalendar(ctx, models.GetActivityCalendarParams{
+		ID:        id,
+		Namespace: namespace,
 	})
 }
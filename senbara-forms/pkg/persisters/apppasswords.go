@@ -0,0 +1,30 @@
+package persisters
+
+import (
+	"context"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func (p *sqlPersister) GetAppPasswords(ctx context.Context, namespace string) ([]models.GetAppPasswordsRow, error) {
+	return p.queries.GetAppPasswords(ctx, namespace)
+}
+
+func (p *sqlPersister) CreateAppPassword(ctx context.Context, name, hash, namespace string) (int32, error) {
+	return p.queries.CreateAppPassword(ctx, models.CreateAppPasswordParams{
+		Name:      name,
+		Hash:      hash,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) DeleteAppPassword(ctx context.Context, id int32, namespace string) error {
+	return p.queries.DeleteAppPassword(ctx, models.DeleteAppPasswordParams{
+		ID:        id,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) GetAppPasswordNamespace(ctx context.Context, hash string) (string, error) {
+	return p.queries.GetAppPasswordNamespace(ctx, hash)
+}
@@ -0,0 +1,30 @@
+package persisters
+
+import (
+	"context"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func (p *sqlPersister) UpsertCalendarFeed(ctx context.Context, hash, namespace string) error {
+	return p.queries.UpsertCalendarFeed(ctx, models.UpsertCalendarFeedParams{
+		Hash:      hash,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) GetCalendarFeed(ctx context.Context, namespace string) (models.GetCalendarFeedRow, error) {
+	return p.queries.GetCalendarFeed(ctx, namespace)
+}
+
+func (p *sqlPersister) GetCalendarFeedNamespace(ctx context.Context, hash string) (string, error) {
+	return p.queries.GetCalendarFeedNamespace(ctx, hash)
+}
+
+func (p *sqlPersister) DeleteCalendarFeed(ctx context.Context, namespace string) error {
+	return p.queries.DeleteCalendarFeedForNamespace(ctx, namespace)
+}
+
+func (p *sqlPersister) GetActivitiesCalendar(ctx context.Context, namespace string) ([]models.GetActivitiesCalendarForNamespaceRow, error) {
+	return p.queries.GetActivitiesCalendarForNamespace(ctx, namespace)
+}
@@ -0,0 +1,254 @@
+package persisters
+
+import (
+	"context"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+)
+
+func (p *sqlPersister) GetContactDetails(ctx context.Context, contactID int32, namespace string) (models.ContactDetails, error) {
+	emails, err := p.queries.GetContactEmails(ctx, models.GetContactEmailsParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return models.ContactDetails{}, err
+	}
+
+	phones, err := p.queries.GetContactPhones(ctx, models.GetContactPhonesParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return models.ContactDetails{}, err
+	}
+
+	addresses, err := p.queries.GetContactAddresses(ctx, models.GetContactAddressesParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return models.ContactDetails{}, err
+	}
+
+	websites, err := p.queries.GetContactWebsites(ctx, models.GetContactWebsitesParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return models.ContactDetails{}, err
+	}
+
+	return models.ContactDetails{
+		Emails:    emails,
+		Phones:    phones,
+		Addresses: addresses,
+		Websites:  websites,
+	}, nil
+}
+
+// GetContactDetailsForNamespace returns the details of all contacts in a
+// namespace, indexed by their contact IDs, so that listing contacts with their
+// details doesn't need a query per contact
+func (p *sqlPersister) GetContactDetailsForNamespace(ctx context.Context, namespace string) (map[int32]models.ContactDetails, error) {
+	return getContactDetailsForNamespace(ctx, p.queries, namespace)
+}
+
+func getContactDetailsForNamespace(ctx context.Context, q *tables.Queries, namespace string) (map[int32]models.ContactDetails, error) {
+	emails, err := q.GetContactEmailsForNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	phones, err := q.GetContactPhonesForNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := q.GetContactAddressesForNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	websites, err := q.GetContactWebsitesForNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[int32]models.ContactDetails{}
+	for _, email := range emails {
+		d := details[email.ContactID]
+		d.Emails = append(d.Emails, email)
+		details[email.ContactID] = d
+	}
+
+	for _, phone := range phones {
+		d := details[phone.ContactID]
+		d.Phones = append(d.Phones, phone)
+		details[phone.ContactID] = d
+	}
+
+	for _, address := range addresses {
+		d := details[address.ContactID]
+		d.Addresses = append(d.Addresses, address)
+		details[address.ContactID] = d
+	}
+
+	for _, website := range websites {
+		d := details[website.ContactID]
+		d.Websites = append(d.Websites, website)
+		details[website.ContactID] = d
+	}
+
+	return details, nil
+}
+
+// deleteContactDetails removes all email addresses, phone numbers, postal
+// addresses and websites of a contact
+func deleteContactDetails(ctx context.Context, qtx *tables.Queries, contactID int32, namespace string) error {
+	if err := qtx.DeleteContactEmails(ctx, models.DeleteContactEmailsParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	}); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactPhones(ctx, models.DeleteContactPhonesParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	}); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactAddresses(ctx, models.DeleteContactAddressesParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	}); err != nil {
+		return err
+	}
+
+	return qtx.DeleteContactWebsites(ctx, models.DeleteContactWebsitesParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	})
+}
+
+// setContactDetails adds the details to a contact, ignoring their IDs and
+// contact IDs; the caller must have checked that the contact belongs to the
+// namespace and removed its previous details
+func setContactDetails(ctx context.Context, qtx *tables.Queries, contactID int32, details models.ContactDetails) error {
+	for _, email := range details.Emails {
+		if err := qtx.CreateContactEmail(ctx, models.CreateContactEmailParams{
+			ContactID: contactID,
+			Label:     email.Label,
+			Email:     email.Email,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, phone := range details.Phones {
+		if err := qtx.CreateContactPhone(ctx, models.CreateContactPhoneParams{
+			ContactID: contactID,
+			Label:     phone.Label,
+			Number:    phone.Number,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, address := range details.Addresses {
+		if err := qtx.CreateContactAddress(ctx, models.CreateContactAddressParams{
+			ContactID: contactID,
+			Label:     address.Label,
+			Address:   address.Address,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, website := range details.Websites {
+		if err := qtx.CreateContactWebsite(ctx, models.CreateContactWebsiteParams{
+			ContactID: contactID,
+			Label:     website.Label,
+			Url:       website.Url,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withExportedContactDetails adds the details of a contact to its export, which
+// uses empty lists instead of `nil` like `exportedTags`
+func withExportedContactDetails(contact models.ExportedContact, details models.ContactDetails) models.ExportedContact {
+	contact.Emails = []models.ExportedContactEmail{}
+	for _, email := range details.Emails {
+		contact.Emails = append(contact.Emails, models.ExportedContactEmail{
+			Label: email.Label,
+			Email: email.Email,
+		})
+	}
+
+	contact.Phones = []models.ExportedContactPhone{}
+	for _, phone := range details.Phones {
+		contact.Phones = append(contact.Phones, models.ExportedContactPhone{
+			Label:  phone.Label,
+			Number: phone.Number,
+		})
+	}
+
+	contact.Addresses = []models.ExportedContactAddress{}
+	for _, address := range details.Addresses {
+		contact.Addresses = append(contact.Addresses, models.ExportedContactAddress{
+			Label:   address.Label,
+			Address: address.Address,
+		})
+	}
+
+	contact.Websites = []models.ExportedContactWebsite{}
+	for _, website := range details.Websites {
+		contact.Websites = append(contact.Websites, models.ExportedContactWebsite{
+			Label: website.Label,
+			URL:   website.Url,
+		})
+	}
+
+	return contact
+}
+
+// importedContactDetails returns the details of an exported contact
+func importedContactDetails(contact models.ExportedContact) models.ContactDetails {
+	details := models.ContactDetails{}
+	for _, email := range contact.Emails {
+		details.Emails = append(details.Emails, models.ContactEmail{
+			Label: email.Label,
+			Email: email.Email,
+		})
+	}
+
+	for _, phone := range contact.Phones {
+		details.Phones = append(details.Phones, models.ContactPhone{
+			Label:  phone.Label,
+			Number: phone.Number,
+		})
+	}
+
+	for _, address := range contact.Addresses {
+		details.Addresses = append(details.Addresses, models.ContactAddress{
+			Label:   address.Label,
+			Address: address.Address,
+		})
+	}
+
+	for _, website := range contact.Websites {
+		details.Websites = append(details.Websites, models.ContactWebsite{
+			Label: website.Label,
+			Url:   website.URL,
+		})
+	}
+
+	return details
+}
@@ -3,80 +3,328 @@ package persisters
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/tables"
 )
 
-func (p *Persister) GetContacts(ctx context.Context, namespace string) ([]models.Contact, error) {
+func (p *sqlPersister) GetContacts(ctx context.Context, namespace string) ([]models.Contact, error) {
 	return p.queries.GetContacts(ctx, namespace)
 }
 
-func (p *Persister) CreateContact(
+// The orders `ListContacts` can sort contacts in; all of them are ascending
+// except for `ContactsSortLastContacted`, which lists the contacts with the
+// most recent activities first
+const (
+	ContactsSortFirstName     = "first_name"
+	ContactsSortLastName      = "last_name"
+	ContactsSortBirthday      = "birthday"
+	ContactsSortLastContacted = "last_contacted"
+)
+
+// ListContacts returns a page of contacts ordered by `sort`. The next page
+// starts after the sort key and ID of the last contact of the previous one;
+// an `afterID` of 0 returns the first page. `birthdayMonth` limits the
+// contacts to those born in a month if it isn't 0, and `tag` limits them to
+// those with a tag if it isn't empty.
+func (p *sqlPersister) ListContacts(
+	ctx context.Context,
+	namespace string,
+	sort string,
+	hasDebts bool,
+	birthdayMonth int32,
+	tag string,
+	afterKey string,
+	afterID int32,
+	limit int32,
+) ([]models.ListContactsRow, error) {
+	return p.queries.ListContacts(ctx, models.ListContactsParams{
+		Sort:          sort,
+		Namespace:     namespace,
+		HasDebts:      hasDebts,
+		BirthdayMonth: birthdayMonth,
+		Tag:           tag,
+		AfterID:       afterID,
+		AfterKey:      afterKey,
+		ResultLimit:   limit,
+	})
+}
+
+func (p *sqlPersister) CreateContact(
 	ctx context.Context,
 	firstName string,
 	lastName string,
 	nickname string,
-	email string,
 	pronouns string,
 	namespace string,
+	details models.ContactDetails,
 ) (int32, error) {
-	return p.queries.CreateContact(ctx, models.CreateContactParams{
+	tx, err := p.db.Begin()
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	id, err := qtx.CreateContact(ctx, models.CreateContactParams{
 		FirstName: firstName,
 		LastName:  lastName,
 		Nickname:  nickname,
-		Email:     email,
 		Pronouns:  pronouns,
 		Namespace: namespace,
 	})
+	if err != nil {
+		return -1, err
+	}
+
+	if err := setContactDetails(ctx, qtx, id, details); err != nil {
+		return -1, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return -1, err
+	}
+
+	return id, nil
 }
 
-func (p *Persister) GetContact(ctx context.Context, id int32, namespace string) (models.Contact, error) {
+// CreateContacts creates the contacts with the details at the same index
+// in `details` in a single transaction
+func (p *sqlPersister) CreateContacts(
+	ctx context.Context,
+	contacts []models.Contact,
+	details []models.ContactDetails,
+	namespace string,
+) ([]int32, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	ids := []int32{}
+	for i, contact := range contacts {
+		id, err := qtx.CreateContact(ctx, models.CreateContactParams{
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+			Nickname:  contact.Nickname,
+			Pronouns:  contact.Pronouns,
+			Namespace: namespace,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qtx.UpdateContact(ctx, models.UpdateContactParams{
+			ID:        id,
+			Namespace: namespace,
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+			Nickname:  contact.Nickname,
+			Pronouns:  contact.Pronouns,
+			Birthday:  contact.Birthday,
+			Notes:     contact.Notes,
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := setContactDetails(ctx, qtx, id, details[i]); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (p *sqlPersister) GetContact(ctx context.Context, id int32, namespace string) (models.Contact, error) {
 	return p.queries.GetContact(ctx, models.GetContactParams{
 		ID:        id,
 		Namespace: namespace,
 	})
 }
 
-func (p *Persister) DeleteContact(ctx context.Context, id int32, namespace string) error {
+func (p *sqlPersister) GetContactByEmail(ctx context.Context, email string, namespace string) (models.Contact, error) {
+	return p.queries.GetContactByEmail(ctx, models.GetContactByEmailParams{
+		Namespace: namespace,
+		Lower:     email,
+	})
+}
+
+func (p *sqlPersister) DeleteContact(ctx context.Context, id int32, namespace string) error {
+	_, err := p.deleteContact(ctx, id, sql.NullInt32{}, namespace)
+
+	return err
+}
+
+// DeleteContactIfVersion deletes a contact only if it still has `version`, so
+// that changes made since it was read aren't lost; it returns `false` if it
+// doesn't
+func (p *sqlPersister) DeleteContactIfVersion(ctx context.Context, id, version int32, namespace string) (bool, error) {
+	return p.deleteContact(ctx, id, sql.NullInt32{Int32: version, Valid: true}, namespace)
+}
+
+func (p *sqlPersister) deleteContact(ctx context.Context, id int32, version sql.NullInt32, namespace string) (bool, error) {
 	tx, err := p.db.Begin()
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer tx.Rollback()
 
-	qtx := p.queries.WithTx(tx)
+	qtx := p.withTx(tx)
+
+	if ok, err := lockContactVersion(ctx, qtx, id, version, namespace); err != nil || !ok {
+		return false, err
+	}
+
+	// Activities are kept for their other participants
+	if err := qtx.DeleteActivityParticipantsForContact(ctx, models.DeleteActivityParticipantsForContactParams{
+		ContactID: id,
+		Namespace: namespace,
+	}); err != nil {
+		return false, err
+	}
+
+	if err := qtx.DeleteActivitiesWithoutParticipantsForNamespace(ctx, namespace); err != nil {
+		return false, err
+	}
+
+	if err := qtx.DeleteDebtPaymentsForContact(ctx, models.DeleteDebtPaymentsForContactParams{
+		ID:        id,
+		Namespace: namespace,
+	}); err != nil {
+		return false, err
+	}
 
 	if err := qtx.DeleteDebtsForContact(ctx, models.DeleteDebtsForContactParams{
 		ID:        id,
 		Namespace: namespace,
 	}); err != nil {
-		return err
+		return false, err
+	}
+
+	if err := qtx.DeleteContactTags(ctx, models.DeleteContactTagsParams{
+		ContactID: id,
+		Namespace: namespace,
+	}); err != nil {
+		return false, err
+	}
+
+	if err := deleteContactDetails(ctx, qtx, id, namespace); err != nil {
+		return false, err
+	}
+
+	if err := qtx.DeleteContactRelationshipsForContact(ctx, models.DeleteContactRelationshipsForContactParams{
+		ContactID: id,
+		Namespace: namespace,
+	}); err != nil {
+		return false, err
+	}
+
+	if err := qtx.DeleteContactGroupMembersForContact(ctx, models.DeleteContactGroupMembersForContactParams{
+		ContactID: id,
+		Namespace: namespace,
+	}); err != nil {
+		return false, err
 	}
 
 	if err := qtx.DeleteContact(ctx, models.DeleteContactParams{
 		ID:        id,
 		Namespace: namespace,
 	}); err != nil {
-		return err
+		return false, err
+	}
+
+	if err := qtx.DeleteUnusedTags(ctx, namespace); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// lockContactVersion checks that a contact still has `version` and locks it for
+// the rest of the transaction so that it can't change before it is written to;
+// it doesn't check anything if `version` isn't valid
+func lockContactVersion(ctx context.Context, qtx *tables.Queries, id int32, version sql.NullInt32, namespace string) (bool, error) {
+	if !version.Valid {
+		return true, nil
+	}
+
+	rows, err := qtx.LockContactVersion(ctx, models.LockContactVersionParams{
+		ID:        id,
+		Namespace: namespace,
+		Version:   version.Int32,
+	})
+	if err != nil {
+		return false, err
 	}
 
-	return tx.Commit()
+	return rows == 1, nil
 }
 
-func (p *Persister) UpdateContact(
+// UpdateContact updates a contact and replaces its details
+func (p *sqlPersister) UpdateContact(
 	ctx context.Context,
 	id int32,
 	firstName,
 	lastName,
 	nickname,
-	email,
 	pronouns,
 	namespace string,
 	birthday *time.Time,
-	address,
 	notes string,
+	details models.ContactDetails,
 ) error {
+	_, err := p.updateContact(ctx, id, sql.NullInt32{}, firstName, lastName, nickname, pronouns, namespace, birthday, notes, details)
+
+	return err
+}
+
+// UpdateContactIfVersion updates a contact like `UpdateContact`, but only if
+// it still has `version`; it returns `false` if it doesn't
+func (p *sqlPersister) UpdateContactIfVersion(
+	ctx context.Context,
+	id,
+	version int32,
+	firstName,
+	lastName,
+	nickname,
+	pronouns,
+	namespace string,
+	birthday *time.Time,
+	notes string,
+	details models.ContactDetails,
+) (bool, error) {
+	return p.updateContact(ctx, id, sql.NullInt32{Int32: version, Valid: true}, firstName, lastName, nickname, pronouns, namespace, birthday, notes, details)
+}
+
+func (p *sqlPersister) updateContact(
+	ctx context.Context,
+	id int32,
+	version sql.NullInt32,
+	firstName,
+	lastName,
+	nickname,
+	pronouns,
+	namespace string,
+	birthday *time.Time,
+	notes string,
+	details models.ContactDetails,
+) (bool, error) {
 	var birthdayDate sql.NullTime
 	if birthday != nil {
 		birthdayDate = sql.NullTime{
@@ -85,16 +333,54 @@ func (p *Persister) UpdateContact(
 		}
 	}
 
-	return p.queries.UpdateContact(ctx, models.UpdateContactParams{
+	tx, err := p.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	// Like the other updates, updating another namespace's contact does nothing
+	if _, err := qtx.GetContact(ctx, models.GetContactParams{
+		ID:        id,
+		Namespace: namespace,
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if ok, err := lockContactVersion(ctx, qtx, id, version, namespace); err != nil || !ok {
+		return false, err
+	}
+
+	if err := qtx.UpdateContact(ctx, models.UpdateContactParams{
 		ID:        id,
 		Namespace: namespace,
 		FirstName: firstName,
 		LastName:  lastName,
 		Nickname:  nickname,
-		Email:     email,
 		Pronouns:  pronouns,
 		Birthday:  birthdayDate,
-		Address:   address,
 		Notes:     notes,
-	})
+	}); err != nil {
+		return false, err
+	}
+
+	if err := deleteContactDetails(ctx, qtx, id, namespace); err != nil {
+		return false, err
+	}
+
+	if err := setContactDetails(ctx, qtx, id, details); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
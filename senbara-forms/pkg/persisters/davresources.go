@@ -0,0 +1,40 @@
+package persisters
+
+import (
+	"context"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func (p *sqlPersister) GetDAVResources(ctx context.Context, collection, namespace string) ([]models.GetDAVResourcesRow, error) {
+	return p.queries.GetDAVResources(ctx, models.GetDAVResourcesParams{
+		Collection: collection,
+		Namespace:  namespace,
+	})
+}
+
+func (p *sqlPersister) GetDAVResource(ctx context.Context, collection, name, namespace string) (models.GetDAVResourceRow, error) {
+	return p.queries.GetDAVResource(ctx, models.GetDAVResourceParams{
+		Collection: collection,
+		Name:       name,
+		Namespace:  namespace,
+	})
+}
+
+func (p *sqlPersister) UpsertDAVResource(ctx context.Context, collection, name string, resourceID int32, uid, namespace string) error {
+	return p.queries.UpsertDAVResource(ctx, models.UpsertDAVResourceParams{
+		Collection: collection,
+		Name:       name,
+		ResourceID: resourceID,
+		Uid:        uid,
+		Namespace:  namespace,
+	})
+}
+
+func (p *sqlPersister) DeleteDAVResources(ctx context.Context, collection string, resourceID int32, namespace string) error {
+	return p.queries.DeleteDAVResources(ctx, models.DeleteDAVResourcesParams{
+		Collection: collection,
+		ResourceID: resourceID,
+		Namespace:  namespace,
+	})
+}
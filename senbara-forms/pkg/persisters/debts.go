@@ -2,16 +2,18 @@ package persisters
 
 import (
 	"context"
+	"time"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
 )
 
-func (p *Persister) CreateDebt(
+func (p *sqlPersister) CreateDebt(
 	ctx context.Context,
 
-	amount float64,
+	amount int64,
 	currency,
 	description string,
+	date time.Time,
 
 	contactID int32,
 	namespace string,
@@ -22,10 +24,57 @@ func (p *Persister) CreateDebt(
 		Amount:      amount,
 		Currency:    currency,
 		Description: description,
+		Date:        date,
 	})
 }
 
-func (p *Persister) GetDebts(
+// CreateDebts creates a debt with the amount at the same index in `amounts`
+// for each of the contacts in a single transaction and returns `sql.ErrNoRows`
+// if any of them belongs to another namespace
+func (p *sqlPersister) CreateDebts(
+	ctx context.Context,
+
+	amounts []int64,
+	currency,
+	description string,
+	date time.Time,
+
+	contactIDs []int32,
+	namespace string,
+) ([]int32, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	ids := []int32{}
+	for i, contactID := range contactIDs {
+		id, err := qtx.CreateDebt(ctx, models.CreateDebtParams{
+			ID:          contactID,
+			Namespace:   namespace,
+			Amount:      amounts[i],
+			Currency:    currency,
+			Description: description,
+			Date:        date,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (p *sqlPersister) GetDebts(
 	ctx context.Context,
 
 	contactID int32,
@@ -37,23 +86,45 @@ func (p *Persister) GetDebts(
 	})
 }
 
-func (p *Persister) SettleDebt(
+func (p *sqlPersister) GetDebtPayments(
+	ctx context.Context,
+
+	contactID int32,
+	namespace string,
+) ([]models.DebtPayment, error) {
+	return p.queries.GetDebtPayments(ctx, models.GetDebtPaymentsParams{
+		ID:        contactID,
+		Namespace: namespace,
+	})
+}
+
+// SettleDebt records a full or partial payment of a debt and returns
+// `sql.ErrNoRows` if the debt belongs to another contact or namespace
+func (p *sqlPersister) SettleDebt(
 	ctx context.Context,
 
 	id int32,
 
 	contactID int32,
 	namespace string,
-) error {
-	return p.queries.SettleDebt(ctx, models.SettleDebtParams{
+
+	amount int64,
+	date time.Time,
+	description string,
+) (int32, error) {
+	return p.queries.CreateDebtPayment(ctx, models.CreateDebtPaymentParams{
 		ID_2: id,
 
 		ID:        contactID,
 		Namespace: namespace,
+
+		Amount:      amount,
+		Date:        date,
+		Description: description,
 	})
 }
 
-func (p *Persister) GetDebtAndContact(
+func (p *sqlPersister) GetDebtAndContact(
 	ctx context.Context,
 
 	id int32,
@@ -69,7 +140,7 @@ func (p *Persister) GetDebtAndContact(
 	})
 }
 
-func (p *Persister) UpdateDebt(
+func (p *sqlPersister) UpdateDebt(
 	ctx context.Context,
 
 	id int32,
@@ -77,9 +148,10 @@ func (p *Persister) UpdateDebt(
 	contactID int32,
 	namespace string,
 
-	amount float64,
+	amount int64,
 	currency,
 	description string,
+	date time.Time,
 ) error {
 	return p.queries.UpdateDebt(ctx, models.UpdateDebtParams{
 		ID_2: id,
@@ -90,5 +162,6 @@ func (p *Persister) UpdateDebt(
 		Amount:      amount,
 		Currency:    currency,
 		Description: description,
+		Date:        date,
 	})
 }
@@ -0,0 +1,87 @@
+package persisters
+
+import (
+	"context"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func (p *sqlPersister) GetContactGroups(ctx context.Context, namespace string) ([]models.GetContactGroupsRow, error) {
+	return p.queries.GetContactGroups(ctx, namespace)
+}
+
+func (p *sqlPersister) CreateContactGroup(ctx context.Context, name, namespace string) (int32, error) {
+	return p.queries.CreateContactGroup(ctx, models.CreateContactGroupParams{
+		Name:      name,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) GetContactGroup(ctx context.Context, id int32, namespace string) (models.ContactGroup, error) {
+	return p.queries.GetContactGroup(ctx, models.GetContactGroupParams{
+		ID:        id,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) UpdateContactGroup(ctx context.Context, id int32, name, namespace string) error {
+	return p.queries.UpdateContactGroup(ctx, models.UpdateContactGroupParams{
+		ID:        id,
+		Namespace: namespace,
+		Name:      name,
+	})
+}
+
+// DeleteContactGroup deletes a group and its memberships; the contacts
+// themselves are kept
+func (p *sqlPersister) DeleteContactGroup(ctx context.Context, id int32, namespace string) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	if err := qtx.DeleteContactGroupMembers(ctx, models.DeleteContactGroupMembersParams{
+		ID:        id,
+		Namespace: namespace,
+	}); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactGroup(ctx, models.DeleteContactGroupParams{
+		ID:        id,
+		Namespace: namespace,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *sqlPersister) GetContactGroupMembers(ctx context.Context, groupID int32, namespace string) ([]models.GetContactGroupMembersRow, error) {
+	return p.queries.GetContactGroupMembers(ctx, models.GetContactGroupMembersParams{
+		GroupID:   groupID,
+		Namespace: namespace,
+	})
+}
+
+// CreateContactGroupMember adds a contact to a group; it does nothing if the
+// contact already is a member or if the group or the contact belong to another
+// namespace
+func (p *sqlPersister) CreateContactGroupMember(ctx context.Context, groupID, contactID int32, namespace string) error {
+	return p.queries.CreateContactGroupMember(ctx, models.CreateContactGroupMemberParams{
+		ID:        groupID,
+		ID_2:      contactID,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) DeleteContactGroupMember(ctx context.Context, groupID, contactID int32, namespace string) error {
+	return p.queries.DeleteContactGroupMember(ctx, models.DeleteContactGroupMemberParams{
+		GroupID:   groupID,
+		ContactID: contactID,
+		Namespace: namespace,
+	})
+}
@@ -2,15 +2,64 @@ package persisters
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
 )
 
-func (p *Persister) GetJournalEntries(ctx context.Context, namespace string) ([]models.JournalEntry, error) {
+func (p *sqlPersister) GetJournalEntries(ctx context.Context, namespace string) ([]models.JournalEntry, error) {
 	return p.queries.GetJournalEntries(ctx, namespace)
 }
 
-func (p *Persister) CreateJournalEntry(ctx context.Context, title, body string, rating int32, namespace string) (int32, error) {
+// ListJournalEntries returns a page of journal entries, newest first unless
+// `oldestFirst` is set. The next page starts after the date and ID of the last
+// entry of the previous one; an `afterID` of 0 returns the first page. A
+// `rating` of 0 matches all ratings, `from` and `to` limit the entries to
+// `[from, to)` if they aren't nil, and `tag` limits them to those with a tag
+// if it isn't empty.
+func (p *sqlPersister) ListJournalEntries(
+	ctx context.Context,
+	namespace string,
+	oldestFirst bool,
+	rating int32,
+	from,
+	to *time.Time,
+	tag string,
+	afterDate time.Time,
+	afterID int32,
+	limit int32,
+) ([]models.ListJournalEntriesRow, error) {
+	var fromDate sql.NullTime
+	if from != nil {
+		fromDate = sql.NullTime{
+			Time:  *from,
+			Valid: true,
+		}
+	}
+
+	var toDate sql.NullTime
+	if to != nil {
+		toDate = sql.NullTime{
+			Time:  *to,
+			Valid: true,
+		}
+	}
+
+	return p.queries.ListJournalEntries(ctx, models.ListJournalEntriesParams{
+		Namespace:   namespace,
+		Rating:      rating,
+		FromDate:    fromDate,
+		ToDate:      toDate,
+		Tag:         tag,
+		AfterID:     afterID,
+		OldestFirst: oldestFirst,
+		AfterDate:   afterDate,
+		ResultLimit: limit,
+	})
+}
+
+func (p *sqlPersister) CreateJournalEntry(ctx context.Context, title, body string, rating int32, namespace string) (int32, error) {
 	return p.queries.CreateJournalEntry(ctx, models.CreateJournalEntryParams{
 		Title:     title,
 		Body:      body,
@@ -19,21 +68,44 @@ func (p *Persister) CreateJournalEntry(ctx context.Context, title, body string,
 	})
 }
 
-func (p *Persister) DeleteJournalEntry(ctx context.Context, id int32, namespace string) error {
-	return p.queries.DeleteJournalEntry(ctx, models.DeleteJournalEntryParams{
+func (p *sqlPersister) DeleteJournalEntry(ctx context.Context, id int32, namespace string) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	if err := qtx.DeleteJournalEntryTags(ctx, models.DeleteJournalEntryTagsParams{
+		JournalEntryID: id,
+		Namespace:      namespace,
+	}); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteJournalEntry(ctx, models.DeleteJournalEntryParams{
 		ID:        id,
 		Namespace: namespace,
-	})
+	}); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteUnusedTags(ctx, namespace); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (p *Persister) GetJournalEntry(ctx context.Context, id int32, namespace string) (models.JournalEntry, error) {
+func (p *sqlPersister) GetJournalEntry(ctx context.Context, id int32, namespace string) (models.JournalEntry, error) {
 	return p.queries.GetJournalEntry(ctx, models.GetJournalEntryParams{
 		ID:        id,
 		Namespace: namespace,
 	})
 }
 
-func (p *Persister) UpdateJournalEntry(ctx context.Context, id int32, title, body string, rating int32, namespace string) error {
+func (p *sqlPersister) UpdateJournalEntry(ctx context.Context, id int32, title, body string, rating int32, namespace string) error {
 	return p.queries.UpdateJournalEntry(ctx, models.UpdateJournalEntryParams{
 		ID:        id,
 		Namespace: namespace,
@@ -0,0 +1,2837 @@
+package persisters
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/money"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+)
+
+// MemoryPersister keeps all data in memory, which makes it useful for tests and
+// demos. Like the SQL persisters, it only ever returns or changes the data of
+// the namespace passed to a method and returns `sql.ErrNoRows` if a single
+// record can't be found.
+type MemoryPersister struct {
+	lock sync.Mutex
+
+	lastID int32
+
+	journalEntries       map[int32]tables.JournalEntry
+	contacts             map[int32]tables.Contact
+	debts                map[int32]tables.Debt
+	debtPayments         map[int32]tables.DebtPayment
+	activities           map[int32]tables.Activity
+	activityParticipants map[tables.ActivityParticipant]struct{}
+	appPasswords         map[int32]tables.AppPassword
+	calendarFeeds        map[int32]tables.CalendarFeed
+	personalAccessTokens map[int32]tables.PersonalAccessToken
+	sessions             map[int32]tables.Session
+	stagedUserData       map[string]tables.StagedUserDatum
+	davResources         map[davResourceKey]tables.DavResource
+	tags                 map[int32]tables.Tag
+	contactTags          map[tables.ContactTag]struct{}
+	journalEntryTags     map[tables.JournalEntryTag]struct{}
+	contactEmails        map[int32]tables.ContactEmail
+	contactPhones        map[int32]tables.ContactPhone
+	contactAddresses     map[int32]tables.ContactAddress
+	contactWebsites      map[int32]tables.ContactWebsite
+	relationships        map[int32]tables.ContactRelationship
+	groups               map[int32]tables.ContactGroup
+	groupMembers         map[tables.ContactGroupMember]struct{}
+}
+
+type davResourceKey struct {
+	collection string
+	name       string
+	namespace  string
+}
+
+func NewMemoryPersister() *MemoryPersister {
+	return &MemoryPersister{}
+}
+
+func (p *MemoryPersister) Init() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.journalEntries = map[int32]tables.JournalEntry{}
+	p.contacts = map[int32]tables.Contact{}
+	p.debts = map[int32]tables.Debt{}
+	p.debtPayments = map[int32]tables.DebtPayment{}
+	p.activities = map[int32]tables.Activity{}
+	p.activityParticipants = map[tables.ActivityParticipant]struct{}{}
+	p.appPasswords = map[int32]tables.AppPassword{}
+	p.calendarFeeds = map[int32]tables.CalendarFeed{}
+	p.personalAccessTokens = map[int32]tables.PersonalAccessToken{}
+	p.sessions = map[int32]tables.Session{}
+	p.stagedUserData = map[string]tables.StagedUserDatum{}
+	p.davResources = map[davResourceKey]tables.DavResource{}
+	p.tags = map[int32]tables.Tag{}
+	p.contactTags = map[tables.ContactTag]struct{}{}
+	p.journalEntryTags = map[tables.JournalEntryTag]struct{}{}
+	p.contactEmails = map[int32]tables.ContactEmail{}
+	p.contactPhones = map[int32]tables.ContactPhone{}
+	p.contactAddresses = map[int32]tables.ContactAddress{}
+	p.contactWebsites = map[int32]tables.ContactWebsite{}
+	p.relationships = map[int32]tables.ContactRelationship{}
+	p.groups = map[int32]tables.ContactGroup{}
+	p.groupMembers = map[tables.ContactGroupMember]struct{}{}
+
+	return nil
+}
+
+// nextID returns a new ID; IDs are unique across all records, which makes
+// mixing them up between tables in the controllers fail instead of silently
+// returning the wrong record
+func (p *MemoryPersister) nextID() int32 {
+	p.lastID++
+
+	return p.lastID
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func sortedValues[T any](records map[int32]T, keep func(record T) bool, less func(a, b T) bool) []T {
+	values := []T{}
+	for _, record := range records {
+		if keep(record) {
+			values = append(values, record)
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return less(values[i], values[j])
+	})
+
+	return values
+}
+
+// contact returns a contact if it belongs to the namespace; the caller must
+// hold the lock
+func (p *MemoryPersister) contact(id int32, namespace string) (tables.Contact, bool) {
+	contact, ok := p.contacts[id]
+	if !ok || contact.Namespace != namespace {
+		return tables.Contact{}, false
+	}
+
+	return contact, true
+}
+
+// activity returns an activity if it belongs to the namespace; the caller
+// must hold the lock
+func (p *MemoryPersister) activity(id int32, namespace string) (tables.Activity, bool) {
+	activity, ok := p.activities[id]
+	if !ok || activity.Namespace != namespace {
+		return tables.Activity{}, false
+	}
+
+	return activity, true
+}
+
+// activityParticipantIDs returns the IDs of an activity's participants in
+// ascending order; the caller must hold the lock
+func (p *MemoryPersister) activityParticipantIDs(activityID int32) []int32 {
+	contactIDs := []int32{}
+	for activityParticipant := range p.activityParticipants {
+		if activityParticipant.ActivityID == activityID {
+			contactIDs = append(contactIDs, activityParticipant.ContactID)
+		}
+	}
+
+	slices.Sort(contactIDs)
+
+	return contactIDs
+}
+
+// activityParticipantContacts returns the participants of an activity ordered by
+// their names; the caller must hold the lock
+func (p *MemoryPersister) activityParticipantContacts(activityID int32) []tables.Contact {
+	contacts := []tables.Contact{}
+	for _, contactID := range p.activityParticipantIDs(activityID) {
+		contacts = append(contacts, p.contacts[contactID])
+	}
+
+	slices.SortStableFunc(contacts, func(a, b tables.Contact) int {
+		if c := strings.Compare(a.FirstName, b.FirstName); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.LastName, b.LastName)
+	})
+
+	return contacts
+}
+
+// isActivityParticipant returns whether a contact participates in an
+// activity; the caller must hold the lock
+func (p *MemoryPersister) isActivityParticipant(activityID, contactID int32) bool {
+	_, ok := p.activityParticipants[tables.ActivityParticipant{
+		ActivityID: activityID,
+		ContactID:  contactID,
+	}]
+
+	return ok
+}
+
+func (p *MemoryPersister) CreateActivity(ctx context.Context, name string, date time.Time, description string, contactIDs []int32, namespace string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// All contacts are checked first so that no activity is added if one of
+	// them belongs to another namespace
+	for _, contactID := range contactIDs {
+		if _, ok := p.contact(contactID, namespace); !ok {
+			return -1, sql.ErrNoRows
+		}
+	}
+
+	return p.createActivity(name, date, description, contactIDs, namespace), nil
+}
+
+// createActivity adds an activity without checking its participants; the
+// caller must hold the lock
+func (p *MemoryPersister) createActivity(name string, date time.Time, description string, contactIDs []int32, namespace string) int32 {
+	id := p.nextID()
+	p.activities[id] = tables.Activity{
+		ID:          id,
+		Name:        name,
+		Date:        date,
+		Description: description,
+		Version:     1,
+		Namespace:   namespace,
+	}
+
+	for _, contactID := range contactIDs {
+		p.activityParticipants[tables.ActivityParticipant{
+			ActivityID: id,
+			ContactID:  contactID,
+		}] = struct{}{}
+	}
+
+	return id
+}
+
+func (p *MemoryPersister) GetActivities(ctx context.Context, contactID int32, namespace string) ([]models.GetActivitiesRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil, nil
+	}
+
+	rows := []models.GetActivitiesRow{}
+	for _, activity := range sortedValues(p.activities, func(activity tables.Activity) bool {
+		return activity.Namespace == namespace && p.isActivityParticipant(activity.ID, contactID)
+	}, func(a, b tables.Activity) bool {
+		return a.ID < b.ID
+	}) {
+		rows = append(rows, models.GetActivitiesRow{
+			ID:          activity.ID,
+			Name:        activity.Name,
+			Date:        activity.Date,
+			Description: activity.Description,
+		})
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) DeleteActivity(ctx context.Context, id int32, contactID int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil
+	}
+
+	if _, ok := p.activity(id, namespace); !ok || !p.isActivityParticipant(id, contactID) {
+		return nil
+	}
+
+	p.deleteActivity(id)
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteActivityIfVersion(ctx context.Context, id, version int32, contactID int32, namespace string) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return false, nil
+	}
+
+	if activity, ok := p.activity(id, namespace); !ok || !p.isActivityParticipant(id, contactID) || activity.Version != version {
+		return false, nil
+	}
+
+	p.deleteActivity(id)
+
+	return true, nil
+}
+
+// deleteActivity deletes an activity and its participants; the caller must
+// hold the lock
+func (p *MemoryPersister) deleteActivity(id int32) {
+	for activityParticipant := range p.activityParticipants {
+		if activityParticipant.ActivityID == id {
+			delete(p.activityParticipants, activityParticipant)
+		}
+	}
+
+	delete(p.activities, id)
+}
+
+func (p *MemoryPersister) GetActivityAndContact(ctx context.Context, id int32, contactID int32, namespace string) (models.GetActivityAndContactRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	contact, ok := p.contact(contactID, namespace)
+	if !ok {
+		return models.GetActivityAndContactRow{}, sql.ErrNoRows
+	}
+
+	activity, ok := p.activity(id, namespace)
+	if !ok || !p.isActivityParticipant(id, contactID) {
+		return models.GetActivityAndContactRow{}, sql.ErrNoRows
+	}
+
+	return models.GetActivityAndContactRow{
+		ActivityID:  activity.ID,
+		Name:        activity.Name,
+		Date:        activity.Date,
+		Description: activity.Description,
+		ContactID:   contact.ID,
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+	}, nil
+}
+
+func (p *MemoryPersister) GetActivityParticipants(ctx context.Context, id int32, namespace string) ([]models.GetActivityParticipantsRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.activity(id, namespace); !ok {
+		return nil, nil
+	}
+
+	rows := []models.GetActivityParticipantsRow{}
+	for _, contact := range p.activityParticipantContacts(id) {
+		rows = append(rows, models.GetActivityParticipantsRow{
+			ID:        contact.ID,
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+		})
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) UpdateActivity(ctx context.Context, id int32, contactID int32, namespace string, name string, date time.Time, description string, contactIDs []int32) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil
+	}
+
+	activity, ok := p.activity(id, namespace)
+	if !ok || !p.isActivityParticipant(id, contactID) {
+		return nil
+	}
+
+	return p.updateActivity(activity, namespace, name, date, description, contactIDs)
+}
+
+func (p *MemoryPersister) UpdateActivityIfVersion(ctx context.Context, id, version int32, contactID int32, namespace string, name string, date time.Time, description string, contactIDs []int32) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return false, nil
+	}
+
+	activity, ok := p.activity(id, namespace)
+	if !ok || !p.isActivityParticipant(id, contactID) || activity.Version != version {
+		return false, nil
+	}
+
+	if err := p.updateActivity(activity, namespace, name, date, description, contactIDs); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// updateActivity updates an activity and replaces its participants if
+// `contactIDs` isn't nil; the caller must hold the lock
+func (p *MemoryPersister) updateActivity(activity tables.Activity, namespace string, name string, date time.Time, description string, contactIDs []int32) error {
+	// All contacts are checked first so that the activity isn't changed if one
+	// of them belongs to another namespace
+	for _, participantID := range contactIDs {
+		if _, ok := p.contact(participantID, namespace); !ok {
+			return sql.ErrNoRows
+		}
+	}
+
+	activity.Name = name
+	activity.Date = date
+	activity.Description = description
+	activity.Version++
+
+	p.activities[activity.ID] = activity
+
+	if contactIDs != nil {
+		for activityParticipant := range p.activityParticipants {
+			if activityParticipant.ActivityID == activity.ID {
+				delete(p.activityParticipants, activityParticipant)
+			}
+		}
+
+		for _, participantID := range contactIDs {
+			p.activityParticipants[tables.ActivityParticipant{
+				ActivityID: activity.ID,
+				ContactID:  participantID,
+			}] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// activityCalendarRows returns an activity once for each of its participants
+// with the participant's first email address; the caller must hold the lock
+func (p *MemoryPersister) activityCalendarRows(activity tables.Activity) []models.GetActivityCalendarRow {
+	rows := []models.GetActivityCalendarRow{}
+	for _, contact := range p.activityParticipantContacts(activity.ID) {
+		email := ""
+		if emails := p.contactDetails(contact.ID).Emails; len(emails) > 0 {
+			email = emails[0].Email
+		}
+
+		rows = append(rows, models.GetActivityCalendarRow{
+			ID:          activity.ID,
+			Name:        activity.Name,
+			Date:        activity.Date,
+			Description: activity.Description,
+			Version:     activity.Version,
+			ContactID:   contact.ID,
+			FirstName:   contact.FirstName,
+			LastName:    contact.LastName,
+			Email:       email,
+		})
+	}
+
+	return rows
+}
+
+func (p *MemoryPersister) GetActivityCalendar(ctx context.Context, id int32, namespace string) ([]models.GetActivityCalendarRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	activity, ok := p.activity(id, namespace)
+	if !ok {
+		return nil, nil
+	}
+
+	return p.activityCalendarRows(activity), nil
+}
+
+func (p *MemoryPersister) GetAppPasswords(ctx context.Context, namespace string) ([]models.GetAppPasswordsRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rows := []models.GetAppPasswordsRow{}
+	for _, appPassword := range sortedValues(p.appPasswords, func(appPassword tables.AppPassword) bool {
+		return appPassword.Namespace == namespace
+	}, func(a, b tables.AppPassword) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}) {
+		rows = append(rows, models.GetAppPasswordsRow{
+			ID:        appPassword.ID,
+			Name:      appPassword.Name,
+			CreatedAt: appPassword.CreatedAt,
+		})
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) CreateAppPassword(ctx context.Context, name, hash, namespace string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	id := p.nextID()
+	p.appPasswords[id] = tables.AppPassword{
+		ID:        id,
+		Name:      name,
+		Hash:      hash,
+		CreatedAt: now(),
+		Namespace: namespace,
+	}
+
+	return id, nil
+}
+
+func (p *MemoryPersister) DeleteAppPassword(ctx context.Context, id int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if appPassword, ok := p.appPasswords[id]; ok && appPassword.Namespace == namespace {
+		delete(p.appPasswords, id)
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) GetAppPasswordNamespace(ctx context.Context, hash string) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, appPassword := range p.appPasswords {
+		if appPassword.Hash == hash {
+			return appPassword.Namespace, nil
+		}
+	}
+
+	return "", sql.ErrNoRows
+}
+
+func (p *MemoryPersister) UpsertCalendarFeed(ctx context.Context, hash, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for id, calendarFeed := range p.calendarFeeds {
+		if calendarFeed.Namespace == namespace {
+			calendarFeed.Hash = hash
+			calendarFeed.CreatedAt = now()
+
+			p.calendarFeeds[id] = calendarFeed
+
+			return nil
+		}
+	}
+
+	id := p.nextID()
+	p.calendarFeeds[id] = tables.CalendarFeed{
+		ID:        id,
+		Hash:      hash,
+		CreatedAt: now(),
+		Namespace: namespace,
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) GetCalendarFeed(ctx context.Context, namespace string) (models.GetCalendarFeedRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, calendarFeed := range p.calendarFeeds {
+		if calendarFeed.Namespace == namespace {
+			return models.GetCalendarFeedRow{
+				ID:        calendarFeed.ID,
+				CreatedAt: calendarFeed.CreatedAt,
+			}, nil
+		}
+	}
+
+	return models.GetCalendarFeedRow{}, sql.ErrNoRows
+}
+
+func (p *MemoryPersister) GetCalendarFeedNamespace(ctx context.Context, hash string) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, calendarFeed := range p.calendarFeeds {
+		if calendarFeed.Hash == hash {
+			return calendarFeed.Namespace, nil
+		}
+	}
+
+	return "", sql.ErrNoRows
+}
+
+func (p *MemoryPersister) DeleteCalendarFeed(ctx context.Context, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for id, calendarFeed := range p.calendarFeeds {
+		if calendarFeed.Namespace == namespace {
+			delete(p.calendarFeeds, id)
+		}
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) GetActivitiesCalendar(ctx context.Context, namespace string) ([]models.GetActivitiesCalendarForNamespaceRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rows := []models.GetActivitiesCalendarForNamespaceRow{}
+	for _, activity := range sortedValues(p.activities, func(activity tables.Activity) bool {
+		return activity.Namespace == namespace
+	}, func(a, b tables.Activity) bool {
+		if !a.Date.Equal(b.Date) {
+			return a.Date.After(b.Date)
+		}
+
+		return a.ID < b.ID
+	}) {
+		for _, row := range p.activityCalendarRows(activity) {
+			rows = append(rows, models.GetActivitiesCalendarForNamespaceRow(row))
+		}
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) GetDAVResources(ctx context.Context, collection, namespace string) ([]models.GetDAVResourcesRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rows := []models.GetDAVResourcesRow{}
+	for _, davResource := range p.davResources {
+		if davResource.Collection == collection && davResource.Namespace == namespace {
+			rows = append(rows, models.GetDAVResourcesRow{
+				Name:       davResource.Name,
+				ResourceID: davResource.ResourceID,
+				Uid:        davResource.Uid,
+			})
+		}
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) GetDAVResource(ctx context.Context, collection, name, namespace string) (models.GetDAVResourceRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	davResource, ok := p.davResources[davResourceKey{collection, name, namespace}]
+	if !ok {
+		return models.GetDAVResourceRow{}, sql.ErrNoRows
+	}
+
+	return models.GetDAVResourceRow{
+		ResourceID: davResource.ResourceID,
+		Uid:        davResource.Uid,
+	}, nil
+}
+
+func (p *MemoryPersister) UpsertDAVResource(ctx context.Context, collection, name string, resourceID int32, uid, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.davResources[davResourceKey{collection, name, namespace}] = tables.DavResource{
+		Collection: collection,
+		Name:       name,
+		ResourceID: resourceID,
+		Uid:        uid,
+		Namespace:  namespace,
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteDAVResources(ctx context.Context, collection string, resourceID int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for key, davResource := range p.davResources {
+		if key.collection == collection && key.namespace == namespace && davResource.ResourceID == resourceID {
+			delete(p.davResources, key)
+		}
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) GetContacts(ctx context.Context, namespace string) ([]models.Contact, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return sortedValues(p.contacts, func(contact tables.Contact) bool {
+		return contact.Namespace == namespace
+	}, func(a, b tables.Contact) bool {
+		return a.FirstName > b.FirstName
+	}), nil
+}
+
+// contactSortKey returns the key `ListContacts` sorts a contact by, in the
+// same format as the SQL persisters; the caller must hold the lock
+func (p *MemoryPersister) contactSortKey(contact tables.Contact, sort string) string {
+	switch sort {
+	case ContactsSortLastName:
+		return contact.LastName
+
+	case ContactsSortBirthday:
+		if !contact.Birthday.Valid {
+			return "99-99"
+		}
+
+		return contact.Birthday.Time.Format("01-02")
+
+	case ContactsSortLastContacted:
+		var lastContacted time.Time
+		for _, activity := range p.activities {
+			if p.isActivityParticipant(activity.ID, contact.ID) && activity.Date.After(lastContacted) {
+				lastContacted = activity.Date
+			}
+		}
+
+		if lastContacted.IsZero() {
+			return ""
+		}
+
+		return lastContacted.Format("2006-01-02T15:04:05")
+
+	default:
+		return contact.FirstName
+	}
+}
+
+func (p *MemoryPersister) ListContacts(ctx context.Context, namespace, sort string, hasDebts bool, birthdayMonth int32, tag, afterKey string, afterID, limit int32) ([]models.ListContactsRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	descending := sort == ContactsSortLastContacted
+
+	// before reports whether a contact with the sort key and ID `a` is listed
+	// before one with `b`
+	before := func(aKey string, aID int32, bKey string, bID int32) bool {
+		if aKey != bKey {
+			return (aKey < bKey) != descending
+		}
+
+		return (aID < bID) != descending
+	}
+
+	rows := []models.ListContactsRow{}
+	for _, contact := range p.contacts {
+		if contact.Namespace != namespace {
+			continue
+		}
+
+		if hasDebts {
+			found := false
+			for _, debt := range p.debts {
+				if debt.ContactID == contact.ID && money.Abs(debt.Amount)-p.debtPaid(debt.ID) > 0 {
+					found = true
+
+					break
+				}
+			}
+
+			if !found {
+				continue
+			}
+		}
+
+		if birthdayMonth != 0 && (!contact.Birthday.Valid || int32(contact.Birthday.Time.Month()) != birthdayMonth) {
+			continue
+		}
+
+		tags := p.contactTagNames(contact.ID)
+		if tag != "" && !slices.Contains(tags, tag) {
+			continue
+		}
+
+		row := models.ListContactsRow{
+			ID:        contact.ID,
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+			Nickname:  contact.Nickname,
+			Pronouns:  contact.Pronouns,
+			Namespace: contact.Namespace,
+			Birthday:  contact.Birthday,
+			Notes:     contact.Notes,
+			Version:   contact.Version,
+			SortKey:   p.contactSortKey(contact, sort),
+			Tags:      strings.Join(tags, ", "),
+		}
+
+		if emails := p.contactDetails(contact.ID).Emails; len(emails) > 0 {
+			row.Email = emails[0].Email
+		}
+
+		if afterID != 0 && !before(afterKey, afterID, row.SortKey, row.ID) {
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	slices.SortFunc(rows, func(a, b models.ListContactsRow) int {
+		if before(a.SortKey, a.ID, b.SortKey, b.ID) {
+			return -1
+		}
+
+		return 1
+	})
+
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+	}
+
+	return rows, nil
+}
+
+// createContact adds a contact; the caller must hold the lock
+func (p *MemoryPersister) createContact(contact models.Contact, namespace string) int32 {
+	contact.ID = p.nextID()
+	contact.Namespace = namespace
+	contact.Version = 1
+
+	p.contacts[contact.ID] = contact
+
+	return contact.ID
+}
+
+func (p *MemoryPersister) CreateContact(ctx context.Context, firstName, lastName, nickname, pronouns, namespace string, details models.ContactDetails) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	id := p.createContact(models.Contact{
+		FirstName: firstName,
+		LastName:  lastName,
+		Nickname:  nickname,
+		Pronouns:  pronouns,
+	}, namespace)
+
+	p.setContactDetails(id, details)
+
+	return id, nil
+}
+
+func (p *MemoryPersister) CreateContacts(ctx context.Context, contacts []models.Contact, details []models.ContactDetails, namespace string) ([]int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ids := []int32{}
+	for i, contact := range contacts {
+		id := p.createContact(models.Contact{
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+			Nickname:  contact.Nickname,
+			Pronouns:  contact.Pronouns,
+			Birthday:  contact.Birthday,
+			Notes:     contact.Notes,
+		}, namespace)
+
+		p.setContactDetails(id, details[i])
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func (p *MemoryPersister) GetContact(ctx context.Context, id int32, namespace string) (models.Contact, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	contact, ok := p.contact(id, namespace)
+	if !ok {
+		return models.Contact{}, sql.ErrNoRows
+	}
+
+	return contact, nil
+}
+
+func (p *MemoryPersister) GetContactByEmail(ctx context.Context, email string, namespace string) (models.Contact, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	contacts := sortedValues(p.contacts, func(contact tables.Contact) bool {
+		if contact.Namespace != namespace {
+			return false
+		}
+
+		for _, contactEmail := range p.contactEmails {
+			if contactEmail.ContactID == contact.ID && strings.EqualFold(contactEmail.Email, email) {
+				return true
+			}
+		}
+
+		return false
+	}, func(a, b tables.Contact) bool {
+		return a.ID < b.ID
+	})
+	if len(contacts) == 0 {
+		return models.Contact{}, sql.ErrNoRows
+	}
+
+	return contacts[0], nil
+}
+
+func (p *MemoryPersister) DeleteContact(ctx context.Context, id int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(id, namespace); !ok {
+		return nil
+	}
+
+	p.deleteContact(id, namespace)
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteContactIfVersion(ctx context.Context, id, version int32, namespace string) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if contact, ok := p.contact(id, namespace); !ok || contact.Version != version {
+		return false, nil
+	}
+
+	p.deleteContact(id, namespace)
+
+	return true, nil
+}
+
+// deleteContact deletes a contact and everything that belongs to it; the
+// caller must hold the lock
+func (p *MemoryPersister) deleteContact(id int32, namespace string) {
+	// Activities are kept for their other participants
+	for activityParticipant := range p.activityParticipants {
+		if activityParticipant.ContactID == id {
+			delete(p.activityParticipants, activityParticipant)
+		}
+	}
+
+	for activityID, activity := range p.activities {
+		if activity.Namespace == namespace && len(p.activityParticipantIDs(activityID)) == 0 {
+			delete(p.activities, activityID)
+		}
+	}
+
+	for debtID, debt := range p.debts {
+		if debt.ContactID == id {
+			p.deleteDebt(debtID)
+		}
+	}
+
+	for contactTag := range p.contactTags {
+		if contactTag.ContactID == id {
+			delete(p.contactTags, contactTag)
+		}
+	}
+
+	p.deleteContactDetails(id)
+
+	for relationshipID, relationship := range p.relationships {
+		if relationship.ContactID == id || relationship.RelatedContactID == id {
+			delete(p.relationships, relationshipID)
+		}
+	}
+
+	for groupMember := range p.groupMembers {
+		if groupMember.ContactID == id {
+			delete(p.groupMembers, groupMember)
+		}
+	}
+
+	delete(p.contacts, id)
+
+	p.deleteUnusedTags(namespace)
+}
+
+func (p *MemoryPersister) UpdateContact(ctx context.Context, id int32, firstName, lastName, nickname, pronouns, namespace string, birthday *time.Time, notes string, details models.ContactDetails) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	contact, ok := p.contact(id, namespace)
+	if !ok {
+		return nil
+	}
+
+	p.updateContact(contact, firstName, lastName, nickname, pronouns, birthday, notes, details)
+
+	return nil
+}
+
+func (p *MemoryPersister) UpdateContactIfVersion(ctx context.Context, id, version int32, firstName, lastName, nickname, pronouns, namespace string, birthday *time.Time, notes string, details models.ContactDetails) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	contact, ok := p.contact(id, namespace)
+	if !ok || contact.Version != version {
+		return false, nil
+	}
+
+	p.updateContact(contact, firstName, lastName, nickname, pronouns, birthday, notes, details)
+
+	return true, nil
+}
+
+// updateContact updates a contact and replaces its details; the caller must
+// hold the lock
+func (p *MemoryPersister) updateContact(contact tables.Contact, firstName, lastName, nickname, pronouns string, birthday *time.Time, notes string, details models.ContactDetails) {
+	contact.FirstName = firstName
+	contact.LastName = lastName
+	contact.Nickname = nickname
+	contact.Pronouns = pronouns
+	contact.Notes = notes
+	contact.Version++
+
+	contact.Birthday = sql.NullTime{}
+	if birthday != nil {
+		contact.Birthday = sql.NullTime{
+			Time:  *birthday,
+			Valid: true,
+		}
+	}
+
+	p.contacts[contact.ID] = contact
+
+	p.deleteContactDetails(contact.ID)
+	p.setContactDetails(contact.ID, details)
+}
+
+// contactDetails returns the details of a contact in the order they were added
+// in; the caller must hold the lock
+func (p *MemoryPersister) contactDetails(contactID int32) models.ContactDetails {
+	return models.ContactDetails{
+		Emails: sortedValues(p.contactEmails, func(email tables.ContactEmail) bool {
+			return email.ContactID == contactID
+		}, func(a, b tables.ContactEmail) bool {
+			return a.ID < b.ID
+		}),
+		Phones: sortedValues(p.contactPhones, func(phone tables.ContactPhone) bool {
+			return phone.ContactID == contactID
+		}, func(a, b tables.ContactPhone) bool {
+			return a.ID < b.ID
+		}),
+		Addresses: sortedValues(p.contactAddresses, func(address tables.ContactAddress) bool {
+			return address.ContactID == contactID
+		}, func(a, b tables.ContactAddress) bool {
+			return a.ID < b.ID
+		}),
+		Websites: sortedValues(p.contactWebsites, func(website tables.ContactWebsite) bool {
+			return website.ContactID == contactID
+		}, func(a, b tables.ContactWebsite) bool {
+			return a.ID < b.ID
+		}),
+	}
+}
+
+// joinedEmails returns the email addresses of a contact separated by spaces,
+// which is how the SQL persisters index them for search
+func joinedEmails(details models.ContactDetails) string {
+	emails := []string{}
+	for _, email := range details.Emails {
+		emails = append(emails, email.Email)
+	}
+
+	return strings.Join(emails, " ")
+}
+
+// deleteContactDetails removes the details of a contact; the caller must hold
+// the lock
+func (p *MemoryPersister) deleteContactDetails(contactID int32) {
+	for id, email := range p.contactEmails {
+		if email.ContactID == contactID {
+			delete(p.contactEmails, id)
+		}
+	}
+
+	for id, phone := range p.contactPhones {
+		if phone.ContactID == contactID {
+			delete(p.contactPhones, id)
+		}
+	}
+
+	for id, address := range p.contactAddresses {
+		if address.ContactID == contactID {
+			delete(p.contactAddresses, id)
+		}
+	}
+
+	for id, website := range p.contactWebsites {
+		if website.ContactID == contactID {
+			delete(p.contactWebsites, id)
+		}
+	}
+}
+
+// setContactDetails adds the details to a contact; the caller must hold the
+// lock
+func (p *MemoryPersister) setContactDetails(contactID int32, details models.ContactDetails) {
+	for _, email := range details.Emails {
+		email.ID = p.nextID()
+		email.ContactID = contactID
+
+		p.contactEmails[email.ID] = email
+	}
+
+	for _, phone := range details.Phones {
+		phone.ID = p.nextID()
+		phone.ContactID = contactID
+
+		p.contactPhones[phone.ID] = phone
+	}
+
+	for _, address := range details.Addresses {
+		address.ID = p.nextID()
+		address.ContactID = contactID
+
+		p.contactAddresses[address.ID] = address
+	}
+
+	for _, website := range details.Websites {
+		website.ID = p.nextID()
+		website.ContactID = contactID
+
+		p.contactWebsites[website.ID] = website
+	}
+}
+
+func (p *MemoryPersister) GetContactDetails(ctx context.Context, contactID int32, namespace string) (models.ContactDetails, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return models.ContactDetails{}, nil
+	}
+
+	return p.contactDetails(contactID), nil
+}
+
+func (p *MemoryPersister) GetContactDetailsForNamespace(ctx context.Context, namespace string) (map[int32]models.ContactDetails, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	details := map[int32]models.ContactDetails{}
+	for _, contact := range p.contacts {
+		if contact.Namespace == namespace {
+			details[contact.ID] = p.contactDetails(contact.ID)
+		}
+	}
+
+	return details, nil
+}
+
+func (p *MemoryPersister) CreateContactRelationship(ctx context.Context, contactID, relatedContactID int32, relationshipType, label string, reciprocal bool, namespace string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if contactID == relatedContactID {
+		return -1, sql.ErrNoRows
+	}
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return -1, sql.ErrNoRows
+	}
+
+	if _, ok := p.contact(relatedContactID, namespace); !ok {
+		return -1, sql.ErrNoRows
+	}
+
+	return p.createContactRelationship(contactID, relatedContactID, relationshipType, label, reciprocal, namespace), nil
+}
+
+// createContactRelationship adds a relationship without checking the contacts;
+// the caller must hold the lock
+func (p *MemoryPersister) createContactRelationship(contactID, relatedContactID int32, relationshipType, label string, reciprocal bool, namespace string) int32 {
+	id := p.nextID()
+	p.relationships[id] = tables.ContactRelationship{
+		ID:               id,
+		Namespace:        namespace,
+		ContactID:        contactID,
+		RelatedContactID: relatedContactID,
+		Type:             relationshipType,
+		Label:            label,
+		Reciprocal:       reciprocal,
+	}
+
+	return id
+}
+
+func (p *MemoryPersister) GetContactRelationships(ctx context.Context, contactID int32, namespace string) ([]models.GetContactRelationshipsRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rows := []models.GetContactRelationshipsRow{}
+	for _, relationship := range sortedValues(p.relationships, func(relationship tables.ContactRelationship) bool {
+		return relationship.Namespace == namespace && (relationship.ContactID == contactID || relationship.RelatedContactID == contactID)
+	}, func(a, b tables.ContactRelationship) bool {
+		return a.ID < b.ID
+	}) {
+		contact, ok := p.contact(relationship.ContactID, namespace)
+		if !ok {
+			continue
+		}
+
+		relatedContact, ok := p.contact(relationship.RelatedContactID, namespace)
+		if !ok {
+			continue
+		}
+
+		rows = append(rows, models.GetContactRelationshipsRow{
+			ID:               relationship.ID,
+			ContactID:        relationship.ContactID,
+			RelatedContactID: relationship.RelatedContactID,
+			Type:             relationship.Type,
+			Label:            relationship.Label,
+			Reciprocal:       relationship.Reciprocal,
+			FirstName:        contact.FirstName,
+			LastName:         contact.LastName,
+			RelatedFirstName: relatedContact.FirstName,
+			RelatedLastName:  relatedContact.LastName,
+		})
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) GetContactRelationshipsForNamespace(ctx context.Context, namespace string) ([]models.ContactRelationship, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.contactRelationships(namespace), nil
+}
+
+// contactRelationships returns the relationships of a namespace in the order
+// they were added in; the caller must hold the lock
+func (p *MemoryPersister) contactRelationships(namespace string) []tables.ContactRelationship {
+	return sortedValues(p.relationships, func(relationship tables.ContactRelationship) bool {
+		return relationship.Namespace == namespace
+	}, func(a, b tables.ContactRelationship) bool {
+		return a.ID < b.ID
+	})
+}
+
+func (p *MemoryPersister) DeleteContactRelationship(ctx context.Context, id int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if relationship, ok := p.relationships[id]; ok && relationship.Namespace == namespace {
+		delete(p.relationships, id)
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) GetContactGroups(ctx context.Context, namespace string) ([]models.GetContactGroupsRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rows := []models.GetContactGroupsRow{}
+	for _, group := range p.contactGroups(namespace) {
+		rows = append(rows, models.GetContactGroupsRow{
+			ID:          group.ID,
+			Name:        group.Name,
+			MemberCount: int64(len(p.contactGroupMemberIDs(group.ID))),
+		})
+	}
+
+	slices.SortStableFunc(rows, func(a, b models.GetContactGroupsRow) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return rows, nil
+}
+
+// contactGroups returns the groups of a namespace in the order they were
+// created in; the caller must hold the lock
+func (p *MemoryPersister) contactGroups(namespace string) []tables.ContactGroup {
+	return sortedValues(p.groups, func(group tables.ContactGroup) bool {
+		return group.Namespace == namespace
+	}, func(a, b tables.ContactGroup) bool {
+		return a.ID < b.ID
+	})
+}
+
+// contactGroupMemberIDs returns the IDs of the members of a group in ascending
+// order; the caller must hold the lock
+func (p *MemoryPersister) contactGroupMemberIDs(groupID int32) []int32 {
+	contactIDs := []int32{}
+	for groupMember := range p.groupMembers {
+		if groupMember.GroupID == groupID {
+			contactIDs = append(contactIDs, groupMember.ContactID)
+		}
+	}
+
+	slices.Sort(contactIDs)
+
+	return contactIDs
+}
+
+func (p *MemoryPersister) CreateContactGroup(ctx context.Context, name, namespace string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.createContactGroup(name, namespace), nil
+}
+
+// createContactGroup adds a group; the caller must hold the lock
+func (p *MemoryPersister) createContactGroup(name, namespace string) int32 {
+	id := p.nextID()
+	p.groups[id] = tables.ContactGroup{
+		ID:        id,
+		Namespace: namespace,
+		Name:      name,
+	}
+
+	return id
+}
+
+func (p *MemoryPersister) GetContactGroup(ctx context.Context, id int32, namespace string) (models.ContactGroup, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	group, ok := p.groups[id]
+	if !ok || group.Namespace != namespace {
+		return models.ContactGroup{}, sql.ErrNoRows
+	}
+
+	return group, nil
+}
+
+func (p *MemoryPersister) UpdateContactGroup(ctx context.Context, id int32, name, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	group, ok := p.groups[id]
+	if !ok || group.Namespace != namespace {
+		return nil
+	}
+
+	group.Name = name
+	p.groups[id] = group
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteContactGroup(ctx context.Context, id int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if group, ok := p.groups[id]; !ok || group.Namespace != namespace {
+		return nil
+	}
+
+	for groupMember := range p.groupMembers {
+		if groupMember.GroupID == id {
+			delete(p.groupMembers, groupMember)
+		}
+	}
+
+	delete(p.groups, id)
+
+	return nil
+}
+
+func (p *MemoryPersister) GetContactGroupMembers(ctx context.Context, groupID int32, namespace string) ([]models.GetContactGroupMembersRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if group, ok := p.groups[groupID]; !ok || group.Namespace != namespace {
+		return nil, nil
+	}
+
+	rows := []models.GetContactGroupMembersRow{}
+	for _, contactID := range p.contactGroupMemberIDs(groupID) {
+		contact, ok := p.contact(contactID, namespace)
+		if !ok {
+			continue
+		}
+
+		rows = append(rows, models.GetContactGroupMembersRow{
+			ID:        contact.ID,
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+		})
+	}
+
+	slices.SortStableFunc(rows, func(a, b models.GetContactGroupMembersRow) int {
+		if c := strings.Compare(a.FirstName, b.FirstName); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.LastName, b.LastName)
+	})
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) CreateContactGroupMember(ctx context.Context, groupID, contactID int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if group, ok := p.groups[groupID]; !ok || group.Namespace != namespace {
+		return nil
+	}
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil
+	}
+
+	p.groupMembers[tables.ContactGroupMember{
+		GroupID:   groupID,
+		ContactID: contactID,
+	}] = struct{}{}
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteContactGroupMember(ctx context.Context, groupID, contactID int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if group, ok := p.groups[groupID]; !ok || group.Namespace != namespace {
+		return nil
+	}
+
+	delete(p.groupMembers, tables.ContactGroupMember{
+		GroupID:   groupID,
+		ContactID: contactID,
+	})
+
+	return nil
+}
+
+func (p *MemoryPersister) CreateDebt(ctx context.Context, amount int64, currency, description string, date time.Time, contactID int32, namespace string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return -1, sql.ErrNoRows
+	}
+
+	return p.createDebt(amount, currency, description, date, contactID), nil
+}
+
+// createDebt adds a debt without checking the contact; the caller must hold
+// the lock
+func (p *MemoryPersister) createDebt(amount int64, currency, description string, date time.Time, contactID int32) int32 {
+	id := p.nextID()
+	p.debts[id] = tables.Debt{
+		ID:          id,
+		Amount:      amount,
+		Currency:    currency,
+		ContactID:   contactID,
+		Description: description,
+		Date:        date,
+	}
+
+	return id
+}
+
+// createDebtPayment adds a payment without checking the debt; the caller must
+// hold the lock
+func (p *MemoryPersister) createDebtPayment(debtID int32, amount int64, date time.Time, description string) int32 {
+	id := p.nextID()
+	p.debtPayments[id] = tables.DebtPayment{
+		ID:          id,
+		DebtID:      debtID,
+		Amount:      amount,
+		Date:        date,
+		Description: description,
+	}
+
+	return id
+}
+
+// debtPaid returns the sum of the payments of a debt; the caller must hold the
+// lock
+func (p *MemoryPersister) debtPaid(debtID int32) int64 {
+	paid := int64(0)
+	for _, payment := range p.debtPayments {
+		if payment.DebtID == debtID {
+			paid += payment.Amount
+		}
+	}
+
+	return paid
+}
+
+// deleteDebt removes a debt and its payments; the caller must hold the lock
+func (p *MemoryPersister) deleteDebt(id int32) {
+	for paymentID, payment := range p.debtPayments {
+		if payment.DebtID == id {
+			delete(p.debtPayments, paymentID)
+		}
+	}
+
+	delete(p.debts, id)
+}
+
+// sortedDebtPayments returns the payments of the debts matching `keep`, oldest
+// first; the caller must hold the lock
+func (p *MemoryPersister) sortedDebtPayments(keep func(debt tables.Debt) bool) []tables.DebtPayment {
+	return sortedValues(p.debtPayments, func(payment tables.DebtPayment) bool {
+		debt, ok := p.debts[payment.DebtID]
+
+		return ok && keep(debt)
+	}, func(a, b tables.DebtPayment) bool {
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+
+		return a.ID < b.ID
+	})
+}
+
+func (p *MemoryPersister) CreateDebts(ctx context.Context, amounts []int64, currency, description string, date time.Time, contactIDs []int32, namespace string) ([]int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// All contacts are checked first so that no debts are added if one of them
+	// belongs to another namespace
+	for _, contactID := range contactIDs {
+		if _, ok := p.contact(contactID, namespace); !ok {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	ids := []int32{}
+	for i, contactID := range contactIDs {
+		ids = append(ids, p.createDebt(amounts[i], currency, description, date, contactID))
+	}
+
+	return ids, nil
+}
+
+func (p *MemoryPersister) GetDebts(ctx context.Context, contactID int32, namespace string) ([]models.GetDebtsRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil, nil
+	}
+
+	rows := []models.GetDebtsRow{}
+	for _, debt := range sortedValues(p.debts, func(debt tables.Debt) bool {
+		return debt.ContactID == contactID
+	}, func(a, b tables.Debt) bool {
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+
+		return a.ID < b.ID
+	}) {
+		rows = append(rows, models.GetDebtsRow{
+			ID:          debt.ID,
+			Amount:      debt.Amount,
+			Currency:    debt.Currency,
+			Description: debt.Description,
+			Date:        debt.Date,
+			Paid:        p.debtPaid(debt.ID),
+		})
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) GetDebtPayments(ctx context.Context, contactID int32, namespace string) ([]models.DebtPayment, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil, nil
+	}
+
+	return p.sortedDebtPayments(func(debt tables.Debt) bool {
+		return debt.ContactID == contactID
+	}), nil
+}
+
+func (p *MemoryPersister) SettleDebt(ctx context.Context, id int32, contactID int32, namespace string, amount int64, date time.Time, description string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return -1, sql.ErrNoRows
+	}
+
+	debt, ok := p.debts[id]
+	if !ok || debt.ContactID != contactID {
+		return -1, sql.ErrNoRows
+	}
+
+	return p.createDebtPayment(id, amount, date, description), nil
+}
+
+func (p *MemoryPersister) GetDebtAndContact(ctx context.Context, id int32, contactID int32, namespace string) (models.GetDebtAndContactRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	contact, ok := p.contact(contactID, namespace)
+	if !ok {
+		return models.GetDebtAndContactRow{}, sql.ErrNoRows
+	}
+
+	debt, ok := p.debts[id]
+	if !ok || debt.ContactID != contactID {
+		return models.GetDebtAndContactRow{}, sql.ErrNoRows
+	}
+
+	return models.GetDebtAndContactRow{
+		DebtID:      debt.ID,
+		Amount:      debt.Amount,
+		Currency:    debt.Currency,
+		Description: debt.Description,
+		Date:        debt.Date,
+		Paid:        p.debtPaid(debt.ID),
+		ContactID:   contact.ID,
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+	}, nil
+}
+
+func (p *MemoryPersister) UpdateDebt(ctx context.Context, id int32, contactID int32, namespace string, amount int64, currency, description string, date time.Time) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil
+	}
+
+	debt, ok := p.debts[id]
+	if !ok || debt.ContactID != contactID {
+		return nil
+	}
+
+	debt.Amount = amount
+	debt.Currency = currency
+	debt.Description = description
+	debt.Date = date
+
+	p.debts[id] = debt
+
+	return nil
+}
+
+func (p *MemoryPersister) GetJournalEntries(ctx context.Context, namespace string) ([]models.JournalEntry, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return sortedValues(p.journalEntries, func(journalEntry tables.JournalEntry) bool {
+		return journalEntry.Namespace == namespace
+	}, func(a, b tables.JournalEntry) bool {
+		return a.Date.After(b.Date)
+	}), nil
+}
+
+func (p *MemoryPersister) ListJournalEntries(ctx context.Context, namespace string, oldestFirst bool, rating int32, from, to *time.Time, tag string, afterDate time.Time, afterID, limit int32) ([]models.ListJournalEntriesRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// before reports whether an entry with the date and ID `a` is listed before
+	// one with `b`
+	before := func(aDate time.Time, aID int32, bDate time.Time, bID int32) bool {
+		if !aDate.Equal(bDate) {
+			return aDate.Before(bDate) == oldestFirst
+		}
+
+		return (aID < bID) == oldestFirst
+	}
+
+	journalEntries := sortedValues(p.journalEntries, func(journalEntry tables.JournalEntry) bool {
+		return journalEntry.Namespace == namespace &&
+			(rating == 0 || journalEntry.Rating == rating) &&
+			(from == nil || !journalEntry.Date.Before(*from)) &&
+			(to == nil || journalEntry.Date.Before(*to)) &&
+			(tag == "" || slices.Contains(p.journalEntryTagNames(journalEntry.ID), tag)) &&
+			(afterID == 0 || before(afterDate, afterID, journalEntry.Date, journalEntry.ID))
+	}, func(a, b tables.JournalEntry) bool {
+		return before(a.Date, a.ID, b.Date, b.ID)
+	})
+
+	if len(journalEntries) > int(limit) {
+		journalEntries = journalEntries[:limit]
+	}
+
+	rows := []models.ListJournalEntriesRow{}
+	for _, journalEntry := range journalEntries {
+		rows = append(rows, models.ListJournalEntriesRow{
+			ID:        journalEntry.ID,
+			Title:     journalEntry.Title,
+			Date:      journalEntry.Date,
+			Body:      journalEntry.Body,
+			Rating:    journalEntry.Rating,
+			Namespace: journalEntry.Namespace,
+			Tags:      strings.Join(p.journalEntryTagNames(journalEntry.ID), ", "),
+		})
+	}
+
+	return rows, nil
+}
+
+// createJournalEntry adds a journal entry; the caller must hold the lock
+func (p *MemoryPersister) createJournalEntry(title, body string, rating int32, namespace string) int32 {
+	id := p.nextID()
+	p.journalEntries[id] = tables.JournalEntry{
+		ID:        id,
+		Title:     title,
+		Date:      now(),
+		Body:      body,
+		Rating:    rating,
+		Namespace: namespace,
+	}
+
+	return id
+}
+
+func (p *MemoryPersister) CreateJournalEntry(ctx context.Context, title, body string, rating int32, namespace string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.createJournalEntry(title, body, rating, namespace), nil
+}
+
+func (p *MemoryPersister) DeleteJournalEntry(ctx context.Context, id int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if journalEntry, ok := p.journalEntries[id]; ok && journalEntry.Namespace == namespace {
+		for journalEntryTag := range p.journalEntryTags {
+			if journalEntryTag.JournalEntryID == id {
+				delete(p.journalEntryTags, journalEntryTag)
+			}
+		}
+
+		delete(p.journalEntries, id)
+
+		p.deleteUnusedTags(namespace)
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) GetJournalEntry(ctx context.Context, id int32, namespace string) (models.JournalEntry, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	journalEntry, ok := p.journalEntries[id]
+	if !ok || journalEntry.Namespace != namespace {
+		return models.JournalEntry{}, sql.ErrNoRows
+	}
+
+	return journalEntry, nil
+}
+
+func (p *MemoryPersister) UpdateJournalEntry(ctx context.Context, id int32, title, body string, rating int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	journalEntry, ok := p.journalEntries[id]
+	if !ok || journalEntry.Namespace != namespace {
+		return nil
+	}
+
+	journalEntry.Title = title
+	journalEntry.Body = body
+	journalEntry.Rating = rating
+
+	p.journalEntries[id] = journalEntry
+
+	return nil
+}
+
+func (p *MemoryPersister) GetPersonalAccessTokens(ctx context.Context, namespace string) ([]models.GetPersonalAccessTokensRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rows := []models.GetPersonalAccessTokensRow{}
+	for _, personalAccessToken := range sortedValues(p.personalAccessTokens, func(personalAccessToken tables.PersonalAccessToken) bool {
+		return personalAccessToken.Namespace == namespace
+	}, func(a, b tables.PersonalAccessToken) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}) {
+		rows = append(rows, models.GetPersonalAccessTokensRow{
+			ID:        personalAccessToken.ID,
+			Name:      personalAccessToken.Name,
+			Scopes:    personalAccessToken.Scopes,
+			CreatedAt: personalAccessToken.CreatedAt,
+			ExpiresAt: personalAccessToken.ExpiresAt,
+		})
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) CreatePersonalAccessToken(ctx context.Context, name, hash, scopes string, expiresAt *time.Time, namespace string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var expiresAtTime sql.NullTime
+	if expiresAt != nil {
+		expiresAtTime = sql.NullTime{
+			Time:  *expiresAt,
+			Valid: true,
+		}
+	}
+
+	id := p.nextID()
+	p.personalAccessTokens[id] = tables.PersonalAccessToken{
+		ID:        id,
+		Name:      name,
+		Hash:      hash,
+		Scopes:    scopes,
+		CreatedAt: now(),
+		ExpiresAt: expiresAtTime,
+		Namespace: namespace,
+	}
+
+	return id, nil
+}
+
+func (p *MemoryPersister) DeletePersonalAccessToken(ctx context.Context, id int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if personalAccessToken, ok := p.personalAccessTokens[id]; ok && personalAccessToken.Namespace == namespace {
+		delete(p.personalAccessTokens, id)
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) GetPersonalAccessToken(ctx context.Context, hash string) (models.GetPersonalAccessTokenRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, personalAccessToken := range p.personalAccessTokens {
+		if personalAccessToken.Hash == hash {
+			return models.GetPersonalAccessTokenRow{
+				Namespace: personalAccessToken.Namespace,
+				Scopes:    personalAccessToken.Scopes,
+				ExpiresAt: personalAccessToken.ExpiresAt,
+			}, nil
+		}
+	}
+
+	return models.GetPersonalAccessTokenRow{}, sql.ErrNoRows
+}
+
+func (p *MemoryPersister) GetSessions(ctx context.Context, namespace string) ([]models.GetSessionsRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rows := []models.GetSessionsRow{}
+	for _, session := range sortedValues(p.sessions, func(session tables.Session) bool {
+		return session.Namespace == namespace
+	}, func(a, b tables.Session) bool {
+		return a.LastSeenAt.After(b.LastSeenAt)
+	}) {
+		rows = append(rows, models.GetSessionsRow{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			Ip:         session.Ip,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		})
+	}
+
+	return rows, nil
+}
+
+func (p *MemoryPersister) CreateSession(ctx context.Context, hash, refreshToken, idToken, userAgent, ip, subject, oidcSessionID, namespace string) (int32, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	id := p.nextID()
+	p.sessions[id] = tables.Session{
+		ID:            id,
+		Hash:          hash,
+		RefreshToken:  refreshToken,
+		IDToken:       idToken,
+		UserAgent:     userAgent,
+		Ip:            ip,
+		CreatedAt:     now(),
+		LastSeenAt:    now(),
+		Namespace:     namespace,
+		Subject:       subject,
+		OidcSessionID: oidcSessionID,
+	}
+
+	return id, nil
+}
+
+func (p *MemoryPersister) GetSession(ctx context.Context, hash string) (models.GetSessionRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, session := range p.sessions {
+		if session.Hash == hash {
+			return models.GetSessionRow{
+				ID:           session.ID,
+				RefreshToken: session.RefreshToken,
+				IDToken:      session.IDToken,
+				Namespace:    session.Namespace,
+			}, nil
+		}
+	}
+
+	return models.GetSessionRow{}, sql.ErrNoRows
+}
+
+func (p *MemoryPersister) UpdateSessionTokens(ctx context.Context, id int32, refreshToken, idToken string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	session, ok := p.sessions[id]
+	if !ok {
+		return nil
+	}
+
+	session.RefreshToken = refreshToken
+	session.IDToken = idToken
+
+	p.sessions[id] = session
+
+	return nil
+}
+
+func (p *MemoryPersister) UpdateSessionLastSeen(ctx context.Context, id int32, ip string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	session, ok := p.sessions[id]
+	if !ok {
+		return nil
+	}
+
+	session.LastSeenAt = now()
+	session.Ip = ip
+
+	p.sessions[id] = session
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteSession(ctx context.Context, id int32, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if session, ok := p.sessions[id]; ok && session.Namespace == namespace {
+		delete(p.sessions, id)
+	}
+
+	return nil
+}
+
+// deleteSessions deletes all sessions that match; the caller must hold the lock
+func (p *MemoryPersister) deleteSessions(match func(session tables.Session) bool) {
+	for id, session := range p.sessions {
+		if match(session) {
+			delete(p.sessions, id)
+		}
+	}
+}
+
+func (p *MemoryPersister) DeleteSessions(ctx context.Context, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.deleteSessions(func(session tables.Session) bool {
+		return session.Namespace == namespace
+	})
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteSessionsForOIDCSession(ctx context.Context, oidcSessionID string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.deleteSessions(func(session tables.Session) bool {
+		return session.OidcSessionID == oidcSessionID
+	})
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteSessionsForSubject(ctx context.Context, subject string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.deleteSessions(func(session tables.Session) bool {
+		return session.Subject == subject
+	})
+
+	return nil
+}
+
+func (p *MemoryPersister) GetTags(ctx context.Context, namespace string) ([]models.Tag, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return sortedValues(p.tags, func(tag tables.Tag) bool {
+		return tag.Namespace == namespace
+	}, func(a, b tables.Tag) bool {
+		return a.Name < b.Name
+	}), nil
+}
+
+// upsertTag returns the ID of the tag with a name, creating it if it doesn't
+// exist yet; the caller must hold the lock
+func (p *MemoryPersister) upsertTag(name, namespace string) int32 {
+	for _, tag := range p.tags {
+		if tag.Namespace == namespace && tag.Name == name {
+			return tag.ID
+		}
+	}
+
+	id := p.nextID()
+	p.tags[id] = tables.Tag{
+		ID:        id,
+		Name:      name,
+		Namespace: namespace,
+	}
+
+	return id
+}
+
+// deleteUnusedTags removes the tags which are no longer used by any contact or
+// journal entry; the caller must hold the lock
+func (p *MemoryPersister) deleteUnusedTags(namespace string) {
+	used := map[int32]struct{}{}
+	for contactTag := range p.contactTags {
+		used[contactTag.TagID] = struct{}{}
+	}
+
+	for journalEntryTag := range p.journalEntryTags {
+		used[journalEntryTag.TagID] = struct{}{}
+	}
+
+	for id, tag := range p.tags {
+		if _, ok := used[id]; !ok && tag.Namespace == namespace {
+			delete(p.tags, id)
+		}
+	}
+}
+
+// contactTagNames returns the sorted names of a contact's tags; the caller must
+// hold the lock
+func (p *MemoryPersister) contactTagNames(contactID int32) []string {
+	names := []string{}
+	for contactTag := range p.contactTags {
+		if contactTag.ContactID == contactID {
+			names = append(names, p.tags[contactTag.TagID].Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// setContactTags links a contact to the tags with the names in `tags`; the
+// caller must hold the lock
+func (p *MemoryPersister) setContactTags(contactID int32, tags []string, namespace string) {
+	for _, tag := range tags {
+		p.contactTags[tables.ContactTag{
+			ContactID: contactID,
+			TagID:     p.upsertTag(tag, namespace),
+		}] = struct{}{}
+	}
+}
+
+func (p *MemoryPersister) GetContactTags(ctx context.Context, contactID int32, namespace string) ([]string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil, nil
+	}
+
+	return p.contactTagNames(contactID), nil
+}
+
+func (p *MemoryPersister) UpdateContactTags(ctx context.Context, contactID int32, tags []string, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.contact(contactID, namespace); !ok {
+		return nil
+	}
+
+	for contactTag := range p.contactTags {
+		if contactTag.ContactID == contactID {
+			delete(p.contactTags, contactTag)
+		}
+	}
+
+	p.setContactTags(contactID, tags, namespace)
+
+	p.deleteUnusedTags(namespace)
+
+	return nil
+}
+
+// journalEntryTagNames returns the sorted names of a journal entry's tags; the
+// caller must hold the lock
+func (p *MemoryPersister) journalEntryTagNames(journalEntryID int32) []string {
+	names := []string{}
+	for journalEntryTag := range p.journalEntryTags {
+		if journalEntryTag.JournalEntryID == journalEntryID {
+			names = append(names, p.tags[journalEntryTag.TagID].Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// setJournalEntryTags links a journal entry to the tags with the names in
+// `tags`; the caller must hold the lock
+func (p *MemoryPersister) setJournalEntryTags(journalEntryID int32, tags []string, namespace string) {
+	for _, tag := range tags {
+		p.journalEntryTags[tables.JournalEntryTag{
+			JournalEntryID: journalEntryID,
+			TagID:          p.upsertTag(tag, namespace),
+		}] = struct{}{}
+	}
+}
+
+func (p *MemoryPersister) GetJournalEntryTags(ctx context.Context, journalEntryID int32, namespace string) ([]string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if journalEntry, ok := p.journalEntries[journalEntryID]; !ok || journalEntry.Namespace != namespace {
+		return nil, nil
+	}
+
+	return p.journalEntryTagNames(journalEntryID), nil
+}
+
+func (p *MemoryPersister) UpdateJournalEntryTags(ctx context.Context, journalEntryID int32, tags []string, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if journalEntry, ok := p.journalEntries[journalEntryID]; !ok || journalEntry.Namespace != namespace {
+		return nil
+	}
+
+	for journalEntryTag := range p.journalEntryTags {
+		if journalEntryTag.JournalEntryID == journalEntryID {
+			delete(p.journalEntryTags, journalEntryTag)
+		}
+	}
+
+	p.setJournalEntryTags(journalEntryID, tags, namespace)
+
+	p.deleteUnusedTags(namespace)
+
+	return nil
+}
+
+// UpdateSearchConfig does nothing, since the memory persister matches queries
+// without a text search configuration
+func (p *MemoryPersister) UpdateSearchConfig(ctx context.Context, config, namespace string) error {
+	return nil
+}
+
+// Search matches the query as a case-insensitive phrase like the SQLite
+// persister; the documents are built from the records the same way the
+// triggers of the SQL persisters build them
+func (p *MemoryPersister) Search(ctx context.Context, query string, limit int32, namespace string) ([]models.SearchDocumentsRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	documents := []models.SearchDocumentsRow{}
+	addDocument := func(entityName string, entityID, contactID int32, title, body string) {
+		document := models.SearchDocumentsRow{
+			EntityName:    entityName,
+			EntityID:      entityID,
+			ContactID:     sql.NullInt32{Int32: contactID, Valid: contactID != 0},
+			TitleHeadline: highlightSubstring(title, query),
+			BodyHeadline:  highlightSubstring(body, query),
+		}
+
+		if document.TitleHeadline != title {
+			document.Rank += 2
+		}
+
+		if document.BodyHeadline != body {
+			document.Rank++
+		}
+
+		if document.Rank > 0 {
+			documents = append(documents, document)
+		}
+	}
+
+	for _, journalEntry := range p.journalEntries {
+		if journalEntry.Namespace == namespace {
+			addDocument("journalEntry", journalEntry.ID, 0, journalEntry.Title, journalEntry.Body)
+		}
+	}
+
+	for _, contact := range p.contacts {
+		if contact.Namespace == namespace {
+			addDocument(
+				"contact",
+				contact.ID,
+				contact.ID,
+				strings.TrimSpace(contact.FirstName+" "+contact.LastName+" "+contact.Nickname),
+				strings.TrimSpace(joinedEmails(p.contactDetails(contact.ID))+" "+contact.Notes),
+			)
+		}
+	}
+
+	for _, debt := range p.debts {
+		if _, ok := p.contact(debt.ContactID, namespace); ok {
+			addDocument("debt", debt.ID, debt.ContactID, debt.Description, "")
+		}
+	}
+
+	// Activities link to their first participant like in the SQL persisters
+	for _, activity := range p.activities {
+		if activity.Namespace != namespace {
+			continue
+		}
+
+		if contactIDs := p.activityParticipantIDs(activity.ID); len(contactIDs) > 0 {
+			addDocument("activity", activity.ID, contactIDs[0], activity.Name, activity.Description)
+		}
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		if documents[i].Rank != documents[j].Rank {
+			return documents[i].Rank > documents[j].Rank
+		}
+
+		return documents[i].EntityID > documents[j].EntityID
+	})
+
+	if len(documents) > int(limit) {
+		documents = documents[:limit]
+	}
+
+	return documents, nil
+}
+
+func (p *MemoryPersister) CreateStagedUserData(ctx context.Context, hash string, data []byte, expiresAt time.Time, limit int64, namespace string) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var count int64
+	for h, stagedUserData := range p.stagedUserData {
+		if !stagedUserData.ExpiresAt.After(now()) {
+			delete(p.stagedUserData, h)
+
+			continue
+		}
+
+		if stagedUserData.Namespace == namespace {
+			count++
+		}
+	}
+
+	if count >= limit {
+		return false, nil
+	}
+
+	p.stagedUserData[hash] = tables.StagedUserDatum{
+		Hash:      hash,
+		Data:      append([]byte{}, data...),
+		ExpiresAt: expiresAt,
+		Namespace: namespace,
+	}
+
+	return true, nil
+}
+
+func (p *MemoryPersister) GetStagedUserData(ctx context.Context, hash, namespace string) (models.GetStagedUserDataRow, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	stagedUserData, ok := p.stagedUserData[hash]
+	if !ok || stagedUserData.Namespace != namespace {
+		return models.GetStagedUserDataRow{}, sql.ErrNoRows
+	}
+
+	return models.GetStagedUserDataRow{
+		Data:      stagedUserData.Data,
+		ExpiresAt: stagedUserData.ExpiresAt,
+	}, nil
+}
+
+func (p *MemoryPersister) DeleteStagedUserData(ctx context.Context, hash, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if stagedUserData, ok := p.stagedUserData[hash]; ok && stagedUserData.Namespace == namespace {
+		delete(p.stagedUserData, hash)
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) GetUserData(
+	ctx context.Context,
+
+	namespace string,
+
+	onManifest func(manifest models.ExportedManifest) error,
+	onJournalEntry func(journalEntry models.ExportedJournalEntry) error,
+	onContact func(contact models.ExportedContact) error,
+	onDebt func(debt models.ExportedDebt) error,
+	onActivity func(activity models.ExportedActivity) error,
+	onRelationship func(relationship models.ExportedRelationship) error,
+	onGroup func(group models.ExportedGroup) error,
+) error {
+	// The records are copied so that the callbacks, which might be slow, don't
+	// have to hold the lock
+	p.lock.Lock()
+
+	journalEntries := sortedValues(p.journalEntries, func(journalEntry tables.JournalEntry) bool {
+		return journalEntry.Namespace == namespace
+	}, func(a, b tables.JournalEntry) bool {
+		return a.Date.After(b.Date)
+	})
+
+	contacts := sortedValues(p.contacts, func(contact tables.Contact) bool {
+		return contact.Namespace == namespace
+	}, func(a, b tables.Contact) bool {
+		return a.FirstName > b.FirstName
+	})
+
+	debts := sortedValues(p.debts, func(debt tables.Debt) bool {
+		_, ok := p.contact(debt.ContactID, namespace)
+
+		return ok
+	}, func(a, b tables.Debt) bool {
+		return a.ID < b.ID
+	})
+
+	debtPayments := map[int32][]models.DebtPayment{}
+	for _, payment := range p.sortedDebtPayments(func(debt tables.Debt) bool {
+		_, ok := p.contact(debt.ContactID, namespace)
+
+		return ok
+	}) {
+		debtPayments[payment.DebtID] = append(debtPayments[payment.DebtID], payment)
+	}
+
+	activities := sortedValues(p.activities, func(activity tables.Activity) bool {
+		return activity.Namespace == namespace
+	}, func(a, b tables.Activity) bool {
+		return a.ID < b.ID
+	})
+
+	activityParticipants := map[int32][]int32{}
+	for _, activity := range activities {
+		activityParticipants[activity.ID] = p.activityParticipantIDs(activity.ID)
+	}
+
+	relationships := p.contactRelationships(namespace)
+
+	groups := p.contactGroups(namespace)
+
+	groupMembers := map[int32][]int32{}
+	for _, group := range groups {
+		groupMembers[group.ID] = p.contactGroupMemberIDs(group.ID)
+	}
+
+	journalEntryTags := map[int32][]string{}
+	for _, journalEntry := range journalEntries {
+		journalEntryTags[journalEntry.ID] = p.journalEntryTagNames(journalEntry.ID)
+	}
+
+	contactTags := map[int32][]string{}
+	contactDetails := map[int32]models.ContactDetails{}
+	for _, contact := range contacts {
+		contactTags[contact.ID] = p.contactTagNames(contact.ID)
+		contactDetails[contact.ID] = p.contactDetails(contact.ID)
+	}
+
+	p.lock.Unlock()
+
+	if err := onManifest(models.ExportedManifest{
+		Counts: models.ExportedCounts{
+			JournalEntries: len(journalEntries),
+			Contacts:       len(contacts),
+			Debts:          len(debts),
+			Activities:     len(activities),
+			Relationships:  len(relationships),
+			Groups:         len(groups),
+		},
+	}); err != nil {
+		return err
+	}
+
+	for _, journalEntry := range journalEntries {
+		if err := onJournalEntry(models.ExportedJournalEntry{
+			ID:        journalEntry.ID,
+			Title:     journalEntry.Title,
+			Date:      journalEntry.Date,
+			Body:      journalEntry.Body,
+			Rating:    journalEntry.Rating,
+			Namespace: journalEntry.Namespace,
+			Tags:      journalEntryTags[journalEntry.ID],
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, contact := range contacts {
+		if err := onContact(withExportedContactDetails(models.ExportedContact{
+			ID:        contact.ID,
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+			Nickname:  contact.Nickname,
+			Pronouns:  contact.Pronouns,
+			Namespace: contact.Namespace,
+			Birthday:  contact.Birthday,
+			Notes:     contact.Notes,
+			Tags:      contactTags[contact.ID],
+		}, contactDetails[contact.ID])); err != nil {
+			return err
+		}
+	}
+
+	for _, debt := range debts {
+		if err := onDebt(models.ExportedDebt{
+			ID:          debt.ID,
+			Amount:      json.Number(money.FormatAmount(debt.Amount, debt.Currency)),
+			Currency:    debt.Currency,
+			Description: debt.Description,
+			Date:        debt.Date,
+			ContactID: sql.NullInt32{
+				Int32: debt.ContactID,
+				Valid: true,
+			},
+			Payments: exportedDebtPayments(debtPayments[debt.ID], debt.Currency),
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, activity := range activities {
+		var contactID sql.NullInt32
+		if participants := activityParticipants[activity.ID]; len(participants) > 0 {
+			contactID = sql.NullInt32{
+				Int32: participants[0],
+				Valid: true,
+			}
+		}
+
+		if err := onActivity(models.ExportedActivity{
+			ID:          activity.ID,
+			Name:        activity.Name,
+			Date:        activity.Date,
+			Description: activity.Description,
+			ContactID:   contactID,
+			ContactIDs:  activityParticipants[activity.ID],
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, relationship := range relationships {
+		if err := onRelationship(models.ExportedRelationship{
+			ID: relationship.ID,
+			ContactID: sql.NullInt32{
+				Int32: relationship.ContactID,
+				Valid: true,
+			},
+			RelatedContactID: sql.NullInt32{
+				Int32: relationship.RelatedContactID,
+				Valid: true,
+			},
+			Type:       relationship.Type,
+			Label:      relationship.Label,
+			Reciprocal: relationship.Reciprocal,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range groups {
+		if err := onGroup(models.ExportedGroup{
+			ID:         group.ID,
+			Name:       group.Name,
+			ContactIDs: groupMembers[group.ID],
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *MemoryPersister) DeleteUserData(ctx context.Context, namespace string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for id, tag := range p.tags {
+		if tag.Namespace != namespace {
+			continue
+		}
+
+		for contactTag := range p.contactTags {
+			if contactTag.TagID == id {
+				delete(p.contactTags, contactTag)
+			}
+		}
+
+		for journalEntryTag := range p.journalEntryTags {
+			if journalEntryTag.TagID == id {
+				delete(p.journalEntryTags, journalEntryTag)
+			}
+		}
+
+		delete(p.tags, id)
+	}
+
+	for id, relationship := range p.relationships {
+		if relationship.Namespace == namespace {
+			delete(p.relationships, id)
+		}
+	}
+
+	for id, group := range p.groups {
+		if group.Namespace != namespace {
+			continue
+		}
+
+		for groupMember := range p.groupMembers {
+			if groupMember.GroupID == id {
+				delete(p.groupMembers, groupMember)
+			}
+		}
+
+		delete(p.groups, id)
+	}
+
+	for id, activity := range p.activities {
+		if activity.Namespace == namespace {
+			p.deleteActivity(id)
+		}
+	}
+
+	for id, debt := range p.debts {
+		if _, ok := p.contact(debt.ContactID, namespace); ok {
+			p.deleteDebt(id)
+		}
+	}
+
+	for id, contact := range p.contacts {
+		if contact.Namespace == namespace {
+			p.deleteContactDetails(id)
+
+			delete(p.contacts, id)
+		}
+	}
+
+	for id, journalEntry := range p.journalEntries {
+		if journalEntry.Namespace == namespace {
+			delete(p.journalEntries, id)
+		}
+	}
+
+	for id, appPassword := range p.appPasswords {
+		if appPassword.Namespace == namespace {
+			delete(p.appPasswords, id)
+		}
+	}
+
+	for id, calendarFeed := range p.calendarFeeds {
+		if calendarFeed.Namespace == namespace {
+			delete(p.calendarFeeds, id)
+		}
+	}
+
+	for id, personalAccessToken := range p.personalAccessTokens {
+		if personalAccessToken.Namespace == namespace {
+			delete(p.personalAccessTokens, id)
+		}
+	}
+
+	p.deleteSessions(func(session tables.Session) bool {
+		return session.Namespace == namespace
+	})
+
+	for hash, stagedUserData := range p.stagedUserData {
+		if stagedUserData.Namespace == namespace {
+			delete(p.stagedUserData, hash)
+		}
+	}
+
+	for key := range p.davResources {
+		if key.namespace == namespace {
+			delete(p.davResources, key)
+		}
+	}
+
+	return nil
+}
+
+// CreateUserData stages all records and only adds them once `commit` is called,
+// which has the same effect as the transaction the SQL persisters use
+func (p *MemoryPersister) CreateUserData(ctx context.Context, stagedUserDataHash, namespace string) (
+	createJournalEntry func(journalEntry models.ExportedJournalEntry) error,
+	createContact func(contact models.ExportedContact) error,
+	createDebt func(debt models.ExportedDebt) error,
+	createActivity func(activty models.ExportedActivity) error,
+	createRelationship func(relationship models.ExportedRelationship) error,
+	createGroup func(group models.ExportedGroup) error,
+
+	commit func() error,
+	rollback func() error,
+
+	err error,
+) {
+	p.lock.Lock()
+	if stagedUserData, ok := p.stagedUserData[stagedUserDataHash]; !ok || stagedUserData.Namespace != namespace {
+		p.lock.Unlock()
+
+		err = sql.ErrNoRows
+
+		return
+	}
+	p.lock.Unlock()
+
+	var (
+		pendingLock sync.Mutex
+		done        bool
+
+		pendingJournalEntries = []models.ExportedJournalEntry{}
+		pendingContacts       = []models.ExportedContact{}
+		pendingDebts          = []models.ExportedDebt{}
+		pendingActivities     = []models.ExportedActivity{}
+		pendingRelationships  = []models.ExportedRelationship{}
+		pendingGroups         = []models.ExportedGroup{}
+	)
+
+	createJournalEntry = func(journalEntry models.ExportedJournalEntry) error {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+
+		pendingJournalEntries = append(pendingJournalEntries, journalEntry)
+
+		return nil
+	}
+
+	createContact = func(contact models.ExportedContact) error {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+
+		pendingContacts = append(pendingContacts, contact)
+
+		return nil
+	}
+
+	createDebt = func(debt models.ExportedDebt) error {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+
+		pendingDebts = append(pendingDebts, debt)
+
+		return nil
+	}
+
+	createActivity = func(activity models.ExportedActivity) error {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+
+		pendingActivities = append(pendingActivities, activity)
+
+		return nil
+	}
+
+	createRelationship = func(relationship models.ExportedRelationship) error {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+
+		pendingRelationships = append(pendingRelationships, relationship)
+
+		return nil
+	}
+
+	createGroup = func(group models.ExportedGroup) error {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+
+		pendingGroups = append(pendingGroups, group)
+
+		return nil
+	}
+
+	commit = func() error {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+
+		if done {
+			return sql.ErrTxDone
+		}
+		done = true
+
+		contactIDs := map[int32]struct{}{}
+		for _, contact := range pendingContacts {
+			contactIDs[contact.ID] = struct{}{}
+		}
+
+		// Check all references before adding anything so that a failed import
+		// doesn't leave partial data behind
+		resolveContactID := func(contactID sql.NullInt32) error {
+			if !contactID.Valid {
+				return errMissingContactID
+			}
+
+			if _, ok := contactIDs[contactID.Int32]; !ok {
+				return errors.Join(errUnknownContactID, fmt.Errorf("contact ID %v", contactID.Int32))
+			}
+
+			return nil
+		}
+
+		for _, debt := range pendingDebts {
+			if err := resolveContactID(debt.ContactID); err != nil {
+				return errors.Join(errCouldNotCreateDebt, err)
+			}
+
+			if _, _, err := importedDebtAmounts(debt); err != nil {
+				return errors.Join(errCouldNotCreateDebt, err)
+			}
+		}
+
+		for _, activity := range pendingActivities {
+			if len(activity.ContactIDs) == 0 {
+				return errors.Join(errCouldNotCreateActivity, errMissingContactID)
+			}
+
+			for _, contactID := range activity.ContactIDs {
+				if err := resolveContactID(sql.NullInt32{
+					Int32: contactID,
+					Valid: true,
+				}); err != nil {
+					return errors.Join(errCouldNotCreateActivity, err)
+				}
+			}
+		}
+
+		for _, relationship := range pendingRelationships {
+			if err := resolveContactID(relationship.ContactID); err != nil {
+				return errors.Join(errCouldNotCreateRelationship, err)
+			}
+
+			if err := resolveContactID(relationship.RelatedContactID); err != nil {
+				return errors.Join(errCouldNotCreateRelationship, err)
+			}
+		}
+
+		for _, group := range pendingGroups {
+			for _, contactID := range group.ContactIDs {
+				if err := resolveContactID(sql.NullInt32{
+					Int32: contactID,
+					Valid: true,
+				}); err != nil {
+					return errors.Join(errCouldNotCreateGroup, err)
+				}
+			}
+		}
+
+		p.lock.Lock()
+		defer p.lock.Unlock()
+
+		// Claiming the staged user data while holding the same lock as the
+		// import makes sure that it can only be imported once
+		if stagedUserData, ok := p.stagedUserData[stagedUserDataHash]; !ok || stagedUserData.Namespace != namespace {
+			return sql.ErrNoRows
+		}
+		delete(p.stagedUserData, stagedUserDataHash)
+
+		for _, journalEntry := range pendingJournalEntries {
+			id := p.createJournalEntry(journalEntry.Title, journalEntry.Body, journalEntry.Rating, namespace)
+
+			p.setJournalEntryTags(id, journalEntry.Tags, namespace)
+		}
+
+		contactIDMap := map[int32]int32{}
+		for _, contact := range pendingContacts {
+			id := p.createContact(models.Contact{
+				FirstName: contact.FirstName,
+				LastName:  contact.LastName,
+				Nickname:  contact.Nickname,
+				Pronouns:  contact.Pronouns,
+				Birthday:  contact.Birthday,
+				Notes:     contact.Notes,
+			}, namespace)
+
+			p.setContactDetails(id, importedContactDetails(contact))
+			p.setContactTags(id, contact.Tags, namespace)
+
+			contactIDMap[contact.ID] = id
+		}
+
+		for _, debt := range pendingDebts {
+			// The amounts have been validated above
+			amount, paymentAmounts, _ := importedDebtAmounts(debt)
+
+			id := p.createDebt(amount, debt.Currency, debt.Description, debt.Date, contactIDMap[debt.ContactID.Int32])
+
+			for i, payment := range debt.Payments {
+				p.createDebtPayment(id, paymentAmounts[i], payment.Date, payment.Description)
+			}
+		}
+
+		for _, activity := range pendingActivities {
+			contactIDs := []int32{}
+			for _, contactID := range activity.ContactIDs {
+				contactIDs = append(contactIDs, contactIDMap[contactID])
+			}
+
+			p.createActivity(activity.Name, activity.Date, activity.Description, contactIDs, namespace)
+		}
+
+		for _, relationship := range pendingRelationships {
+			p.createContactRelationship(
+				contactIDMap[relationship.ContactID.Int32],
+				contactIDMap[relationship.RelatedContactID.Int32],
+				relationship.Type,
+				relationship.Label,
+				relationship.Reciprocal,
+				namespace,
+			)
+		}
+
+		for _, group := range pendingGroups {
+			id := p.createContactGroup(group.Name, namespace)
+
+			for _, contactID := range group.ContactIDs {
+				p.groupMembers[tables.ContactGroupMember{
+					GroupID:   id,
+					ContactID: contactIDMap[contactID],
+				}] = struct{}{}
+			}
+		}
+
+		return nil
+	}
+
+	rollback = func() error {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+
+		if done {
+			return sql.ErrTxDone
+		}
+		done = true
+
+		return nil
+	}
+
+	return
+}
@@ -0,0 +1,138 @@
+package persisters
+
+import (
+	"context"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+// Persister stores the data of all users; every method takes the namespace
+// (the user's email) of the data it accesses
+type Persister interface {
+	Init() error
+
+	CreateActivity(ctx context.Context, name string, date time.Time, description string, contactIDs []int32, namespace string) (int32, error)
+	GetActivities(ctx context.Context, contactID int32, namespace string) ([]models.GetActivitiesRow, error)
+	DeleteActivity(ctx context.Context, id int32, contactID int32, namespace string) error
+	DeleteActivityIfVersion(ctx context.Context, id, version int32, contactID int32, namespace string) (bool, error)
+	GetActivityAndContact(ctx context.Context, id int32, contactID int32, namespace string) (models.GetActivityAndContactRow, error)
+	GetActivityParticipants(ctx context.Context, id int32, namespace string) ([]models.GetActivityParticipantsRow, error)
+	UpdateActivity(ctx context.Context, id int32, contactID int32, namespace string, name string, date time.Time, description string, contactIDs []int32) error
+	UpdateActivityIfVersion(ctx context.Context, id, version int32, contactID int32, namespace string, name string, date time.Time, description string, contactIDs []int32) (bool, error)
+	GetActivityCalendar(ctx context.Context, id int32, namespace string) ([]models.GetActivityCalendarRow, error)
+
+	GetAppPasswords(ctx context.Context, namespace string) ([]models.GetAppPasswordsRow, error)
+	CreateAppPassword(ctx context.Context, name, hash, namespace string) (int32, error)
+	DeleteAppPassword(ctx context.Context, id int32, namespace string) error
+	GetAppPasswordNamespace(ctx context.Context, hash string) (string, error)
+
+	UpsertCalendarFeed(ctx context.Context, hash, namespace string) error
+	GetCalendarFeed(ctx context.Context, namespace string) (models.GetCalendarFeedRow, error)
+	GetCalendarFeedNamespace(ctx context.Context, hash string) (string, error)
+	DeleteCalendarFeed(ctx context.Context, namespace string) error
+	GetActivitiesCalendar(ctx context.Context, namespace string) ([]models.GetActivitiesCalendarForNamespaceRow, error)
+
+	GetDAVResources(ctx context.Context, collection, namespace string) ([]models.GetDAVResourcesRow, error)
+	GetDAVResource(ctx context.Context, collection, name, namespace string) (models.GetDAVResourceRow, error)
+	UpsertDAVResource(ctx context.Context, collection, name string, resourceID int32, uid, namespace string) error
+	DeleteDAVResources(ctx context.Context, collection string, resourceID int32, namespace string) error
+
+	GetContacts(ctx context.Context, namespace string) ([]models.Contact, error)
+	ListContacts(ctx context.Context, namespace, sort string, hasDebts bool, birthdayMonth int32, tag, afterKey string, afterID, limit int32) ([]models.ListContactsRow, error)
+	CreateContact(ctx context.Context, firstName, lastName, nickname, pronouns, namespace string, details models.ContactDetails) (int32, error)
+	CreateContacts(ctx context.Context, contacts []models.Contact, details []models.ContactDetails, namespace string) ([]int32, error)
+	GetContact(ctx context.Context, id int32, namespace string) (models.Contact, error)
+	GetContactByEmail(ctx context.Context, email string, namespace string) (models.Contact, error)
+	DeleteContact(ctx context.Context, id int32, namespace string) error
+	DeleteContactIfVersion(ctx context.Context, id, version int32, namespace string) (bool, error)
+	UpdateContact(ctx context.Context, id int32, firstName, lastName, nickname, pronouns, namespace string, birthday *time.Time, notes string, details models.ContactDetails) error
+	UpdateContactIfVersion(ctx context.Context, id, version int32, firstName, lastName, nickname, pronouns, namespace string, birthday *time.Time, notes string, details models.ContactDetails) (bool, error)
+	GetContactDetails(ctx context.Context, contactID int32, namespace string) (models.ContactDetails, error)
+	GetContactDetailsForNamespace(ctx context.Context, namespace string) (map[int32]models.ContactDetails, error)
+
+	CreateContactRelationship(ctx context.Context, contactID, relatedContactID int32, relationshipType, label string, reciprocal bool, namespace string) (int32, error)
+	GetContactRelationships(ctx context.Context, contactID int32, namespace string) ([]models.GetContactRelationshipsRow, error)
+	GetContactRelationshipsForNamespace(ctx context.Context, namespace string) ([]models.ContactRelationship, error)
+	DeleteContactRelationship(ctx context.Context, id int32, namespace string) error
+
+	GetContactGroups(ctx context.Context, namespace string) ([]models.GetContactGroupsRow, error)
+	CreateContactGroup(ctx context.Context, name, namespace string) (int32, error)
+	GetContactGroup(ctx context.Context, id int32, namespace string) (models.ContactGroup, error)
+	UpdateContactGroup(ctx context.Context, id int32, name, namespace string) error
+	DeleteContactGroup(ctx context.Context, id int32, namespace string) error
+	GetContactGroupMembers(ctx context.Context, groupID int32, namespace string) ([]models.GetContactGroupMembersRow, error)
+	CreateContactGroupMember(ctx context.Context, groupID, contactID int32, namespace string) error
+	DeleteContactGroupMember(ctx context.Context, groupID, contactID int32, namespace string) error
+
+	CreateDebt(ctx context.Context, amount int64, currency, description string, date time.Time, contactID int32, namespace string) (int32, error)
+	CreateDebts(ctx context.Context, amounts []int64, currency, description string, date time.Time, contactIDs []int32, namespace string) ([]int32, error)
+	GetDebts(ctx context.Context, contactID int32, namespace string) ([]models.GetDebtsRow, error)
+	GetDebtPayments(ctx context.Context, contactID int32, namespace string) ([]models.DebtPayment, error)
+	SettleDebt(ctx context.Context, id int32, contactID int32, namespace string, amount int64, date time.Time, description string) (int32, error)
+	GetDebtAndContact(ctx context.Context, id int32, contactID int32, namespace string) (models.GetDebtAndContactRow, error)
+	UpdateDebt(ctx context.Context, id int32, contactID int32, namespace string, amount int64, currency, description string, date time.Time) error
+
+	GetJournalEntries(ctx context.Context, namespace string) ([]models.JournalEntry, error)
+	ListJournalEntries(ctx context.Context, namespace string, oldestFirst bool, rating int32, from, to *time.Time, tag string, afterDate time.Time, afterID, limit int32) ([]models.ListJournalEntriesRow, error)
+	CreateJournalEntry(ctx context.Context, title, body string, rating int32, namespace string) (int32, error)
+	DeleteJournalEntry(ctx context.Context, id int32, namespace string) error
+	GetJournalEntry(ctx context.Context, id int32, namespace string) (models.JournalEntry, error)
+	UpdateJournalEntry(ctx context.Context, id int32, title, body string, rating int32, namespace string) error
+
+	GetPersonalAccessTokens(ctx context.Context, namespace string) ([]models.GetPersonalAccessTokensRow, error)
+	CreatePersonalAccessToken(ctx context.Context, name, hash, scopes string, expiresAt *time.Time, namespace string) (int32, error)
+	DeletePersonalAccessToken(ctx context.Context, id int32, namespace string) error
+	GetPersonalAccessToken(ctx context.Context, hash string) (models.GetPersonalAccessTokenRow, error)
+
+	GetSessions(ctx context.Context, namespace string) ([]models.GetSessionsRow, error)
+	CreateSession(ctx context.Context, hash, refreshToken, idToken, userAgent, ip, subject, oidcSessionID, namespace string) (int32, error)
+	GetSession(ctx context.Context, hash string) (models.GetSessionRow, error)
+	UpdateSessionTokens(ctx context.Context, id int32, refreshToken, idToken string) error
+	UpdateSessionLastSeen(ctx context.Context, id int32, ip string) error
+	DeleteSession(ctx context.Context, id int32, namespace string) error
+	DeleteSessions(ctx context.Context, namespace string) error
+	DeleteSessionsForOIDCSession(ctx context.Context, oidcSessionID string) error
+	DeleteSessionsForSubject(ctx context.Context, subject string) error
+
+	GetTags(ctx context.Context, namespace string) ([]models.Tag, error)
+	GetContactTags(ctx context.Context, contactID int32, namespace string) ([]string, error)
+	UpdateContactTags(ctx context.Context, contactID int32, tags []string, namespace string) error
+	GetJournalEntryTags(ctx context.Context, journalEntryID int32, namespace string) ([]string, error)
+	UpdateJournalEntryTags(ctx context.Context, journalEntryID int32, tags []string, namespace string) error
+
+	UpdateSearchConfig(ctx context.Context, config, namespace string) error
+	Search(ctx context.Context, query string, limit int32, namespace string) ([]models.SearchDocumentsRow, error)
+
+	CreateStagedUserData(ctx context.Context, hash string, data []byte, expiresAt time.Time, limit int64, namespace string) (bool, error)
+	GetStagedUserData(ctx context.Context, hash, namespace string) (models.GetStagedUserDataRow, error)
+	DeleteStagedUserData(ctx context.Context, hash, namespace string) error
+
+	GetUserData(
+		ctx context.Context,
+
+		namespace string,
+
+		onManifest func(manifest models.ExportedManifest) error,
+		onJournalEntry func(journalEntry models.ExportedJournalEntry) error,
+		onContact func(contact models.ExportedContact) error,
+		onDebt func(debt models.ExportedDebt) error,
+		onActivity func(activity models.ExportedActivity) error,
+		onRelationship func(relationship models.ExportedRelationship) error,
+		onGroup func(group models.ExportedGroup) error,
+	) error
+	DeleteUserData(ctx context.Context, namespace string) error
+	CreateUserData(ctx context.Context, stagedUserDataHash, namespace string) (
+		createJournalEntry func(journalEntry models.ExportedJournalEntry) error,
+		createContact func(contact models.ExportedContact) error,
+		createDebt func(debt models.ExportedDebt) error,
+		createActivity func(activty models.ExportedActivity) error,
+		createRelationship func(relationship models.ExportedRelationship) error,
+		createGroup func(group models.ExportedGroup) error,
+
+		commit func() error,
+		rollback func() error,
+
+		err error,
+	)
+}
@@ -0,0 +1,51 @@
+package persisters
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func (p *sqlPersister) GetPersonalAccessTokens(ctx context.Context, namespace string) ([]models.GetPersonalAccessTokensRow, error) {
+	return p.queries.GetPersonalAccessTokens(ctx, namespace)
+}
+
+func (p *sqlPersister) CreatePersonalAccessToken(
+	ctx context.Context,
+
+	name,
+	hash,
+	scopes string,
+	expiresAt *time.Time,
+
+	namespace string,
+) (int32, error) {
+	var expiresAtTime sql.NullTime
+	if expiresAt != nil {
+		expiresAtTime = sql.NullTime{
+			Time:  *expiresAt,
+			Valid: true,
+		}
+	}
+
+	return p.queries.CreatePersonalAccessToken(ctx, models.CreatePersonalAccessTokenParams{
+		Name:      name,
+		Hash:      hash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAtTime,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) DeletePersonalAccessToken(ctx context.Context, id int32, namespace string) error {
+	return p.queries.DeletePersonalAccessToken(ctx, models.DeletePersonalAccessTokenParams{
+		ID:        id,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) GetPersonalAccessToken(ctx context.Context, hash string) (models.GetPersonalAccessTokenRow, error) {
+	return p.queries.GetPersonalAccessToken(ctx, hash)
+}
@@ -0,0 +1,50 @@
+package persisters
+
+import (
+	"context"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+// CreateContactRelationship relates two contacts of the same namespace and
+// returns `sql.ErrNoRows` if either of them belongs to another namespace
+func (p *sqlPersister) CreateContactRelationship(
+	ctx context.Context,
+
+	contactID,
+	relatedContactID int32,
+
+	relationshipType,
+	label string,
+	reciprocal bool,
+
+	namespace string,
+) (int32, error) {
+	return p.queries.CreateContactRelationship(ctx, models.CreateContactRelationshipParams{
+		Namespace:        namespace,
+		Type:             relationshipType,
+		Label:            label,
+		Reciprocal:       reciprocal,
+		ContactID:        contactID,
+		RelatedContactID: relatedContactID,
+	})
+}
+
+// GetContactRelationships returns the relationships from and to a contact
+func (p *sqlPersister) GetContactRelationships(ctx context.Context, contactID int32, namespace string) ([]models.GetContactRelationshipsRow, error) {
+	return p.queries.GetContactRelationships(ctx, models.GetContactRelationshipsParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) GetContactRelationshipsForNamespace(ctx context.Context, namespace string) ([]models.ContactRelationship, error) {
+	return p.queries.GetContactRelationshipsForNamespace(ctx, namespace)
+}
+
+func (p *sqlPersister) DeleteContactRelationship(ctx context.Context, id int32, namespace string) error {
+	return p.queries.DeleteContactRelationship(ctx, models.DeleteContactRelationshipParams{
+		ID:        id,
+		Namespace: namespace,
+	})
+}
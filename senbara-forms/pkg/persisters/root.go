@@ -4,27 +4,76 @@ package persisters
 
 import (
 	"database/sql"
+	"errors"
+	"net/url"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/migrations"
 	"github.com/pojntfx/senbara/senbara-forms/pkg/tables"
 	"github.com/pressly/goose/v3"
 )
 
-type Persister struct {
-	pgaddr  string
+var errUnsupportedDatabaseScheme = errors.New("unsupported database URL scheme, must be one of postgres://, postgresql://, sqlite://, file: or memory:")
+
+var (
+	_ Persister = &PostgresPersister{}
+	_ Persister = &SQLitePersister{}
+	_ Persister = &MemoryPersister{}
+)
+
+// NewPersister creates the persister for the database URL's scheme
+func NewPersister(databaseURL string) (Persister, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		return NewPostgresPersister(databaseURL), nil
+
+	case "sqlite", "file":
+		return NewSQLitePersister(databaseURL), nil
+
+	case "memory":
+		return NewMemoryPersister(), nil
+
+	default:
+		return nil, errUnsupportedDatabaseScheme
+	}
+}
+
+// sqlPersister implements the persister methods on top of the sqlc queries;
+// `wrap` allows a dialect to adjust how the queries are sent to the database
+type sqlPersister struct {
 	queries *tables.Queries
 	db      *sql.DB
+	wrap    func(db tables.DBTX) tables.DBTX
+}
+
+func (p *sqlPersister) init(db *sql.DB, wrap func(db tables.DBTX) tables.DBTX) {
+	p.db = db
+	p.wrap = wrap
+	p.queries = tables.New(wrap(db))
+}
+
+func (p *sqlPersister) withTx(tx *sql.Tx) *tables.Queries {
+	return tables.New(p.wrap(tx))
+}
+
+type PostgresPersister struct {
+	sqlPersister
+
+	pgaddr string
 }
 
-func NewPersister(pgaddr string) *Persister {
-	return &Persister{
+func NewPostgresPersister(pgaddr string) *PostgresPersister {
+	return &PostgresPersister{
 		pgaddr: pgaddr,
 	}
 }
 
-func (p *Persister) Init() error {
-	var err error
-	p.db, err = sql.Open("postgres", p.pgaddr)
+func (p *PostgresPersister) Init() error {
+	db, err := sql.Open("postgres", p.pgaddr)
 	if err != nil {
 		return err
 	}
@@ -35,11 +84,13 @@ func (p *Persister) Init() error {
 		return err
 	}
 
-	if err := goose.Up(p.db, "."); err != nil {
+	if err := goose.Up(db, "."); err != nil {
 		return err
 	}
 
-	p.queries = tables.New(p.db)
+	p.init(db, func(db tables.DBTX) tables.DBTX {
+		return db
+	})
 
 	return nil
 }
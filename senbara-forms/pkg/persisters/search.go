@@ -0,0 +1,83 @@
+package persisters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+const (
+	// SearchHighlightStart and SearchHighlightStop surround the matches in the
+	// headlines of search results; they are control characters so that they
+	// can't be confused with the escaped HTML the headlines are rendered as
+	SearchHighlightStart = "\x02"
+	SearchHighlightStop  = "\x03"
+)
+
+var searchHeadlineOptions = fmt.Sprintf(
+	"StartSel=%v, StopSel=%v, MaxWords=35, MinWords=15, MaxFragments=3",
+	SearchHighlightStart,
+	SearchHighlightStop,
+)
+
+// UpdateSearchConfig reindexes the documents of a namespace with the text
+// search configuration `config`, i.e. `english`, if they were indexed with
+// another one
+func (p *sqlPersister) UpdateSearchConfig(ctx context.Context, config, namespace string) error {
+	return p.queries.UpdateSearchDocumentsConfig(ctx, models.UpdateSearchDocumentsConfigParams{
+		Config:    config,
+		Namespace: namespace,
+	})
+}
+
+// Search ranks the contacts, journal entries, debts and activities of a
+// namespace by how well they match `query`, using the text search
+// configuration the namespace's documents are indexed with
+func (p *sqlPersister) Search(ctx context.Context, query string, limit int32, namespace string) ([]models.SearchDocumentsRow, error) {
+	return p.queries.SearchDocuments(ctx, models.SearchDocumentsParams{
+		HeadlineOptions: searchHeadlineOptions,
+		Namespace:       namespace,
+		Query:           query,
+		ResultLimit:     limit,
+	})
+}
+
+// highlightSubstring surrounds the case-insensitive occurrences of `substr`
+// in `text` with the search highlight markers
+func highlightSubstring(text, substr string) string {
+	if substr == "" {
+		return text
+	}
+
+	lowerText := strings.ToLower(text)
+	lowerSubstr := strings.ToLower(substr)
+
+	// Lowercasing can change the length of some characters, in which case the
+	// indexes of the lowercased text can't be used for the original one
+	if len(lowerText) != len(text) || !utf8.ValidString(text) {
+		return text
+	}
+
+	var highlighted strings.Builder
+	for {
+		i := strings.Index(lowerText, lowerSubstr)
+		if i < 0 {
+			highlighted.WriteString(text)
+
+			break
+		}
+
+		highlighted.WriteString(text[:i])
+		highlighted.WriteString(SearchHighlightStart)
+		highlighted.WriteString(text[i : i+len(lowerSubstr)])
+		highlighted.WriteString(SearchHighlightStop)
+
+		text = text[i+len(lowerSubstr):]
+		lowerText = lowerText[i+len(lowerSubstr):]
+	}
+
+	return highlighted.String()
+}
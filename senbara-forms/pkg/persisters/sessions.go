@@ -0,0 +1,74 @@
+package persisters
+
+import (
+	"context"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+func (p *sqlPersister) GetSessions(ctx context.Context, namespace string) ([]models.GetSessionsRow, error) {
+	return p.queries.GetSessions(ctx, namespace)
+}
+
+func (p *sqlPersister) CreateSession(
+	ctx context.Context,
+
+	hash,
+	refreshToken,
+	idToken,
+	userAgent,
+	ip,
+	subject,
+	oidcSessionID,
+
+	namespace string,
+) (int32, error) {
+	return p.queries.CreateSession(ctx, models.CreateSessionParams{
+		Hash:          hash,
+		RefreshToken:  refreshToken,
+		IDToken:       idToken,
+		UserAgent:     userAgent,
+		Ip:            ip,
+		Subject:       subject,
+		OidcSessionID: oidcSessionID,
+		Namespace:     namespace,
+	})
+}
+
+func (p *sqlPersister) GetSession(ctx context.Context, hash string) (models.GetSessionRow, error) {
+	return p.queries.GetSession(ctx, hash)
+}
+
+func (p *sqlPersister) UpdateSessionTokens(ctx context.Context, id int32, refreshToken, idToken string) error {
+	return p.queries.UpdateSessionTokens(ctx, models.UpdateSessionTokensParams{
+		ID:           id,
+		RefreshToken: refreshToken,
+		IDToken:      idToken,
+	})
+}
+
+func (p *sqlPersister) UpdateSessionLastSeen(ctx context.Context, id int32, ip string) error {
+	return p.queries.UpdateSessionLastSeen(ctx, models.UpdateSessionLastSeenParams{
+		ID: id,
+		Ip: ip,
+	})
+}
+
+func (p *sqlPersister) DeleteSession(ctx context.Context, id int32, namespace string) error {
+	return p.queries.DeleteSession(ctx, models.DeleteSessionParams{
+		ID:        id,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) DeleteSessions(ctx context.Context, namespace string) error {
+	return p.queries.DeleteSessionsForNamespace(ctx, namespace)
+}
+
+func (p *sqlPersister) DeleteSessionsForOIDCSession(ctx context.Context, oidcSessionID string) error {
+	return p.queries.DeleteSessionsForOIDCSession(ctx, oidcSessionID)
+}
+
+func (p *sqlPersister) DeleteSessionsForSubject(ctx context.Context, subject string) error {
+	return p.queries.DeleteSessionsForSubject(ctx, subject)
+}
@@ -0,0 +1,198 @@
+package persisters
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	sqliteMigrations "github.com/pojntfx/senbara/senbara-forms/pkg/migrations/sqlite"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/queries"
+	sqliteQueries "github.com/pojntfx/senbara/senbara-forms/pkg/queries/sqlite"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+	"github.com/pressly/goose/v3"
+)
+
+var (
+	errMissingSQLiteQuery = errors.New("missing SQLite query")
+)
+
+// SQLitePersister stores the data in an SQLite database, which is useful for
+// self-hosting for a single user. It uses the same sqlc-generated code as the
+// Postgres persister, but replaces each query with its SQLite version from
+// `pkg/queries/sqlite`, which must keep the same name, parameters and columns.
+type SQLitePersister struct {
+	sqlPersister
+
+	dsn string
+}
+
+// NewSQLitePersister creates a persister for a database URL like
+// `sqlite:///var/lib/senbara-forms/senbara-forms.db` or
+// `file:senbara-forms.db?cache=shared`
+func NewSQLitePersister(databaseURL string) *SQLitePersister {
+	dsn := databaseURL
+	if path, ok := strings.CutPrefix(databaseURL, "sqlite://"); ok {
+		dsn = "file:" + path
+	}
+
+	return &SQLitePersister{
+		dsn: dsn,
+	}
+}
+
+func (p *SQLitePersister) Init() error {
+	postgresQueries, err := parseQueries(queries.FS)
+	if err != nil {
+		return err
+	}
+
+	replacements, err := parseQueries(sqliteQueries.FS)
+	if err != nil {
+		return err
+	}
+
+	for name := range postgresQueries {
+		if _, ok := replacements[name]; !ok {
+			return errors.Join(errMissingSQLiteQuery, fmt.Errorf("query %v", name))
+		}
+	}
+
+	dsn := p.dsn
+	if strings.Contains(dsn, "?") {
+		dsn += "&_foreign_keys=on"
+	} else {
+		dsn += "?_foreign_keys=on"
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return err
+	}
+
+	// SQLite only allows one writer at a time, so using a single connection
+	// prevents transactions from failing with "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	goose.SetBaseFS(sqliteMigrations.FS)
+
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		return err
+	}
+
+	if err := goose.Up(db, "."); err != nil {
+		return err
+	}
+
+	p.init(db, func(db tables.DBTX) tables.DBTX {
+		return &sqliteDBTX{
+			db:      db,
+			queries: replacements,
+		}
+	})
+
+	return nil
+}
+
+// parseQueries reads the queries from sqlc query files and indexes them by
+// their `-- name:` annotation
+func parseQueries(fsys fs.FS) (map[string]string, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := map[string]string{}
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		name := ""
+		scanner := bufio.NewScanner(strings.NewReader(string(content)))
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			if n, ok := queryName(line); ok {
+				name = n
+			} else if name == "" {
+				continue
+			}
+
+			parsed[name] += line + "\n"
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return parsed, nil
+}
+
+// queryName returns the name from the first line of a query, which sqlc
+// annotates with `-- name: Name :kind`
+func queryName(query string) (string, bool) {
+	line, _, _ := strings.Cut(query, "\n")
+
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return "", false
+	}
+
+	return fields[2], true
+}
+
+// sqliteDBTX replaces the Postgres queries sqlc generated with their SQLite
+// versions before sending them to the database
+type sqliteDBTX struct {
+	db      tables.DBTX
+	queries map[string]string
+}
+
+func (d *sqliteDBTX) query(query string) string {
+	name, ok := queryName(query)
+	if !ok {
+		return query
+	}
+
+	// `Init` checked that every query has an SQLite version
+	return d.queries[name]
+}
+
+func (d *sqliteDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.db.ExecContext(ctx, d.query(query), args...)
+}
+
+func (d *sqliteDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return d.db.PrepareContext(ctx, d.query(query))
+}
+
+func (d *sqliteDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, d.query(query), args...)
+}
+
+func (d *sqliteDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.db.QueryRowContext(ctx, d.query(query), args...)
+}
+
+// Search matches the query as a phrase, since SQLite has no full-text search
+// without extensions, and highlights it the way `ts_headline` does in Postgres
+func (p *SQLitePersister) Search(ctx context.Context, query string, limit int32, namespace string) ([]models.SearchDocumentsRow, error) {
+	results, err := p.sqlPersister.Search(ctx, query, limit, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		results[i].TitleHeadline = highlightSubstring(result.TitleHeadline, query)
+		results[i].BodyHeadline = highlightSubstring(result.BodyHeadline, query)
+	}
+
+	return results, nil
+}
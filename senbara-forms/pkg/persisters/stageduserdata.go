@@ -0,0 +1,72 @@
+package persisters
+
+import (
+	"context"
+	"time"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+)
+
+// CreateStagedUserData removes all expired staged user data and stores the new
+// one, unless the namespace already has `limit` pending imports, in which case
+// it returns `false`
+func (p *sqlPersister) CreateStagedUserData(
+	ctx context.Context,
+
+	hash string,
+	data []byte,
+	expiresAt time.Time,
+	limit int64,
+
+	namespace string,
+) (bool, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	if err := qtx.DeleteExpiredStagedUserData(ctx, time.Now().UTC()); err != nil {
+		return false, err
+	}
+
+	count, err := qtx.CountStagedUserData(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+
+	if count >= limit {
+		return false, nil
+	}
+
+	if err := qtx.CreateStagedUserData(ctx, models.CreateStagedUserDataParams{
+		Hash:      hash,
+		Data:      data,
+		ExpiresAt: expiresAt,
+		Namespace: namespace,
+	}); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *sqlPersister) GetStagedUserData(ctx context.Context, hash, namespace string) (models.GetStagedUserDataRow, error) {
+	return p.queries.GetStagedUserData(ctx, models.GetStagedUserDataParams{
+		Hash:      hash,
+		Namespace: namespace,
+	})
+}
+
+func (p *sqlPersister) DeleteStagedUserData(ctx context.Context, hash, namespace string) error {
+	return p.queries.DeleteStagedUserData(ctx, models.DeleteStagedUserDataParams{
+		Hash:      hash,
+		Namespace: namespace,
+	})
+}
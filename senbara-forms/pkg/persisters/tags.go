@@ -0,0 +1,160 @@
+package persisters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/tables"
+)
+
+func (p *sqlPersister) GetTags(ctx context.Context, namespace string) ([]models.Tag, error) {
+	return p.queries.GetTags(ctx, namespace)
+}
+
+func (p *sqlPersister) GetContactTags(ctx context.Context, contactID int32, namespace string) ([]string, error) {
+	return p.queries.GetContactTags(ctx, models.GetContactTagsParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	})
+}
+
+// setContactTags links a contact to the tags with the names in `tags`, creating
+// the tags which don't exist yet; the caller must have checked that the contact
+// belongs to the namespace
+func setContactTags(ctx context.Context, qtx *tables.Queries, contactID int32, tags []string, namespace string) error {
+	for _, tag := range tags {
+		tagID, err := qtx.UpsertTag(ctx, models.UpsertTagParams{
+			Name:      tag,
+			Namespace: namespace,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.CreateContactTag(ctx, models.CreateContactTagParams{
+			ContactID: contactID,
+			TagID:     tagID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateContactTags replaces the tags of a contact and removes the tags
+// which are no longer used by any contact or journal entry
+func (p *sqlPersister) UpdateContactTags(ctx context.Context, contactID int32, tags []string, namespace string) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	// Like the other updates, changing the tags of another namespace's contact
+	// does nothing
+	if _, err := qtx.GetContact(ctx, models.GetContactParams{
+		ID:        contactID,
+		Namespace: namespace,
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := qtx.DeleteContactTags(ctx, models.DeleteContactTagsParams{
+		ContactID: contactID,
+		Namespace: namespace,
+	}); err != nil {
+		return err
+	}
+
+	if err := setContactTags(ctx, qtx, contactID, tags, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteUnusedTags(ctx, namespace); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *sqlPersister) GetJournalEntryTags(ctx context.Context, journalEntryID int32, namespace string) ([]string, error) {
+	return p.queries.GetJournalEntryTags(ctx, models.GetJournalEntryTagsParams{
+		JournalEntryID: journalEntryID,
+		Namespace:      namespace,
+	})
+}
+
+// setJournalEntryTags links a journal entry to the tags with the names in
+// `tags`, creating the tags which don't exist yet; the caller must have
+// checked that the journal entry belongs to the namespace
+func setJournalEntryTags(ctx context.Context, qtx *tables.Queries, journalEntryID int32, tags []string, namespace string) error {
+	for _, tag := range tags {
+		tagID, err := qtx.UpsertTag(ctx, models.UpsertTagParams{
+			Name:      tag,
+			Namespace: namespace,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.CreateJournalEntryTag(ctx, models.CreateJournalEntryTagParams{
+			JournalEntryID: journalEntryID,
+			TagID:          tagID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateJournalEntryTags replaces the tags of a journal entry and removes the
+// tags which are no longer used by any contact or journal entry
+func (p *sqlPersister) UpdateJournalEntryTags(ctx context.Context, journalEntryID int32, tags []string, namespace string) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := p.withTx(tx)
+
+	// Like the other updates, changing the tags of another namespace's journal entry
+	// does nothing
+	if _, err := qtx.GetJournalEntry(ctx, models.GetJournalEntryParams{
+		ID:        journalEntryID,
+		Namespace: namespace,
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := qtx.DeleteJournalEntryTags(ctx, models.DeleteJournalEntryTagsParams{
+		JournalEntryID: journalEntryID,
+		Namespace:      namespace,
+	}); err != nil {
+		return err
+	}
+
+	if err := setJournalEntryTags(ctx, qtx, journalEntryID, tags, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteUnusedTags(ctx, namespace); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
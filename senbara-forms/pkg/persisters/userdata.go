@@ -3,20 +3,36 @@ package persisters
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/pojntfx/senbara/senbara-forms/pkg/models"
+	"github.com/pojntfx/senbara/senbara-forms/pkg/money"
 )
 
-func (p *Persister) GetUserData(
+var (
+	errMissingContactID           = errors.New("missing contact ID")
+	errUnknownContactID           = errors.New("unknown contact ID")
+	errCouldNotCreateDebt         = errors.New("could not create debt")
+	errCouldNotCreateActivity     = errors.New("could not create activity")
+	errCouldNotCreateRelationship = errors.New("could not create relationship")
+	errCouldNotCreateGroup        = errors.New("could not create group")
+)
+
+func (p *sqlPersister) GetUserData(
 	ctx context.Context,
 
 	namespace string,
 
+	onManifest func(manifest models.ExportedManifest) error,
 	onJournalEntry func(journalEntry models.ExportedJournalEntry) error,
 	onContact func(contact models.ExportedContact) error,
 	onDebt func(debt models.ExportedDebt) error,
 	onActivity func(activity models.ExportedActivity) error,
+	onRelationship func(relationship models.ExportedRelationship) error,
+	onGroup func(group models.ExportedGroup) error,
 ) error {
 	tx, err := p.db.Begin()
 	if err != nil {
@@ -24,13 +40,106 @@ func (p *Persister) GetUserData(
 	}
 	defer tx.Rollback()
 
-	qtx := p.queries.WithTx(tx)
+	qtx := p.withTx(tx)
 
 	journalEntries, err := qtx.GetJournalEntriesExportForNamespace(ctx, namespace)
 	if err != nil {
 		return err
 	}
 
+	journalEntryTagRows, err := qtx.GetJournalEntryTagsExportForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	journalEntryTags := map[int32][]string{}
+	for _, journalEntryTag := range journalEntryTagRows {
+		journalEntryTags[journalEntryTag.JournalEntryID] = append(journalEntryTags[journalEntryTag.JournalEntryID], journalEntryTag.Name)
+	}
+
+	contacts, err := qtx.GetContactsExportForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	contactTagRows, err := qtx.GetContactTagsExportForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	contactTags := map[int32][]string{}
+	for _, contactTag := range contactTagRows {
+		contactTags[contactTag.ContactID] = append(contactTags[contactTag.ContactID], contactTag.Name)
+	}
+
+	contactDetails, err := getContactDetailsForNamespace(ctx, qtx, namespace)
+	if err != nil {
+		return err
+	}
+
+	debts, err := qtx.GetDebtsExportForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	debtPaymentRows, err := qtx.GetDebtPaymentsExportForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	debtPayments := map[int32][]models.DebtPayment{}
+	for _, debtPayment := range debtPaymentRows {
+		debtPayments[debtPayment.DebtID] = append(debtPayments[debtPayment.DebtID], debtPayment)
+	}
+
+	activities, err := qtx.GetActivitiesExportForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	activityParticipantRows, err := qtx.GetActivityParticipantsExportForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	activityParticipants := map[int32][]int32{}
+	for _, activityParticipant := range activityParticipantRows {
+		activityParticipants[activityParticipant.ActivityID] = append(activityParticipants[activityParticipant.ActivityID], activityParticipant.ContactID)
+	}
+
+	relationships, err := qtx.GetContactRelationshipsForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	groups, err := qtx.GetContactGroupsForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	groupMemberRows, err := qtx.GetContactGroupMembersExportForNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	groupMembers := map[int32][]int32{}
+	for _, groupMember := range groupMemberRows {
+		groupMembers[groupMember.GroupID] = append(groupMembers[groupMember.GroupID], groupMember.ContactID)
+	}
+
+	if err := onManifest(models.ExportedManifest{
+		Counts: models.ExportedCounts{
+			JournalEntries: len(journalEntries),
+			Contacts:       len(contacts),
+			Debts:          len(debts),
+			Activities:     len(activities),
+			Relationships:  len(relationships),
+			Groups:         len(groups),
+		},
+	}); err != nil {
+		return err
+	}
+
 	for _, journalEntry := range journalEntries {
 		if err := onJournalEntry(models.ExportedJournalEntry{
 			ID:        journalEntry.ID,
@@ -39,62 +148,89 @@ func (p *Persister) GetUserData(
 			Body:      journalEntry.Body,
 			Rating:    journalEntry.Rating,
 			Namespace: journalEntry.Namespace,
+			Tags:      exportedTags(journalEntryTags[journalEntry.ID]),
 		}); err != nil {
 			return err
 		}
 	}
 
-	contacts, err := qtx.GetContactsExportForNamespace(ctx, namespace)
-	if err != nil {
-		return err
-	}
-
 	for _, contact := range contacts {
-		if err := onContact(models.ExportedContact{
+		if err := onContact(withExportedContactDetails(models.ExportedContact{
 			ID:        contact.ID,
 			FirstName: contact.FirstName,
 			LastName:  contact.LastName,
 			Nickname:  contact.Nickname,
-			Email:     contact.Email,
 			Pronouns:  contact.Pronouns,
 			Namespace: contact.Namespace,
 			Birthday:  contact.Birthday,
-			Address:   contact.Address,
 			Notes:     contact.Notes,
-		}); err != nil {
+			Tags:      exportedTags(contactTags[contact.ID]),
+		}, contactDetails[contact.ID])); err != nil {
 			return err
 		}
 	}
 
-	debts, err := qtx.GetDebtsExportForNamespace(ctx, namespace)
-	if err != nil {
-		return err
-	}
-
 	for _, debt := range debts {
 		if err := onDebt(models.ExportedDebt{
 			ID:          debt.ID,
-			Amount:      debt.Amount,
+			Amount:      json.Number(money.FormatAmount(debt.Amount, debt.Currency)),
 			Currency:    debt.Currency,
 			Description: debt.Description,
+			Date:        debt.Date,
 			ContactID:   debt.ContactID,
+			Payments:    exportedDebtPayments(debtPayments[debt.ID], debt.Currency),
 		}); err != nil {
 			return err
 		}
 	}
 
-	activities, err := qtx.GetActivitiesExportForNamespace(ctx, namespace)
-	if err != nil {
-		return err
-	}
-
 	for _, activity := range activities {
+		// The first participant is also exported as the contact of the
+		// activity, which is all that earlier versions can import
+		var contactID sql.NullInt32
+		if participants := activityParticipants[activity.ID]; len(participants) > 0 {
+			contactID = sql.NullInt32{
+				Int32: participants[0],
+				Valid: true,
+			}
+		}
+
 		if err := onActivity(models.ExportedActivity{
 			ID:          activity.ID,
 			Name:        activity.Name,
 			Date:        activity.Date,
 			Description: activity.Description,
-			ContactID:   activity.ContactID,
+			ContactID:   contactID,
+			ContactIDs:  exportedContactIDs(activityParticipants[activity.ID]),
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, relationship := range relationships {
+		if err := onRelationship(models.ExportedRelationship{
+			ID: relationship.ID,
+			ContactID: sql.NullInt32{
+				Int32: relationship.ContactID,
+				Valid: true,
+			},
+			RelatedContactID: sql.NullInt32{
+				Int32: relationship.RelatedContactID,
+				Valid: true,
+			},
+			Type:       relationship.Type,
+			Label:      relationship.Label,
+			Reciprocal: relationship.Reciprocal,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range groups {
+		if err := onGroup(models.ExportedGroup{
+			ID:         group.ID,
+			Name:       group.Name,
+			ContactIDs: exportedContactIDs(groupMembers[group.ID]),
 		}); err != nil {
 			return err
 		}
@@ -103,19 +239,125 @@ func (p *Persister) GetUserData(
 	return nil
 }
 
-func (p *Persister) DeleteUserData(ctx context.Context, namespace string) error {
+// exportedTags returns an empty list instead of `nil` so that records without
+// tags are exported with `"tags": []` instead of `"tags": null`
+func exportedTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+
+	return tags
+}
+
+// exportedContactIDs returns an empty list instead of `nil` so that groups
+// without members and activities without participants are exported with
+// `"contactIds": []`
+func exportedContactIDs(contactIDs []int32) []int32 {
+	if contactIDs == nil {
+		return []int32{}
+	}
+
+	return contactIDs
+}
+
+// exportedDebtPayments formats the amounts of the payments of a debt in its
+// currency and returns an empty list instead of `nil` so that debts without
+// payments are exported with `"payments": []`
+func exportedDebtPayments(payments []models.DebtPayment, currency string) []models.ExportedDebtPayment {
+	exported := []models.ExportedDebtPayment{}
+	for _, payment := range payments {
+		exported = append(exported, models.ExportedDebtPayment{
+			Amount:      json.Number(money.FormatAmount(payment.Amount, currency)),
+			Date:        payment.Date,
+			Description: payment.Description,
+		})
+	}
+
+	return exported
+}
+
+// importedDebtAmounts parses the amounts of a debt and its payments into minor
+// units of its currency
+func importedDebtAmounts(debt models.ExportedDebt) (int64, []int64, error) {
+	amount, err := money.ParseAmount(string(debt.Amount), debt.Currency)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	payments := []int64{}
+	for _, payment := range debt.Payments {
+		paymentAmount, err := money.ParseAmount(string(payment.Amount), debt.Currency)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		payments = append(payments, paymentAmount)
+	}
+
+	return amount, payments, nil
+}
+
+func (p *sqlPersister) DeleteUserData(ctx context.Context, namespace string) error {
 	tx, err := p.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	qtx := p.queries.WithTx(tx)
+	qtx := p.withTx(tx)
+
+	if err := qtx.DeleteContactTagsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteJournalEntryTagsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteTagsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactRelationshipsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactGroupMembersForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactGroupsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactEmailsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactPhonesForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactAddressesForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteContactWebsitesForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteActivityParticipantsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
 
 	if err := qtx.DeleteActivitiesForNamespace(ctx, namespace); err != nil {
 		return err
 	}
 
+	if err := qtx.DeleteDebtPaymentsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
 	if err := qtx.DeleteDebtsForNamespace(ctx, namespace); err != nil {
 		return err
 	}
@@ -128,14 +370,43 @@ func (p *Persister) DeleteUserData(ctx context.Context, namespace string) error
 		return err
 	}
 
+	if err := qtx.DeleteAppPasswordsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteCalendarFeedForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeletePersonalAccessTokensForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteSessionsForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteStagedUserDataForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteDAVResourcesForNamespace(ctx, namespace); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
-func (p *Persister) CreateUserData(ctx context.Context, namespace string) (
+// CreateUserData deletes the staged user data with the given hash in the same
+// transaction as the import, so that it can't be imported twice; if it has
+// already been deleted, it returns `sql.ErrNoRows`
+func (p *sqlPersister) CreateUserData(ctx context.Context, stagedUserDataHash, namespace string) (
 	createJournalEntry func(journalEntry models.ExportedJournalEntry) error,
 	createContact func(contact models.ExportedContact) error,
 	createDebt func(debt models.ExportedDebt) error,
 	createActivity func(activty models.ExportedActivity) error,
+	createRelationship func(relationship models.ExportedRelationship) error,
+	createGroup func(group models.ExportedGroup) error,
 
 	commit func() error,
 	rollback func() error,
@@ -146,6 +417,8 @@ func (p *Persister) CreateUserData(ctx context.Context, namespace string) (
 	createContact = func(contact models.ExportedContact) error { return nil }
 	createDebt = func(debt models.ExportedDebt) error { return nil }
 	createActivity = func(activity models.ExportedActivity) error { return nil }
+	createRelationship = func(relationship models.ExportedRelationship) error { return nil }
+	createGroup = func(group models.ExportedGroup) error { return nil }
 
 	commit = func() error { return nil }
 	rollback = func() error { return nil }
@@ -156,11 +429,35 @@ func (p *Persister) CreateUserData(ctx context.Context, namespace string) (
 		return
 	}
 
-	qtx := p.queries.WithTx(tx)
+	qtx := p.withTx(tx)
+
+	if _, err = qtx.ClaimStagedUserData(ctx, models.ClaimStagedUserDataParams{
+		Hash:      stagedUserDataHash,
+		Namespace: namespace,
+	}); err != nil {
+		tx.Rollback()
+
+		return
+	}
 
 	var (
 		journalEntryIDMapLock sync.Mutex
 		journalEntryIDMap     = map[int32]int32{}
+
+		contactIDMapLock sync.Mutex
+		contactIDMap     = map[int32]int32{}
+
+		pendingDebtsLock sync.Mutex
+		pendingDebts     = []models.ExportedDebt{}
+
+		pendingActivitiesLock sync.Mutex
+		pendingActivities     = []models.ExportedActivity{}
+
+		pendingRelationshipsLock sync.Mutex
+		pendingRelationships     = []models.ExportedRelationship{}
+
+		pendingGroupsLock sync.Mutex
+		pendingGroups     = []models.ExportedGroup{}
 	)
 
 	createJournalEntry = func(journalEntry models.ExportedJournalEntry) error {
@@ -175,6 +472,10 @@ func (p *Persister) CreateUserData(ctx context.Context, namespace string) (
 			return err
 		}
 
+		if err := setJournalEntryTags(ctx, qtx, id, journalEntry.Tags, namespace); err != nil {
+			return err
+		}
+
 		journalEntryIDMapLock.Lock()
 		defer journalEntryIDMapLock.Unlock()
 
@@ -183,26 +484,244 @@ func (p *Persister) CreateUserData(ctx context.Context, namespace string) (
 		return nil
 	}
 
-	// TODO: Import debts and activities, use `journalEntryIDMap` to resolve external
-	// to internal/actual IDs
-
 	createContact = func(contact models.ExportedContact) error {
-		if _, err := qtx.CreateContact(ctx, models.CreateContactParams{
+		id, err := qtx.CreateContact(ctx, models.CreateContactParams{
 			FirstName: contact.FirstName,
 			LastName:  contact.LastName,
 			Nickname:  contact.Nickname,
-			Email:     contact.Email,
 			Pronouns:  contact.Pronouns,
 
+			Namespace: namespace,
+		})
+		if err != nil {
+			return err
+		}
+
+		// `CreateContact` only sets the fields required to add a contact, so the
+		// optional fields need to be set in a second step
+		if err := qtx.UpdateContact(ctx, models.UpdateContactParams{
+			ID:        id,
+			FirstName: contact.FirstName,
+			LastName:  contact.LastName,
+			Nickname:  contact.Nickname,
+			Pronouns:  contact.Pronouns,
+			Birthday:  contact.Birthday,
+			Notes:     contact.Notes,
+
 			Namespace: namespace,
 		}); err != nil {
 			return err
 		}
 
+		if err := setContactDetails(ctx, qtx, id, importedContactDetails(contact)); err != nil {
+			return err
+		}
+
+		if err := setContactTags(ctx, qtx, id, contact.Tags, namespace); err != nil {
+			return err
+		}
+
+		contactIDMapLock.Lock()
+		defer contactIDMapLock.Unlock()
+
+		contactIDMap[contact.ID] = id
+
 		return nil
 	}
 
-	commit = tx.Commit
+	// Debts, activities, relationships and groups reference contacts, so they
+	// are only created once all contacts have been created and `contactIDMap`
+	// can resolve external to internal/actual IDs
+	createDebt = func(debt models.ExportedDebt) error {
+		pendingDebtsLock.Lock()
+		defer pendingDebtsLock.Unlock()
+
+		pendingDebts = append(pendingDebts, debt)
+
+		return nil
+	}
+
+	createActivity = func(activity models.ExportedActivity) error {
+		pendingActivitiesLock.Lock()
+		defer pendingActivitiesLock.Unlock()
+
+		pendingActivities = append(pendingActivities, activity)
+
+		return nil
+	}
+
+	createRelationship = func(relationship models.ExportedRelationship) error {
+		pendingRelationshipsLock.Lock()
+		defer pendingRelationshipsLock.Unlock()
+
+		pendingRelationships = append(pendingRelationships, relationship)
+
+		return nil
+	}
+
+	createGroup = func(group models.ExportedGroup) error {
+		pendingGroupsLock.Lock()
+		defer pendingGroupsLock.Unlock()
+
+		pendingGroups = append(pendingGroups, group)
+
+		return nil
+	}
+
+	resolveContactID := func(contactID sql.NullInt32) (int32, error) {
+		if !contactID.Valid {
+			return -1, errMissingContactID
+		}
+
+		contactIDMapLock.Lock()
+		defer contactIDMapLock.Unlock()
+
+		id, ok := contactIDMap[contactID.Int32]
+		if !ok {
+			return -1, errors.Join(errUnknownContactID, fmt.Errorf("contact ID %v", contactID.Int32))
+		}
+
+		return id, nil
+	}
+
+	commit = func() error {
+		pendingDebtsLock.Lock()
+		defer pendingDebtsLock.Unlock()
+
+		for _, debt := range pendingDebts {
+			contactID, err := resolveContactID(debt.ContactID)
+			if err != nil {
+				return errors.Join(errCouldNotCreateDebt, err)
+			}
+
+			amount, paymentAmounts, err := importedDebtAmounts(debt)
+			if err != nil {
+				return errors.Join(errCouldNotCreateDebt, err)
+			}
+
+			id, err := qtx.CreateDebt(ctx, models.CreateDebtParams{
+				ID:          contactID,
+				Namespace:   namespace,
+				Amount:      amount,
+				Currency:    debt.Currency,
+				Description: debt.Description,
+				Date:        debt.Date,
+			})
+			if err != nil {
+				return errors.Join(errCouldNotCreateDebt, err)
+			}
+
+			for i, payment := range debt.Payments {
+				if _, err := qtx.CreateDebtPayment(ctx, models.CreateDebtPaymentParams{
+					ID_2: id,
+
+					ID:        contactID,
+					Namespace: namespace,
+
+					Amount:      paymentAmounts[i],
+					Date:        payment.Date,
+					Description: payment.Description,
+				}); err != nil {
+					return errors.Join(errCouldNotCreateDebt, err)
+				}
+			}
+		}
+
+		pendingActivitiesLock.Lock()
+		defer pendingActivitiesLock.Unlock()
+
+		for _, activity := range pendingActivities {
+			contactIDs := []int32{}
+			for _, exportedContactID := range activity.ContactIDs {
+				contactID, err := resolveContactID(sql.NullInt32{
+					Int32: exportedContactID,
+					Valid: true,
+				})
+				if err != nil {
+					return errors.Join(errCouldNotCreateActivity, err)
+				}
+
+				contactIDs = append(contactIDs, contactID)
+			}
+
+			if len(contactIDs) == 0 {
+				return errors.Join(errCouldNotCreateActivity, errMissingContactID)
+			}
+
+			activityID, err := qtx.CreateActivity(ctx, models.CreateActivityParams{
+				Name:        activity.Name,
+				Date:        activity.Date,
+				Description: activity.Description,
+				Namespace:   namespace,
+			})
+			if err != nil {
+				return errors.Join(errCouldNotCreateActivity, err)
+			}
+
+			if err := createActivityParticipants(ctx, qtx, activityID, contactIDs, namespace); err != nil {
+				return errors.Join(errCouldNotCreateActivity, err)
+			}
+		}
+
+		pendingRelationshipsLock.Lock()
+		defer pendingRelationshipsLock.Unlock()
+
+		for _, relationship := range pendingRelationships {
+			contactID, err := resolveContactID(relationship.ContactID)
+			if err != nil {
+				return errors.Join(errCouldNotCreateRelationship, err)
+			}
+
+			relatedContactID, err := resolveContactID(relationship.RelatedContactID)
+			if err != nil {
+				return errors.Join(errCouldNotCreateRelationship, err)
+			}
+
+			if _, err := qtx.CreateContactRelationship(ctx, models.CreateContactRelationshipParams{
+				Namespace:        namespace,
+				Type:             relationship.Type,
+				Label:            relationship.Label,
+				Reciprocal:       relationship.Reciprocal,
+				ContactID:        contactID,
+				RelatedContactID: relatedContactID,
+			}); err != nil {
+				return errors.Join(errCouldNotCreateRelationship, err)
+			}
+		}
+
+		pendingGroupsLock.Lock()
+		defer pendingGroupsLock.Unlock()
+
+		for _, group := range pendingGroups {
+			groupID, err := qtx.CreateContactGroup(ctx, models.CreateContactGroupParams{
+				Name:      group.Name,
+				Namespace: namespace,
+			})
+			if err != nil {
+				return errors.Join(errCouldNotCreateGroup, err)
+			}
+
+			for _, exportedContactID := range group.ContactIDs {
+				contactID, err := resolveContactID(sql.NullInt32{
+					Int32: exportedContactID,
+					Valid: true,
+				})
+				if err != nil {
+					return errors.Join(errCouldNotCreateGroup, err)
+				}
+
+				if err := qtx.CreateContactGroupMember(ctx, models.CreateContactGroupMemberParams{
+					ID:        groupID,
+					ID_2:      contactID,
+					Namespace: namespace,
+				}); err != nil {
+					return errors.Join(errCouldNotCreateGroup, err)
+				}
+			}
+		}
+
+		return tx.Commit()
+	}
 	rollback = tx.Rollback
 
 	return
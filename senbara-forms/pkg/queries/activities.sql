@@ -1,50 +1,72 @@
 -- name: CreateActivity :one
-with contact as (
-    select id
-    from contacts
-    where contacts.id = $1
-        and namespace = $2
-),
-insertion as (
-    insert into activities (name, date, description, contact_id)
-    select $3,
-        $4,
-        $5,
-        $1
-    from contact
-    where exists (
-            select 1
-            from contact
-        )
-    returning activities.id
-)
-select id
-from insertion;
+insert into activities (name, date, description, namespace)
+values ($1, $2, $3, $4)
+returning id;
+-- name: CreateActivityParticipant :one
+insert into activity_participants (activity_id, contact_id)
+select activities.id,
+    contacts.id
+from activities,
+    contacts
+where activities.id = $1
+    and activities.namespace = $3
+    and contacts.id = $2
+    and contacts.namespace = $3
+returning contact_id;
 -- name: GetActivities :many
 select activities.id,
     activities.name,
     activities.date,
     activities.description
-from contacts
-    right join activities on activities.contact_id = contacts.id
-where contacts.id = $1
-    and contacts.namespace = $2;
+from activity_participants
+    join activities on activities.id = activity_participants.activity_id
+where activity_participants.contact_id = $1
+    and activities.namespace = $2;
 -- name: GetActivity :one
 select *
 from contacts
 where contacts.id = $1
     and contacts.namespace = $2;
+-- name: GetActivityParticipants :many
+select contacts.id,
+    contacts.first_name,
+    contacts.last_name
+from activity_participants
+    join activities on activities.id = activity_participants.activity_id
+    join contacts on contacts.id = activity_participants.contact_id
+where activity_participants.activity_id = $1
+    and activities.namespace = $2
+order by contacts.first_name,
+    contacts.last_name,
+    contacts.id;
 -- name: DeleteActivity :exec
-delete from activities using contacts
-where activities.id = $3
-    and activities.contact_id = contacts.id
-    and contacts.id = $1
-    and contacts.namespace = $2;
--- name: DeleteActivitesForContact :exec
-delete from activities using contacts
-where activities.contact_id = contacts.id
-    and contacts.id = $1
-    and contacts.namespace = $2;
+delete from activities
+where id = $1
+    and namespace = $2;
+-- name: DeleteActivityParticipants :exec
+delete from activity_participants
+where activity_id in (
+        select id
+        from activities
+        where id = $1
+            and namespace = $2
+    );
+-- name: DeleteActivityParticipantsForContact :exec
+delete from activity_participants
+where contact_id = $1
+    and activity_id in (
+        select id
+        from activities
+        where namespace = $2
+    );
+-- name: DeleteActivitiesWithoutParticipantsForNamespace :exec
+delete from activities
+where namespace = $1
+    and not exists (
+        select 1
+        from activity_participants
+        where activity_participants.activity_id = activities.id
+    );
 -- name: GetActivityAndContact :one
 select activities.id as activity_id,
     activities.name,
@@ -54,31 +76,110 @@ select activities.id as activity_id,
     contacts.first_name,
     contacts.last_name
 from contacts
-    inner join activities on activities.contact_id = contacts.id
+    inner join activity_participants on activity_participants.contact_id = contacts.id
+    inner join activities on activities.id = activity_participants.activity_id
 where contacts.id = $1
     and contacts.namespace = $2
     and activities.id = $3;
+-- name: LockActivityVersion :execrows
+update activities
+set version = version
+where id = $1
+    and namespace = $2
+    and version = $3;
 -- name: UpdateActivity :exec
 update activities
 set name = $4,
     date = $5,
-    description = $6
-from contacts
-where contacts.id = $1
-    and contacts.namespace = $2
+    description = $6,
+    version = activities.version + 1
+from activity_participants
+where activity_participants.contact_id = $1
+    and activities.namespace = $2
     and activities.id = $3
-    and activities.contact_id = contacts.id;
+    and activity_participants.activity_id = activities.id;
 -- name: GetActivitiesExportForNamespace :many
 select 'activites' as table_name,
     activities.id,
     activities.name,
     activities.date,
-    activities.description,
-    contacts.id as contact_id
-from contacts
-    right join activities on activities.contact_id = contacts.id
-where contacts.namespace = $1;
+    activities.description
+from activities
+where activities.namespace = $1;
+-- name: GetActivityParticipantsExportForNamespace :many
+select activity_participants.activity_id,
+    activity_participants.contact_id
+from activity_participants
+    join activities on activities.id = activity_participants.activity_id
+where activities.namespace = $1
+order by activity_participants.activity_id,
+    activity_participants.contact_id;
+-- name: DeleteActivityParticipantsForNamespace :exec
+delete from activity_participants
+where activity_id in (
+        select id
+        from activities
+        where namespace = $1
+    );
 -- name: DeleteActivitiesForNamespace :exec
-delete from activities using contacts
-where activities.contact_id = contacts.id
-    and contacts.namespace = $1;
\ No newline at end of file
+delete from activities
+where namespace = $1;
+-- name: GetActivitiesCalendarForNamespace :many
+select activities.id,
+    activities.name,
+    activities.date,
+    activities.description,
+    activities.version,
+    contacts.id as contact_id,
+    contacts.first_name,
+    contacts.last_name,
+    cast(
+        coalesce(
+            (
+                select contact_emails.email
+                from contact_emails
+                where contact_emails.contact_id = contacts.id
+                order by contact_emails.id
+                limit 1
+            ),
+            ''
+        ) as text
+    ) as email
+from activities
+    inner join activity_participants on activity_participants.activity_id = activities.id
+    inner join contacts on contacts.id = activity_participants.contact_id
+where activities.namespace = $1
+order by activities.date desc,
+    activities.id,
+    contacts.first_name,
+    contacts.last_name,
+    contacts.id;
+-- name: GetActivityCalendar :many
+select activities.id,
+    activities.name,
+    activities.date,
+    activities.description,
+    activities.version,
+    contacts.id as contact_id,
+    contacts.first_name,
+    contacts.last_name,
+    cast(
+        coalesce(
+            (
+                select contact_emails.email
+                from contact_emails
+                where contact_emails.contact_id = contacts.id
+                order by contact_emails.id
+                limit 1
+            ),
+            ''
+        ) as text
+    ) as email
+from activities
+    inner join activity_participants on activity_participants.activity_id = activities.id
+    inner join contacts on contacts.id = activity_participants.contact_id
+where activities.id = $1
+    and activities.namespace = $2
+order by contacts.first_name,
+    contacts.last_name,
+    contacts.id;
\ No newline at end of file
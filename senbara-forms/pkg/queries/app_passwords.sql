@@ -0,0 +1,22 @@
+-- name: GetAppPasswords :many
+select id,
+    name,
+    created_at
+from app_passwords
+where namespace = $1
+order by created_at desc;
+-- name: CreateAppPassword :one
+insert into app_passwords (name, hash, namespace)
+values ($1, $2, $3)
+returning id;
+-- name: DeleteAppPassword :exec
+delete from app_passwords
+where id = $1
+    and namespace = $2;
+-- name: GetAppPasswordNamespace :one
+select namespace
+from app_passwords
+where hash = $1;
+-- name: DeleteAppPasswordsForNamespace :exec
+delete from app_passwords
+where namespace = $1;
\ No newline at end of file
@@ -0,0 +1,128 @@
+-- name: GetContactEmails :many
+select contact_emails.*
+from contact_emails
+    join contacts on contacts.id = contact_emails.contact_id
+where contact_emails.contact_id = $1
+    and contacts.namespace = $2
+order by contact_emails.id;
+-- name: GetContactEmailsForNamespace :many
+select contact_emails.*
+from contact_emails
+    join contacts on contacts.id = contact_emails.contact_id
+where contacts.namespace = $1
+order by contact_emails.contact_id,
+    contact_emails.id;
+-- name: CreateContactEmail :exec
+insert into contact_emails (contact_id, label, email)
+values ($1, $2, $3);
+-- name: DeleteContactEmails :exec
+delete from contact_emails
+where contact_id in (
+        select id
+        from contacts
+        where id = $1
+            and namespace = $2
+    );
+-- name: DeleteContactEmailsForNamespace :exec
+delete from contact_emails
+where contact_id in (
+        select id
+        from contacts
+        where namespace = $1
+    );
+-- name: GetContactPhones :many
+select contact_phones.*
+from contact_phones
+    join contacts on contacts.id = contact_phones.contact_id
+where contact_phones.contact_id = $1
+    and contacts.namespace = $2
+order by contact_phones.id;
+-- name: GetContactPhonesForNamespace :many
+select contact_phones.*
+from contact_phones
+    join contacts on contacts.id = contact_phones.contact_id
+where contacts.namespace = $1
+order by contact_phones.contact_id,
+    contact_phones.id;
+-- name: CreateContactPhone :exec
+insert into contact_phones (contact_id, label, number)
+values ($1, $2, $3);
+-- name: DeleteContactPhones :exec
+delete from contact_phones
+where contact_id in (
+        select id
+        from contacts
+        where id = $1
+            and namespace = $2
+    );
+-- name: DeleteContactPhonesForNamespace :exec
+delete from contact_phones
+where contact_id in (
+        select id
+        from contacts
+        where namespace = $1
+    );
+-- name: GetContactAddresses :many
+select contact_addresses.*
+from contact_addresses
+    join contacts on contacts.id = contact_addresses.contact_id
+where contact_addresses.contact_id = $1
+    and contacts.namespace = $2
+order by contact_addresses.id;
+-- name: GetContactAddressesForNamespace :many
+select contact_addresses.*
+from contact_addresses
+    join contacts on contacts.id = contact_addresses.contact_id
+where contacts.namespace = $1
+order by contact_addresses.contact_id,
+    contact_addresses.id;
+-- name: CreateContactAddress :exec
+insert into contact_addresses (contact_id, label, address)
+values ($1, $2, $3);
+-- name: DeleteContactAddresses :exec
+delete from contact_addresses
+where contact_id in (
+        select id
+        from contacts
+        where id = $1
+            and namespace = $2
+    );
+-- name: DeleteContactAddressesForNamespace :exec
+delete from contact_addresses
+where contact_id in (
+        select id
+        from contacts
+        where namespace = $1
+    );
+-- name: GetContactWebsites :many
+select contact_websites.*
+from contact_websites
+    join contacts on contacts.id = contact_websites.contact_id
+where contact_websites.contact_id = $1
+    and contacts.namespace = $2
+order by contact_websites.id;
+-- name: GetContactWebsitesForNamespace :many
+select contact_websites.*
+from contact_websites
+    join contacts on contacts.id = contact_websites.contact_id
+where contacts.namespace = $1
+order by contact_websites.contact_id,
+    contact_websites.id;
+-- name: CreateContactWebsite :exec
+insert into contact_websites (contact_id, label, url)
+values ($1, $2, $3);
+-- name: DeleteContactWebsites :exec
+delete from contact_websites
+where contact_id in (
+        select id
+        from contacts
+        where id = $1
+            and namespace = $2
+    );
+-- name: DeleteContactWebsitesForNamespace :exec
+delete from contact_websites
+where contact_id in (
+        select id
+        from contacts
+        where namespace = $1
+    );
\ No newline at end of file
@@ -0,0 +1,99 @@
+-- name: CreateContactGroup :one
+insert into contact_groups (name, namespace)
+values ($1, $2)
+returning id;
+-- name: GetContactGroups :many
+select contact_groups.id,
+    contact_groups.name,
+    count(contact_group_members.contact_id) as member_count
+from contact_groups
+    left join contact_group_members on contact_group_members.group_id = contact_groups.id
+where contact_groups.namespace = $1
+group by contact_groups.id,
+    contact_groups.name
+order by contact_groups.name,
+    contact_groups.id;
+-- name: GetContactGroup :one
+select *
+from contact_groups
+where id = $1
+    and namespace = $2;
+-- name: UpdateContactGroup :exec
+update contact_groups
+set name = $3
+where id = $1
+    and namespace = $2;
+-- name: DeleteContactGroup :exec
+delete from contact_groups
+where id = $1
+    and namespace = $2;
+-- name: GetContactGroupMembers :many
+select contacts.id,
+    contacts.first_name,
+    contacts.last_name
+from contact_group_members
+    join contact_groups on contact_groups.id = contact_group_members.group_id
+    join contacts on contacts.id = contact_group_members.contact_id
+where contact_group_members.group_id = $1
+    and contact_groups.namespace = $2
+order by contacts.first_name,
+    contacts.last_name,
+    contacts.id;
+-- name: CreateContactGroupMember :exec
+insert into contact_group_members (group_id, contact_id)
+select contact_groups.id,
+    contacts.id
+from contact_groups,
+    contacts
+where contact_groups.id = $1
+    and contact_groups.namespace = $3
+    and contacts.id = $2
+    and contacts.namespace = $3 on conflict do nothing;
+-- name: DeleteContactGroupMember :exec
+delete from contact_group_members
+where group_id = $1
+    and contact_id = $2
+    and group_id in (
+        select id
+        from contact_groups
+        where namespace = $3
+    );
+-- name: DeleteContactGroupMembers :exec
+delete from contact_group_members
+where group_id in (
+        select id
+        from contact_groups
+        where id = $1
+            and namespace = $2
+    );
+-- name: DeleteContactGroupMembersForContact :exec
+delete from contact_group_members
+where contact_id = $1
+    and group_id in (
+        select id
+        from contact_groups
+        where namespace = $2
+    );
+-- name: GetContactGroupsForNamespace :many
+select *
+from contact_groups
+where namespace = $1
+order by id;
+-- name: GetContactGroupMembersExportForNamespace :many
+select contact_group_members.group_id,
+    contact_group_members.contact_id
+from contact_group_members
+    join contact_groups on contact_groups.id = contact_group_members.group_id
+where contact_groups.namespace = $1
+order by contact_group_members.group_id,
+    contact_group_members.contact_id;
+-- name: DeleteContactGroupMembersForNamespace :exec
+delete from contact_group_members
+where group_id in (
+        select id
+        from contact_groups
+        where namespace = $1
+    );
+-- name: DeleteContactGroupsForNamespace :exec
+delete from contact_groups
+where namespace = $1;
\ No newline at end of file
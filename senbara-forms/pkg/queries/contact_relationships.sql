@@ -0,0 +1,62 @@
+-- name: CreateContactRelationship :one
+insert into contact_relationships (
+        namespace,
+        contact_id,
+        related_contact_id,
+        type,
+        label,
+        reciprocal
+    )
+select sqlc.arg(namespace),
+    contacts.id,
+    related_contacts.id,
+    sqlc.arg(type),
+    sqlc.arg(label),
+    sqlc.arg(reciprocal)
+from contacts,
+    contacts as related_contacts
+where contacts.id = sqlc.arg(contact_id)
+    and contacts.namespace = sqlc.arg(namespace)
+    and related_contacts.id = sqlc.arg(related_contact_id)
+    and related_contacts.namespace = sqlc.arg(namespace)
+    and contacts.id <> related_contacts.id
+returning id;
+-- name: GetContactRelationships :many
+select contact_relationships.id,
+    contact_relationships.contact_id,
+    contact_relationships.related_contact_id,
+    contact_relationships.type,
+    contact_relationships.label,
+    contact_relationships.reciprocal,
+    contacts.first_name,
+    contacts.last_name,
+    related_contacts.first_name as related_first_name,
+    related_contacts.last_name as related_last_name
+from contact_relationships
+    join contacts on contacts.id = contact_relationships.contact_id
+    join contacts as related_contacts on related_contacts.id = contact_relationships.related_contact_id
+where contact_relationships.namespace = $2
+    and (
+        contact_relationships.contact_id = $1
+        or contact_relationships.related_contact_id = $1
+    )
+order by contact_relationships.id;
+-- name: GetContactRelationshipsForNamespace :many
+select *
+from contact_relationships
+where namespace = $1
+order by id;
+-- name: DeleteContactRelationship :exec
+delete from contact_relationships
+where id = $1
+    and namespace = $2;
+-- name: DeleteContactRelationshipsForContact :exec
+delete from contact_relationships
+where namespace = $2
+    and (
+        contact_id = $1
+        or related_contact_id = $1
+    );
+-- name: DeleteContactRelationshipsForNamespace :exec
+delete from contact_relationships
+where namespace = $1;
\ No newline at end of file
@@ -8,11 +8,10 @@ insert into contacts (
         first_name,
         last_name,
         nickname,
-        email,
         pronouns,
         namespace
     )
-values ($1, $2, $3, $4, $5, $6)
+values ($1, $2, $3, $4, $5)
 returning id;
 -- name: DeleteContact :exec
 delete from contacts
@@ -28,13 +27,18 @@ update contacts
 set first_name = $3,
     last_name = $4,
     nickname = $5,
-    email = $6,
-    pronouns = $7,
-    birthday = $8,
-    address = $9,
-    notes = $10
+    pronouns = $6,
+    birthday = $7,
+    notes = $8,
+    version = version + 1
 where id = $1
     and namespace = $2;
+-- name: LockContactVersion :execrows
+update contacts
+set version = version
+where id = $1
+    and namespace = $2
+    and version = $3;
 -- name: DeleteContactsForNamespace :exec
 delete from contacts
 where namespace = $1;
@@ -43,4 +47,110 @@ select 'contacts' as table_name,
     *
 from contacts
 where namespace = $1
-order by first_name desc;
\ No newline at end of file
+order by first_name desc;
+-- name: GetContactByEmail :one
+select contacts.*
+from contacts
+    join contact_emails on contact_emails.contact_id = contacts.id
+where contacts.namespace = $1
+    and lower(contact_emails.email) = lower($2)
+order by contacts.id
+limit 1;
+-- name: ListContacts :many
+select *
+from (
+        select contacts.*,
+            cast(
+                case
+                    sqlc.arg(sort)::text
+                    when 'last_name' then contacts.last_name
+                    when 'birthday' then coalesce(to_char(contacts.birthday, 'MM-DD'), '99-99')
+                    when 'last_contacted' then coalesce(
+                        (
+                            select to_char(max(activities.date), 'YYYY-MM-DD"T"HH24:MI:SS')
+                            from activity_participants
+                                join activities on activities.id = activity_participants.activity_id
+                            where activity_participants.contact_id = contacts.id
+                        ),
+                        ''
+                    )
+                    else contacts.first_name
+                end as text
+            ) as sort_key,
+            cast(
+                coalesce(
+                    (
+                        select string_agg(tags.name, ', ' order by tags.name)
+                        from contact_tags
+                            join tags on tags.id = contact_tags.tag_id
+                        where contact_tags.contact_id = contacts.id
+                    ),
+                    ''
+                ) as text
+            ) as tags,
+            cast(
+                coalesce(
+                    (
+                        select contact_emails.email
+                        from contact_emails
+                        where contact_emails.contact_id = contacts.id
+                        order by contact_emails.id
+                        limit 1
+                    ),
+                    ''
+                ) as text
+            ) as email
+        from contacts
+        where contacts.namespace = sqlc.arg(namespace)
+            and (
+                not sqlc.arg(has_debts)::boolean
+                or exists (
+                    select 1
+                    from debts
+                    where debts.contact_id = contacts.id
+                        and abs(debts.amount) - coalesce(
+                            (
+                                select sum(debt_payments.amount)
+                                from debt_payments
+                                where debt_payments.debt_id = debts.id
+                            ),
+                            0
+                        ) > 0
+                )
+            )
+            and (
+                sqlc.arg(birthday_month)::integer = 0
+                or extract(
+                    month
+                    from contacts.birthday
+                ) = sqlc.arg(birthday_month)::integer
+            )
+            and (
+                sqlc.arg(tag)::text = ''
+                or exists (
+                    select 1
+                    from contact_tags
+                        join tags on tags.id = contact_tags.tag_id
+                    where contact_tags.contact_id = contacts.id
+                        and tags.name = sqlc.arg(tag)::text
+                )
+            )
+    ) as sorted_contacts
+where sqlc.arg(after_id)::integer = 0
+    or (
+        sqlc.arg(sort)::text = 'last_contacted'
+        and (sort_key, id) < (sqlc.arg(after_key)::text, sqlc.arg(after_id)::integer)
+    )
+    or (
+        sqlc.arg(sort)::text <> 'last_contacted'
+        and (sort_key, id) > (sqlc.arg(after_key)::text, sqlc.arg(after_id)::integer)
+    )
+order by case
+        when sqlc.arg(sort)::text = 'last_contacted' then sort_key
+    end desc,
+    case
+        when sqlc.arg(sort)::text = 'last_contacted' then id
+    end desc,
+    sort_key asc,
+    id asc
+limit sqlc.arg(result_limit);
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- name: GetDAVResources :many
+select name,
+    resource_id,
+    uid
+from dav_resources
+where collection = $1
+    and namespace = $2;
+-- name: GetDAVResource :one
+select resource_id,
+    uid
+from dav_resources
+where collection = $1
+    and name = $2
+    and namespace = $3;
+-- name: UpsertDAVResource :exec
+insert into dav_resources (collection, name, resource_id, uid, namespace)
+values ($1, $2, $3, $4, $5) on conflict (collection, name, namespace) do
+update
+set resource_id = excluded.resource_id,
+    uid = excluded.uid;
+-- name: DeleteDAVResources :exec
+delete from dav_resources
+where collection = $1
+    and resource_id = $2
+    and namespace = $3;
+-- name: DeleteDAVResourcesForNamespace :exec
+delete from dav_resources
+where namespace = $1;
\ No newline at end of file
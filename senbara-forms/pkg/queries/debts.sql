@@ -6,10 +6,11 @@ with contact as (
         and namespace = $2
 ),
 insertion as (
-    insert into debts (amount, currency, description, contact_id)
+    insert into debts (amount, currency, description, date, contact_id)
     select $3,
         $4,
         $5,
+        $6,
         $1
     from contact
     where exists (
@@ -24,14 +25,51 @@ from insertion;
 select debts.id,
     debts.amount,
     debts.currency,
-    debts.description
+    debts.description,
+    debts.date,
+    coalesce(
+        (
+            select sum(debt_payments.amount)
+            from debt_payments
+            where debt_payments.debt_id = debts.id
+        ),
+        0
+    )::bigint as paid
 from contacts
     right join debts on debts.contact_id = contacts.id
 where contacts.id = $1
-    and contacts.namespace = $2;
--- name: SettleDebt :exec
-delete from debts using contacts
-where debts.id = $3
+    and contacts.namespace = $2
+order by debts.date,
+    debts.id;
+-- name: CreateDebtPayment :one
+insert into debt_payments (debt_id, amount, date, description)
+select debts.id,
+    $4,
+    $5,
+    $6
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+where contacts.id = $1
+    and contacts.namespace = $2
+    and debts.id = $3
+returning debt_payments.id;
+-- name: GetDebtPayments :many
+select debt_payments.id,
+    debt_payments.debt_id,
+    debt_payments.amount,
+    debt_payments.date,
+    debt_payments.description
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+    inner join debt_payments on debt_payments.debt_id = debts.id
+where contacts.id = $1
+    and contacts.namespace = $2
+order by debt_payments.date,
+    debt_payments.id;
+-- name: DeleteDebtPaymentsForContact :exec
+delete from debt_payments using debts,
+    contacts
+where debt_payments.debt_id = debts.id
     and debts.contact_id = contacts.id
     and contacts.id = $1
     and contacts.namespace = $2;
@@ -45,6 +83,15 @@ select debts.id as debt_id,
     debts.amount,
     debts.currency,
     debts.description,
+    debts.date,
+    coalesce(
+        (
+            select sum(debt_payments.amount)
+            from debt_payments
+            where debt_payments.debt_id = debts.id
+        ),
+        0
+    )::bigint as paid,
     contacts.id as contact_id,
     contacts.first_name,
     contacts.last_name
@@ -57,7 +104,8 @@ where contacts.id = $1
 update debts
 set amount = $4,
     currency = $5,
-    description = $6
+    description = $6,
+    date = $7
 from contacts
 where contacts.id = $1
     and contacts.namespace = $2
@@ -69,10 +117,29 @@ select 'debts' as table_name,
     debts.amount,
     debts.currency,
     debts.description,
+    debts.date,
     contacts.id as contact_id
 from contacts
     right join debts on debts.contact_id = contacts.id
 where contacts.namespace = $1;
+-- name: GetDebtPaymentsExportForNamespace :many
+select debt_payments.id,
+    debt_payments.debt_id,
+    debt_payments.amount,
+    debt_payments.date,
+    debt_payments.description
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+    inner join debt_payments on debt_payments.debt_id = debts.id
+where contacts.namespace = $1
+order by debt_payments.date,
+    debt_payments.id;
+-- name: DeleteDebtPaymentsForNamespace :exec
+delete from debt_payments using debts,
+    contacts
+where debt_payments.debt_id = debts.id
+    and debts.contact_id = contacts.id
+    and contacts.namespace = $1;
 -- name: DeleteDebtsForNamespace :exec
 delete from debts using contacts
 where debts.contact_id = contacts.id
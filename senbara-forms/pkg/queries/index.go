@@ -0,0 +1,6 @@
+package queries
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
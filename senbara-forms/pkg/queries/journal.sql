@@ -31,4 +31,61 @@ select 'journal_entries' as table_name,
     *
 from journal_entries
 where namespace = $1
-order by date desc;
\ No newline at end of file
+order by date desc;
+-- name: ListJournalEntries :many
+select journal_entries.*,
+    cast(
+        coalesce(
+            (
+                select string_agg(tags.name, ', ' order by tags.name)
+                from journal_entry_tags
+                    join tags on tags.id = journal_entry_tags.tag_id
+                where journal_entry_tags.journal_entry_id = journal_entries.id
+            ),
+            ''
+        ) as text
+    ) as tags
+from journal_entries
+where namespace = sqlc.arg(namespace)
+    and (
+        sqlc.arg(rating)::integer = 0
+        or rating = sqlc.arg(rating)::integer
+    )
+    and (
+        sqlc.narg(from_date)::timestamp is null
+        or date >= sqlc.narg(from_date)::timestamp
+    )
+    and (
+        sqlc.narg(to_date)::timestamp is null
+        or date < sqlc.narg(to_date)::timestamp
+    )
+    and (
+        sqlc.arg(tag)::text = ''
+        or exists (
+            select 1
+            from journal_entry_tags
+                join tags on tags.id = journal_entry_tags.tag_id
+            where journal_entry_tags.journal_entry_id = journal_entries.id
+                and tags.name = sqlc.arg(tag)::text
+        )
+    )
+    and (
+        sqlc.arg(after_id)::integer = 0
+        or (
+            sqlc.arg(oldest_first)::boolean
+            and (date, id) > (sqlc.arg(after_date)::timestamp, sqlc.arg(after_id)::integer)
+        )
+        or (
+            not sqlc.arg(oldest_first)::boolean
+            and (date, id) < (sqlc.arg(after_date)::timestamp, sqlc.arg(after_id)::integer)
+        )
+    )
+order by case
+        when sqlc.arg(oldest_first)::boolean then date
+    end asc,
+    case
+        when sqlc.arg(oldest_first)::boolean then id
+    end asc,
+    date desc,
+    id desc
+limit sqlc.arg(result_limit);
\ No newline at end of file
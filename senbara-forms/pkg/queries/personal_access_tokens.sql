@@ -0,0 +1,26 @@
+-- name: GetPersonalAccessTokens :many
+select id,
+    name,
+    scopes,
+    created_at,
+    expires_at
+from personal_access_tokens
+where namespace = $1
+order by created_at desc;
+-- name: CreatePersonalAccessToken :one
+insert into personal_access_tokens (name, hash, scopes, expires_at, namespace)
+values ($1, $2, $3, $4, $5)
+returning id;
+-- name: DeletePersonalAccessToken :exec
+delete from personal_access_tokens
+where id = $1
+    and namespace = $2;
+-- name: GetPersonalAccessToken :one
+select namespace,
+    scopes,
+    expires_at
+from personal_access_tokens
+where hash = $1;
+-- name: DeletePersonalAccessTokensForNamespace :exec
+delete from personal_access_tokens
+where namespace = $1;
\ No newline at end of file
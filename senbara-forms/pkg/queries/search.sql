@@ -0,0 +1,32 @@
+-- name: UpdateSearchDocumentsConfig :exec
+update search_documents
+set config = sqlc.arg(config)::regconfig
+where namespace = sqlc.arg(namespace)
+    and config <> sqlc.arg(config)::regconfig;
+-- name: SearchDocuments :many
+select entity_name,
+    entity_id,
+    contact_id,
+    ts_rank_cd(document, query)::real as rank,
+    ts_headline(
+        config,
+        title,
+        query,
+        sqlc.arg(headline_options)
+    ) as title_headline,
+    ts_headline(
+        config,
+        body,
+        query,
+        sqlc.arg(headline_options)
+    ) as body_headline
+from search_documents,
+    websearch_to_tsquery(
+        search_config_for_namespace(sqlc.arg(namespace)),
+        sqlc.arg(query)
+    ) as query
+where namespace = sqlc.arg(namespace)
+    and document @@ query
+order by rank desc,
+    entity_id desc
+limit sqlc.arg(result_limit);
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- name: UpsertCalendarFeed :exec
+insert into calendar_feeds (hash, namespace)
+values (?1, ?2) on conflict (namespace) do
+update
+set hash = excluded.hash,
+    created_at = current_timestamp;
+-- name: GetCalendarFeed :one
+select id,
+    created_at
+from calendar_feeds
+where namespace = ?1;
+-- name: GetCalendarFeedNamespace :one
+select namespace
+from calendar_feeds
+where hash = ?1;
+-- name: DeleteCalendarFeedForNamespace :exec
+delete from calendar_feeds
+where namespace = ?1;
\ No newline at end of file
@@ -0,0 +1,155 @@
+-- name: GetContacts :many
+select *
+from contacts
+where namespace = ?1
+order by first_name desc;
+-- name: CreateContact :one
+insert into contacts (
+        first_name,
+        last_name,
+        nickname,
+        pronouns,
+        namespace
+    )
+values (?1, ?2, ?3, ?4, ?5)
+returning id;
+-- name: DeleteContact :exec
+delete from contacts
+where id = ?1
+    and namespace = ?2;
+-- name: GetContact :one
+select *
+from contacts
+where id = ?1
+    and namespace = ?2;
+-- name: UpdateContact :exec
+update contacts
+set first_name = ?3,
+    last_name = ?4,
+    nickname = ?5,
+    pronouns = ?6,
+    birthday = ?7,
+    notes = ?8,
+    version = version + 1
+where id = ?1
+    and namespace = ?2;
+-- name: LockContactVersion :execrows
+update contacts
+set version = version
+where id = ?1
+    and namespace = ?2
+    and version = ?3;
+-- name: DeleteContactsForNamespace :exec
+delete from contacts
+where namespace = ?1;
+-- name: GetContactsExportForNamespace :many
+select 'contacts' as table_name,
+    *
+from contacts
+where namespace = ?1
+order by first_name desc;
+-- name: GetContactByEmail :one
+select contacts.*
+from contacts
+    join contact_emails on contact_emails.contact_id = contacts.id
+where contacts.namespace = ?1
+    and lower(contact_emails.email) = lower(?2)
+order by contacts.id
+limit 1;
+-- name: ListContacts :many
+select *
+from (
+        select contacts.*,
+            cast(
+                case
+                    ?1
+                    when 'last_name' then contacts.last_name
+                    when 'birthday' then coalesce(strftime('%m-%d', contacts.birthday), '99-99')
+                    when 'last_contacted' then coalesce(
+                        (
+                            select strftime('%Y-%m-%dT%H:%M:%S', max(datetime(activities.date)))
+                            from activity_participants
+                                join activities on activities.id = activity_participants.activity_id
+                            where activity_participants.contact_id = contacts.id
+                        ),
+                        ''
+                    )
+                    else contacts.first_name
+                end as text
+            ) as sort_key,
+            coalesce(
+                (
+                    select group_concat(name, ', ')
+                    from (
+                            select tags.name
+                            from contact_tags
+                                join tags on tags.id = contact_tags.tag_id
+                            where contact_tags.contact_id = contacts.id
+                            order by tags.name
+                        )
+                ),
+                ''
+            ) as tags,
+            cast(
+                coalesce(
+                    (
+                        select contact_emails.email
+                        from contact_emails
+                        where contact_emails.contact_id = contacts.id
+                        order by contact_emails.id
+                        limit 1
+                    ),
+                    ''
+                ) as text
+            ) as email
+        from contacts
+        where contacts.namespace = ?2
+            and (
+                not ?3
+                or exists (
+                    select 1
+                    from debts
+                    where debts.contact_id = contacts.id
+                        and abs(debts.amount) - coalesce(
+                            (
+                                select sum(debt_payments.amount)
+                                from debt_payments
+                                where debt_payments.debt_id = debts.id
+                            ),
+                            0
+                        ) > 0
+                )
+            )
+            and (
+                ?4 = 0
+                or cast(strftime('%m', contacts.birthday) as integer) = ?4
+            )
+            and (
+                ?5 = ''
+                or exists (
+                    select 1
+                    from contact_tags
+                        join tags on tags.id = contact_tags.tag_id
+                    where contact_tags.contact_id = contacts.id
+                        and tags.name = ?5
+                )
+            )
+    ) as sorted_contacts
+where ?6 = 0
+    or (
+        ?1 = 'last_contacted'
+        and (sort_key, id) < (?7, ?6)
+    )
+    or (
+        ?1 <> 'last_contacted'
+        and (sort_key, id) > (?7, ?6)
+    )
+order by case
+        when ?1 = 'last_contacted' then sort_key
+    end desc,
+    case
+        when ?1 = 'last_contacted' then id
+    end desc,
+    sort_key asc,
+    id asc
+limit ?8;
\ No newline at end of file
@@ -0,0 +1,148 @@
+-- name: CreateDebt :one
+insert into debts (amount, currency, description, date, contact_id)
+select ?3,
+    ?4,
+    ?5,
+    ?6,
+    contacts.id
+from contacts
+where contacts.id = ?1
+    and contacts.namespace = ?2
+returning id;
+-- name: GetDebts :many
+select debts.id,
+    debts.amount,
+    debts.currency,
+    debts.description,
+    debts.date,
+    cast(
+        coalesce(
+            (
+                select sum(debt_payments.amount)
+                from debt_payments
+                where debt_payments.debt_id = debts.id
+            ),
+            0
+        ) as integer
+    ) as paid
+from contacts
+    right join debts on debts.contact_id = contacts.id
+where contacts.id = ?1
+    and contacts.namespace = ?2
+order by debts.date,
+    debts.id;
+-- name: CreateDebtPayment :one
+insert into debt_payments (debt_id, amount, date, description)
+select debts.id,
+    ?4,
+    ?5,
+    ?6
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+where contacts.id = ?1
+    and contacts.namespace = ?2
+    and debts.id = ?3
+returning id;
+-- name: GetDebtPayments :many
+select debt_payments.id,
+    debt_payments.debt_id,
+    debt_payments.amount,
+    debt_payments.date,
+    debt_payments.description
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+    inner join debt_payments on debt_payments.debt_id = debts.id
+where contacts.id = ?1
+    and contacts.namespace = ?2
+order by debt_payments.date,
+    debt_payments.id;
+-- name: DeleteDebtPaymentsForContact :exec
+delete from debt_payments
+where debt_payments.debt_id in (
+        select debts.id
+        from contacts
+            inner join debts on debts.contact_id = contacts.id
+        where contacts.id = ?1
+            and contacts.namespace = ?2
+    );
+-- name: DeleteDebtsForContact :exec
+delete from debts
+where debts.contact_id in (
+        select contacts.id
+        from contacts
+        where contacts.id = ?1
+            and contacts.namespace = ?2
+    );
+-- name: GetDebtAndContact :one
+select debts.id as debt_id,
+    debts.amount,
+    debts.currency,
+    debts.description,
+    debts.date,
+    cast(
+        coalesce(
+            (
+                select sum(debt_payments.amount)
+                from debt_payments
+                where debt_payments.debt_id = debts.id
+            ),
+            0
+        ) as integer
+    ) as paid,
+    contacts.id as contact_id,
+    contacts.first_name,
+    contacts.last_name
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+where contacts.id = ?1
+    and contacts.namespace = ?2
+    and debts.id = ?3;
+-- name: UpdateDebt :exec
+update debts
+set amount = ?4,
+    currency = ?5,
+    description = ?6,
+    date = ?7
+from contacts
+where contacts.id = ?1
+    and contacts.namespace = ?2
+    and debts.id = ?3
+    and debts.contact_id = contacts.id;
+-- name: GetDebtsExportForNamespace :many
+select 'debts' as table_name,
+    debts.id,
+    debts.amount,
+    debts.currency,
+    debts.description,
+    debts.date,
+    contacts.id as contact_id
+from contacts
+    right join debts on debts.contact_id = contacts.id
+where contacts.namespace = ?1;
+-- name: GetDebtPaymentsExportForNamespace :many
+select debt_payments.id,
+    debt_payments.debt_id,
+    debt_payments.amount,
+    debt_payments.date,
+    debt_payments.description
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+    inner join debt_payments on debt_payments.debt_id = debts.id
+where contacts.namespace = ?1
+order by debt_payments.date,
+    debt_payments.id;
+-- name: DeleteDebtPaymentsForNamespace :exec
+delete from debt_payments
+where debt_payments.debt_id in (
+        select debts.id
+        from contacts
+            inner join debts on debts.contact_id = contacts.id
+        where contacts.namespace = ?1
+    );
+-- name: DeleteDebtsForNamespace :exec
+delete from debts
+where debts.contact_id in (
+        select contacts.id
+        from contacts
+        where contacts.namespace = ?1
+    );
\ No newline at end of file
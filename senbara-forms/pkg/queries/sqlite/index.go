@@ -0,0 +1,10 @@
+package sqlite
+
+import "embed"
+
+// FS contains the SQLite versions of the queries in `pkg/queries`. They keep
+// the names, parameter numbers and result columns of the Postgres queries so
+// that they can be run by the code sqlc generated for them.
+//
+//go:embed *.sql
+var FS embed.FS
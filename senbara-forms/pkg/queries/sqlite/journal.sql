@@ -0,0 +1,93 @@
+-- name: GetJournalEntries :many
+select *
+from journal_entries
+where namespace = ?1
+order by date desc;
+-- name: GetJournalEntry :one
+select *
+from journal_entries
+where id = ?1
+    and namespace = ?2;
+-- name: CreateJournalEntry :one
+insert into journal_entries (title, body, rating, namespace)
+values (?1, ?2, ?3, ?4)
+returning id;
+-- name: DeleteJournalEntry :exec
+delete from journal_entries
+where id = ?1
+    and namespace = ?2;
+-- name: UpdateJournalEntry :exec
+update journal_entries
+set title = ?3,
+    body = ?4,
+    rating = ?5
+where id = ?1
+    and namespace = ?2;
+-- name: DeleteJournalEntriesForNamespace :exec
+delete from journal_entries
+where namespace = ?1;
+-- name: GetJournalEntriesExportForNamespace :many
+select 'journal_entries' as table_name,
+    *
+from journal_entries
+where namespace = ?1
+order by date desc;
+-- name: ListJournalEntries :many
+select journal_entries.*,
+    coalesce(
+        (
+            select group_concat(name, ', ')
+            from (
+                    select tags.name
+                    from journal_entry_tags
+                        join tags on tags.id = journal_entry_tags.tag_id
+                    where journal_entry_tags.journal_entry_id = journal_entries.id
+                    order by tags.name
+                )
+        ),
+        ''
+    ) as tags
+from journal_entries
+where namespace = ?1
+    and (
+        ?2 = 0
+        or rating = ?2
+    )
+    and (
+        ?3 is null
+        or datetime(date) >= datetime(?3)
+    )
+    and (
+        ?4 is null
+        or datetime(date) < datetime(?4)
+    )
+    and (
+        ?5 = ''
+        or exists (
+            select 1
+            from journal_entry_tags
+                join tags on tags.id = journal_entry_tags.tag_id
+            where journal_entry_tags.journal_entry_id = journal_entries.id
+                and tags.name = ?5
+        )
+    )
+    and (
+        ?6 = 0
+        or (
+            ?7
+            and (datetime(date), id) > (datetime(?8), ?6)
+        )
+        or (
+            not ?7
+            and (datetime(date), id) < (datetime(?8), ?6)
+        )
+    )
+order by case
+        when ?7 then datetime(date)
+    end asc,
+    case
+        when ?7 then id
+    end asc,
+    datetime(date) desc,
+    id desc
+limit ?9;
\ No newline at end of file
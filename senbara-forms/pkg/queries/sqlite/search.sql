@@ -0,0 +1,27 @@
+-- name: UpdateSearchDocumentsConfig :exec
+update search_documents
+set config = ?1
+where namespace = ?2
+    and config <> ?1;
+-- name: SearchDocuments :many
+-- SQLite has no `tsvector`, so this matches the query as a case-insensitive
+-- phrase and leaves the highlighting to `SQLitePersister.Search`; the
+-- headline options are only bound to keep the parameters of the Postgres query
+select entity_name,
+    entity_id,
+    contact_id,
+    cast(
+        (instr(lower(title), lower(?3)) > 0) * 2 + (instr(lower(body), lower(?3)) > 0) as real
+    ) as rank,
+    title as title_headline,
+    body as body_headline
+from search_documents
+where namespace = ?2
+    and ?1 = ?1
+    and (
+        instr(lower(title), lower(?3)) > 0
+        or instr(lower(body), lower(?3)) > 0
+    )
+order by rank desc,
+    entity_id desc
+limit ?4;
\ No newline at end of file
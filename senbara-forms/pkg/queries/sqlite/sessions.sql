@@ -0,0 +1,52 @@
+-- name: GetSessions :many
+select id,
+    user_agent,
+    ip,
+    created_at,
+    last_seen_at
+from sessions
+where namespace = ?1
+order by last_seen_at desc;
+-- name: CreateSession :one
+insert into sessions (
+        hash,
+        refresh_token,
+        id_token,
+        user_agent,
+        ip,
+        subject,
+        oidc_session_id,
+        namespace
+    )
+values (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+returning id;
+-- name: GetSession :one
+select id,
+    refresh_token,
+    id_token,
+    namespace
+from sessions
+where hash = ?1;
+-- name: UpdateSessionTokens :exec
+update sessions
+set refresh_token = ?2,
+    id_token = ?3
+where id = ?1;
+-- name: UpdateSessionLastSeen :exec
+update sessions
+set last_seen_at = current_timestamp,
+    ip = ?2
+where id = ?1;
+-- name: DeleteSession :exec
+delete from sessions
+where id = ?1
+    and namespace = ?2;
+-- name: DeleteSessionsForNamespace :exec
+delete from sessions
+where namespace = ?1;
+-- name: DeleteSessionsForOIDCSession :exec
+delete from sessions
+where oidc_session_id = ?1;
+-- name: DeleteSessionsForSubject :exec
+delete from sessions
+where subject = ?1;
\ No newline at end of file
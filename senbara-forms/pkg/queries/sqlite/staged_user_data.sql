@@ -0,0 +1,28 @@
+-- name: CreateStagedUserData :exec
+insert into staged_user_data (hash, data, expires_at, namespace)
+values (?1, ?2, ?3, ?4);
+-- name: CountStagedUserData :one
+select count(*)
+from staged_user_data
+where namespace = ?1;
+-- name: GetStagedUserData :one
+select data,
+    expires_at
+from staged_user_data
+where hash = ?1
+    and namespace = ?2;
+-- name: DeleteStagedUserData :exec
+delete from staged_user_data
+where hash = ?1
+    and namespace = ?2;
+-- name: ClaimStagedUserData :one
+delete from staged_user_data
+where hash = ?1
+    and namespace = ?2
+returning hash;
+-- name: DeleteExpiredStagedUserData :exec
+delete from staged_user_data
+where expires_at <= ?1;
+-- name: DeleteStagedUserDataForNamespace :exec
+delete from staged_user_data
+where namespace = ?1;
\ No newline at end of file
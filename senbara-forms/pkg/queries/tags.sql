@@ -0,0 +1,93 @@
+-- name: GetTags :many
+select *
+from tags
+where namespace = $1
+order by name;
+-- name: UpsertTag :one
+insert into tags (name, namespace)
+values ($1, $2) on conflict (namespace, name) do
+update
+set name = excluded.name
+returning id;
+-- name: DeleteUnusedTags :exec
+delete from tags
+where namespace = $1
+    and not exists (
+        select 1
+        from contact_tags
+        where contact_tags.tag_id = tags.id
+    )
+    and not exists (
+        select 1
+        from journal_entry_tags
+        where journal_entry_tags.tag_id = tags.id
+    );
+-- name: DeleteTagsForNamespace :exec
+delete from tags
+where namespace = $1;
+-- name: GetContactTags :many
+select tags.name
+from contact_tags
+    join tags on tags.id = contact_tags.tag_id
+where contact_tags.contact_id = $1
+    and tags.namespace = $2
+order by tags.name;
+-- name: CreateContactTag :exec
+insert into contact_tags (contact_id, tag_id)
+values ($1, $2) on conflict do nothing;
+-- name: DeleteContactTags :exec
+delete from contact_tags
+where contact_id = $1
+    and tag_id in (
+        select id
+        from tags
+        where namespace = $2
+    );
+-- name: DeleteContactTagsForNamespace :exec
+delete from contact_tags
+where tag_id in (
+        select id
+        from tags
+        where namespace = $1
+    );
+-- name: GetContactTagsExportForNamespace :many
+select contact_tags.contact_id,
+    tags.name
+from contact_tags
+    join tags on tags.id = contact_tags.tag_id
+where tags.namespace = $1
+order by contact_tags.contact_id,
+    tags.name;
+-- name: GetJournalEntryTags :many
+select tags.name
+from journal_entry_tags
+    join tags on tags.id = journal_entry_tags.tag_id
+where journal_entry_tags.journal_entry_id = $1
+    and tags.namespace = $2
+order by tags.name;
+-- name: CreateJournalEntryTag :exec
+insert into journal_entry_tags (journal_entry_id, tag_id)
+values ($1, $2) on conflict do nothing;
+-- name: DeleteJournalEntryTags :exec
+delete from journal_entry_tags
+where journal_entry_id = $1
+    and tag_id in (
+        select id
+        from tags
+        where namespace = $2
+    );
+-- name: DeleteJournalEntryTagsForNamespace :exec
+delete from journal_entry_tags
+where tag_id in (
+        select id
+        from tags
+        where namespace = $1
+    );
+-- name: GetJournalEntryTagsExportForNamespace :many
+select journal_entry_tags.journal_entry_id,
+    tags.name
+from journal_entry_tags
+    join tags on tags.id = journal_entry_tags.tag_id
+where tags.namespace = $1
+order by journal_entry_tags.journal_entry_id,
+    tags.name;
\ No newline at end of file
@@ -7,76 +7,63 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"time"
 )
 
 const createActivity = `-- name: CreateActivity :one
-with contact as (
-    select id
-    from contacts
-    where contacts.id = $1
-        and namespace = $2
-),
-insertion as (
-    insert into activities (name, date, description, contact_id)
-    select $3,
-        $4,
-        $5,
-        $1
-    from contact
-    where exists (
-            select 1
-            from contact
-        )
-    returning activities.id
-)
-select id
-from insertion
+insert into activities (name, date, description, namespace)
+values ($1, $2, $3, $4)
+returning id
 `
 
 type CreateActivityParams struct {
-	ID          int32
-	Namespace   string
 	Name        string
 	Date        time.Time
 	Description string
+	Namespace   string
 }
 
 func (q *Queries) CreateActivity(ctx context.Context, arg CreateActivityParams) (int32, error) {
 	row := q.db.QueryRowContext(ctx, createActivity,
-		arg.ID,
-		arg.Namespace,
 		arg.Name,
 		arg.Date,
 		arg.Description,
+		arg.Namespace,
 	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
 }
 
-const deleteActivitesForContact = `-- name: DeleteActivitesForContact :exec
-delete from activities using contacts
-where activities.contact_id = contacts.id
-    and contacts.id = $1
-    and contacts.namespace = $2
+const createActivityParticipant = `-- name: CreateActivityParticipant :one
+insert into activity_participants (activity_id, contact_id)
+select activities.id,
+    contacts.id
+from activities,
+    contacts
+where activities.id = $1
+    and activities.namespace = $3
+    and contacts.id = $2
+    and contacts.namespace = $3
+returning contact_id
 `
 
-type DeleteActivitesForContactParams struct {
+type CreateActivityParticipantParams struct {
 	ID        int32
+	ID_2      int32
 	Namespace string
 }
 
-func (q *Queries) DeleteActivitesForContact(ctx context.Context, arg DeleteActivitesForContactParams) error {
-	_, err := q.db.ExecContext(ctx, deleteActivitesForContact, arg.ID, arg.Namespace)
-	return err
+func (q *Queries) CreateActivityParticipant(ctx context.Context, arg CreateActivityParticipantParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, createActivityParticipant, arg.ID, arg.ID_2, arg.Namespace)
+	var contact_id int32
+	err := row.Scan(&contact_id)
+	return contact_id, err
 }
 
 const deleteActivitiesForNamespace = `-- name: DeleteActivitiesForNamespace :exec
-delete from activities using contacts
-where activities.contact_id = contacts.id
-    and contacts.namespace = $1
+delete from activities
+where namespace = $1
 `
 
 func (q *Queries) DeleteActivitiesForNamespace(ctx context.Context, namespace string) error {
@@ -84,22 +71,88 @@ func (q *Queries) DeleteActivitiesForNamespace(ctx context.Context, namespace st
 	return err
 }
 
+const deleteActivitiesWithoutParticipantsForNamespace = `-- name: DeleteActivitiesWithoutParticipantsForNamespace :exec
+delete from activities
+where namespace = $1
+    and not exists (
+        select 1
+        from activity_participants
+        where activity_participants.activity_id = activities.id
+    )
+`
+
+func (q *Queries) DeleteActivitiesWithoutParticipantsForNamespace(ctx context.Context, namespace string) error {
+	_, err := q.db.ExecContext(ctx, deleteActivitiesWithoutParticipantsForNamespace, namespace)
+	return err
+}
+
 const deleteActivity = `-- name: DeleteActivity :exec
-delete from activities using contacts
-where activities.id = $3
-    and activities.contact_id = contacts.id
-    and contacts.id = $1
-    and contacts.namespace = $2
+delete from activities
+where id = $1
+    and namespace = $2
 `
 
 type DeleteActivityParams struct {
 	ID        int32
 	Namespace string
-	ID_2      int32
 }
 
 func (q *Queries) DeleteActivity(ctx context.Context, arg DeleteActivityParams) error {
-	_, err := q.db.ExecContext(ctx, deleteActivity, arg.ID, arg.Namespace, arg.ID_2)
+	_, err := q.db.ExecContext(ctx, deleteActivity, arg.ID, arg.Namespace)
+	return err
+}
+
+const deleteActivityParticipants = `-- name: DeleteActivityParticipants :exec
+delete from activity_participants
+where activity_id in (
+        select id
+        from activities
+        where id = $1
+            and namespace = $2
+    )
+`
+
+type DeleteActivityParticipantsParams struct {
+	ID        int32
+	Namespace string
+}
+
+func (q *Queries) DeleteActivityParticipants(ctx context.Context, arg DeleteActivityParticipantsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteActivityParticipants, arg.ID, arg.Namespace)
+	return err
+}
+
+const deleteActivityParticipantsForContact = `-- name: DeleteActivityParticipantsForContact :exec
+delete from activity_participants
+where contact_id = $1
+    and activity_id in (
+        select id
+        from activities
+        where namespace = $2
+    )
+`
+
+type DeleteActivityParticipantsForContactParams struct {
+	ContactID int32
+	Namespace string
+}
+
+func (q *Queries) DeleteActivityParticipantsForContact(ctx context.Context, arg DeleteActivityParticipantsForContactParams) error {
+	_, err := q.db.ExecContext(ctx, deleteActivityParticipantsForContact, arg.ContactID, arg.Namespace)
+	return err
+}
+
+const deleteActivityParticipantsForNamespace = `-- name: DeleteActivityParticipantsForNamespace :exec
+delete from activity_participants
+where activity_id in (
+        select id
+        from activities
+        where namespace = $1
+    )
+`
+
+func (q *Queries) DeleteActivityParticipantsForNamespace(ctx context.Context, namespace string) error {
+	_, err := q.db.ExecContext(ctx, deleteActivityParticipantsForNamespace, namespace)
 	return err
 }
 
@@ -108,14 +161,14 @@ select activities.id,
     activities.name,
     activities.date,
     activities.description
-from contacts
-    right join activities on activities.contact_id = contacts.id
-where contacts.id = $1
-    and contacts.namespace = $2
+from activity_participants
+    join activities on activities.id = activity_participants.activity_id
+where activity_participants.contact_id = $1
+    and activities.namespace = $2
 `
 
 type GetActivitiesParams struct {
-	ID        int32
+	ContactID int32
 	Namespace string
 }
 
@@ -127,7 +180,7 @@ type GetActivitiesRow struct {
 }
 
 func (q *Queries) GetActivities(ctx context.Context, arg GetActivitiesParams) ([]GetActivitiesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getActivities, arg.ID, arg.Namespace)
+	rows, err := q.db.QueryContext(ctx, getActivities, arg.ContactID, arg.Namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -154,16 +207,91 @@ func (q *Queries) GetActivities(ctx context.Context, arg GetActivitiesParams) ([
 	return items, nil
 }
 
+const getActivitiesCalendarForNamespace = `-- name: GetActivitiesCalendarForNamespace :many
+select activities.id,
+    activities.name,
+    activities.date,
+    activities.description,
+    activities.version,
+    contacts.id as contact_id,
+    contacts.first_name,
+    contacts.last_name,
+    cast(
+        coalesce(
+            (
+                select contact_emails.email
+                from contact_emails
+                where contact_emails.contact_id = contacts.id
+                order by contact_emails.id
+                limit 1
+            ),
+            ''
+        ) as text
+    ) as email
+from activities
+    inner join activity_participants on activity_participants.activity_id = activities.id
+    inner join contacts on contacts.id = activity_participants.contact_id
+where activities.namespace = $1
+order by activities.date desc,
+    activities.id,
+    contacts.first_name,
+    contacts.last_name,
+    contacts.id
+`
+
+type GetActivitiesCalendarForNamespaceRow struct {
+	ID          int32
+	Name        string
+	Date        time.Time
+	Description string
+	Version     int32
+	ContactID   int32
+	FirstName   string
+	LastName    string
+	Email       string
+}
+
+func (q *Queries) GetActivitiesCalendarForNamespace(ctx context.Context, namespace string) ([]GetActivitiesCalendarForNamespaceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getActivitiesCalendarForNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActivitiesCalendarForNamespaceRow
+	for rows.Next() {
+		var i GetActivitiesCalendarForNamespaceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Date,
+			&i.Description,
+			&i.Version,
+			&i.ContactID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getActivitiesExportForNamespace = `-- name: GetActivitiesExportForNamespace :many
 select 'activites' as table_name,
     activities.id,
     activities.name,
     activities.date,
-    activities.description,
-    contacts.id as contact_id
-from contacts
-    right join activities on activities.contact_id = contacts.id
-where contacts.namespace = $1
+    activities.description
+from activities
+where activities.namespace = $1
 `
 
 type GetActivitiesExportForNamespaceRow struct {
@@ -172,7 +300,6 @@ type GetActivitiesExportForNamespaceRow struct {
 	Name        string
 	Date        time.Time
 	Description string
-	ContactID   sql.NullInt32
 }
 
 func (q *Queries) GetActivitiesExportForNamespace(ctx context.Context, namespace string) ([]GetActivitiesExportForNamespaceRow, error) {
@@ -190,7 +317,6 @@ func (q *Queries) GetActivitiesExportForNamespace(ctx context.Context, namespace
 			&i.Name,
 			&i.Date,
 			&i.Description,
-			&i.ContactID,
 		); err != nil {
 			return nil, err
 		}
@@ -206,7 +332,7 @@ func (q *Queries) GetActivitiesExportForNamespace(ctx context.Context, namespace
 }
 
 const getActivity = `-- name: GetActivity :one
-select id, first_name, last_name, nickname, email, pronouns, namespace, birthday, address, notes
+select id, first_name, last_name, nickname, pronouns, namespace, birthday, notes, version
 from contacts
 where contacts.id = $1
     and contacts.namespace = $2
@@ -225,12 +351,11 @@ func (q *Queries) GetActivity(ctx context.Context, arg GetActivityParams) (Conta
 		&i.FirstName,
 		&i.LastName,
 		&i.Nickname,
-		&i.Email,
 		&i.Pronouns,
 		&i.Namespace,
 		&i.Birthday,
-		&i.Address,
 		&i.Notes,
+		&i.Version,
 	)
 	return i, err
 }
@@ -244,7 +369,8 @@ select activities.id as activity_id,
     contacts.first_name,
     contacts.last_name
 from contacts
-    inner join activities on activities.contact_id = contacts.id
+    inner join activity_participants on activity_participants.contact_id = contacts.id
+    inner join activities on activities.id = activity_participants.activity_id
 where contacts.id = $1
     and contacts.namespace = $2
     and activities.id = $3
@@ -281,22 +407,212 @@ func (q *Queries) GetActivityAndContact(ctx context.Context, arg GetActivityAndC
 	return i, err
 }
 
+const getActivityCalendar = `-- name: GetActivityCalendar :many
+select activities.id,
+    activities.name,
+    activities.date,
+    activities.description,
+    activities.version,
+    contacts.id as contact_id,
+    contacts.first_name,
+    contacts.last_name,
+    cast(
+        coalesce(
+            (
+                select contact_emails.email
+                from contact_emails
+                where contact_emails.contact_id = contacts.id
+                order by contact_emails.id
+                limit 1
+            ),
+            ''
+        ) as text
+    ) as email
+from activities
+    inner join activity_participants on activity_participants.activity_id = activities.id
+    inner join contacts on contacts.id = activity_participants.contact_id
+where activities.id = $1
+    and activities.namespace = $2
+order by contacts.first_name,
+    contacts.last_name,
+    contacts.id
+`
+
+type GetActivityCalendarParams struct {
+	ID        int32
+	Namespace string
+}
+
+type GetActivityCalendarRow struct {
+	ID          int32
+	Name        string
+	Date        time.Time
+	Description string
+	Version     int32
+	ContactID   int32
+	FirstName   string
+	LastName    string
+	Email       string
+}
+
+func (q *Queries) GetActivityCalendar(ctx context.Context, arg GetActivityCalendarParams) ([]GetActivityCalendarRow, error) {
+	rows, err := q.db.QueryContext(ctx, getActivityCalendar, arg.ID, arg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActivityCalendarRow
+	for rows.Next() {
+		var i GetActivityCalendarRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Date,
+			&i.Description,
+			&i.Version,
+			&i.ContactID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActivityParticipants = `-- name: GetActivityParticipants :many
+select contacts.id,
+    contacts.first_name,
+    contacts.last_name
+from activity_participants
+    join activities on activities.id = activity_participants.activity_id
+    join contacts on contacts.id = activity_participants.contact_id
+where activity_participants.activity_id = $1
+    and activities.namespace = $2
+order by contacts.first_name,
+    contacts.last_name,
+    contacts.id
+`
+
+type GetActivityParticipantsParams struct {
+	ActivityID int32
+	Namespace  string
+}
+
+type GetActivityParticipantsRow struct {
+	ID        int32
+	FirstName string
+	LastName  string
+}
+
+func (q *Queries) GetActivityParticipants(ctx context.Context, arg GetActivityParticipantsParams) ([]GetActivityParticipantsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getActivityParticipants, arg.ActivityID, arg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActivityParticipantsRow
+	for rows.Next() {
+		var i GetActivityParticipantsRow
+		if err := rows.Scan(&i.ID, &i.FirstName, &i.LastName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActivityParticipantsExportForNamespace = `-- name: GetActivityParticipantsExportForNamespace :many
+select activity_participants.activity_id,
+    activity_participants.contact_id
+from activity_participants
+    join activities on activities.id = activity_participants.activity_id
+where activities.namespace = $1
+order by activity_participants.activity_id,
+    activity_participants.contact_id
+`
+
+type GetActivityParticipantsExportForNamespaceRow struct {
+	ActivityID int32
+	ContactID  int32
+}
+
+func (q *Queries) GetActivityParticipantsExportForNamespace(ctx context.Context, namespace string) ([]GetActivityParticipantsExportForNamespaceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getActivityParticipantsExportForNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetActivityParticipantsExportForNamespaceRow
+	for rows.Next() {
+		var i GetActivityParticipantsExportForNamespaceRow
+		if err := rows.Scan(&i.ActivityID, &i.ContactID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockActivityVersion = `-- name: LockActivityVersion :execrows
+update activities
+set version = version
+where id = $1
+    and namespace = $2
+    and version = $3
+`
+
+type LockActivityVersionParams struct {
+	ID        int32
+	Namespace string
+	Version   int32
+}
+
+func (q *Queries) LockActivityVersion(ctx context.Context, arg LockActivityVersionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, lockActivityVersion, arg.ID, arg.Namespace, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateActivity = `-- name: UpdateActivity :exec
 update activities
 set name = $4,
     date = $5,
-    description = $6
-from contacts
-where contacts.id = $1
-    and contacts.namespace = $2
+    description = $6,
+    version = activities.version + 1
+from activity_participants
+where activity_participants.contact_id = $1
+    and activities.namespace = $2
     and activities.id = $3
-    and activities.contact_id = contacts.id
+    and activity_participants.activity_id = activities.id
 `
 
 type UpdateActivityParams struct {
-	ID          int32
+	ContactID   int32
 	Namespace   string
-	ID_2        int32
+	ID          int32
 	Name        string
 	Date        time.Time
 	Description string
@@ -304,9 +620,9 @@ type UpdateActivityParams struct {
 
 func (q *Queries) UpdateActivity(ctx context.Context, arg UpdateActivityParams) error {
 	_, err := q.db.ExecContext(ctx, updateActivity,
-		arg.ID,
+		arg.ContactID,
 		arg.Namespace,
-		arg.ID_2,
+		arg.ID,
 		arg.Name,
 		arg.Date,
 		arg.Description,
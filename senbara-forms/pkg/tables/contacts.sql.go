@@ -15,11 +15,10 @@ insert into contacts (
         first_name,
         last_name,
         nickname,
-        email,
         pronouns,
         namespace
     )
-values ($1, $2, $3, $4, $5, $6)
+values ($1, $2, $3, $4, $5)
 returning id
 `
 
@@ -27,7 +26,6 @@ type CreateContactParams struct {
 	FirstName string
 	LastName  string
 	Nickname  string
-	Email     string
 	Pronouns  string
 	Namespace string
 }
@@ -37,7 +35,6 @@ func (q *Queries) CreateContact(ctx context.Context, arg CreateContactParams) (i
 		arg.FirstName,
 		arg.LastName,
 		arg.Nickname,
-		arg.Email,
 		arg.Pronouns,
 		arg.Namespace,
 	)
@@ -73,7 +70,7 @@ func (q *Queries) DeleteContactsForNamespace(ctx context.Context, namespace stri
 }
 
 const getContact = `-- name: GetContact :one
-select id, first_name, last_name, nickname, email, pronouns, namespace, birthday, address, notes
+select id, first_name, last_name, nickname, pronouns, namespace, birthday, notes, version
 from contacts
 where id = $1
     and namespace = $2
@@ -92,18 +89,49 @@ func (q *Queries) GetContact(ctx context.Context, arg GetContactParams) (Contact
 		&i.FirstName,
 		&i.LastName,
 		&i.Nickname,
-		&i.Email,
 		&i.Pronouns,
 		&i.Namespace,
 		&i.Birthday,
-		&i.Address,
 		&i.Notes,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getContactByEmail = `-- name: GetContactByEmail :one
+select contacts.id, contacts.first_name, contacts.last_name, contacts.nickname, contacts.pronouns, contacts.namespace, contacts.birthday, contacts.notes, contacts.version
+from contacts
+    join contact_emails on contact_emails.contact_id = contacts.id
+where contacts.namespace = $1
+    and lower(contact_emails.email) = lower($2)
+order by contacts.id
+limit 1
+`
+
+type GetContactByEmailParams struct {
+	Namespace string
+	Lower     string
+}
+
+func (q *Queries) GetContactByEmail(ctx context.Context, arg GetContactByEmailParams) (Contact, error) {
+	row := q.db.QueryRowContext(ctx, getContactByEmail, arg.Namespace, arg.Lower)
+	var i Contact
+	err := row.Scan(
+		&i.ID,
+		&i.FirstName,
+		&i.LastName,
+		&i.Nickname,
+		&i.Pronouns,
+		&i.Namespace,
+		&i.Birthday,
+		&i.Notes,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getContacts = `-- name: GetContacts :many
-select id, first_name, last_name, nickname, email, pronouns, namespace, birthday, address, notes
+select id, first_name, last_name, nickname, pronouns, namespace, birthday, notes, version
 from contacts
 where namespace = $1
 order by first_name desc
@@ -123,12 +151,11 @@ func (q *Queries) GetContacts(ctx context.Context, namespace string) ([]Contact,
 			&i.FirstName,
 			&i.LastName,
 			&i.Nickname,
-			&i.Email,
 			&i.Pronouns,
 			&i.Namespace,
 			&i.Birthday,
-			&i.Address,
 			&i.Notes,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -145,7 +172,7 @@ func (q *Queries) GetContacts(ctx context.Context, namespace string) ([]Contact,
 
 const getContactsExportForNamespace = `-- name: GetContactsExportForNamespace :many
 select 'contacts' as table_name,
-    id, first_name, last_name, nickname, email, pronouns, namespace, birthday, address, notes
+    id, first_name, last_name, nickname, pronouns, namespace, birthday, notes, version
 from contacts
 where namespace = $1
 order by first_name desc
@@ -157,12 +184,11 @@ type GetContactsExportForNamespaceRow struct {
 	FirstName string
 	LastName  string
 	Nickname  string
-	Email     string
 	Pronouns  string
 	Namespace string
 	Birthday  sql.NullTime
-	Address   string
 	Notes     string
+	Version   int32
 }
 
 func (q *Queries) GetContactsExportForNamespace(ctx context.Context, namespace string) ([]GetContactsExportForNamespaceRow, error) {
@@ -180,12 +206,182 @@ func (q *Queries) GetContactsExportForNamespace(ctx context.Context, namespace s
 			&i.FirstName,
 			&i.LastName,
 			&i.Nickname,
-			&i.Email,
 			&i.Pronouns,
 			&i.Namespace,
 			&i.Birthday,
-			&i.Address,
 			&i.Notes,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContacts = `-- name: ListContacts :many
+select id, first_name, last_name, nickname, pronouns, namespace, birthday, notes, version, sort_key, tags, email
+from (
+        select contacts.id, contacts.first_name, contacts.last_name, contacts.nickname, contacts.pronouns, contacts.namespace, contacts.birthday, contacts.notes, contacts.version,
+            cast(
+                case
+                    $1::text
+                    when 'last_name' then contacts.last_name
+                    when 'birthday' then coalesce(to_char(contacts.birthday, 'MM-DD'), '99-99')
+                    when 'last_contacted' then coalesce(
+                        (
+                            select to_char(max(activities.date), 'YYYY-MM-DD"T"HH24:MI:SS')
+                            from activity_participants
+                                join activities on activities.id = activity_participants.activity_id
+                            where activity_participants.contact_id = contacts.id
+                        ),
+                        ''
+                    )
+                    else contacts.first_name
+                end as text
+            ) as sort_key,
+            cast(
+                coalesce(
+                    (
+                        select string_agg(tags.name, ', ' order by tags.name)
+                        from contact_tags
+                            join tags on tags.id = contact_tags.tag_id
+                        where contact_tags.contact_id = contacts.id
+                    ),
+                    ''
+                ) as text
+            ) as tags,
+            cast(
+                coalesce(
+                    (
+                        select contact_emails.email
+                        from contact_emails
+                        where contact_emails.contact_id = contacts.id
+                        order by contact_emails.id
+                        limit 1
+                    ),
+                    ''
+                ) as text
+            ) as email
+        from contacts
+        where contacts.namespace = $2
+            and (
+                not $3::boolean
+                or exists (
+                    select 1
+                    from debts
+                    where debts.contact_id = contacts.id
+                        and abs(debts.amount) - coalesce(
+                            (
+                                select sum(debt_payments.amount)
+                                from debt_payments
+                                where debt_payments.debt_id = debts.id
+                            ),
+                            0
+                        ) > 0
+                )
+            )
+            and (
+                $4::integer = 0
+                or extract(
+                    month
+                    from contacts.birthday
+                ) = $4::integer
+            )
+            and (
+                $5::text = ''
+                or exists (
+                    select 1
+                    from contact_tags
+                        join tags on tags.id = contact_tags.tag_id
+                    where contact_tags.contact_id = contacts.id
+                        and tags.name = $5::text
+                )
+            )
+    ) as sorted_contacts
+where $6::integer = 0
+    or (
+        $1::text = 'last_contacted'
+        and (sort_key, id) < ($7::text, $6::integer)
+    )
+    or (
+        $1::text <> 'last_contacted'
+        and (sort_key, id) > ($7::text, $6::integer)
+    )
+order by case
+        when $1::text = 'last_contacted' then sort_key
+    end desc,
+    case
+        when $1::text = 'last_contacted' then id
+    end desc,
+    sort_key asc,
+    id asc
+limit $8
+`
+
+type ListContactsParams struct {
+	Sort          string
+	Namespace     string
+	HasDebts      bool
+	BirthdayMonth int32
+	Tag           string
+	AfterID       int32
+	AfterKey      string
+	ResultLimit   int32
+}
+
+type ListContactsRow struct {
+	ID        int32
+	FirstName string
+	LastName  string
+	Nickname  string
+	Pronouns  string
+	Namespace string
+	Birthday  sql.NullTime
+	Notes     string
+	Version   int32
+	SortKey   string
+	Tags      string
+	Email     string
+}
+
+func (q *Queries) ListContacts(ctx context.Context, arg ListContactsParams) ([]ListContactsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listContacts,
+		arg.Sort,
+		arg.Namespace,
+		arg.HasDebts,
+		arg.BirthdayMonth,
+		arg.Tag,
+		arg.AfterID,
+		arg.AfterKey,
+		arg.ResultLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListContactsRow
+	for rows.Next() {
+		var i ListContactsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Nickname,
+			&i.Pronouns,
+			&i.Namespace,
+			&i.Birthday,
+			&i.Notes,
+			&i.Version,
+			&i.SortKey,
+			&i.Tags,
+			&i.Email,
 		); err != nil {
 			return nil, err
 		}
@@ -200,16 +396,37 @@ func (q *Queries) GetContactsExportForNamespace(ctx context.Context, namespace s
 	return items, nil
 }
 
+const lockContactVersion = `-- name: LockContactVersion :execrows
+update contacts
+set version = version
+where id = $1
+    and namespace = $2
+    and version = $3
+`
+
+type LockContactVersionParams struct {
+	ID        int32
+	Namespace string
+	Version   int32
+}
+
+func (q *Queries) LockContactVersion(ctx context.Context, arg LockContactVersionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, lockContactVersion, arg.ID, arg.Namespace, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateContact = `-- name: UpdateContact :exec
 update contacts
 set first_name = $3,
     last_name = $4,
     nickname = $5,
-    email = $6,
-    pronouns = $7,
-    birthday = $8,
-    address = $9,
-    notes = $10
+    pronouns = $6,
+    birthday = $7,
+    notes = $8,
+    version = version + 1
 where id = $1
     and namespace = $2
 `
@@ -220,10 +437,8 @@ type UpdateContactParams struct {
 	FirstName string
 	LastName  string
 	Nickname  string
-	Email     string
 	Pronouns  string
 	Birthday  sql.NullTime
-	Address   string
 	Notes     string
 }
 
@@ -234,10 +449,8 @@ func (q *Queries) UpdateContact(ctx context.Context, arg UpdateContactParams) er
 		arg.FirstName,
 		arg.LastName,
 		arg.Nickname,
-		arg.Email,
 		arg.Pronouns,
 		arg.Birthday,
-		arg.Address,
 		arg.Notes,
 	)
 	return err
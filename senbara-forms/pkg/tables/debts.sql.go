@@ -8,6 +8,7 @@ package tables
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 const createDebt = `-- name: CreateDebt :one
@@ -18,10 +19,11 @@ with contact as (
         and namespace = $2
 ),
 insertion as (
-    insert into debts (amount, currency, description, contact_id)
+    insert into debts (amount, currency, description, date, contact_id)
     select $3,
         $4,
         $5,
+        $6,
         $1
     from contact
     where exists (
@@ -37,9 +39,10 @@ from insertion
 type CreateDebtParams struct {
 	ID          int32
 	Namespace   string
-	Amount      float64
+	Amount      int64
 	Currency    string
 	Description string
+	Date        time.Time
 }
 
 func (q *Queries) CreateDebt(ctx context.Context, arg CreateDebtParams) (int32, error) {
@@ -49,12 +52,82 @@ func (q *Queries) CreateDebt(ctx context.Context, arg CreateDebtParams) (int32,
 		arg.Amount,
 		arg.Currency,
 		arg.Description,
+		arg.Date,
 	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
 }
 
+const createDebtPayment = `-- name: CreateDebtPayment :one
+insert into debt_payments (debt_id, amount, date, description)
+select debts.id,
+    $4,
+    $5,
+    $6
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+where contacts.id = $1
+    and contacts.namespace = $2
+    and debts.id = $3
+returning debt_payments.id
+`
+
+type CreateDebtPaymentParams struct {
+	ID          int32
+	Namespace   string
+	ID_2        int32
+	Amount      int64
+	Date        time.Time
+	Description string
+}
+
+func (q *Queries) CreateDebtPayment(ctx context.Context, arg CreateDebtPaymentParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, createDebtPayment,
+		arg.ID,
+		arg.Namespace,
+		arg.ID_2,
+		arg.Amount,
+		arg.Date,
+		arg.Description,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteDebtPaymentsForContact = `-- name: DeleteDebtPaymentsForContact :exec
+delete from debt_payments using debts,
+    contacts
+where debt_payments.debt_id = debts.id
+    and debts.contact_id = contacts.id
+    and contacts.id = $1
+    and contacts.namespace = $2
+`
+
+type DeleteDebtPaymentsForContactParams struct {
+	ID        int32
+	Namespace string
+}
+
+func (q *Queries) DeleteDebtPaymentsForContact(ctx context.Context, arg DeleteDebtPaymentsForContactParams) error {
+	_, err := q.db.ExecContext(ctx, deleteDebtPaymentsForContact, arg.ID, arg.Namespace)
+	return err
+}
+
+const deleteDebtPaymentsForNamespace = `-- name: DeleteDebtPaymentsForNamespace :exec
+delete from debt_payments using debts,
+    contacts
+where debt_payments.debt_id = debts.id
+    and debts.contact_id = contacts.id
+    and contacts.namespace = $1
+`
+
+func (q *Queries) DeleteDebtPaymentsForNamespace(ctx context.Context, namespace string) error {
+	_, err := q.db.ExecContext(ctx, deleteDebtPaymentsForNamespace, namespace)
+	return err
+}
+
 const deleteDebtsForContact = `-- name: DeleteDebtsForContact :exec
 delete from debts using contacts
 where debts.contact_id = contacts.id
@@ -88,6 +161,15 @@ select debts.id as debt_id,
     debts.amount,
     debts.currency,
     debts.description,
+    debts.date,
+    coalesce(
+        (
+            select sum(debt_payments.amount)
+            from debt_payments
+            where debt_payments.debt_id = debts.id
+        ),
+        0
+    )::bigint as paid,
     contacts.id as contact_id,
     contacts.first_name,
     contacts.last_name
@@ -106,9 +188,11 @@ type GetDebtAndContactParams struct {
 
 type GetDebtAndContactRow struct {
 	DebtID      int32
-	Amount      float64
+	Amount      int64
 	Currency    string
 	Description string
+	Date        time.Time
+	Paid        int64
 	ContactID   int32
 	FirstName   string
 	LastName    string
@@ -122,6 +206,8 @@ func (q *Queries) GetDebtAndContact(ctx context.Context, arg GetDebtAndContactPa
 		&i.Amount,
 		&i.Currency,
 		&i.Description,
+		&i.Date,
+		&i.Paid,
 		&i.ContactID,
 		&i.FirstName,
 		&i.LastName,
@@ -129,15 +215,118 @@ func (q *Queries) GetDebtAndContact(ctx context.Context, arg GetDebtAndContactPa
 	return i, err
 }
 
+const getDebtPayments = `-- name: GetDebtPayments :many
+select debt_payments.id,
+    debt_payments.debt_id,
+    debt_payments.amount,
+    debt_payments.date,
+    debt_payments.description
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+    inner join debt_payments on debt_payments.debt_id = debts.id
+where contacts.id = $1
+    and contacts.namespace = $2
+order by debt_payments.date,
+    debt_payments.id
+`
+
+type GetDebtPaymentsParams struct {
+	ID        int32
+	Namespace string
+}
+
+func (q *Queries) GetDebtPayments(ctx context.Context, arg GetDebtPaymentsParams) ([]DebtPayment, error) {
+	rows, err := q.db.QueryContext(ctx, getDebtPayments, arg.ID, arg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DebtPayment
+	for rows.Next() {
+		var i DebtPayment
+		if err := rows.Scan(
+			&i.ID,
+			&i.DebtID,
+			&i.Amount,
+			&i.Date,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDebtPaymentsExportForNamespace = `-- name: GetDebtPaymentsExportForNamespace :many
+select debt_payments.id,
+    debt_payments.debt_id,
+    debt_payments.amount,
+    debt_payments.date,
+    debt_payments.description
+from contacts
+    inner join debts on debts.contact_id = contacts.id
+    inner join debt_payments on debt_payments.debt_id = debts.id
+where contacts.namespace = $1
+order by debt_payments.date,
+    debt_payments.id
+`
+
+func (q *Queries) GetDebtPaymentsExportForNamespace(ctx context.Context, namespace string) ([]DebtPayment, error) {
+	rows, err := q.db.QueryContext(ctx, getDebtPaymentsExportForNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DebtPayment
+	for rows.Next() {
+		var i DebtPayment
+		if err := rows.Scan(
+			&i.ID,
+			&i.DebtID,
+			&i.Amount,
+			&i.Date,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDebts = `-- name: GetDebts :many
 select debts.id,
     debts.amount,
     debts.currency,
-    debts.description
+    debts.description,
+    debts.date,
+    coalesce(
+        (
+            select sum(debt_payments.amount)
+            from debt_payments
+            where debt_payments.debt_id = debts.id
+        ),
+        0
+    )::bigint as paid
 from contacts
     right join debts on debts.contact_id = contacts.id
 where contacts.id = $1
     and contacts.namespace = $2
+order by debts.date,
+    debts.id
 `
 
 type GetDebtsParams struct {
@@ -147,9 +336,11 @@ type GetDebtsParams struct {
 
 type GetDebtsRow struct {
 	ID          int32
-	Amount      float64
+	Amount      int64
 	Currency    string
 	Description string
+	Date        time.Time
+	Paid        int64
 }
 
 func (q *Queries) GetDebts(ctx context.Context, arg GetDebtsParams) ([]GetDebtsRow, error) {
@@ -166,6 +357,8 @@ func (q *Queries) GetDebts(ctx context.Context, arg GetDebtsParams) ([]GetDebtsR
 			&i.Amount,
 			&i.Currency,
 			&i.Description,
+			&i.Date,
+			&i.Paid,
 		); err != nil {
 			return nil, err
 		}
@@ -186,6 +379,7 @@ select 'debts' as table_name,
     debts.amount,
     debts.currency,
     debts.description,
+    debts.date,
     contacts.id as contact_id
 from contacts
     right join debts on debts.contact_id = contacts.id
@@ -195,9 +389,10 @@ where contacts.namespace = $1
 type GetDebtsExportForNamespaceRow struct {
 	TableName   string
 	ID          int32
-	Amount      float64
+	Amount      int64
 	Currency    string
 	Description string
+	Date        time.Time
 	ContactID   sql.NullInt32
 }
 
@@ -216,6 +411,7 @@ func (q *Queries) GetDebtsExportForNamespace(ctx context.Context, namespace stri
 			&i.Amount,
 			&i.Currency,
 			&i.Description,
+			&i.Date,
 			&i.ContactID,
 		); err != nil {
 			return nil, err
@@ -231,30 +427,12 @@ func (q *Queries) GetDebtsExportForNamespace(ctx context.Context, namespace stri
 	return items, nil
 }
 
-const settleDebt = `-- name: SettleDebt :exec
-delete from debts using contacts
-where debts.id = $3
-    and debts.contact_id = contacts.id
-    and contacts.id = $1
-    and contacts.namespace = $2
-`
-
-type SettleDebtParams struct {
-	ID        int32
-	Namespace string
-	ID_2      int32
-}
-
-func (q *Queries) SettleDebt(ctx context.Context, arg SettleDebtParams) error {
-	_, err := q.db.ExecContext(ctx, settleDebt, arg.ID, arg.Namespace, arg.ID_2)
-	return err
-}
-
 const updateDebt = `-- name: UpdateDebt :exec
 update debts
 set amount = $4,
     currency = $5,
-    description = $6
+    description = $6,
+    date = $7
 from contacts
 where contacts.id = $1
     and contacts.namespace = $2
@@ -266,9 +444,10 @@ type UpdateDebtParams struct {
 	ID          int32
 	Namespace   string
 	ID_2        int32
-	Amount      float64
+	Amount      int64
 	Currency    string
 	Description string
+	Date        time.Time
 }
 
 func (q *Queries) UpdateDebt(ctx context.Context, arg UpdateDebtParams) error {
@@ -279,6 +458,7 @@ func (q *Queries) UpdateDebt(ctx context.Context, arg UpdateDebtParams) error {
 		arg.Amount,
 		arg.Currency,
 		arg.Description,
+		arg.Date,
 	)
 	return err
 }
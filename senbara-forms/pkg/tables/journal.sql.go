@@ -7,6 +7,7 @@ package tables
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -173,6 +174,128 @@ func (q *Queries) GetJournalEntry(ctx context.Context, arg GetJournalEntryParams
 	return i, err
 }
 
+const listJournalEntries = `-- name: ListJournalEntries :many
+select journal_entries.id, journal_entries.title, journal_entries.date, journal_entries.body, journal_entries.rating, journal_entries.namespace,
+    cast(
+        coalesce(
+            (
+                select string_agg(tags.name, ', ' order by tags.name)
+                from journal_entry_tags
+                    join tags on tags.id = journal_entry_tags.tag_id
+                where journal_entry_tags.journal_entry_id = journal_entries.id
+            ),
+            ''
+        ) as text
+    ) as tags
+from journal_entries
+where namespace = $1
+    and (
+        $2::integer = 0
+        or rating = $2::integer
+    )
+    and (
+        $3::timestamp is null
+        or date >= $3::timestamp
+    )
+    and (
+        $4::timestamp is null
+        or date < $4::timestamp
+    )
+    and (
+        $5::text = ''
+        or exists (
+            select 1
+            from journal_entry_tags
+                join tags on tags.id = journal_entry_tags.tag_id
+            where journal_entry_tags.journal_entry_id = journal_entries.id
+                and tags.name = $5::text
+        )
+    )
+    and (
+        $6::integer = 0
+        or (
+            $7::boolean
+            and (date, id) > ($8::timestamp, $6::integer)
+        )
+        or (
+            not $7::boolean
+            and (date, id) < ($8::timestamp, $6::integer)
+        )
+    )
+order by case
+        when $7::boolean then date
+    end asc,
+    case
+        when $7::boolean then id
+    end asc,
+    date desc,
+    id desc
+limit $9
+`
+
+type ListJournalEntriesParams struct {
+	Namespace   string
+	Rating      int32
+	FromDate    sql.NullTime
+	ToDate      sql.NullTime
+	Tag         string
+	AfterID     int32
+	OldestFirst bool
+	AfterDate   time.Time
+	ResultLimit int32
+}
+
+type ListJournalEntriesRow struct {
+	ID        int32
+	Title     string
+	Date      time.Time
+	Body      string
+	Rating    int32
+	Namespace string
+	Tags      string
+}
+
+func (q *Queries) ListJournalEntries(ctx context.Context, arg ListJournalEntriesParams) ([]ListJournalEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listJournalEntries,
+		arg.Namespace,
+		arg.Rating,
+		arg.FromDate,
+		arg.ToDate,
+		arg.Tag,
+		arg.AfterID,
+		arg.OldestFirst,
+		arg.AfterDate,
+		arg.ResultLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJournalEntriesRow
+	for rows.Next() {
+		var i ListJournalEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Date,
+			&i.Body,
+			&i.Rating,
+			&i.Namespace,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateJournalEntry = `-- name: UpdateJournalEntry :exec
 update journal_entries
 set title = $3,
@@ -13,8 +13,29 @@ type Activity struct {
 	ID          int32
 	Name        string
 	Date        time.Time
-	ContactID   int32
 	Description string
+	Version     int32
+	Namespace   string
+}
+
+type ActivityParticipant struct {
+	ActivityID int32
+	ContactID  int32
+}
+
+type AppPassword struct {
+	ID        int32
+	Name      string
+	Hash      string
+	CreatedAt time.Time
+	Namespace string
+}
+
+type CalendarFeed struct {
+	ID        int32
+	Hash      string
+	CreatedAt time.Time
+	Namespace string
 }
 
 type Contact struct {
@@ -22,20 +43,90 @@ type Contact struct {
 	FirstName string
 	LastName  string
 	Nickname  string
-	Email     string
 	Pronouns  string
 	Namespace string
 	Birthday  sql.NullTime
-	Address   string
 	Notes     string
+	Version   int32
+}
+
+type ContactAddress struct {
+	ID        int32
+	ContactID int32
+	Label     string
+	Address   string
+}
+
+type ContactEmail struct {
+	ID        int32
+	ContactID int32
+	Label     string
+	Email     string
+}
+
+type ContactGroup struct {
+	ID        int32
+	Namespace string
+	Name      string
+}
+
+type ContactGroupMember struct {
+	GroupID   int32
+	ContactID int32
+}
+
+type ContactPhone struct {
+	ID        int32
+	ContactID int32
+	Label     string
+	Number    string
+}
+
+type ContactRelationship struct {
+	ID               int32
+	Namespace        string
+	ContactID        int32
+	RelatedContactID int32
+	Type             string
+	Label            string
+	Reciprocal       bool
+}
+
+type ContactTag struct {
+	ContactID int32
+	TagID     int32
+}
+
+type ContactWebsite struct {
+	ID        int32
+	ContactID int32
+	Label     string
+	Url       string
+}
+
+type DavResource struct {
+	Collection string
+	Name       string
+	ResourceID int32
+	Uid        string
+	Namespace  string
 }
 
 type Debt struct {
 	ID          int32
-	Amount      float64
+	Amount      int64
 	Currency    string
 	ContactID   int32
 	Description string
+	Date        time.Time
+}
+
+type DebtPayment struct {
+	ID          int32
+	DebtID      int32
+	Amount      int64
+	Date        time.Time
+	Description string
 }
 
 type JournalEntry struct {
@@ -46,3 +137,56 @@ type JournalEntry struct {
 	Rating    int32
 	Namespace string
 }
+
+type JournalEntryTag struct {
+	JournalEntryID int32
+	TagID          int32
+}
+
+type PersonalAccessToken struct {
+	ID        int32
+	Name      string
+	Hash      string
+	Scopes    string
+	CreatedAt time.Time
+	ExpiresAt sql.NullTime
+	Namespace string
+}
+
+type SearchDocument struct {
+	EntityName string
+	EntityID   int32
+	ContactID  sql.NullInt32
+	Namespace  string
+	Config     interface{}
+	Title      string
+	Body       string
+	Document   interface{}
+}
+
+type Session struct {
+	ID            int32
+	Hash          string
+	RefreshToken  string
+	IDToken       string
+	UserAgent     string
+	Ip            string
+	CreatedAt     time.Time
+	LastSeenAt    time.Time
+	Namespace     string
+	Subject       string
+	OidcSessionID string
+}
+
+type StagedUserDatum struct {
+	Hash      string
+	Data      []byte
+	ExpiresAt time.Time
+	Namespace string
+}
+
+type Tag struct {
+	ID        int32
+	Name      string
+	Namespace string
+}
@@ -0,0 +1,509 @@
+package vcards
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	Version3 = "3.0"
+	Version4 = "4.0"
+
+	maxLineLength = 75
+)
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported vCard version")
+	ErrMissingName        = errors.New("missing name in vCard")
+	ErrUnterminatedCard   = errors.New("unterminated vCard")
+)
+
+// Card is the subset of a vCard which maps onto a contact
+type Card struct {
+	UID       string
+	FirstName string
+	LastName  string
+	Nickname  string
+	Pronouns  string
+	Birthday  *time.Time
+	Notes     string
+
+	Emails    []Property
+	Phones    []Property
+	Addresses []Property
+	Websites  []Property
+}
+
+// Property is a value of a property which a vCard can have more than once,
+// such as `EMAIL`, with the lowercased values of its `TYPE` parameter, i.e.
+// `work` or `cell`
+type Property struct {
+	Types []string
+	Value string
+}
+
+// Encode writes the cards as vCards of the given version to `w`
+func Encode(w io.Writer, version string, cards ...Card) error {
+	if version != Version3 && version != Version4 {
+		return errors.Join(ErrUnsupportedVersion, fmt.Errorf("version %v", version))
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for _, card := range cards {
+		lines := []string{
+			"BEGIN:VCARD",
+			"VERSION:" + version,
+		}
+
+		if card.UID != "" {
+			lines = append(lines, "UID:"+escape(card.UID))
+		}
+
+		lines = append(
+			lines,
+			"FN:"+escape(strings.TrimSpace(card.FirstName+" "+card.LastName)),
+			"N:"+escape(card.LastName)+";"+escape(card.FirstName)+";;;",
+		)
+
+		if card.Nickname != "" {
+			lines = append(lines, "NICKNAME:"+escape(card.Nickname))
+		}
+
+		for i, email := range card.Emails {
+			// The first email address is the preferred one
+			types := email.Types
+			if version == Version3 {
+				types = append([]string{"internet"}, types...)
+				if i == 0 {
+					types = append(types, "pref")
+				}
+			}
+
+			params := typeParam(types)
+			if version == Version4 && i == 0 {
+				params += ";PREF=1"
+			}
+
+			lines = append(lines, "EMAIL"+params+":"+escape(email.Value))
+		}
+
+		for _, phone := range card.Phones {
+			if version == Version3 {
+				lines = append(lines, "TEL"+typeParam(phone.Types)+":"+escape(phone.Value))
+			} else {
+				lines = append(lines, "TEL;VALUE=uri"+typeParam(phone.Types)+":tel:"+escape(phone.Value))
+			}
+		}
+
+		if card.Birthday != nil {
+			if version == Version3 {
+				lines = append(lines, "BDAY:"+card.Birthday.Format("2006-01-02"))
+			} else {
+				lines = append(lines, "BDAY:"+card.Birthday.Format("20060102"))
+			}
+		}
+
+		for _, address := range card.Addresses {
+			// Addresses are stored as free text, so they are exported as the street
+			// component, and as a label too if the version supports it
+			params := typeParam(address.Types)
+			if version == Version3 {
+				lines = append(
+					lines,
+					"ADR"+params+":;;"+escape(address.Value)+";;;;",
+					"LABEL"+params+":"+escape(address.Value),
+				)
+			} else {
+				lines = append(lines, "ADR"+params+":;;"+escape(address.Value)+";;;;")
+			}
+		}
+
+		for _, website := range card.Websites {
+			lines = append(lines, "URL"+typeParam(website.Types)+":"+escape(website.Value))
+		}
+
+		if card.Notes != "" {
+			lines = append(lines, "NOTE:"+escape(card.Notes))
+		}
+
+		if card.Pronouns != "" {
+			if version == Version3 {
+				lines = append(lines, "X-PRONOUNS:"+escape(card.Pronouns))
+			} else {
+				lines = append(lines, "PRONOUNS:"+escape(card.Pronouns))
+			}
+		}
+
+		lines = append(lines, "END:VCARD")
+
+		for _, line := range lines {
+			if _, err := bw.WriteString(fold(line)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Decode reads all vCards from `r`
+func Decode(r io.Reader) ([]Card, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cards = []Card{}
+
+		card    *Card
+		version string
+		fn      string
+		labels  int
+	)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "BEGIN":
+			if strings.EqualFold(value, "VCARD") {
+				card = &Card{}
+				version = ""
+				fn = ""
+				labels = 0
+			}
+
+			continue
+
+		case "END":
+			if !strings.EqualFold(value, "VCARD") || card == nil {
+				continue
+			}
+
+			if version != "" && version != Version3 && version != Version4 {
+				return nil, errors.Join(ErrUnsupportedVersion, fmt.Errorf("version %v", version))
+			}
+
+			if card.FirstName == "" && card.LastName == "" {
+				// Fall back to the formatted name if the card has no structured name
+				parts := strings.Fields(fn)
+				if len(parts) == 0 {
+					return nil, ErrMissingName
+				}
+
+				card.FirstName = parts[0]
+				card.LastName = strings.Join(parts[1:], " ")
+			}
+
+			cards = append(cards, *card)
+			card = nil
+
+			continue
+		}
+
+		if card == nil {
+			continue
+		}
+
+		switch name {
+		case "VERSION":
+			version = value
+
+		case "UID":
+			card.UID = unescape(value)
+
+		case "FN":
+			fn = unescape(value)
+
+		case "N":
+			components := splitComponents(value)
+			if len(components) > 0 {
+				card.LastName = components[0]
+			}
+
+			if len(components) > 1 {
+				card.FirstName = components[1]
+			}
+
+		case "NICKNAME":
+			if card.Nickname == "" {
+				card.Nickname = strings.Split(unescape(value), ",")[0]
+			}
+
+		case "EMAIL":
+			email := Property{
+				Types: parseTypes(params),
+				Value: unescape(value),
+			}
+
+			// Preferred email addresses are listed first, since the first email
+			// address is the preferred one when encoding
+			if slices.Contains(email.Types, "pref") || strings.Contains(strings.ToUpper(params), "PREF=") {
+				card.Emails = append([]Property{email}, card.Emails...)
+			} else {
+				card.Emails = append(card.Emails, email)
+			}
+
+		case "TEL":
+			number := unescape(value)
+			if rest, ok := strings.CutPrefix(number, "tel:"); ok {
+				number = rest
+			}
+
+			card.Phones = append(card.Phones, Property{
+				Types: parseTypes(params),
+				Value: number,
+			})
+
+		case "URL":
+			card.Websites = append(card.Websites, Property{
+				Types: parseTypes(params),
+				Value: unescape(value),
+			})
+
+		case "BDAY":
+			if birthday, ok := parseDate(value); ok {
+				card.Birthday = &birthday
+			}
+
+		case "ADR":
+			components := []string{}
+			for _, component := range splitComponents(value) {
+				if strings.TrimSpace(component) != "" {
+					components = append(components, component)
+				}
+			}
+
+			card.Addresses = append(card.Addresses, Property{
+				Types: parseTypes(params),
+				Value: strings.Join(components, "\n"),
+			})
+
+		case "LABEL":
+			// vCard 3.0 labels contain the formatted address of the `ADR` property at
+			// the same position, which is preferred over joining its components
+			if labels < len(card.Addresses) {
+				card.Addresses[labels].Value = unescape(value)
+			} else {
+				card.Addresses = append(card.Addresses, Property{
+					Types: parseTypes(params),
+					Value: unescape(value),
+				})
+			}
+
+			labels++
+
+		case "NOTE":
+			card.Notes = unescape(value)
+
+		case "PRONOUNS", "X-PRONOUNS":
+			card.Pronouns = unescape(value)
+		}
+	}
+
+	if card != nil {
+		return nil, ErrUnterminatedCard
+	}
+
+	return cards, nil
+}
+
+func parseLine(line string) (name, params, value string, ok bool) {
+	colon := strings.Index(line, ":")
+	if colon == -1 {
+		return "", "", "", false
+	}
+
+	head := line[:colon]
+	value = line[colon+1:]
+
+	name, params, _ = strings.Cut(head, ";")
+
+	// Properties can be grouped, i.e. `item1.EMAIL`
+	if dot := strings.LastIndex(name, "."); dot != -1 {
+		name = name[dot+1:]
+	}
+
+	return strings.ToUpper(name), params, value, true
+}
+
+// typeParam returns the `TYPE` parameter for the types, or an empty string if
+// there are no types
+func typeParam(types []string) string {
+	if len(types) == 0 {
+		return ""
+	}
+
+	return ";TYPE=" + strings.Join(types, ",")
+}
+
+// parseTypes returns the lowercased values of the `TYPE` parameters, which can
+// be separated by commas or repeated, and of the parameters without a name,
+// which vCard 2.1 uses for types, i.e. `TEL;HOME;VOICE`
+func parseTypes(params string) []string {
+	types := []string{}
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			value = name
+		} else if !strings.EqualFold(name, "TYPE") {
+			continue
+		}
+
+		for _, t := range strings.Split(strings.Trim(value, `"`), ",") {
+			if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+
+	return types
+}
+
+// parseDate parses a full date; dates without a year, i.e. `--0412`, are
+// rejected since a birthday can't be stored without one
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	if len(value) >= 10 {
+		if t, err := time.Parse("2006-01-02", value[:10]); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		",", `\,`,
+		";", `\;`,
+	).Replace(value)
+}
+
+func unescape(value string) string {
+	var (
+		b       strings.Builder
+		escaped = false
+	)
+	for _, r := range value {
+		if escaped {
+			switch r {
+			case 'n', 'N':
+				b.WriteRune('\n')
+			default:
+				b.WriteRune(r)
+			}
+
+			escaped = false
+
+			continue
+		}
+
+		if r == '\\' {
+			escaped = true
+
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func splitComponents(value string) []string {
+	var (
+		components = []string{}
+		current    strings.Builder
+		escaped    = false
+	)
+	for _, r := range value {
+		if escaped {
+			current.WriteRune('\\')
+			current.WriteRune(r)
+
+			escaped = false
+
+			continue
+		}
+
+		switch r {
+		case '\\':
+			escaped = true
+
+		case ';':
+			components = append(components, unescape(current.String()))
+			current.Reset()
+
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	return append(components, unescape(current.String()))
+}
+
+func fold(line string) string {
+	var b strings.Builder
+
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > maxLineLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+
+		b.WriteRune(r)
+		length += size
+	}
+
+	b.WriteString("\r\n")
+
+	return b.String()
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lines := []string{}
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}